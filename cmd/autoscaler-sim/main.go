@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// autoscaler-sim replays a traffic trace through the autoscaler of a single
+// revision and prints the resulting pod count timeline. It allows tuning
+// config-autoscaler and the autoscaling annotations against real traffic
+// before changing them in a cluster.
+//
+// Example:
+//
+//	autoscaler-sim -load '0,0;30s,100;3m,10' -pod-ready-delay 10s \
+//	    -set stable-window=2m -annotation autoscaling.knative.dev/target=50
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/config"
+)
+
+// keyValues is a repeatable key=value flag.
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	pairs := make([]string, 0, len(kv))
+	for k, v := range kv {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("want key=value, got %q", s)
+	}
+	kv[parts[0]] = parts[1]
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	var (
+		settings    = keyValues{}
+		annotations = keyValues{}
+	)
+	fs := flag.NewFlagSet("autoscaler-sim", flag.ContinueOnError)
+	fs.Var(settings, "set", "A config-autoscaler `key=value` setting, e.g. stable-window=2m. Repeatable.")
+	fs.Var(annotations, "annotation", "A revision `key=value` annotation, e.g. autoscaling.knative.dev/target=50. Repeatable.")
+	traceFile := fs.String("trace", "", "The file with the trace, one offset,concurrency[,rps] point per line; - reads stdin.")
+	inline := fs.String("load", "", "The trace inline, with the points separated by semicolons, e.g. '0,0;1m,100'.")
+	cc := fs.Int64("container-concurrency", 0, "The containerConcurrency of the revision.")
+	readyDelay := fs.Duration("pod-ready-delay", 10*time.Second, "How long a new pod takes to become ready.")
+	duration := fs.Duration("duration", 0, "How long to simulate; defaults to the end of the trace plus twice the stable window and the scale to zero grace period.")
+	asCSV := fs.Bool("csv", false, "Print the timeline as CSV.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tr, err := loadTrace(*traceFile, *inline, stdin)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.NewConfigFromMap(settings)
+	if err != nil {
+		return fmt.Errorf("invalid config-autoscaler settings: %w", err)
+	}
+	ctx := context.Background()
+	if err := autoscaling.ValidateAnnotations(ctx, cfg, annotations); err != nil {
+		return fmt.Errorf("invalid annotations: %w", err)
+	}
+
+	pa := &v1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "sim",
+			Name:        "sim",
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.PodAutoscalerSpec{
+			ContainerConcurrency: *cc,
+			Reachability:         v1alpha1.ReachabilityReachable,
+		},
+	}
+	if pa.Class() != autoscaling.KPA {
+		return fmt.Errorf("only the %s class can be simulated, got %s", autoscaling.KPA, pa.Class())
+	}
+
+	sim := &simulation{
+		config:     cfg,
+		pa:         pa,
+		trace:      tr,
+		readyDelay: *readyDelay,
+		duration:   *duration,
+	}
+	if sim.duration <= 0 {
+		sim.duration = tr.end() + 2*cfg.StableWindow + cfg.ScaleToZeroGracePeriod
+	}
+
+	if *asCSV {
+		return printCSV(sim, stdout)
+	}
+	return printTable(sim, stdout)
+}
+
+func loadTrace(file, inline string, stdin io.Reader) (trace, error) {
+	switch {
+	case file != "" && inline != "":
+		return nil, fmt.Errorf("only one of -trace and -load may be set")
+	case inline != "":
+		return parseInlineTrace(inline)
+	case file == "-":
+		return parseTrace(stdin)
+	case file != "":
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return parseTrace(f)
+	}
+	return nil, fmt.Errorf("one of -trace and -load must be set")
+}
+
+var header = []string{"TIME", "CONCURRENCY", "RPS", "DESIRED", "WANT", "READY", "PENDING", "PANIC", "EBC"}
+
+func (s sample) fields() []string {
+	return []string{
+		s.Offset.String(),
+		strconv.FormatFloat(s.Concurrency, 'f', -1, 64),
+		strconv.FormatFloat(s.RPS, 'f', -1, 64),
+		strconv.Itoa(int(s.Desired)),
+		strconv.Itoa(int(s.Want)),
+		strconv.Itoa(s.Ready),
+		strconv.Itoa(s.Pending),
+		strconv.FormatBool(s.Panic),
+		strconv.Itoa(int(s.ExcessBurstCapacity)),
+	}
+}
+
+func printTable(sim *simulation, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	if err := sim.run(func(s sample) {
+		fmt.Fprintln(tw, strings.Join(s.fields(), "\t"))
+	}); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func printCSV(sim *simulation, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	if err := sim.run(func(s sample) {
+		cw.Write(s.fields())
+	}); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
+	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+)
+
+const (
+	// statInterval is how often the simulated queue-proxies report stats.
+	statInterval = time.Second
+	// tickInterval is how often the simulated decider is ticked, like the
+	// MultiScaler does.
+	tickInterval = 2 * time.Second
+)
+
+// simulation replays a trace through the autoscaler of a single revision.
+type simulation struct {
+	config *autoscalerconfig.Config
+	pa     *v1alpha1.PodAutoscaler
+	trace  trace
+
+	// readyDelay is how long a new pod takes to become ready.
+	readyDelay time.Duration
+	// duration is how long the simulation runs.
+	duration time.Duration
+}
+
+// sample is the state of the simulated revision at the end of a tick.
+type sample struct {
+	Offset      time.Duration
+	Concurrency float64
+	RPS         float64
+	// Desired is the pod count recommended by the autoscaler, -1 if none.
+	Desired int32
+	// Want is the pod count after applying the scale bounds and the
+	// scale to zero grace period.
+	Want    int32
+	Ready   int
+	Pending int
+	Panic   bool
+	// ExcessBurstCapacity as computed by the autoscaler.
+	ExcessBurstCapacity int32
+}
+
+// pods simulates the pods of the revision. It implements
+// resources.EndpointsCounter for the autoscaler.
+type pods struct {
+	ready int
+	// pending holds the times the pending pods become ready, in order.
+	pending []time.Time
+}
+
+func (p *pods) ReadyCount() (int, error) {
+	return p.ready, nil
+}
+
+func (p *pods) NotReadyCount() (int, error) {
+	return len(p.pending), nil
+}
+
+// promote makes the pending pods whose ready time has passed ready.
+func (p *pods) promote(now time.Time) {
+	for len(p.pending) > 0 && !p.pending[0].After(now) {
+		p.pending = p.pending[1:]
+		p.ready++
+	}
+}
+
+// scale changes the number of pods. New pods become ready after readyDelay;
+// scaling down removes the pending pods first.
+func (p *pods) scale(want int, now time.Time, readyDelay time.Duration) {
+	total := p.ready + len(p.pending)
+	for ; total < want; total++ {
+		p.pending = append(p.pending, now.Add(readyDelay))
+	}
+	if drop := total - want; drop > 0 {
+		n := drop
+		if n > len(p.pending) {
+			n = len(p.pending)
+		}
+		p.pending = p.pending[:len(p.pending)-n]
+		p.ready -= drop - n
+	}
+}
+
+// run runs the simulation, calling emit with the state of the revision after
+// every tick of the decider.
+func (s *simulation) run(emit func(sample)) error {
+	ctx := logging.WithLogger(context.Background(), zap.NewNop().Sugar())
+	key := types.NamespacedName{Namespace: s.pa.Namespace, Name: s.pa.Name}
+
+	decider := kparesources.MakeDecider(ctx, s.pa, s.config)
+	// An empty scrape target disables scraping, so the collector only sees
+	// the stats recorded below.
+	collector := metrics.NewMetricCollector(
+		func(*v1alpha1.Metric, *zap.SugaredLogger) (metrics.StatsScraper, error) {
+			return nil, nil
+		}, logging.FromContext(ctx))
+	if err := collector.CreateOrUpdate(aresources.MakeMetric(s.pa, "", s.config)); err != nil {
+		return fmt.Errorf("failed to create metric collection: %w", err)
+	}
+	defer collector.Delete(key.Namespace, key.Name)
+
+	p := &pods{ready: int(decider.Spec.InitialScale)}
+	scaler, err := scaling.NewForAlgorithm(ctx, key.Namespace, key.Name, collector, p, &decider.Spec)
+	if err != nil {
+		return err
+	}
+	min, max := s.pa.ScaleBounds(s.config)
+
+	// The simulated clock starts at the current time, since the autoscaler
+	// starts its panic window at the wall clock time when it's created with
+	// more than one pod. Samples only report offsets from the start, so runs
+	// stay reproducible.
+	start := time.Now()
+	var zeroSince time.Time
+	for offset := time.Duration(0); offset <= s.duration; offset += statInterval {
+		now := start.Add(offset)
+		p.promote(now)
+
+		load := s.trace.at(offset)
+		recordLoad(collector, key, now, load, p.ready)
+
+		if offset%tickInterval != 0 {
+			continue
+		}
+		result := scaler.Scale(ctx, now)
+		smp := sample{
+			Offset:      offset,
+			Concurrency: load.concurrency,
+			RPS:         load.rps,
+			Desired:     -1,
+		}
+		want := int32(p.ready + len(p.pending))
+		if result.ScaleValid {
+			smp.Desired = result.DesiredPodCount
+			smp.ExcessBurstCapacity = result.ExcessBurstCapacity
+			want = s.bound(result.DesiredPodCount, min, max)
+		}
+		if dr, ok := scaler.(scaling.DecisionRecorder); ok {
+			if ds := dr.Decisions(); len(ds) > 0 {
+				smp.Panic = ds[len(ds)-1].InPanic
+			}
+		}
+
+		// Like the KPA, only scale to zero once the desired scale has
+		// been zero for the grace period.
+		if want == 0 {
+			if zeroSince.IsZero() {
+				zeroSince = now
+			}
+			if now.Sub(zeroSince) < s.config.ScaleToZeroGracePeriod {
+				want = int32(p.ready + len(p.pending))
+			}
+		} else {
+			zeroSince = time.Time{}
+		}
+
+		p.scale(int(want), now, s.readyDelay)
+		smp.Want, smp.Ready, smp.Pending = want, p.ready, len(p.pending)
+		emit(smp)
+	}
+	return nil
+}
+
+// bound applies the scale bounds of the revision to the desired scale.
+func (s *simulation) bound(desired, min, max int32) int32 {
+	if desired < min {
+		desired = min
+	}
+	if max > 0 && desired > max {
+		desired = max
+	}
+	if desired == 0 && !s.config.EnableScaleToZero {
+		desired = 1
+	}
+	return desired
+}
+
+// recordLoad records the stats the queue-proxies of the ready pods would
+// report for the load, which is spread evenly across them. Without ready
+// pods the requests are buffered by the activator, which reports them instead.
+func recordLoad(collector *metrics.MetricCollector, key types.NamespacedName, now time.Time, load loadPoint, ready int) {
+	if ready == 0 {
+		collector.Record(key, now, metrics.Stat{
+			PodName:                   "activator",
+			AverageConcurrentRequests: load.concurrency,
+			RequestCount:              load.rps,
+		})
+		return
+	}
+	for i := 0; i < ready; i++ {
+		collector.Record(key, now, metrics.Stat{
+			PodName:                   fmt.Sprintf("%s-%d", key.Name, i),
+			AverageConcurrentRequests: load.concurrency / float64(ready),
+			RequestCount:              load.rps / float64(ready),
+		})
+	}
+}
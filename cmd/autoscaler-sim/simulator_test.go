@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPods(t *testing.T) {
+	now := time.Unix(0, 0)
+	p := &pods{ready: 1}
+
+	p.scale(3, now, 10*time.Second)
+	if p.ready != 1 || len(p.pending) != 2 {
+		t.Fatalf("After scale up ready = %d, pending = %d, want: 1, 2", p.ready, len(p.pending))
+	}
+	p.promote(now.Add(9 * time.Second))
+	if p.ready != 1 {
+		t.Errorf("Pods became ready early, ready = %d", p.ready)
+	}
+	p.promote(now.Add(10 * time.Second))
+	if p.ready != 3 || len(p.pending) != 0 {
+		t.Fatalf("After ready delay ready = %d, pending = %d, want: 3, 0", p.ready, len(p.pending))
+	}
+
+	// Pending pods are removed first.
+	p.scale(5, now, time.Minute)
+	p.scale(2, now, time.Minute)
+	if p.ready != 2 || len(p.pending) != 0 {
+		t.Errorf("After scale down ready = %d, pending = %d, want: 2, 0", p.ready, len(p.pending))
+	}
+}
+
+func TestRun(t *testing.T) {
+	// 500 concurrent requests for 2 minutes with a target of 10 per pod,
+	// then nothing.
+	var out bytes.Buffer
+	if err := run([]string{
+		"-csv",
+		"-load", "0,500;2m,0",
+		"-pod-ready-delay", "20s",
+		"-set", "scale-to-zero-grace-period=10s",
+		"-annotation", "autoscaling.knative.dev/target=10",
+		"-annotation", "autoscaling.knative.dev/target-utilization-percentage=100",
+		"-annotation", "autoscaling.knative.dev/maxScale=40",
+	}, nil, &out); err != nil {
+		t.Fatal("run() =", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if got, want := lines[0], strings.Join(header, ","); got != want {
+		t.Errorf("header = %s, want: %s", got, want)
+	}
+	var (
+		maxReady  int
+		sawPanic  bool
+		last      []string
+		readyAt1m string
+	)
+	for _, l := range lines[1:] {
+		last = strings.Split(l, ",")
+		ready := atoi(t, last[5])
+		if ready > maxReady {
+			maxReady = ready
+		}
+		sawPanic = sawPanic || last[7] == "true"
+		if last[0] == "1m0s" {
+			readyAt1m = last[5]
+		}
+	}
+	if maxReady != 40 {
+		t.Errorf("Max ready pods = %d, want: 40 (maxScale)", maxReady)
+	}
+	if readyAt1m != "40" {
+		t.Errorf("Ready pods at 1m = %s, want: 40", readyAt1m)
+	}
+	if !sawPanic {
+		t.Error("Expected the autoscaler to panic on the burst")
+	}
+	if last[5] != "0" || last[6] != "0" {
+		t.Errorf("Final pods ready = %s, pending = %s, want: scaled to zero", last[5], last[6])
+	}
+}
+
+func TestRunInitialScaleLeavesPanic(t *testing.T) {
+	// Starting with more than one pod starts the autoscaler in panic mode,
+	// which must end once the panic window passed on the simulated clock.
+	var out bytes.Buffer
+	if err := run([]string{
+		"-csv",
+		"-load", "0,1",
+		"-duration", "3m",
+		"-annotation", "autoscaling.knative.dev/initialScale=4",
+		"-annotation", "autoscaling.knative.dev/target=10",
+	}, nil, &out); err != nil {
+		t.Fatal("run() =", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if first := strings.Split(lines[1], ","); first[7] != "true" {
+		t.Errorf("First sample panic = %s, want: true", first[7])
+	}
+	last := strings.Split(lines[len(lines)-1], ",")
+	if last[7] != "false" {
+		t.Errorf("Final panic = %s, want: false", last[7])
+	}
+	if got := atoi(t, last[5]); got != 1 {
+		t.Errorf("Final ready pods = %d, want: 1", got)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no trace":          {},
+		"both traces":       {"-load", "0,1", "-trace", "-"},
+		"bad setting":       {"-load", "0,1", "-set", "stable-window=forever"},
+		"bad annotation":    {"-load", "0,1", "-annotation", "autoscaling.knative.dev/minScale=-1"},
+		"not key value":     {"-load", "0,1", "-set", "stable-window"},
+		"hpa class":         {"-load", "0,1", "-annotation", "autoscaling.knative.dev/class=hpa.autoscaling.knative.dev"},
+		"unknown algorithm": {"-load", "0,1", "-annotation", "autoscaling.knative.dev/algorithm=magic"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := run(args, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+				t.Error("run() succeeded unexpectedly")
+			}
+		})
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-trace", "-", "-duration", "10s"}, strings.NewReader("0,1\n"), &out); err != nil {
+		t.Fatal("run() =", err)
+	}
+	// The header and a row per tick at 0s, 2s, ..., 10s.
+	if got, want := strings.Count(out.String(), "\n"), 7; got != want {
+		t.Errorf("Got %d lines, want: %d:\n%s", got, want, out.String())
+	}
+}
+
+func atoi(t *testing.T, s string) int {
+	t.Helper()
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("%q is not a number: %v", s, err)
+	}
+	return i
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadPoint is the load of the revision from offset until the next point.
+type loadPoint struct {
+	offset      time.Duration
+	concurrency float64
+	rps         float64
+}
+
+// trace is a step function of the revision load, ordered by offset.
+type trace []loadPoint
+
+// parseTrace parses a trace with one `offset,concurrency[,rps]` point per line.
+// The offset is either a duration, e.g. 1m30s, or a number of seconds.
+// If rps is omitted it is presumed to equal concurrency, i.e. requests take
+// a second each. Empty lines and lines starting with # are ignored.
+func parseTrace(r io.Reader) (trace, error) {
+	var (
+		ret  trace
+		line int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		fields := strings.Split(l, ",")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: want offset,concurrency[,rps], got %q", line, l)
+		}
+		offset, err := parseOffset(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(ret) > 0 && offset <= ret[len(ret)-1].offset {
+			return nil, fmt.Errorf("line %d: offset %v is not after the previous one", line, offset)
+		}
+		p := loadPoint{offset: offset}
+		if p.concurrency, err = parseLoad(fields[1]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid concurrency: %w", line, err)
+		}
+		p.rps = p.concurrency
+		if len(fields) == 3 {
+			if p.rps, err = parseLoad(fields[2]); err != nil {
+				return nil, fmt.Errorf("line %d: invalid rps: %w", line, err)
+			}
+		}
+		ret = append(ret, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("trace is empty")
+	}
+	return ret, nil
+}
+
+// parseInlineTrace parses a trace with the points separated by semicolons.
+func parseInlineTrace(s string) (trace, error) {
+	return parseTrace(strings.NewReader(strings.ReplaceAll(s, ";", "\n")))
+}
+
+func parseOffset(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if secs < 0 {
+			return 0, fmt.Errorf("offset %q must not be negative", s)
+		}
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("offset %q must not be negative", s)
+	}
+	return d, nil
+}
+
+func parseLoad(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("%v must not be negative", v)
+	}
+	return v, nil
+}
+
+// at returns the load at the given offset. The load is zero before the first point.
+func (t trace) at(offset time.Duration) loadPoint {
+	ret := loadPoint{offset: offset}
+	for _, p := range t {
+		if p.offset > offset {
+			break
+		}
+		ret.concurrency, ret.rps = p.concurrency, p.rps
+	}
+	return ret
+}
+
+// end returns the offset of the last point.
+func (t trace) end() time.Duration {
+	return t[len(t)-1].offset
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    trace
+		wantErr bool
+	}{{
+		name: "durations and seconds",
+		in:   "# offset,concurrency,rps\n0,1\n\n1m30s, 10, 20\n120.5,0\n",
+		want: trace{
+			{offset: 0, concurrency: 1, rps: 1},
+			{offset: 90 * time.Second, concurrency: 10, rps: 20},
+			{offset: 120500 * time.Millisecond},
+		},
+	}, {
+		name:    "empty",
+		in:      "# nothing\n",
+		wantErr: true,
+	}, {
+		name:    "too few fields",
+		in:      "10s",
+		wantErr: true,
+	}, {
+		name:    "bad offset",
+		in:      "soon,1",
+		wantErr: true,
+	}, {
+		name:    "negative offset",
+		in:      "-1s,1",
+		wantErr: true,
+	}, {
+		name:    "negative load",
+		in:      "0,-1",
+		wantErr: true,
+	}, {
+		name:    "out of order",
+		in:      "10s,1\n5s,1",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTrace(strings.NewReader(test.in))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseTrace() = %v, wantErr = %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want, cmp.AllowUnexported(loadPoint{})) {
+				t.Error("parseTrace() (-want, +got):", cmp.Diff(test.want, got, cmp.AllowUnexported(loadPoint{})))
+			}
+		})
+	}
+}
+
+func TestTraceAt(t *testing.T) {
+	tr, err := parseInlineTrace("10s,5;20s,7,3")
+	if err != nil {
+		t.Fatal("parseInlineTrace() =", err)
+	}
+	for _, test := range []struct {
+		at               time.Duration
+		concurrency, rps float64
+	}{
+		{at: 0},
+		{at: 10 * time.Second, concurrency: 5, rps: 5},
+		{at: 19 * time.Second, concurrency: 5, rps: 5},
+		{at: time.Hour, concurrency: 7, rps: 3},
+	} {
+		got := tr.at(test.at)
+		if got.concurrency != test.concurrency || got.rps != test.rps {
+			t.Errorf("at(%v) = %v/%v, want: %v/%v", test.at, got.concurrency, got.rps, test.concurrency, test.rps)
+		}
+	}
+	if got, want := tr.end(), 20*time.Second; got != want {
+		t.Errorf("end() = %v, want: %v", got, want)
+	}
+}
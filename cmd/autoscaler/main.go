@@ -29,12 +29,15 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
+	"knative.dev/pkg/hash"
 	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/injection/sharedmain"
 	"knative.dev/pkg/leaderelection"
 
@@ -49,8 +52,10 @@ import (
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler/bucket"
+	"knative.dev/serving/pkg/autoscaler/debug"
 	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/autoscaler/scaling"
+	"knative.dev/serving/pkg/autoscaler/statestore"
 	"knative.dev/serving/pkg/autoscaler/statforwarder"
 	"knative.dev/serving/pkg/autoscaler/statserver"
 	smetrics "knative.dev/serving/pkg/metrics"
@@ -61,6 +66,7 @@ import (
 
 const (
 	statsServerAddr = ":8080"
+	debugServerAddr = ":8081"
 	statsBufferLen  = 1000
 	component       = "autoscaler"
 	controllerNum   = 2
@@ -125,7 +131,7 @@ func main() {
 	podLister := podinformer.Get(ctx).Lister()
 
 	collector := asmetrics.NewMetricCollector(
-		statsScraperFactoryFunc(podLister), logger)
+		statsScraperFactoryFunc(podLister, dynamicclient.Get(ctx)), logger)
 
 	// Set up scalers.
 	// uniScalerFactory depends endpointsInformer to be set.
@@ -133,7 +139,7 @@ func main() {
 		uniScalerFactoryFunc(podLister, collector), logger)
 
 	controllers := []*controller.Impl{
-		kpa.NewController(ctx, cmw, multiScaler),
+		kpa.NewController(ctx, cmw, multiScaler, collector),
 		metric.NewController(ctx, cmw, collector),
 	}
 
@@ -152,13 +158,18 @@ func main() {
 	// accept is the func to call when this pod owns the Revision for this StatMessage.
 	accept := func(sm asmetrics.StatMessage) {
 		collector.Record(sm.Key, time.Unix(sm.Stat.Timestamp, 0), sm.Stat)
-		multiScaler.Poke(sm.Key, sm.Stat)
+		// Only the activators see the requests of revisions scaled to zero.
+		if !sm.Stat.PushedByPod {
+			multiScaler.Poke(sm.Key, sm.Stat)
+		}
 	}
 
 	var f *statforwarder.Forwarder
-	if b, bs, err := leaderelection.NewStatefulSetBucketAndSet(int(cc.Buckets)); err == nil {
+	var bs *hash.BucketSet
+	if b, sbs, err := leaderelection.NewStatefulSetBucketAndSet(int(cc.Buckets)); err == nil {
 		logger.Info("Running with StatefulSet leader election")
 		ctx = leaderelection.WithStatefulSetElectorBuilder(ctx, cc, b)
+		bs = sbs
 		f = statforwarder.New(ctx, bs)
 		if err := statforwarder.StatefulSetBasedProcessor(ctx, f, accept); err != nil {
 			logger.Fatalw("Failed to set up statefulset processors", zap.Error(err))
@@ -166,12 +177,17 @@ func main() {
 	} else {
 		logger.Info("Running with Standard leader election")
 		ctx = leaderelection.WithStandardLeaderElectorBuilder(ctx, kubeClient, cc)
-		f = statforwarder.New(ctx, bucket.AutoscalerBucketSet(cc.Buckets))
+		bs = bucket.AutoscalerBucketSet(cc.Buckets)
+		f = statforwarder.New(ctx, bs)
 		if err := statforwarder.LeaseBasedProcessor(ctx, f, accept); err != nil {
 			logger.Fatalw("Failed to set up lease tracking", zap.Error(err))
 		}
 	}
 
+	// Persist the state of the owned buckets, so that the pod acquiring
+	// one of them resumes from it.
+	go statestore.New(ctx, bs, f.IsBucketOwner, collector, multiScaler).Run(ctx.Done())
+
 	// Set up a statserver.
 	statsServer := statserver.New(statsServerAddr, statsCh, logger, f.IsBucketOwner)
 
@@ -191,9 +207,20 @@ func main() {
 
 	profilingServer := profiling.NewServer(profilingHandler)
 
+	// Set up a debug server, exposing the state of the revisions to the
+	// callers authorized to get its path.
+	debugMux := http.NewServeMux()
+	debugMux.Handle(debug.Path, debug.NewHandler(logger, debug.NewKubeAuthorizer(kubeClient),
+		multiScaler, collector, f))
+	debugServer := &http.Server{
+		Addr:    debugServerAddr,
+		Handler: debugMux,
+	}
+
 	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(statsServer.ListenAndServe)
 	eg.Go(profilingServer.ListenAndServe)
+	eg.Go(debugServer.ListenAndServe)
 
 	// This will block until either a signal arrives or one of the grouped functions
 	// returns an error.
@@ -201,6 +228,7 @@ func main() {
 
 	statsServer.Shutdown(5 * time.Second)
 	profilingServer.Shutdown(context.Background())
+	debugServer.Shutdown(context.Background())
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Errorw("Error while running server", zap.Error(err))
@@ -224,12 +252,12 @@ func uniScalerFactoryFunc(podLister corev1listers.PodLister,
 		ctx := smetrics.RevisionContext(decider.Namespace, serviceName, configName, revisionName)
 
 		podAccessor := resources.NewPodAccessor(podLister, decider.Namespace, revisionName)
-		return scaling.New(ctx, decider.Namespace, decider.Name, metricClient,
-			podAccessor, &decider.Spec), nil
+		return scaling.NewForAlgorithm(ctx, decider.Namespace, decider.Name, metricClient,
+			podAccessor, &decider.Spec)
 	}
 }
 
-func statsScraperFactoryFunc(podLister corev1listers.PodLister) asmetrics.StatsScraperFactory {
+func statsScraperFactoryFunc(podLister corev1listers.PodLister, dynamicClient dynamic.Interface) asmetrics.StatsScraperFactory {
 	return func(metric *av1alpha1.Metric, logger *zap.SugaredLogger) (asmetrics.StatsScraper, error) {
 		if metric.Spec.ScrapeTarget == "" {
 			return nil, nil
@@ -241,7 +269,8 @@ func statsScraperFactoryFunc(podLister corev1listers.PodLister) asmetrics.StatsS
 		}
 
 		podAccessor := resources.NewPodAccessor(podLister, metric.Namespace, revisionName)
-		return asmetrics.NewStatsScraper(metric, revisionName, podAccessor, logger), nil
+		scraper := asmetrics.NewStatsScraper(metric, revisionName, podAccessor, logger)
+		return asmetrics.NewResourceScraper(scraper, metric, revisionName, dynamicClient, podLister), nil
 	}
 }
 
@@ -33,9 +33,10 @@ var kubeInformer = kubeinformers.NewSharedInformerFactory(fakek8s.NewSimpleClien
 
 func TestUniscalerFactoryFailures(t *testing.T) {
 	tests := []struct {
-		name   string
-		labels map[string]string
-		want   string
+		name      string
+		labels    map[string]string
+		algorithm string
+		want      string
 	}{{
 		name:   "nil labels",
 		labels: nil,
@@ -60,6 +61,15 @@ func TestUniscalerFactoryFailures(t *testing.T) {
 			serving.RevisionLabelKey: "bamba",
 		},
 		want: fmt.Sprintf("label %q not found or empty in Decider", serving.ConfigurationLabelKey),
+	}, {
+		name: "unknown algorithm",
+		labels: map[string]string{
+			serving.ServiceLabelKey:       "la",
+			serving.ConfigurationLabelKey: "bamba",
+			serving.RevisionLabelKey:      "bamba",
+		},
+		algorithm: "magic",
+		want:      `unknown scaling algorithm "magic"`,
 	}}
 
 	uniScalerFactory := testUniScalerFactory()
@@ -74,6 +84,7 @@ func TestUniscalerFactoryFailures(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			decider.Labels = test.labels
+			decider.Spec.Algorithm = test.algorithm
 
 			_, err := uniScalerFactory(decider)
 			if err == nil {
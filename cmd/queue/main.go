@@ -43,10 +43,14 @@ import (
 	pkgnet "knative.dev/pkg/network"
 	"knative.dev/pkg/profiling"
 	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 	"knative.dev/pkg/tracing"
 	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/pkg/tracing/propagation/tracecontextb3"
+	"knative.dev/pkg/websocket"
+	"knative.dev/serving/pkg/activator"
 	activatorutil "knative.dev/serving/pkg/activator/util"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	pkghttp "knative.dev/serving/pkg/http"
 	"knative.dev/serving/pkg/http/handler"
 	"knative.dev/serving/pkg/logging"
@@ -59,6 +63,10 @@ import (
 const (
 	// reportingPeriod is the interval of time between reporting stats by queue proxy.
 	reportingPeriod = 1 * time.Second
+
+	// autoscalerPort is the port of the autoscaler stats server the stats
+	// are pushed to.
+	autoscalerPort = ":8080"
 )
 
 var (
@@ -69,12 +77,17 @@ var (
 )
 
 type config struct {
-	ContainerConcurrency   int    `split_words:"true" required:"true"`
-	QueueServingPort       string `split_words:"true" required:"true"`
-	UserPort               string `split_words:"true" required:"true"`
-	RevisionTimeoutSeconds int    `split_words:"true" required:"true"`
-	ServingReadinessProbe  string `split_words:"true" required:"true"`
-	EnableProfiling        bool   `split_words:"true"` // optional
+	ContainerConcurrency    int    `split_words:"true" required:"true"`
+	QueueServingPort        string `split_words:"true" required:"true"`
+	UserPort                string `split_words:"true" required:"true"`
+	RevisionTimeoutSeconds  int    `split_words:"true" required:"true"`
+	ServingReadinessProbe   string `split_words:"true" required:"true"`
+	EnableProfiling         bool   `split_words:"true"` // optional
+	ServingCustomMetric     string `split_words:"true"` // optional
+	ServingCustomMetricPath string `split_words:"true"` // optional
+	ServingCustomMetricPort string `split_words:"true"` // optional
+	ServingReportLatency    bool   `split_words:"true"` // optional
+	ServingPushStats        bool   `split_words:"true"` // optional
 
 	// Logging configuration
 	ServingLoggingConfig         string `split_words:"true" required:"true"`
@@ -157,20 +170,52 @@ func main() {
 	reportTicker := time.NewTicker(reportingPeriod)
 	defer reportTicker.Stop()
 
+	var latencies *queue.LatencyHistogram
+	if env.ServingReportLatency {
+		latencies = queue.NewLatencyHistogram()
+	}
+
+	// Push the stats to the autoscaler, the same way the activator does,
+	// rather than waiting to be scraped.
+	var statCh chan []asmetrics.StatMessage
+	if env.ServingPushStats {
+		autoscalerEndpoint := fmt.Sprintf("ws://%s.%s.svc.%s%s", "autoscaler", system.Namespace(), pkgnet.GetClusterDomainName(), autoscalerPort)
+		logger.Info("Pushing stats to Autoscaler at ", autoscalerEndpoint)
+		statSink := websocket.NewDurableSendingConnection(autoscalerEndpoint, logger)
+		defer statSink.Shutdown()
+		statCh = make(chan []asmetrics.StatMessage)
+		go activator.ReportStats(logger, statSink, statCh)
+	}
+	statKey := types.NamespacedName{Namespace: env.ServingNamespace, Name: env.ServingRevision}
+
 	stats := network.NewRequestStats(time.Now())
+	queueStats := queue.NewQueueStats(time.Now())
 	go func() {
 		for now := range reportTicker.C {
 			stat := stats.Report(now)
 			promStatReporter.Report(stat)
+			if latencies != nil {
+				protoStatReporter.ReportLatencyHistogram(latencies.Snapshot())
+			}
+			protoStatReporter.ReportQueue(queueStats.Report(now))
 			protoStatReporter.Report(stat)
+			if statCh != nil {
+				s := protoStatReporter.Stat()
+				s.PushedByPod = true
+				statCh <- []asmetrics.StatMessage{{Key: statKey, Stat: s}}
+			}
 		}
 	}()
 
+	if env.ServingCustomMetric != "" {
+		go scrapeCustomMetric(ctx, logger, env, protoStatReporter)
+	}
+
 	// Setup probe to run for checking user-application healthiness.
 	probe := buildProbe(logger, env.ServingReadinessProbe)
 	healthState := &health.State{}
 
-	mainServer := buildServer(ctx, env, healthState, probe, stats, logger)
+	mainServer := buildServer(ctx, env, healthState, probe, stats, queueStats, latencies, logger)
 	servers := map[string]*http.Server{
 		"main":    mainServer,
 		"admin":   buildAdminServer(logger, healthState),
@@ -256,6 +301,43 @@ func main() {
 	}
 }
 
+// scrapeCustomMetric periodically scrapes the custom metric the revision scales
+// on from the user container and hands it to the reporter. The last value is
+// kept if scraping fails.
+func scrapeCustomMetric(ctx context.Context, logger *zap.SugaredLogger, env config, reporter *queue.ProtobufStatsReporter) {
+	scraper := queue.NewCustomMetricScraper(env.ServingCustomMetric,
+		customMetricURL(env), &http.Client{Timeout: reportingPeriod})
+
+	ticker := time.NewTicker(reportingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v, err := scraper.Scrape(ctx)
+			if err != nil {
+				logger.Warnw("Failed to scrape custom metric", zap.String("metric", env.ServingCustomMetric), zap.Error(err))
+				continue
+			}
+			reporter.ReportCustomMetric(v)
+		}
+	}
+}
+
+// customMetricURL returns the URL the custom metric is scraped from, which
+// defaults to the CustomMetricPath on the port of the user container.
+func customMetricURL(env config) string {
+	path, port := env.ServingCustomMetricPath, env.ServingCustomMetricPort
+	if path == "" {
+		path = queue.CustomMetricPath
+	}
+	if port == "" {
+		port = env.UserPort
+	}
+	return "http://" + net.JoinHostPort("127.0.0.1", port) + path
+}
+
 func buildProbe(logger *zap.SugaredLogger, probeJSON string) *readiness.Probe {
 	coreProbe, err := readiness.DecodeProbe(probeJSON)
 	if err != nil {
@@ -265,7 +347,7 @@ func buildProbe(logger *zap.SugaredLogger, probeJSON string) *readiness.Probe {
 }
 
 func buildServer(ctx context.Context, env config, healthState *health.State, rp *readiness.Probe, stats *network.RequestStats,
-	logger *zap.SugaredLogger) *http.Server {
+	queueStats *queue.QueueStats, latencies *queue.LatencyHistogram, logger *zap.SugaredLogger) *http.Server {
 	target := &url.URL{
 		Scheme: "http",
 		Host:   net.JoinHostPort("127.0.0.1", env.UserPort),
@@ -294,9 +376,13 @@ func buildServer(ctx context.Context, env config, healthState *health.State, rp
 	if metricsSupported {
 		composedHandler = requestAppMetricsHandler(logger, composedHandler, breaker, env)
 	}
-	composedHandler = queue.ProxyHandler(breaker, stats, tracingEnabled, composedHandler)
+	composedHandler = queue.ProxyHandler(breaker, stats, queueStats, tracingEnabled, composedHandler)
 	composedHandler = queue.ForwardedShimHandler(composedHandler)
 	composedHandler = handler.NewTimeToFirstByteTimeoutHandler(composedHandler, "request timeout", handler.StaticTimeoutFunc(timeout))
+	if latencies != nil {
+		// Include the time spent queueing in the breaker, which is part of the latency clients see.
+		composedHandler = queue.NewLatencyHistogramHandler(composedHandler, latencies)
+	}
 
 	if metricsSupported {
 		composedHandler = requestMetricsHandler(logger, composedHandler, env)
@@ -146,7 +146,7 @@ func TestQueueTraceSpans(t *testing.T) {
 					Propagation: tracecontextb3.TraceContextB3Egress,
 				}
 
-				h := queue.ProxyHandler(breaker, network.NewRequestStats(time.Now()), true /*tracingEnabled*/, proxy)
+				h := queue.ProxyHandler(breaker, network.NewRequestStats(time.Now()), queue.NewQueueStats(time.Now()), true /*tracingEnabled*/, proxy)
 				h(writer, req)
 			} else {
 				h := health.ProbeHandler(healthState, tc.prober, true /* isAggressive*/, true /*tracingEnabled*/, nil)
@@ -188,3 +188,31 @@ func TestQueueTraceSpans(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomMetricURL(t *testing.T) {
+	tests := []struct {
+		name string
+		env  config
+		want string
+	}{{
+		name: "defaults",
+		env:  config{UserPort: "8080"},
+		want: "http://127.0.0.1:8080/metrics",
+	}, {
+		name: "path and port",
+		env: config{
+			UserPort:                "8080",
+			ServingCustomMetricPath: "/stats/prometheus",
+			ServingCustomMetricPort: "9090",
+		},
+		want: "http://127.0.0.1:9090/stats/prometheus",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := customMetricURL(test.env); got != test.want {
+				t.Errorf("customMetricURL() = %s, want: %s", got, test.want)
+			}
+		})
+	}
+}
@@ -35,20 +35,33 @@ import (
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
 	"knative.dev/serving/pkg/metrics"
+	"knative.dev/serving/pkg/queue"
 )
 
 const reportInterval = time.Second
 
 // revisionStats is a type that wraps information needed to calculate stats per revision.
 //
-// stats is thread-safe in itself and thus needs no extra synchronization.
+// stats and queue are thread-safe in themselves and thus need no extra synchronization.
 // firstRequest is only read/mutated in `report` which is guaranteed to be single-threaded
 // as it is driven by the report channel.
 type revisionStats struct {
 	stats        *network.RequestStats
+	queue        *queue.QueueStats
 	firstRequest float64
 }
 
+// queueStatsKey is the context key of the queue stats of the revision
+// of a request.
+type queueStatsKey struct{}
+
+// queueStatsFrom returns the stats of the requests buffered for the revision
+// of the request with the given context, or nil if it is not reported on.
+func queueStatsFrom(ctx context.Context) *queue.QueueStats {
+	qs, _ := ctx.Value(queueStatsKey{}).(*queue.QueueStats)
+	return qs
+}
+
 // ConcurrencyReporter reports stats based on incoming requests and ticks.
 type ConcurrencyReporter struct {
 	logger  *zap.SugaredLogger
@@ -78,12 +91,13 @@ func NewConcurrencyReporter(ctx context.Context, podName string, statCh chan []a
 }
 
 // handleEvent handles request events (in, out) and updates the respective stats.
-func (cr *ConcurrencyReporter) handleEvent(event network.ReqEvent) {
+func (cr *ConcurrencyReporter) handleEvent(event network.ReqEvent) *revisionStats {
 	stat, msg := cr.getOrCreateStat(event)
 	if msg != nil {
 		cr.statCh <- []asmetrics.StatMessage{*msg}
 	}
 	stat.stats.HandleEvent(event)
+	return stat
 }
 
 // getOrCreateStat gets a stat from the state if present.
@@ -110,6 +124,7 @@ func (cr *ConcurrencyReporter) getOrCreateStat(event network.ReqEvent) (*revisio
 
 	stat = &revisionStats{
 		stats:        network.NewRequestStats(event.Time),
+		queue:        queue.NewQueueStats(event.Time),
 		firstRequest: 1,
 	}
 	cr.stats[event.Key] = stat
@@ -150,6 +165,7 @@ func (cr *ConcurrencyReporter) computeReport(now time.Time) (msgs []asmetrics.St
 	msgs = make([]asmetrics.StatMessage, 0, len(cr.stats))
 	for key, stat := range cr.stats {
 		report := stat.stats.Report(now)
+		queueReport := stat.queue.Report(now)
 
 		firstAdj := stat.firstRequest
 		stat.firstRequest = 0.
@@ -172,6 +188,8 @@ func (cr *ConcurrencyReporter) computeReport(now time.Time) (msgs []asmetrics.St
 				PodName:                   cr.podName,
 				AverageConcurrentRequests: adjustedConcurrency,
 				RequestCount:              adjustedCount,
+				AverageQueuedRequests:     queueReport.AverageQueued,
+				AverageQueueWait:          float64(queueReport.AverageWait) / float64(time.Millisecond),
 			},
 		})
 	}
@@ -219,15 +237,16 @@ func (cr *ConcurrencyReporter) run(stopCh <-chan struct{}, reportCh <-chan time.
 }
 
 // Handler returns a handler that records requests coming in/being finished in the stats
-// machinery.
+// machinery. The stats of the requests buffered for the revision are made available
+// on the request's context.
 func (cr *ConcurrencyReporter) Handler(next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		revisionKey := util.RevIDFrom(r.Context())
-		cr.handleEvent(network.ReqEvent{Key: revisionKey, Type: network.ReqIn, Time: time.Now()})
+		stat := cr.handleEvent(network.ReqEvent{Key: revisionKey, Type: network.ReqIn, Time: time.Now()})
 		defer func() {
 			cr.handleEvent(network.ReqEvent{Key: revisionKey, Type: network.ReqOut, Time: time.Now()})
 		}()
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), queueStatsKey{}, stat.queue)))
 	}
 }
@@ -370,6 +370,32 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestStatsQueued(t *testing.T) {
+	cr, _, cancel := newTestReporter(t)
+	defer cancel()
+
+	start := time.Time{}
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// The request is buffered for half of the reporting period.
+	stat := cr.handleEvent(network.ReqEvent{Key: rev1, Type: network.ReqIn, Time: start})
+	stat.queue.Enqueue(start)
+	stat.queue.Dequeue(at(1), start)
+	cr.handleEvent(network.ReqEvent{Key: rev1, Type: network.ReqOut, Time: at(2)})
+
+	<-cr.statCh // Scale from 0.
+	got := cr.report(at(2))
+	if len(got) != 1 {
+		t.Fatalf("report() = %v, want 1 stat", got)
+	}
+	if got, want := got[0].Stat.AverageQueuedRequests, 0.5; got != want {
+		t.Errorf("AverageQueuedRequests = %v, want: %v", got, want)
+	}
+	if got, want := got[0].Stat.AverageQueueWait, 1.; got != want {
+		t.Errorf("AverageQueueWait = %v, want: %v", got, want)
+	}
+}
+
 func TestConcurrencyReporterRun(t *testing.T) {
 	cr, ctx, cancel := newTestReporter(t)
 	defer cancel()
@@ -423,7 +449,11 @@ func TestConcurrencyReporterHandler(t *testing.T) {
 		close(reportCh)
 	}()
 
-	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if queueStatsFrom(r.Context()) == nil {
+			t.Error("No queue stats in the request context")
+		}
+	})
 	handler := cr.Handler(baseHandler)
 
 	resp := httptest.NewRecorder()
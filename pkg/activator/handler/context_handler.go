@@ -29,8 +29,11 @@ import (
 	"knative.dev/pkg/logging/logkey"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+	"knative.dev/serving/pkg/queue"
 )
 
 // NewContextHandler creates a handler that extracts the necessary context from the request
@@ -67,10 +70,34 @@ func (h *contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = logging.WithLogger(ctx, logger)
 	ctx = util.WithRevision(ctx, revision)
 	ctx = util.WithRevID(ctx, revID)
+	if key := sessionAffinityKey(revision, r); key != "" {
+		ctx = util.WithSessionAffinityKey(ctx, key)
+	}
+	if p := revision.RetryPolicy(); p != nil {
+		ctx = util.WithRetryPolicy(ctx, p)
+	}
+	ctx = util.WithPriority(ctx, queue.PriorityFromHeader(r.Header))
 
 	h.nextHandler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// sessionAffinityKey returns the key the revision pins requests to pods by,
+// or an empty string if the revision has no session affinity or the request
+// does not carry the key.
+func sessionAffinityKey(rev *v1.Revision, r *http.Request) string {
+	source, name, ok := rev.SessionAffinity()
+	if !ok {
+		return ""
+	}
+	if source == serving.SessionAffinitySourceCookie {
+		if c, err := r.Cookie(name); err == nil {
+			return c.Value
+		}
+		return ""
+	}
+	return r.Header.Get(name)
+}
+
 func sendError(err error, w http.ResponseWriter) {
 	msg := fmt.Sprint("Error getting active endpoint: ", err)
 	if k8serrors.IsNotFound(err) {
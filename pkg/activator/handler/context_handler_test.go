@@ -28,6 +28,8 @@ import (
 	rtesting "knative.dev/pkg/reconciler/testing"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/queue"
 )
 
 func TestContextHandler(t *testing.T) {
@@ -45,6 +47,10 @@ func TestContextHandler(t *testing.T) {
 		if got := util.RevIDFrom(r.Context()); got != revID {
 			t.Errorf("revIDFrom() = %v, want %v", got, revID)
 		}
+
+		if got := util.PriorityFrom(r.Context()); got != queue.PriorityBackground {
+			t.Errorf("PriorityFrom() = %v, want %v", got, queue.PriorityBackground)
+		}
 	})
 
 	handler := NewContextHandler(ctx, baseHandler)
@@ -52,6 +58,7 @@ func TestContextHandler(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString(""))
 	req.Header.Set(activator.RevisionHeaderNamespace, revID.Namespace)
 	req.Header.Set(activator.RevisionHeaderName, revID.Name)
+	req.Header.Set(queue.PriorityHeaderName, queue.PriorityBackgroundValue)
 	handler.ServeHTTP(resp, req)
 
 	if got, want := resp.Code, http.StatusOK; got != want {
@@ -59,6 +66,73 @@ func TestContextHandler(t *testing.T) {
 	}
 }
 
+func TestContextHandlerSessionAffinity(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		annotation string
+		header     string
+		cookie     *http.Cookie
+		want       string
+	}{{
+		name:   "no session affinity",
+		header: "user-1",
+	}, {
+		name:       "header",
+		annotation: "header:X-User",
+		header:     "user-1",
+		want:       "user-1",
+	}, {
+		name:       "header missing",
+		annotation: "header:X-User",
+	}, {
+		name:       "cookie",
+		annotation: "cookie:session",
+		header:     "user-1",
+		cookie:     &http.Cookie{Name: "session", Value: "abc"},
+		want:       "abc",
+	}, {
+		name:       "cookie missing",
+		annotation: "cookie:session",
+		header:     "user-1",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer cancel()
+			revID := types.NamespacedName{Namespace: testNamespace, Name: testRevName}
+			revision := revision(revID.Namespace, revID.Name)
+			if tc.annotation != "" {
+				revision.Annotations = map[string]string{
+					serving.SessionAffinityAnnotationKey: tc.annotation,
+				}
+			}
+			revisionInformer(ctx, revision)
+
+			baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := util.SessionAffinityKeyFrom(r.Context()); got != tc.want {
+					t.Errorf("SessionAffinityKeyFrom() = %q, want %q", got, tc.want)
+				}
+			})
+
+			handler := NewContextHandler(ctx, baseHandler)
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString(""))
+			req.Header.Set(activator.RevisionHeaderNamespace, revID.Namespace)
+			req.Header.Set(activator.RevisionHeaderName, revID.Name)
+			if tc.header != "" {
+				req.Header.Set("X-User", tc.header)
+			}
+			if tc.cookie != nil {
+				req.AddCookie(tc.cookie)
+			}
+			handler.ServeHTTP(resp, req)
+
+			if got, want := resp.Code, http.StatusOK; got != want {
+				t.Errorf("StatusCode = %d, want %d, body: %s", got, want, resp.Body.String())
+			}
+		})
+	}
+}
+
 func TestContextHandlerError(t *testing.T) {
 	ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
 	defer cancel()
@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
@@ -29,12 +30,16 @@ import (
 
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/logging"
+	pkgmetrics "knative.dev/pkg/metrics"
 	pkgnet "knative.dev/pkg/network"
 	tracingconfig "knative.dev/pkg/tracing/config"
 	"knative.dev/pkg/tracing/propagation/tracecontextb3"
 	"knative.dev/serving/pkg/activator"
 	activatorconfig "knative.dev/serving/pkg/activator/config"
 	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/apis/serving"
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/queue"
 )
 
@@ -50,6 +55,7 @@ type activationHandler struct {
 	tracingTransport http.RoundTripper
 	throttler        Throttler
 	bufferPool       httputil.BufferPool
+	retryBudgets     *retryBudgets
 }
 
 // New constructs a new http.Handler that deals with revision activation.
@@ -60,8 +66,9 @@ func New(_ context.Context, t Throttler, transport http.RoundTripper) http.Handl
 			Base:        transport,
 			Propagation: tracecontextb3.TraceContextB3Egress,
 		},
-		throttler:  t,
-		bufferPool: network.NewBufferPool(),
+		throttler:    t,
+		bufferPool:   network.NewBufferPool(),
+		retryBudgets: newRetryBudgets(),
 	}
 }
 
@@ -74,24 +81,63 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		tryContext, trySpan = trace.StartSpan(r.Context(), "throttler_try")
 	}
 
-	if err := a.throttler.Try(tryContext, func(dest string) error {
+	// The request is buffered until the throttler finds capacity for it.
+	queueStats, enqueued := queueStatsFrom(r.Context()), time.Now()
+	if queueStats != nil {
+		queueStats.Enqueue(enqueued)
+	}
+	dequeued := false
+	dequeue := func() {
+		if queueStats != nil && !dequeued {
+			queueStats.Dequeue(time.Now(), enqueued)
+		}
+		dequeued = true
+	}
+
+	retry := newRetryState(a.retryBudgets, r)
+	defer retry.done()
+
+	proxy := func(dest string) error {
 		trySpan.End()
+		dequeue()
 
 		proxyCtx, proxySpan := r.Context(), (*trace.Span)(nil)
 		if tracingEnabled {
 			proxyCtx, proxySpan = trace.StartSpan(r.Context(), "activator_proxy")
 		}
-		a.proxyRequest(logger, w, r.WithContext(proxyCtx), &url.URL{
+		req := r.WithContext(proxyCtx)
+		retry.prepare(req, dest)
+		rr := pkghttp.NewResponseRecorder(w, http.StatusOK)
+		a.proxyRequest(logger, rr, req, &url.URL{
 			Scheme: "http",
 			Host:   dest,
-		}, tracingEnabled)
+		}, tracingEnabled, retry)
 		proxySpan.End()
 
+		// Let the throttler know the pod failed the request, so it can
+		// eject it if it keeps failing.
+		if (retry != nil && retry.pending) || rr.ResponseCode >= http.StatusInternalServerError {
+			return util.ErrPodFailed
+		}
 		return nil
-	}); err != nil {
+	}
+
+	err := a.throttler.Try(tryContext, proxy)
+	for ctx, ok := retry.next(tryContext); ok; ctx, ok = retry.next(ctx) {
+		reportEjection(r.Context(), err)
+		err = a.throttler.Try(ctx, proxy)
+	}
+	if err != nil {
+		reportEjection(r.Context(), err)
+		if errors.Is(err, util.ErrPodFailed) {
+			// The response has been proxied already.
+			return
+		}
+
 		// Set error on our capacity waiting span and end it.
 		trySpan.Annotate([]trace.Attribute{trace.StringAttribute("activator.throttler.error", err.Error())}, "ThrottlerTry")
 		trySpan.End()
+		dequeue()
 
 		logger.Errorw("Throttler try error", zap.Error(err))
 
@@ -103,7 +149,18 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *activationHandler) proxyRequest(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, target *url.URL, tracingEnabled bool) {
+// reportEjection records the ejection of a pod the request failed on.
+func reportEjection(ctx context.Context, err error) {
+	var ejected *util.PodEjectedError
+	if errors.As(err, &ejected) {
+		rev := util.RevisionFrom(ctx)
+		reporterCtx := metrics.RevisionContext(rev.Namespace, rev.Labels[serving.ServiceLabelKey],
+			rev.Labels[serving.ConfigurationLabelKey], rev.Name)
+		pkgmetrics.Record(reporterCtx, podEjectionCountM.M(1))
+	}
+}
+
+func (a *activationHandler) proxyRequest(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, target *url.URL, tracingEnabled bool, retry *retryState) {
 	network.RewriteHostIn(r)
 	r.Header.Set(network.ProxyHeaderName, activator.Name)
 
@@ -117,6 +174,22 @@ func (a *activationHandler) proxyRequest(logger *zap.SugaredLogger, w http.Respo
 	proxy.FlushInterval = network.FlushInterval
 	proxy.ErrorHandler = pkgnet.ErrorHandler(logger)
 	util.SetupHeaderPruning(proxy)
+	if retry != nil {
+		// Failed attempts that are retried are not written to the client.
+		errorHandler := proxy.ErrorHandler
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			if retry.policy.StatusCodes.Has(resp.StatusCode) && retry.shouldRetry(r) {
+				return errRetry
+			}
+			return nil
+		}
+		proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+			if errors.Is(err, errRetry) || retry.shouldRetry(req) {
+				return
+			}
+			errorHandler(w, req, err)
+		}
+	}
 
 	proxy.ServeHTTP(w, r)
 }
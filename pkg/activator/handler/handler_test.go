@@ -28,10 +28,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/resource"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	network "knative.dev/networking/pkg"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
 	pkgnet "knative.dev/pkg/network"
 	"knative.dev/pkg/ptr"
 	rtesting "knative.dev/pkg/reconciler/testing"
@@ -154,6 +158,311 @@ func TestActivationHandler(t *testing.T) {
 	}
 }
 
+// ejectingThrottler ejects the pod if the request failed.
+type ejectingThrottler struct {
+	gotErr *error
+}
+
+func (et ejectingThrottler) Try(_ context.Context, f func(string) error) error {
+	dest := "10.10.10.10:1234"
+	err := f(dest)
+	*et.gotErr = err
+	if err != nil {
+		return &util.PodEjectedError{Dest: dest, Duration: time.Minute, Err: err}
+	}
+	return nil
+}
+
+func TestActivationHandlerPodFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		code        int
+		wantErr     error
+		wantEjected int64
+	}{{
+		name: "success",
+		code: http.StatusOK,
+	}, {
+		name: "client error",
+		code: http.StatusNotFound,
+	}, {
+		name:        "server error",
+		code:        http.StatusInternalServerError,
+		wantErr:     util.ErrPodFailed,
+		wantEjected: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			reset()
+			fakeRT := activatortest.FakeRoundTripper{
+				RequestResponse: &activatortest.FakeResponse{
+					Code: tc.code,
+					Body: wantBody,
+				},
+			}
+			rt := pkgnet.RoundTripperFunc(fakeRT.RT)
+
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer cancel()
+			var gotErr error
+			handler := New(ctx, ejectingThrottler{gotErr: &gotErr}, rt)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+
+			configStore := setupConfigStore(t, logging.FromContext(ctx))
+			ctx = configStore.ToContext(ctx)
+			ctx = util.WithRevision(ctx, revision(testNamespace, testRevName))
+			ctx = util.WithRevID(ctx, types.NamespacedName{Namespace: testNamespace, Name: testRevName})
+
+			handler.ServeHTTP(resp, req.WithContext(ctx))
+
+			if !errors.Is(gotErr, tc.wantErr) {
+				t.Errorf("Try function returned %v, want: %v", gotErr, tc.wantErr)
+			}
+			// The proxied response is passed through as is.
+			if resp.Code != tc.code {
+				t.Errorf("Unexpected response status. Want %d, got %d", tc.code, resp.Code)
+			}
+			if got := resp.Body.String(); got != wantBody {
+				t.Errorf("Response body = %q, want: %q", got, wantBody)
+			}
+			if tc.wantEjected > 0 {
+				metricstest.AssertMetric(t, metricstest.IntMetric(podEjectionCountM.Name(), tc.wantEjected, nil).WithResource(&resource.Resource{
+					Type: metricskey.ResourceTypeKnativeRevision,
+					Labels: map[string]string{
+						metricskey.LabelNamespaceName:     testNamespace,
+						metricskey.LabelServiceName:       "service-" + testRevName,
+						metricskey.LabelConfigurationName: "config-" + testRevName,
+						metricskey.LabelRevisionName:      testRevName,
+					},
+				}))
+			} else {
+				metricstest.AssertNoMetric(t, podEjectionCountM.Name())
+			}
+		})
+	}
+}
+
+// retryingThrottler sends the request to the first pod it wasn't excluded from.
+type retryingThrottler struct {
+	dests []string
+	tried *[]string
+}
+
+func (rt retryingThrottler) Try(ctx context.Context, f func(string) error) error {
+	excluded := util.ExcludedDestsFrom(ctx)
+	for _, dest := range rt.dests {
+		if !excluded.Has(dest) {
+			*rt.tried = append(*rt.tried, dest)
+			return f(dest)
+		}
+	}
+	return errors.New("no pods left")
+}
+
+func TestActivationHandlerRetry(t *testing.T) {
+	const (
+		podA    = "10.10.10.10:1234"
+		podB    = "10.10.10.11:1234"
+		reqBody = "retry me"
+	)
+	policy := func(m func(*serving.RetryPolicy)) *serving.RetryPolicy {
+		p, _ := serving.ParseRetryPolicy(map[string]string{serving.RetryAttemptsAnnotationKey: "2"})
+		if m != nil {
+			m(p)
+		}
+		return p
+	}
+	for _, tc := range []struct {
+		name      string
+		policy    *serving.RetryPolicy
+		method    string
+		body      string
+		responses map[string]int // 0 fails to connect
+		retries   int64          // retries already in flight for the revision
+		wantTried []string
+		wantCode  int
+	}{{
+		name:      "no policy",
+		method:    http.MethodGet,
+		responses: map[string]int{podA: http.StatusBadGateway, podB: http.StatusOK},
+		wantTried: []string{podA},
+		wantCode:  http.StatusBadGateway,
+	}, {
+		name:      "retried status code",
+		policy:    policy(nil),
+		method:    http.MethodGet,
+		responses: map[string]int{podA: http.StatusServiceUnavailable, podB: http.StatusOK},
+		wantTried: []string{podA, podB},
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "connection failure with body",
+		policy:    policy(nil),
+		method:    http.MethodPut,
+		body:      reqBody,
+		responses: map[string]int{podA: 0, podB: http.StatusOK},
+		wantTried: []string{podA, podB},
+		wantCode:  http.StatusOK,
+	}, {
+		name:      "status code not retried",
+		policy:    policy(nil),
+		method:    http.MethodGet,
+		responses: map[string]int{podA: http.StatusInternalServerError, podB: http.StatusOK},
+		wantTried: []string{podA},
+		wantCode:  http.StatusInternalServerError,
+	}, {
+		name:      "attempts exhausted",
+		policy:    policy(nil),
+		method:    http.MethodGet,
+		responses: map[string]int{podA: http.StatusServiceUnavailable, podB: http.StatusGatewayTimeout},
+		wantTried: []string{podA, podB},
+		wantCode:  http.StatusGatewayTimeout,
+	}, {
+		name:      "method not retried",
+		policy:    policy(nil),
+		method:    http.MethodPost,
+		body:      reqBody,
+		responses: map[string]int{podA: http.StatusServiceUnavailable, podB: http.StatusOK},
+		wantTried: []string{podA},
+		wantCode:  http.StatusServiceUnavailable,
+	}, {
+		name:      "body too large",
+		policy:    policy(func(p *serving.RetryPolicy) { p.MaxBodyBytes = 4 }),
+		method:    http.MethodPut,
+		body:      reqBody,
+		responses: map[string]int{podA: http.StatusServiceUnavailable, podB: http.StatusOK},
+		wantTried: []string{podA},
+		wantCode:  http.StatusServiceUnavailable,
+	}, {
+		name:      "budget exhausted",
+		policy:    policy(nil),
+		method:    http.MethodGet,
+		responses: map[string]int{podA: http.StatusServiceUnavailable, podB: http.StatusOK},
+		retries:   minRetryConcurrency,
+		wantTried: []string{podA},
+		wantCode:  http.StatusServiceUnavailable,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rt := pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				var got []byte
+				if r.Body != nil {
+					got, _ = ioutil.ReadAll(r.Body)
+				}
+				if string(got) != tc.body {
+					t.Errorf("Pod %s got body %q, want: %q", r.URL.Host, got, tc.body)
+				}
+				code := tc.responses[r.URL.Host]
+				if code == 0 {
+					return nil, errors.New("connection reset by peer")
+				}
+				return &http.Response{
+					StatusCode: code,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewBufferString(r.URL.Host)),
+				}, nil
+			})
+
+			ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
+			defer cancel()
+			var tried []string
+			handler := New(ctx, retryingThrottler{dests: []string{podA, podB}, tried: &tried}, rt)
+
+			revID := types.NamespacedName{Namespace: testNamespace, Name: testRevName}
+			// Hold the budget so that it outlives the request.
+			budgets := handler.(*activationHandler).retryBudgets
+			budget := budgets.acquire(revID)
+			defer budgets.release(revID, tc.retries)
+			budget.retries.Store(tc.retries)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "http://example.com", bytes.NewBufferString(tc.body))
+
+			configStore := setupConfigStore(t, logging.FromContext(ctx))
+			ctx = configStore.ToContext(ctx)
+			ctx = util.WithRevision(ctx, revision(testNamespace, testRevName))
+			ctx = util.WithRevID(ctx, revID)
+			if tc.policy != nil {
+				ctx = util.WithRetryPolicy(ctx, tc.policy)
+			}
+
+			handler.ServeHTTP(resp, req.WithContext(ctx))
+
+			if !cmp.Equal(tried, tc.wantTried) {
+				t.Errorf("Tried pods = %v, want: %v", tried, tc.wantTried)
+			}
+			if resp.Code != tc.wantCode {
+				t.Errorf("Response status = %d, want: %d", resp.Code, tc.wantCode)
+			}
+			if got, want := resp.Body.String(), tried[len(tried)-1]; resp.Code != http.StatusBadGateway && got != want {
+				t.Errorf("Response body = %q, want: %q", got, want)
+			}
+			// The budget taken by the request is released once it's done.
+			if got := budget.retries.Load(); got != tc.retries {
+				t.Errorf("Retries in flight = %d, want: %d", got, tc.retries)
+			}
+			if got := budget.requests.Load(); got != 1 {
+				t.Errorf("Requests in flight = %d, want: 1", got)
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := &retryBudget{}
+	for i := 0; i < minRetryConcurrency; i++ {
+		if !b.acquire(20) {
+			t.Fatalf("acquire #%d = false, want: true", i)
+		}
+	}
+	if b.acquire(20) {
+		t.Error("acquire beyond the minimum concurrency = true, want: false")
+	}
+
+	// 20% of 20 requests in flight allow a fourth retry.
+	b.requests.Store(20)
+	if !b.acquire(20) {
+		t.Error("acquire within the budget = false, want: true")
+	}
+	if b.acquire(20) {
+		t.Error("acquire beyond the budget = true, want: false")
+	}
+}
+
+func TestRetryBudgets(t *testing.T) {
+	revID := types.NamespacedName{Namespace: testNamespace, Name: testRevName}
+	rb := newRetryBudgets()
+
+	b := rb.acquire(revID)
+	if !b.acquire(20) {
+		t.Fatal("acquire = false, want: true")
+	}
+	if got := rb.acquire(revID); got != b {
+		t.Fatal("Budget with requests in flight was not reused")
+	}
+
+	// The budget is kept, with its retries, while a request is in flight.
+	rb.release(revID, 1)
+	if got := rb.acquire(revID); got != b {
+		t.Fatal("Budget with requests in flight was reset")
+	}
+	if got, want := b.requests.Load(), int64(2); got != want {
+		t.Errorf("Requests in flight = %d, want: %d", got, want)
+	}
+	if got := b.retries.Load(); got != 0 {
+		t.Errorf("Retries in flight = %d, want: 0", got)
+	}
+
+	// The budget is dropped once the last request is done.
+	rb.release(revID, 0)
+	rb.release(revID, 0)
+	if got := len(rb.budgets); got != 0 {
+		t.Errorf("Number of budgets = %d, want: 0", got)
+	}
+	if got := rb.acquire(revID); got == b {
+		t.Error("Budget without requests in flight was kept")
+	}
+}
+
 func TestActivationHandlerProxyHeader(t *testing.T) {
 	interceptCh := make(chan *http.Request, 1)
 	rt := pkgnet.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
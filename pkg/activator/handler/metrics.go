@@ -38,6 +38,10 @@ var (
 		"request_latencies",
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	podEjectionCountM = stats.Int64(
+		"pod_ejection_count",
+		"The number of times a pod was ejected from load balancing as an outlier",
+		stats.UnitDimensionless)
 
 	// NOTE: 0 should not be used as boundary. See
 	// https://github.com/census-ecosystem/opencensus-go-exporter-stackdriver/issues/98
@@ -71,6 +75,11 @@ func register() {
 			Aggregation: defaultLatencyDistribution,
 			TagKeys:     []tag.Key{metrics.PodTagKey, metrics.ContainerTagKey, metrics.ResponseCodeKey, metrics.ResponseCodeClassKey},
 		},
+		&view.View{
+			Description: "The number of times a pod was ejected from load balancing as an outlier",
+			Measure:     podEjectionCountM,
+			Aggregation: view.Count(),
+		},
 	); err != nil {
 		panic(err)
 	}
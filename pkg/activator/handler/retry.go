@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+const (
+	// minRetryConcurrency is the number of retries a revision may have in
+	// flight regardless of its budget, so that revisions with little traffic
+	// can retry at all.
+	minRetryConcurrency = 3
+)
+
+// errRetry is returned by the proxy's ModifyResponse for responses that are
+// to be retried, so that they are not written to the client.
+var errRetry = errors.New("response is retried")
+
+// retryBudget caps the retries a revision has in flight to a percentage of
+// its requests in flight, so that retries can't amplify an outage.
+type retryBudget struct {
+	requests atomic.Int64
+	retries  atomic.Int64
+}
+
+// acquire takes a slot for a retry if the budget allows it.
+func (b *retryBudget) acquire(pct float64) bool {
+	allowed := int64(math.Max(minRetryConcurrency, pct/100*float64(b.requests.Load())))
+	if b.retries.Inc() > allowed {
+		b.retries.Dec()
+		return false
+	}
+	return true
+}
+
+// retryBudgets keeps the retry budget of each revision with retryable
+// requests in flight. A budget is dropped once its last request is done, so
+// that the budgets don't grow with the number of revisions ever seen, while a
+// budget with requests in flight is never reset.
+type retryBudgets struct {
+	mu      sync.Mutex
+	budgets map[types.NamespacedName]*retryBudget
+}
+
+func newRetryBudgets() *retryBudgets {
+	return &retryBudgets{budgets: make(map[types.NamespacedName]*retryBudget)}
+}
+
+// acquire returns the budget of the revision, counting a request in flight
+// against it. Every call must be paired with a call to release.
+func (rb *retryBudgets) acquire(revID types.NamespacedName) *retryBudget {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	b, ok := rb.budgets[revID]
+	if !ok {
+		b = &retryBudget{}
+		rb.budgets[revID] = b
+	}
+	b.requests.Inc()
+	return b
+}
+
+// release gives back the request and the retries it took from the budget of
+// the revision, dropping the budget if it has no requests left in flight.
+func (rb *retryBudgets) release(revID types.NamespacedName, retries int64) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	b, ok := rb.budgets[revID]
+	if !ok {
+		return
+	}
+	b.retries.Sub(retries)
+	if b.requests.Dec() == 0 {
+		delete(rb.budgets, revID)
+	}
+}
+
+// retryState tracks the attempts of a single request. All its methods are
+// no-ops on a nil retryState, which is used for requests that are not retried.
+type retryState struct {
+	policy  *serving.RetryPolicy
+	budgets *retryBudgets
+	revID   types.NamespacedName
+	budget  *retryBudget
+	body    []byte
+
+	// attempts is the number of times the request was sent.
+	attempts int
+	// retries is the number of budget slots taken by the request.
+	retries int64
+	// dest is where the last attempt was sent to.
+	dest string
+	// failedDests are the destinations previous attempts failed on.
+	failedDests sets.String
+	// pending is set when the last attempt failed and will be retried.
+	pending bool
+}
+
+// newRetryState returns the retry state of the request, or nil if the request
+// must not be retried. The body of the request is buffered so that it can be
+// replayed.
+func newRetryState(budgets *retryBudgets, r *http.Request) *retryState {
+	policy := util.RetryPolicyFrom(r.Context())
+	if policy == nil || !policy.Methods.Has(r.Method) {
+		return nil
+	}
+
+	s := &retryState{policy: policy}
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, policy.MaxBodyBytes+1))
+		if err != nil || int64(len(body)) > policy.MaxBodyBytes {
+			// The body is too large to replay, so send the request once, with
+			// what we've read put back in front of the rest of the body.
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+			return nil
+		}
+		s.body = body
+	}
+
+	s.budgets = budgets
+	s.revID = util.RevIDFrom(r.Context())
+	s.budget = budgets.acquire(s.revID)
+	s.failedDests = sets.NewString()
+	return s
+}
+
+// prepare readies the request for an attempt to dest.
+func (s *retryState) prepare(r *http.Request, dest string) {
+	if s == nil {
+		return
+	}
+	s.attempts++
+	s.dest = dest
+	if s.body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(s.body))
+	}
+}
+
+// shouldRetry reports whether the failed attempt is retried, taking a slot of
+// the retry budget if so.
+func (s *retryState) shouldRetry(r *http.Request) bool {
+	if s == nil || s.attempts >= s.policy.Attempts || r.Context().Err() != nil {
+		return false
+	}
+	if !s.budget.acquire(s.policy.BudgetPercentage) {
+		return false
+	}
+	s.retries++
+	s.pending = true
+	return true
+}
+
+// next reports whether the request is to be sent again and, if so, returns
+// the context for the next attempt that steers it away from the pods it
+// already failed on.
+func (s *retryState) next(ctx context.Context) (context.Context, bool) {
+	if s == nil || !s.pending {
+		return ctx, false
+	}
+	s.pending = false
+	s.failedDests.Insert(s.dest)
+	return util.WithExcludedDests(ctx, s.failedDests), true
+}
+
+// done releases the budget held by the request.
+func (s *retryState) done() {
+	if s == nil {
+		return
+	}
+	s.budgets.release(s.revID, s.retries)
+}
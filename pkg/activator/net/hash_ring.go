@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the consistent hash ring used for session affinity.
+
+package net
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// hashRingReplicas is the number of points each target gets on the ring.
+// More points give a more even spread of keys at the cost of a bigger ring.
+const hashRingReplicas = 100
+
+// hashRing maps session affinity keys onto targets, so that the same key
+// keeps hitting the same target and only the keys of a target that comes
+// or goes are moved elsewhere.
+// The ring is immutable once built.
+type hashRing struct {
+	points  []uint64
+	targets []*podTracker
+}
+
+func newHashRing(targets []*podTracker) *hashRing {
+	if len(targets) == 0 {
+		return nil
+	}
+	r := &hashRing{
+		points:  make([]uint64, 0, len(targets)*hashRingReplicas),
+		targets: make([]*podTracker, 0, len(targets)*hashRingReplicas),
+	}
+	type point struct {
+		hash   uint64
+		target *podTracker
+	}
+	points := make([]point, 0, len(targets)*hashRingReplicas)
+	for _, t := range targets {
+		for i := 0; i < hashRingReplicas; i++ {
+			points = append(points, point{
+				hash:   hashKey(t.dest + "#" + strconv.Itoa(i)),
+				target: t,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash == points[j].hash {
+			// Keep the order stable on the (unlikely) collisions.
+			return points[i].target.dest < points[j].target.dest
+		}
+		return points[i].hash < points[j].hash
+	})
+	for _, p := range points {
+		r.points = append(r.points, p.hash)
+		r.targets = append(r.targets, p.target)
+	}
+	return r
+}
+
+// pick returns the target owning the key, or the next one clockwise
+// on the ring that can serve the request right now, or (noop, nil) if
+// none of them has capacity.
+func (r *hashRing) pick(ctx context.Context, key string) (func(), *podTracker) {
+	l := len(r.points)
+	h := hashKey(key)
+	start := sort.Search(l, func(i int) bool { return r.points[i] >= h })
+	// Targets appear many times on the ring, so avoid asking the same
+	// full target over and over again.
+	var tried map[*podTracker]struct{}
+	for i := 0; i < l; i++ {
+		t := r.targets[(start+i)%l]
+		if _, ok := tried[t]; ok {
+			continue
+		}
+		if cb, ok := t.Reserve(ctx); ok {
+			return cb, t
+		}
+		if tried == nil {
+			tried = make(map[*podTracker]struct{}, 1)
+		}
+		tried[t] = struct{}{}
+	}
+	return noop, nil
+}
+
+// hashKey computes the 64-bit FNV-1a hash of the key, without allocating.
+// The result is run through a finalizer, since FNV alone spreads keys that
+// differ only in their last characters (like the ring points) poorly.
+func hashKey(key string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= prime64
+	}
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
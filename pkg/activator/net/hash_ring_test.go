@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	pkgnet "knative.dev/networking/pkg/apis/networking"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/queue"
+)
+
+const numHashRingKeys = 1000
+
+func TestHashRingEmpty(t *testing.T) {
+	if r := newHashRing(nil); r != nil {
+		t.Errorf("newHashRing(nil) = %v, want: nil", r)
+	}
+}
+
+func TestHashRingStable(t *testing.T) {
+	r := newHashRing(makeTrackers(5, 0))
+	for i := 0; i < numHashRingKeys; i++ {
+		key := strconv.Itoa(i)
+		_, want := r.pick(context.Background(), key)
+		// A fresh ring over the same targets must agree.
+		_, got := newHashRing(makeTrackers(5, 0)).pick(context.Background(), key)
+		if got.dest != want.dest {
+			t.Fatalf("pick(%q) = %s, want: %s", key, got.dest, want.dest)
+		}
+	}
+}
+
+func TestHashRingSpread(t *testing.T) {
+	r := newHashRing(makeTrackers(5, 0))
+	counts := map[string]int{}
+	for i := 0; i < numHashRingKeys; i++ {
+		_, pt := r.pick(context.Background(), "session-"+strconv.Itoa(i))
+		counts[pt.dest]++
+	}
+	if got, want := len(counts), 5; got != want {
+		t.Fatalf("Keys went to %d targets, want: %d", got, want)
+	}
+	for dest, n := range counts {
+		// Perfect spread is 200 keys per target.
+		if n < 100 || n > 300 {
+			t.Errorf("Target %s got %d keys, want roughly %d", dest, n, numHashRingKeys/5)
+		}
+	}
+}
+
+func TestHashRingMinimalRebalance(t *testing.T) {
+	trackers := makeTrackers(10, 0)
+	before := newHashRing(trackers)
+	// Drop the pod "3".
+	after := newHashRing(append(append([]*podTracker{}, trackers[:3]...), trackers[4:]...))
+
+	moved := 0
+	for i := 0; i < numHashRingKeys; i++ {
+		key := strconv.Itoa(i)
+		_, was := before.pick(context.Background(), key)
+		_, is := after.pick(context.Background(), key)
+		if was.dest == "3" {
+			if is.dest == "3" {
+				t.Fatalf("Key %q still goes to the removed target", key)
+			}
+			continue
+		}
+		if was.dest != is.dest {
+			moved++
+		}
+	}
+	if moved != 0 {
+		t.Errorf("%d keys of the remaining targets moved, want: 0", moved)
+	}
+}
+
+func TestHashRingCapacity(t *testing.T) {
+	trackers := makeTrackers(3, 1)
+	r := newHashRing(trackers)
+
+	cb, first := r.pick(context.Background(), "key")
+	if first == nil {
+		t.Fatal("Tracker was nil")
+	}
+	// The owner of the key is full, so the next target on the ring is used.
+	cb2, second := r.pick(context.Background(), "key")
+	t.Cleanup(cb2)
+	if second == nil || second == first {
+		t.Fatalf("Tracker = %v, want a tracker other than %v", second, first)
+	}
+	cb3, _ := r.pick(context.Background(), "key")
+	t.Cleanup(cb3)
+	// Everything is full now.
+	if _, pt := r.pick(context.Background(), "key"); pt != nil {
+		t.Fatal("Wanted nil, got:", pt)
+	}
+
+	// Once the owner frees up, the key goes back to it.
+	cb()
+	cb, pt := r.pick(context.Background(), "key")
+	t.Cleanup(cb)
+	if pt != first {
+		t.Errorf("Tracker = %v, want: %v", pt, first)
+	}
+}
+
+func TestThrottlerSessionAffinity(t *testing.T) {
+	revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	rt := newRevisionThrottler(context.Background(), revID, 0 /*cc*/, pkgnet.ServicePortNameHTTP1,
+		"" /*lbPolicy*/, true /*sessionAffinity*/, queue.BreakerParams{}, TestLogger(t))
+	rt.numActivators.Store(1)
+	rt.activatorIndex.Store(0)
+	rt.updateThrottlerState(5, makeTrackers(5, 0), nil /*clusterIP*/)
+
+	if rt.hashRing == nil {
+		t.Fatal("hashRing was not built")
+	}
+	ctx := util.WithSessionAffinityKey(context.Background(), "user-42")
+	cb, want := rt.acquireDest(ctx)
+	cb()
+	for i := 0; i < 10; i++ {
+		cb, got := rt.acquireDest(ctx)
+		cb()
+		if got != want {
+			t.Fatalf("acquireDest = %v, want: %v", got, want)
+		}
+	}
+
+	// Requests without a key go through the regular policy.
+	cb, pt := rt.acquireDest(context.Background())
+	cb()
+	if pt == nil {
+		t.Fatal("Tracker was nil")
+	}
+
+	// No pods, no ring.
+	rt.updateThrottlerState(0, nil /*trackers*/, nil /*clusterIP*/)
+	if rt.hashRing != nil {
+		t.Error("hashRing was not cleared")
+	}
+}
+
+func TestThrottlerSessionAffinityMultipleActivators(t *testing.T) {
+	const (
+		cc            = 4
+		numPods       = 4
+		numActivators = 2
+	)
+	// Every activator may reserve any pod for a key, so together they must
+	// not exceed the container concurrency of a pod.
+	reserved := map[string]int{}
+	for ai := 0; ai < numActivators; ai++ {
+		revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+		rt := newRevisionThrottler(context.Background(), revID, cc, pkgnet.ServicePortNameHTTP1,
+			"" /*lbPolicy*/, true /*sessionAffinity*/, testBreakerParams, TestLogger(t))
+		rt.numActivators.Store(numActivators)
+		rt.activatorIndex.Store(int32(ai))
+		rt.updateThrottlerState(numPods, makeTrackers(numPods, cc), nil /*clusterIP*/)
+
+		if got, want := len(rt.assignedTrackers), numPods; got != want {
+			t.Errorf("Activator %d assigned trackers = %d, want: %d", ai, got, want)
+		}
+		ctx := util.WithSessionAffinityKey(context.Background(), "user-42")
+		for {
+			cb, pt := rt.acquireDest(ctx)
+			if pt == nil {
+				break
+			}
+			t.Cleanup(cb)
+			reserved[pt.dest]++
+		}
+	}
+
+	total := 0
+	for dest, n := range reserved {
+		if n > cc {
+			t.Errorf("Pod %s got %d requests, more than the container concurrency %d", dest, n, cc)
+		}
+		total += n
+	}
+	if got, want := total, numPods*cc; got != want {
+		t.Errorf("Reserved %d slots in total, want: %d", got, want)
+	}
+}
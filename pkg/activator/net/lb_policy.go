@@ -22,6 +22,8 @@ import (
 	"context"
 	"math/rand"
 	"sync"
+
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 // lbPolicy is a functor that selects a target pod from the list, or (noop, nil) if
@@ -40,14 +42,16 @@ func randomLBPolicy(_ context.Context, targets []*podTracker) (func(), *podTrack
 }
 
 // randomChoice2Policy implements the Power of 2 choices LB algorithm
-func randomChoice2Policy(_ context.Context, targets []*podTracker) (func(), *podTracker) {
+func randomChoice2Policy(ctx context.Context, targets []*podTracker) (func(), *podTracker) {
 	// Avoid random if possible.
 	l := len(targets)
 	// One tracker = no choice.
 	if l == 1 {
 		pick := targets[0]
-		pick.increaseWeight()
-		return pick.decreaseWeight, pick
+		if cb, ok := pick.reserveWeighted(ctx); ok {
+			return cb, pick
+		}
+		return noop, nil
 	}
 	r1, r2 := 0, 1
 	// Two trackers - we know both contestants,
@@ -63,13 +67,20 @@ func randomChoice2Policy(_ context.Context, targets []*podTracker) (func(), *pod
 	}
 
 	pick, alt := targets[r1], targets[r2]
-	// Possible race here, but this policy is for CC=0,
+	// Possible race here, but the weights are only a heuristic,
 	// so fine.
 	if pick.getWeight() > alt.getWeight() {
-		pick = alt
+		pick, alt = alt, pick
 	}
-	pick.increaseWeight()
-	return pick.decreaseWeight, pick
+	// With CC=0 the reservation always succeeds, otherwise fall back
+	// to the other contestant if the pick has no free capacity.
+	if cb, ok := pick.reserveWeighted(ctx); ok {
+		return cb, pick
+	}
+	if cb, ok := alt.reserveWeighted(ctx); ok {
+		return cb, alt
+	}
+	return noop, nil
 }
 
 // firstAvailableLBPolicy is a load balancer policy, that picks the first target
@@ -83,6 +94,32 @@ func firstAvailableLBPolicy(ctx context.Context, targets []*podTracker) (func(),
 	return noop, nil
 }
 
+// leastRequestsLBPolicy is a load balancer policy that picks the target with
+// the fewest requests outstanding from this activator, which spreads load better
+// than the capacity based policies when request latencies vary a lot.
+func leastRequestsLBPolicy(ctx context.Context, targets []*podTracker) (func(), *podTracker) {
+	var pick *podTracker
+	for _, t := range targets {
+		if pick == nil || t.getWeight() < pick.getWeight() {
+			pick = t
+		}
+	}
+	if pick == nil {
+		return noop, nil
+	}
+	if cb, ok := pick.reserveWeighted(ctx); ok {
+		return cb, pick
+	}
+	// The least loaded target is out of capacity, which can happen if the
+	// weights raced the reservations. Take any target that can serve.
+	for _, t := range targets {
+		if cb, ok := t.reserveWeighted(ctx); ok {
+			return cb, t
+		}
+	}
+	return noop, nil
+}
+
 func newRoundRobinPolicy() lbPolicy {
 	var (
 		mu  sync.Mutex
@@ -111,3 +148,29 @@ func newRoundRobinPolicy() lbPolicy {
 		return noop, nil
 	}
 }
+
+// pickLBPolicy returns the policy with the given name and the name it is
+// reported under. An empty or unknown name picks the policy based on the
+// container concurrency.
+func pickLBPolicy(name string, containerConcurrency int) (lbPolicy, string) {
+	switch name {
+	case serving.LoadBalancingPolicyRandomChoice2:
+		return randomChoice2Policy, name
+	case serving.LoadBalancingPolicyFirstAvailable:
+		return firstAvailableLBPolicy, name
+	case serving.LoadBalancingPolicyRoundRobin:
+		return newRoundRobinPolicy(), name
+	case serving.LoadBalancingPolicyLeastRequests:
+		return leastRequestsLBPolicy, name
+	}
+	switch {
+	case containerConcurrency == 0:
+		return randomChoice2Policy, serving.LoadBalancingPolicyRandomChoice2
+	case containerConcurrency <= 3:
+		// For very low CC values use first available pod.
+		return firstAvailableLBPolicy, serving.LoadBalancingPolicyFirstAvailable
+	default:
+		// Otherwise RR.
+		return newRoundRobinPolicy(), serving.LoadBalancingPolicyRoundRobin
+	}
+}
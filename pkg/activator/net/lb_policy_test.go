@@ -22,6 +22,16 @@ import (
 	"testing"
 	"time"
 
+	"go.opencensus.io/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	pkgnet "knative.dev/networking/pkg/apis/networking"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+	_ "knative.dev/pkg/metrics/testing"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/queue"
 )
 
@@ -234,6 +244,175 @@ func TestRoundRobin(t *testing.T) {
 	})
 }
 
+func TestRandomChoice2WithCapacity(t *testing.T) {
+	podTrackers := makeTrackers(2, 1)
+	cb1, pt1 := randomChoice2Policy(context.Background(), podTrackers)
+	t.Cleanup(cb1)
+	// The first pick is full, so the other one must be used.
+	cb2, pt2 := randomChoice2Policy(context.Background(), podTrackers)
+	t.Cleanup(cb2)
+	if pt1 == nil || pt2 == nil {
+		t.Fatalf("Trackers = %v, %v, want both non-nil", pt1, pt2)
+	}
+	if pt1 == pt2 {
+		t.Errorf("Both picks went to %s, want different trackers", pt1.dest)
+	}
+	// Both are full now.
+	if _, pt := randomChoice2Policy(context.Background(), podTrackers); pt != nil {
+		t.Fatal("Wanted nil, got:", pt)
+	}
+}
+
+func TestLeastRequests(t *testing.T) {
+	t.Run("with cc=0", func(t *testing.T) {
+		podTrackers := makeTrackers(3, 0)
+		podTrackers[0].increaseWeight()
+		podTrackers[0].increaseWeight()
+		podTrackers[2].increaseWeight()
+		cb, pt := leastRequestsLBPolicy(context.Background(), podTrackers)
+		if got, want := pt, podTrackers[1]; got != want {
+			t.Fatalf("Tracker = %v, want: %v", got, want)
+		}
+		if got, want := pt.getWeight(), int32(1); got != want {
+			t.Errorf("pt.weight = %d, want: %d", got, want)
+		}
+		// Now 1 and 2 are tied, the first one wins.
+		cb2, pt := leastRequestsLBPolicy(context.Background(), podTrackers)
+		t.Cleanup(cb2)
+		if got, want := pt, podTrackers[1]; got != want {
+			t.Fatalf("Tracker = %v, want: %v", got, want)
+		}
+		cb()
+		if got, want := podTrackers[1].getWeight(), int32(1); got != want {
+			t.Errorf("pt.weight = %d, want: %d", got, want)
+		}
+	})
+	t.Run("with cc=1", func(t *testing.T) {
+		podTrackers := makeTrackers(2, 1)
+		cb, pt := leastRequestsLBPolicy(context.Background(), podTrackers)
+		t.Cleanup(cb)
+		if got, want := pt, podTrackers[0]; got != want {
+			t.Fatalf("Tracker = %v, want: %v", got, want)
+		}
+		cb, pt = leastRequestsLBPolicy(context.Background(), podTrackers)
+		if got, want := pt, podTrackers[1]; got != want {
+			t.Fatalf("Tracker = %v, want: %v", got, want)
+		}
+		// Everything is occupied.
+		if _, pt := leastRequestsLBPolicy(context.Background(), podTrackers); pt != nil {
+			t.Fatal("Wanted nil, got:", pt)
+		}
+		// Tracker 0 has the lower weight, but no capacity would be left if
+		// the weights got out of sync, so make sure we fall back.
+		podTrackers[0].decreaseWeight()
+		podTrackers[0].decreaseWeight()
+		cb()
+		cb, pt = leastRequestsLBPolicy(context.Background(), podTrackers)
+		t.Cleanup(cb)
+		if got, want := pt, podTrackers[1]; got != want {
+			t.Fatalf("Tracker = %v, want: %v", got, want)
+		}
+	})
+	t.Run("no trackers", func(t *testing.T) {
+		if _, pt := leastRequestsLBPolicy(context.Background(), nil); pt != nil {
+			t.Fatal("Wanted nil, got:", pt)
+		}
+	})
+}
+
+func TestPickLBPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy string
+		cc     int
+		want   string
+	}{{
+		name: "default cc=0",
+		want: serving.LoadBalancingPolicyRandomChoice2,
+	}, {
+		name: "default cc=3",
+		cc:   3,
+		want: serving.LoadBalancingPolicyFirstAvailable,
+	}, {
+		name: "default cc=10",
+		cc:   10,
+		want: serving.LoadBalancingPolicyRoundRobin,
+	}, {
+		name:   "least requests",
+		policy: serving.LoadBalancingPolicyLeastRequests,
+		cc:     10,
+		want:   serving.LoadBalancingPolicyLeastRequests,
+	}, {
+		name:   "round robin with cc=0",
+		policy: serving.LoadBalancingPolicyRoundRobin,
+		want:   serving.LoadBalancingPolicyRoundRobin,
+	}, {
+		name:   "random choice 2 with cc=1",
+		policy: serving.LoadBalancingPolicyRandomChoice2,
+		cc:     1,
+		want:   serving.LoadBalancingPolicyRandomChoice2,
+	}, {
+		name:   "first available with cc=10",
+		policy: serving.LoadBalancingPolicyFirstAvailable,
+		cc:     10,
+		want:   serving.LoadBalancingPolicyFirstAvailable,
+	}, {
+		name:   "unknown falls back to the default",
+		policy: "fastest",
+		cc:     10,
+		want:   serving.LoadBalancingPolicyRoundRobin,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			lbp, got := pickLBPolicy(tc.policy, tc.cc)
+			if lbp == nil {
+				t.Fatal("Policy was nil")
+			}
+			if got != tc.want {
+				t.Errorf("Policy = %s, want: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLBPicksMetric(t *testing.T) {
+	metricstest.Unregister(lbPicksM.Name())
+	register()
+
+	revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	rt := newRevisionThrottler(metrics.RevisionContext(revID.Namespace, "svc", "cfg", revID.Name), revID,
+		1 /*cc*/, pkgnet.ServicePortNameHTTP1, serving.LoadBalancingPolicyLeastRequests,
+		false /*sessionAffinity*/, queue.BreakerParams{QueueDepth: 1, MaxConcurrency: 1}, TestLogger(t))
+	rt.assignedTrackers = makeTrackers(1, 1)
+
+	cb, pt := rt.acquireDest(context.Background())
+	t.Cleanup(cb)
+	if pt == nil {
+		t.Fatal("Tracker was nil")
+	}
+	if _, pt := rt.acquireDest(context.Background()); pt != nil {
+		t.Fatal("Wanted nil, got:", pt)
+	}
+
+	wantResource := &resource.Resource{
+		Type: metricskey.ResourceTypeKnativeRevision,
+		Labels: map[string]string{
+			metricskey.LabelNamespaceName:     revID.Namespace,
+			metricskey.LabelServiceName:       "svc",
+			metricskey.LabelConfigurationName: "cfg",
+			metricskey.LabelRevisionName:      revID.Name,
+		},
+	}
+	picks := metricstest.IntMetric(lbPicksM.Name(), 1, map[string]string{
+		lbPolicyKey.Name(): serving.LoadBalancingPolicyLeastRequests,
+		lbResultKey.Name(): lbResultPicked,
+	}).WithResource(wantResource)
+	picks.Values = append(picks.Values, metricstest.IntMetric(lbPicksM.Name(), 1, map[string]string{
+		lbPolicyKey.Name(): serving.LoadBalancingPolicyLeastRequests,
+		lbResultKey.Name(): lbResultExhausted,
+	}).Values...)
+	metricstest.AssertMetric(t, picks)
+}
+
 func BenchmarkPolicy(b *testing.B) {
 	for _, test := range []struct {
 		name   string
@@ -250,6 +429,9 @@ func BenchmarkPolicy(b *testing.B) {
 	}, {
 		name:   "round-robin",
 		policy: newRoundRobinPolicy(),
+	}, {
+		name:   "least-requests",
+		policy: leastRequestsLBPolicy,
 	}} {
 		for _, n := range []int{1, 2, 3, 10, 100} {
 			b.Run(fmt.Sprintf("%s-%d-trackers-sequential", test.name, n), func(b *testing.B) {
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	pkgmetrics "knative.dev/pkg/metrics"
+)
+
+const (
+	lbResultPicked    = "picked"
+	lbResultExhausted = "exhausted"
+
+	// lbPolicySessionAffinity is the policy picks through the session
+	// affinity hash ring are reported under.
+	lbPolicySessionAffinity = "session-affinity"
+)
+
+var (
+	lbPicksM = stats.Int64(
+		"lb_picks",
+		"The number of times the load balancer was asked for a pod",
+		stats.UnitDimensionless)
+
+	lbPolicyKey = tag.MustNewKey("lb_policy")
+	lbResultKey = tag.MustNewKey("result")
+)
+
+func init() {
+	register()
+}
+
+func register() {
+	if err := pkgmetrics.RegisterResourceView(
+		&view.View{
+			Description: "The number of times the load balancer was asked for a pod",
+			Measure:     lbPicksM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{lbPolicyKey, lbResultKey},
+		},
+	); err != nil {
+		panic(err)
+	}
+}
+
+// pickReporter records the outcome of load balancer picks of a revision.
+// The contexts are tagged upfront, since picks are in the request path.
+type pickReporter struct {
+	pickedCtx    context.Context
+	exhaustedCtx context.Context
+}
+
+func newPickReporter(ctx context.Context, policy string) pickReporter {
+	pickedCtx, _ := tag.New(ctx, tag.Upsert(lbPolicyKey, policy), tag.Upsert(lbResultKey, lbResultPicked))
+	exhaustedCtx, _ := tag.New(ctx, tag.Upsert(lbPolicyKey, policy), tag.Upsert(lbResultKey, lbResultExhausted))
+	return pickReporter{
+		pickedCtx:    pickedCtx,
+		exhaustedCtx: exhaustedCtx,
+	}
+}
+
+func (r pickReporter) report(tracker *podTracker) {
+	if tracker == nil {
+		pkgmetrics.Record(r.exhaustedCtx, lbPicksM.M(1))
+	} else {
+		pkgmetrics.Record(r.pickedCtx, lbPicksM.M(1))
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the passive outlier detection of the pods.
+
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// outlierConsecutiveFailures is the number of failed requests in a row
+	// that get a pod ejected.
+	outlierConsecutiveFailures = 5
+	// outlierFailureRate is the fraction of failed requests within an
+	// outlierInterval that gets a pod ejected, provided it served at least
+	// outlierMinRequests in that interval.
+	outlierFailureRate = 0.5
+	outlierMinRequests = 10
+	outlierInterval    = 10 * time.Second
+
+	// outlierBaseEjection is how long a pod is ejected the first time.
+	// Every further ejection in a row doubles it, up to outlierMaxEjection.
+	outlierBaseEjection = 30 * time.Second
+	outlierMaxEjection  = 5 * time.Minute
+
+	// outlierMaxEjectedPercent caps the share of the pods of a revision that
+	// can be ejected at once, so that a revision-wide problem does not leave
+	// it with no pods at all.
+	outlierMaxEjectedPercent = 50
+)
+
+// outlierDetector tracks the outcome of the requests sent to a pod and
+// decides when the pod should be ejected from load balancing.
+type outlierDetector struct {
+	mu sync.Mutex
+
+	consecutiveFailures int
+	intervalStart       time.Time
+	requests, failures  int
+
+	// ejections is the number of ejections in a row, used for the backoff.
+	// It decays by one for every interval the pod is not ejected in.
+	ejections    int
+	ejectedUntil time.Time
+}
+
+// record records the outcome of a request at time now and returns whether
+// the pod should be ejected now.
+func (o *outlierDetector) record(now time.Time, failed bool) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if now.Before(o.ejectedUntil) {
+		// Requests that were in flight when the pod got ejected.
+		return false
+	}
+	if now.Sub(o.intervalStart) >= outlierInterval {
+		if o.ejections > 0 && !now.Before(o.ejectedUntil.Add(outlierInterval)) {
+			o.ejections--
+		}
+		o.intervalStart = now
+		o.requests, o.failures = 0, 0
+	}
+	o.requests++
+	if !failed {
+		o.consecutiveFailures = 0
+		return false
+	}
+	o.failures++
+	o.consecutiveFailures++
+	return o.consecutiveFailures >= outlierConsecutiveFailures ||
+		(o.requests >= outlierMinRequests && float64(o.failures) >= outlierFailureRate*float64(o.requests))
+}
+
+// eject ejects the pod at time now and returns for how long.
+func (o *outlierDetector) eject(now time.Time) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	d := outlierBaseEjection << o.ejections
+	if d > outlierMaxEjection || d <= 0 {
+		d = outlierMaxEjection
+	} else {
+		o.ejections++
+	}
+	o.ejectedUntil = now.Add(d)
+	// Start over once the pod is back.
+	o.consecutiveFailures = 0
+	o.intervalStart = o.ejectedUntil
+	o.requests, o.failures = 0, 0
+	return d
+}
+
+// ejected returns whether the pod is ejected at time now.
+func (o *outlierDetector) ejected(now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return now.Before(o.ejectedUntil)
+}
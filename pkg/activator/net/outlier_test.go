@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package net
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	pkgnet "knative.dev/networking/pkg/apis/networking"
+	. "knative.dev/pkg/logging/testing"
+	rtesting "knative.dev/pkg/reconciler/testing"
+	"knative.dev/serving/pkg/activator/util"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestOutlierConsecutiveFailures(t *testing.T) {
+	var o outlierDetector
+	now := time.Now()
+	for i := 1; i < outlierConsecutiveFailures; i++ {
+		if o.record(now, true /*failed*/) {
+			t.Fatalf("Ejected after %d failures, want %d", i, outlierConsecutiveFailures)
+		}
+	}
+	// A success resets the streak.
+	o.record(now, false /*failed*/)
+	for i := 1; i < outlierConsecutiveFailures; i++ {
+		if o.record(now, true /*failed*/) {
+			t.Fatalf("Ejected after %d failures following a success", i)
+		}
+	}
+	if !o.record(now, true /*failed*/) {
+		t.Fatalf("Not ejected after %d failures", outlierConsecutiveFailures)
+	}
+}
+
+func TestOutlierFailureRate(t *testing.T) {
+	var o outlierDetector
+	now := time.Now()
+	// Alternate, so that the consecutive failures never trigger.
+	for i := 1; i < outlierMinRequests; i++ {
+		if o.record(now, i%2 == 0) {
+			t.Fatalf("Ejected after %d requests, want at least %d", i, outlierMinRequests)
+		}
+	}
+	if !o.record(now, true /*failed*/) {
+		t.Fatal("Not ejected with a failure rate of 50%")
+	}
+
+	// The window starts over after the interval.
+	o = outlierDetector{}
+	for i := 1; i < outlierMinRequests; i++ {
+		o.record(now, i%2 == 0)
+	}
+	if o.record(now.Add(outlierInterval), true /*failed*/) {
+		t.Fatal("Ejected with failures from the previous interval")
+	}
+}
+
+func TestOutlierEjectionBackoff(t *testing.T) {
+	var o outlierDetector
+	now := time.Now()
+	want := outlierBaseEjection
+	for i := 0; i < 6; i++ {
+		d := o.eject(now)
+		if d != want {
+			t.Errorf("Ejection #%d = %v, want: %v", i+1, d, want)
+		}
+		if !o.ejected(now.Add(d - time.Second)) {
+			t.Errorf("Ejection #%d: not ejected before it ends", i+1)
+		}
+		if o.ejected(now.Add(d)) {
+			t.Errorf("Ejection #%d: still ejected after it ends", i+1)
+		}
+		// Outcomes while ejected are ignored.
+		for j := 0; j < outlierConsecutiveFailures; j++ {
+			if o.record(now.Add(time.Second), true /*failed*/) {
+				t.Fatalf("Ejection #%d: ejected again while ejected", i+1)
+			}
+		}
+		now = now.Add(d)
+		if want *= 2; want > outlierMaxEjection {
+			want = outlierMaxEjection
+		}
+	}
+
+	// The backoff decays while the pod behaves.
+	before := o.ejections
+	o.record(now.Add(2*outlierInterval), false /*failed*/)
+	if got, want := o.ejections, before-1; got != want {
+		t.Errorf("ejections = %d, want: %d", got, want)
+	}
+}
+
+func TestDropEjected(t *testing.T) {
+	now := time.Now()
+	trackers := makeTrackers(4, 0)
+	if got := dropEjected(trackers, now); len(got) != 4 {
+		t.Errorf("dropEjected = %v, want all the trackers", got)
+	}
+	trackers[1].outlier.eject(now)
+	trackers[3].outlier.eject(now)
+	got := dropEjected(trackers, now)
+	if len(got) != 2 || got[0] != trackers[0] || got[1] != trackers[2] {
+		t.Errorf("dropEjected = %v, want: %v", got, []*podTracker{trackers[0], trackers[2]})
+	}
+	// The input is not modified.
+	if trackers[1].dest != "1" {
+		t.Errorf("trackers were modified: %v", trackers)
+	}
+	// All of them ejected, keep them all.
+	trackers[0].outlier.eject(now)
+	trackers[2].outlier.eject(now)
+	if got := dropEjected(trackers, now); len(got) != 4 {
+		t.Errorf("dropEjected = %v, want all the trackers", got)
+	}
+}
+
+func TestThrottlerOutlierEjection(t *testing.T) {
+	revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	rt := newRevisionThrottler(context.Background(), revID, 0 /*cc*/, pkgnet.ServicePortNameHTTP1,
+		serving.LoadBalancingPolicyFirstAvailable, false /*sessionAffinity*/, queue.BreakerParams{}, TestLogger(t))
+	changed := 0
+	rt.outliersChanged = func() { changed++ }
+	rt.numActivators.Store(1)
+	rt.activatorIndex.Store(0)
+	trackers := makeTrackers(3, 0)
+	rt.updateThrottlerState(3, trackers, nil /*clusterIP*/)
+
+	failed := errors.New("failed")
+	for i := 1; i < outlierConsecutiveFailures; i++ {
+		if err := rt.recordOutcome(trackers[0], failed); err != failed {
+			t.Fatalf("recordOutcome = %v, want: %v", err, failed)
+		}
+	}
+	err := rt.recordOutcome(trackers[0], failed)
+	var ejected *util.PodEjectedError
+	if !errors.As(err, &ejected) {
+		t.Fatalf("recordOutcome = %v, want a PodEjectedError", err)
+	}
+	if ejected.Dest != trackers[0].dest || !errors.Is(err, failed) {
+		t.Errorf("PodEjectedError = %#v, want it for %s wrapping %v", ejected, trackers[0].dest, failed)
+	}
+	if changed != 1 || !rt.outliersDirty.Load() {
+		t.Errorf("outliersChanged called %d times, dirty = %v, want 1 and true", changed, rt.outliersDirty.Load())
+	}
+
+	// Reassigning drops the ejected pod.
+	rt.updateCapacity(3)
+	if got := len(rt.assignedTrackers); got != 2 {
+		t.Errorf("Assigned %d trackers, want: 2", got)
+	}
+	for i := 0; i < 10; i++ {
+		cb, pt := rt.acquireDest(context.Background())
+		cb()
+		if pt == trackers[0] {
+			t.Fatal("Picked the ejected pod")
+		}
+	}
+
+	// No more than half of the pods get ejected.
+	for i := 0; i < outlierConsecutiveFailures; i++ {
+		if err := rt.recordOutcome(trackers[1], failed); err != failed {
+			t.Fatalf("recordOutcome = %v, want: %v", err, failed)
+		}
+	}
+	if got, want := rt.numEjected.Load(), int32(1); got != want {
+		t.Errorf("numEjected = %d, want: %d", got, want)
+	}
+}
+
+func TestThrottlerHandleOutliers(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	throttler := newTestThrottler(ctx)
+	revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	rt := newRevisionThrottler(context.Background(), revID, 0 /*cc*/, pkgnet.ServicePortNameHTTP1,
+		"" /*lbPolicy*/, false /*sessionAffinity*/, queue.BreakerParams{}, TestLogger(t))
+	rt.outliersChanged = throttler.signalOutliers
+	throttler.revisionThrottlers[revID] = rt
+	rt.numActivators.Store(1)
+	rt.activatorIndex.Store(0)
+	trackers := makeTrackers(2, 0)
+	rt.updateThrottlerState(2, trackers, nil /*clusterIP*/)
+
+	trackers[0].outlier.eject(time.Now())
+	rt.markOutliersChanged()
+	// Signalling twice must not block.
+	rt.markOutliersChanged()
+
+	select {
+	case <-throttler.outlierCh:
+	default:
+		t.Fatal("The throttler was not signalled")
+	}
+	throttler.handleOutliers()
+	if rt.outliersDirty.Load() {
+		t.Error("The revision is still marked dirty")
+	}
+	if got := len(rt.assignedTrackers); got != 1 {
+		t.Errorf("Assigned %d trackers, want: 1", got)
+	}
+}
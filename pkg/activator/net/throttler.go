@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"sort"
 	"sync"
+	"time"
 
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
@@ -30,6 +31,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
@@ -45,6 +47,7 @@ import (
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1/revision"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/networking"
 	"knative.dev/serving/pkg/queue"
 )
@@ -81,12 +84,33 @@ type podTracker struct {
 	weight atomic.Int32
 	// decreaseWeight is an allocation optimization for the randomChoice2 policy.
 	decreaseWeight func()
+
+	// outlier decides when the pod is ejected from load balancing.
+	outlier outlierDetector
 }
 
 func (p *podTracker) increaseWeight() {
 	p.weight.Add(1)
 }
 
+// reserveWeighted reserves a slot on the tracker and increases its weight.
+// The returned callback undoes both.
+func (p *podTracker) reserveWeighted(ctx context.Context) (func(), bool) {
+	cb, ok := p.Reserve(ctx)
+	if !ok {
+		return noop, false
+	}
+	p.increaseWeight()
+	if p.b == nil {
+		// Nothing to release, avoid the closure allocation.
+		return p.decreaseWeight, true
+	}
+	return func() {
+		cb()
+		p.decreaseWeight()
+	}, true
+}
+
 func (p *podTracker) getWeight() int32 {
 	return p.weight.Load()
 }
@@ -118,7 +142,7 @@ func (p *podTracker) Reserve(ctx context.Context) (func(), bool) {
 
 type breaker interface {
 	Capacity() int
-	Maybe(ctx context.Context, thunk func()) error
+	Maybe(ctx context.Context, p queue.Priority, thunk func()) error
 	UpdateConcurrency(int)
 	Reserve(ctx context.Context) (func(), bool)
 }
@@ -131,6 +155,21 @@ type revisionThrottler struct {
 	revID                types.NamespacedName
 	containerConcurrency int
 	lbPolicy             lbPolicy
+	lbReporter           pickReporter
+
+	// sessionAffinity is set if requests carrying a session affinity key are
+	// routed through hashRing rather than lbPolicy.
+	sessionAffinity  bool
+	hashRing         *hashRing
+	affinityReporter pickReporter
+
+	// outliersChanged is called when a pod got ejected or reinstated,
+	// so that the trackers get reassigned. outliersDirty marks this
+	// revision as the one that needs reassigning.
+	outliersChanged func()
+	outliersDirty   atomic.Bool
+	// numEjected is the number of pods currently ejected.
+	numEjected atomic.Int32
 
 	// These are used in slicing to infer which pods to assign
 	// to this activator.
@@ -167,28 +206,18 @@ type revisionThrottler struct {
 	logger *zap.SugaredLogger
 }
 
-func newRevisionThrottler(revID types.NamespacedName,
-	containerConcurrency int, proto string,
+func newRevisionThrottler(reporterCtx context.Context, revID types.NamespacedName,
+	containerConcurrency int, proto, lbPolicyName string, sessionAffinity bool,
 	breakerParams queue.BreakerParams,
 	logger *zap.SugaredLogger) *revisionThrottler {
 	logger = logger.With(zap.String(logkey.Key, revID.String()))
-	var (
-		revBreaker breaker
-		lbp        lbPolicy
-	)
-	switch {
-	case containerConcurrency == 0:
+	var revBreaker breaker
+	if containerConcurrency == 0 {
 		revBreaker = newInfiniteBreaker(logger)
-		lbp = randomChoice2Policy
-	case containerConcurrency <= 3:
-		// For very low CC values use first available pod.
-		revBreaker = queue.NewBreaker(breakerParams)
-		lbp = firstAvailableLBPolicy
-	default:
-		// Otherwise RR.
+	} else {
 		revBreaker = queue.NewBreaker(breakerParams)
-		lbp = newRoundRobinPolicy()
 	}
+	lbp, lbPolicyName := pickLBPolicy(lbPolicyName, containerConcurrency)
 	return &revisionThrottler{
 		revID:                revID,
 		containerConcurrency: containerConcurrency,
@@ -197,6 +226,10 @@ func newRevisionThrottler(revID types.NamespacedName,
 		protocol:             proto,
 		activatorIndex:       *atomic.NewInt32(-1), // Start with unknown.
 		lbPolicy:             lbp,
+		lbReporter:           newPickReporter(reporterCtx, lbPolicyName),
+		sessionAffinity:      sessionAffinity,
+		affinityReporter:     newPickReporter(reporterCtx, lbPolicySessionAffinity),
+		outliersChanged:      noop,
 	}
 }
 
@@ -211,7 +244,38 @@ func (rt *revisionThrottler) acquireDest(ctx context.Context) (func(), *podTrack
 	if rt.clusterIPTracker != nil {
 		return noop, rt.clusterIPTracker
 	}
-	return rt.lbPolicy(ctx, rt.assignedTrackers)
+	if excluded := util.ExcludedDestsFrom(ctx); excluded.Len() > 0 {
+		// The request is retried, so send it to a pod it hasn't failed on,
+		// bypassing session affinity.
+		cb, tracker := rt.lbPolicy(ctx, excludeDests(rt.assignedTrackers, excluded))
+		rt.lbReporter.report(tracker)
+		return cb, tracker
+	}
+	if rt.hashRing != nil {
+		if key := util.SessionAffinityKeyFrom(ctx); key != "" {
+			cb, tracker := rt.hashRing.pick(ctx, key)
+			rt.affinityReporter.report(tracker)
+			return cb, tracker
+		}
+	}
+	cb, tracker := rt.lbPolicy(ctx, rt.assignedTrackers)
+	rt.lbReporter.report(tracker)
+	return cb, tracker
+}
+
+// excludeDests returns the trackers whose dest is not excluded, or all the
+// trackers if that would leave none.
+func excludeDests(trackers []*podTracker, excluded sets.String) []*podTracker {
+	ret := make([]*podTracker, 0, len(trackers))
+	for _, t := range trackers {
+		if !excluded.Has(t.dest) {
+			ret = append(ret, t)
+		}
+	}
+	if len(ret) == 0 {
+		return trackers
+	}
+	return ret
 }
 
 func (rt *revisionThrottler) try(ctx context.Context, function func(string) error) error {
@@ -223,7 +287,7 @@ func (rt *revisionThrottler) try(ctx context.Context, function func(string) erro
 	reenqueue := true
 	for reenqueue {
 		reenqueue = false
-		if err := rt.breaker.Maybe(ctx, func() {
+		if err := rt.breaker.Maybe(ctx, util.PriorityFrom(ctx), func() {
 			cb, tracker := rt.acquireDest(ctx)
 			if tracker == nil {
 				// This can happen if individual requests raced each other or if pod
@@ -233,7 +297,7 @@ func (rt *revisionThrottler) try(ctx context.Context, function func(string) erro
 			}
 			defer cb()
 			// We already reserved a guaranteed spot. So just execute the passed functor.
-			ret = function(tracker.dest)
+			ret = rt.recordOutcome(tracker, function(tracker.dest))
 		}); err != nil {
 			return err
 		}
@@ -241,6 +305,69 @@ func (rt *revisionThrottler) try(ctx context.Context, function func(string) erro
 	return ret
 }
 
+// recordOutcome feeds the outcome of a request to the outlier detection of
+// the pod that served it and ejects the pod if it became an outlier.
+// Any error returned by the proxying function counts as a failure.
+func (rt *revisionThrottler) recordOutcome(tracker *podTracker, err error) error {
+	now := time.Now()
+	rt.mux.RLock()
+	if tracker == rt.clusterIPTracker {
+		// Not a pod.
+		rt.mux.RUnlock()
+		return err
+	}
+	total := len(rt.podTrackers)
+	rt.mux.RUnlock()
+	if !tracker.outlier.record(now, err != nil) {
+		return err
+	}
+
+	ejected := int(rt.numEjected.Inc())
+	if ejected*100 > total*outlierMaxEjectedPercent {
+		rt.numEjected.Dec()
+		rt.logger.Debugw("Not ejecting outlier pod, too many pods are ejected already",
+			zap.String("dest", tracker.dest), zap.Int("ejected", ejected-1), zap.Int("pods", total))
+		return err
+	}
+	d := tracker.outlier.eject(now)
+	rt.logger.Warnw("Ejecting outlier pod from load balancing",
+		zap.String("dest", tracker.dest), zap.Duration("duration", d), zap.Error(err))
+	rt.markOutliersChanged()
+	time.AfterFunc(d, func() {
+		rt.numEjected.Dec()
+		rt.markOutliersChanged()
+	})
+	return &util.PodEjectedError{Dest: tracker.dest, Duration: d, Err: err}
+}
+
+func (rt *revisionThrottler) markOutliersChanged() {
+	rt.outliersDirty.Store(true)
+	rt.outliersChanged()
+}
+
+// dropEjected returns the trackers that are not ejected at time now.
+// If all of them are, the trackers are returned as they are, since
+// having no pods to route to would be worse.
+func dropEjected(trackers []*podTracker, now time.Time) []*podTracker {
+	var ret []*podTracker
+	for i, t := range trackers {
+		if !t.outlier.ejected(now) {
+			if ret != nil {
+				ret = append(ret, t)
+			}
+			continue
+		}
+		if ret == nil {
+			// First ejected one, copy the ones we've seen so far.
+			ret = append(make([]*podTracker, 0, len(trackers)-1), trackers[:i]...)
+		}
+	}
+	if len(ret) == 0 {
+		return trackers
+	}
+	return ret
+}
+
 func (rt *revisionThrottler) calculateCapacity(size, activatorCount int) int {
 	targetCapacity := rt.containerConcurrency * size
 
@@ -278,6 +405,7 @@ func (rt *revisionThrottler) updateCapacity(backendCount int) {
 	// We have to make assignments on each updateCapacity, since if number
 	// of activators changes, then we need to rebalance the assignedTrackers.
 	ac, ai := int(rt.numActivators.Load()), int(rt.activatorIndex.Load())
+	var healthy []*podTracker
 	numTrackers := func() int {
 		// We do not have to process the `podTrackers` under lock, since
 		// updateCapacity is guaranteed to be executed by a single goroutine.
@@ -296,13 +424,30 @@ func (rt *revisionThrottler) updateCapacity(backendCount int) {
 		assigned := rt.podTrackers
 		if rt.containerConcurrency > 0 {
 			rt.resetTrackers()
-			assigned = assignSlice(rt.podTrackers, ai, ac, rt.containerConcurrency)
+			if rt.sessionAffinity {
+				assigned = shareTrackers(rt.podTrackers, ai, ac, rt.containerConcurrency)
+			} else {
+				assigned = assignSlice(rt.podTrackers, ai, ac, rt.containerConcurrency)
+			}
 		}
+		// Outliers are ejected after slicing, so that the other activators
+		// keep their slices.
+		now := time.Now()
+		assigned = dropEjected(assigned, now)
+		healthy = dropEjected(rt.podTrackers, now)
 		rt.logger.Debugf("Trackers %d/%d: assignment: %v", ai, ac, assigned)
+		// Session affinity hashes over all the pods, so that every activator
+		// sends a key to the same pod. That's why shareTrackers doesn't slice
+		// them.
+		var ring *hashRing
+		if rt.sessionAffinity {
+			ring = newHashRing(healthy)
+		}
 		// The actual write out of the assigned trackers has to be under lock.
 		rt.mux.Lock()
 		defer rt.mux.Unlock()
 		rt.assignedTrackers = assigned
+		rt.hashRing = ring
 		return len(assigned)
 	}()
 
@@ -310,7 +455,7 @@ func (rt *revisionThrottler) updateCapacity(backendCount int) {
 	if numTrackers > 0 {
 		// Capacity is computed based off of number of trackers,
 		// when using pod direct routing.
-		capacity = rt.calculateCapacity(len(rt.podTrackers), ac)
+		capacity = rt.calculateCapacity(len(healthy), ac)
 	} else {
 		// Capacity is computed off of number of ready backends,
 		// when we are using clusterIP routing.
@@ -405,6 +550,22 @@ func assignSlice(trackers []*podTracker, selfIndex, numActivators, cc int) []*po
 	return x
 }
 
+// shareTrackers assigns all the trackers to this activator, each with its
+// share of the pod's capacity. It's used instead of assignSlice when every
+// activator may send requests to any of the pods, as with session affinity,
+// so that together they don't exceed the container concurrency of a pod.
+func shareTrackers(trackers []*podTracker, selfIndex, numActivators, cc int) []*podTracker {
+	// When we're unassigned, doesn't matter what we return.
+	if selfIndex == -1 || numActivators <= 1 {
+		return trackers
+	}
+	dcc := int(math.Ceil(float64(cc) / float64(numActivators)))
+	for _, t := range trackers {
+		t.UpdateConcurrency(dcc)
+	}
+	return trackers
+}
+
 // This function will never be called in parallel but `try` can be called in parallel to this so we need
 // to lock on updating concurrency / trackers
 func (rt *revisionThrottler) handleUpdate(update revisionDestsUpdate) {
@@ -458,6 +619,9 @@ type Throttler struct {
 	ipAddress               string // The IP address of this activator.
 	logger                  *zap.SugaredLogger
 	epsUpdateCh             chan *corev1.Endpoints
+	// outlierCh is signalled when pods of some revisions got ejected or
+	// reinstated. It is buffered, so that signalling never blocks.
+	outlierCh chan struct{}
 }
 
 // NewThrottler creates a new Throttler
@@ -470,6 +634,7 @@ func NewThrottler(ctx context.Context, ipAddr string) *Throttler {
 		ipAddress:          ipAddr,
 		logger:             logging.FromContext(ctx),
 		epsUpdateCh:        make(chan *corev1.Endpoints),
+		outlierCh:          make(chan struct{}, 1),
 	}
 
 	// Watch revisions to create throttler with backlog immediately and delete
@@ -513,6 +678,8 @@ func (t *Throttler) run(updateCh <-chan revisionDestsUpdate) {
 			t.handleUpdate(update)
 		case eps := <-t.epsUpdateCh:
 			t.handlePubEpsUpdate(eps)
+		case <-t.outlierCh:
+			t.handleOutliers()
 		}
 	}
 }
@@ -545,18 +712,51 @@ func (t *Throttler) getOrCreateRevisionThrottler(revID types.NamespacedName) (*r
 		if err != nil {
 			return nil, err
 		}
+		_, _, sessionAffinity := rev.SessionAffinity()
 		revThrottler = newRevisionThrottler(
+			metrics.RevisionContext(rev.Namespace, rev.Labels[serving.ServiceLabelKey],
+				rev.Labels[serving.ConfigurationLabelKey], rev.Name),
 			revID,
 			int(rev.Spec.GetContainerConcurrency()),
 			pkgnet.ServicePortName(rev.GetProtocol()),
+			rev.Annotations[serving.LoadBalancingPolicyAnnotationKey],
+			sessionAffinity,
 			queue.BreakerParams{QueueDepth: breakerQueueDepth, MaxConcurrency: revisionMaxConcurrency},
 			t.logger,
 		)
+		revThrottler.outliersChanged = t.signalOutliers
 		t.revisionThrottlers[revID] = revThrottler
 	}
 	return revThrottler, nil
 }
 
+// signalOutliers tells the run loop that some revisions need their
+// trackers reassigned, without blocking the caller.
+func (t *Throttler) signalOutliers() {
+	select {
+	case t.outlierCh <- struct{}{}:
+	default:
+		// Already signalled.
+	}
+}
+
+// handleOutliers reassigns the trackers of the revisions whose pods got
+// ejected or reinstated.
+func (t *Throttler) handleOutliers() {
+	t.revisionThrottlersMutex.RLock()
+	dirty := make([]*revisionThrottler, 0, 1)
+	for _, rt := range t.revisionThrottlers {
+		if rt.outliersDirty.CAS(true, false) {
+			dirty = append(dirty, rt)
+		}
+	}
+	t.revisionThrottlersMutex.RUnlock()
+
+	for _, rt := range dirty {
+		rt.updateCapacity(rt.backendCount)
+	}
+}
+
 // revisionUpdated is used to ensure we have a backlog set up for a revision as soon as it is created
 // rather than erroring with revision not found until a networking probe succeeds
 func (t *Throttler) revisionUpdated(obj interface{}) {
@@ -745,8 +945,10 @@ func (ib *infiniteBreaker) UpdateConcurrency(cc int) {
 	}
 }
 
-// Maybe executes thunk when capacity is available
-func (ib *infiniteBreaker) Maybe(ctx context.Context, thunk func()) error {
+// Maybe executes thunk when capacity is available.
+// The priority is ignored: once capacity shows up, all the waiting
+// requests are let through at once.
+func (ib *infiniteBreaker) Maybe(ctx context.Context, _ queue.Priority, thunk func()) error {
 	has := ib.Capacity()
 	// We're scaled to serve.
 	if has > 0 {
@@ -386,6 +386,21 @@ func TestThrottlerSuccesses(t *testing.T) {
 		}},
 		requests:  3,
 		wantDests: sets.NewString("128.0.0.1:1234"),
+	}, {
+		name: "clumping test with round robin policy annotation",
+		revision: func() *v1.Revision {
+			rev := revision(types.NamespacedName{Namespace: testNamespace, Name: testRevision}, pkgnet.ProtocolHTTP1, 3)
+			rev.Annotations = map[string]string{
+				serving.LoadBalancingPolicyAnnotationKey: serving.LoadBalancingPolicyRoundRobin,
+			}
+			return rev
+		}(),
+		initUpdates: []revisionDestsUpdate{{
+			Rev:   types.NamespacedName{Namespace: testNamespace, Name: testRevision},
+			Dests: sets.NewString("128.0.0.1:1234", "128.0.0.2:1234", "128.0.0.2:4236"),
+		}},
+		requests:  3,
+		wantDests: sets.NewString("128.0.0.1:1234", "128.0.0.2:1234", "128.0.0.2:4236"),
 	}, {
 		name: "roundrobin test",
 		revision: revision(types.NamespacedName{Namespace: testNamespace, Name: testRevision},
@@ -540,7 +555,7 @@ func TestPodAssignmentFinite(t *testing.T) {
 	defer cancel()
 
 	throttler := newTestThrottler(ctx)
-	rt := newRevisionThrottler(revName, 42 /*cc*/, pkgnet.ServicePortNameHTTP1, testBreakerParams, logger)
+	rt := newRevisionThrottler(context.Background(), revName, 42 /*cc*/, pkgnet.ServicePortNameHTTP1, "" /*lbPolicy*/, false /*sessionAffinity*/, testBreakerParams, logger)
 	rt.numActivators.Store(4)
 	rt.activatorIndex.Store(0)
 	throttler.revisionThrottlers[revName] = rt
@@ -594,7 +609,7 @@ func TestPodAssignmentInfinite(t *testing.T) {
 	defer cancel()
 
 	throttler := newTestThrottler(ctx)
-	rt := newRevisionThrottler(revName, 0 /*cc*/, pkgnet.ServicePortNameHTTP1, testBreakerParams, logger)
+	rt := newRevisionThrottler(context.Background(), revName, 0 /*cc*/, pkgnet.ServicePortNameHTTP1, "" /*lbPolicy*/, false /*sessionAffinity*/, testBreakerParams, logger)
 	throttler.revisionThrottlers[revName] = rt
 
 	update := revisionDestsUpdate{
@@ -824,8 +839,8 @@ func TestMultipleActivators(t *testing.T) {
 
 func TestInfiniteBreakerCreation(t *testing.T) {
 	// This test verifies that we use infiniteBreaker when CC==0.
-	tttl := newRevisionThrottler(types.NamespacedName{Namespace: "a", Name: "b"}, 0, /*cc*/
-		pkgnet.ServicePortNameHTTP1, queue.BreakerParams{}, TestLogger(t))
+	tttl := newRevisionThrottler(context.Background(), types.NamespacedName{Namespace: "a", Name: "b"}, 0, /*cc*/
+		pkgnet.ServicePortNameHTTP1, "" /*lbPolicy*/, false /*sessionAffinity*/, queue.BreakerParams{}, TestLogger(t))
 	if _, ok := tttl.breaker.(*infiniteBreaker); !ok {
 		t.Errorf("The type of revisionBreaker = %T, want %T", tttl, (*infiniteBreaker)(nil))
 	}
@@ -867,7 +882,7 @@ func TestInfiniteBreaker(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	if err := b.Maybe(ctx, nil); err == nil {
+	if err := b.Maybe(ctx, queue.PriorityInteractive, nil); err == nil {
 		t.Error("Should have failed, but didn't")
 	}
 
@@ -882,7 +897,7 @@ func TestInfiniteBreaker(t *testing.T) {
 		ctx, cancel = context.WithCancel(context.Background())
 		cancel()
 		res := false
-		if err := b.Maybe(ctx, func() { res = true }); err != nil {
+		if err := b.Maybe(ctx, queue.PriorityInteractive, func() { res = true }); err != nil {
 			t.Error("Should have succeeded, but didn't")
 		}
 		if !res {
@@ -896,7 +911,7 @@ func TestInfiniteBreaker(t *testing.T) {
 	// Repeat initial test.
 	ctx, cancel = context.WithCancel(context.Background())
 	cancel()
-	if err := b.Maybe(ctx, nil); err == nil {
+	if err := b.Maybe(ctx, queue.PriorityInteractive, nil); err == nil {
 		t.Error("Should have failed, but didn't")
 	}
 	if got, want := b.Capacity(), 0; got != want {
@@ -913,7 +928,7 @@ func TestInfiniteBreaker(t *testing.T) {
 		b.UpdateConcurrency(1)
 	}()
 	res := false
-	if err := b.Maybe(ctx, func() { res = true }); err != nil {
+	if err := b.Maybe(ctx, queue.PriorityInteractive, func() { res = true }); err != nil {
 		t.Error("Should have succeeded, but didn't")
 	}
 	if !res {
@@ -1162,3 +1177,29 @@ func TestAssignSlice(t *testing.T) {
 		}
 	})
 }
+
+func TestThrottlerExcludedDests(t *testing.T) {
+	revID := types.NamespacedName{Namespace: "ns", Name: "rev"}
+	rt := newRevisionThrottler(context.Background(), revID, 0 /*cc*/, pkgnet.ServicePortNameHTTP1,
+		serving.LoadBalancingPolicyFirstAvailable, true /*sessionAffinity*/, queue.BreakerParams{}, TestLogger(t))
+	rt.numActivators.Store(1)
+	rt.activatorIndex.Store(0)
+	rt.updateThrottlerState(3, makeTrackers(3, 0), nil /*clusterIP*/)
+
+	// Retries skip the pods they failed on, even with session affinity.
+	ctx := util.WithSessionAffinityKey(context.Background(), "user-42")
+	ctx = util.WithExcludedDests(ctx, sets.NewString("0", "1"))
+	cb, pt := rt.acquireDest(ctx)
+	cb()
+	if pt == nil || pt.dest != "2" {
+		t.Errorf("acquireDest = %v, want dest 2", pt)
+	}
+
+	// If every pod was excluded, any of them is used.
+	ctx = util.WithExcludedDests(context.Background(), sets.NewString("0", "1", "2"))
+	cb, pt = rt.acquireDest(ctx)
+	cb()
+	if pt == nil {
+		t.Error("acquireDest = nil, want a tracker")
+	}
+}
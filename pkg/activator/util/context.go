@@ -25,12 +25,19 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/queue"
 )
 
 type (
-	revisionKey struct{}
-	revIDKey    struct{}
+	revisionKey        struct{}
+	revIDKey           struct{}
+	sessionAffinityKey struct{}
+	retryPolicyKey     struct{}
+	excludedDestsKey   struct{}
+	priorityKey        struct{}
 )
 
 // WithRevision attaches the Revision object to the context.
@@ -52,3 +59,51 @@ func WithRevID(ctx context.Context, revID types.NamespacedName) context.Context
 func RevIDFrom(ctx context.Context) types.NamespacedName {
 	return ctx.Value(revIDKey{}).(types.NamespacedName)
 }
+
+// WithSessionAffinityKey attaches the session affinity key of the request to the context.
+func WithSessionAffinityKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sessionAffinityKey{}, key)
+}
+
+// SessionAffinityKeyFrom retrieves the session affinity key from the context,
+// or an empty string if the request has none.
+func SessionAffinityKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(sessionAffinityKey{}).(string)
+	return key
+}
+
+// WithRetryPolicy attaches the retry policy of the revision to the context.
+func WithRetryPolicy(ctx context.Context, p *serving.RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, p)
+}
+
+// RetryPolicyFrom retrieves the retry policy from the context, or nil if
+// the request must not be retried.
+func RetryPolicyFrom(ctx context.Context) *serving.RetryPolicy {
+	p, _ := ctx.Value(retryPolicyKey{}).(*serving.RetryPolicy)
+	return p
+}
+
+// WithExcludedDests attaches the destinations a retried request already
+// failed on to the context, so that it's sent to another pod.
+func WithExcludedDests(ctx context.Context, dests sets.String) context.Context {
+	return context.WithValue(ctx, excludedDestsKey{}, dests)
+}
+
+// ExcludedDestsFrom retrieves the destinations to avoid from the context.
+func ExcludedDestsFrom(ctx context.Context) sets.String {
+	dests, _ := ctx.Value(excludedDestsKey{}).(sets.String)
+	return dests
+}
+
+// WithPriority attaches the priority class of the request to the context.
+func WithPriority(ctx context.Context, p queue.Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom retrieves the priority class of the request from the context,
+// defaulting to interactive.
+func PriorityFrom(ctx context.Context) queue.Priority {
+	p, _ := ctx.Value(priorityKey{}).(queue.Priority)
+	return p
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPodFailed is returned by the function passed to the throttler when the
+// pod failed to serve the request, so that it counts towards the outlier
+// detection of the pod. The response has already been written by then.
+var ErrPodFailed = errors.New("pod failed the request")
+
+// PodEjectedError is returned by the throttler when a failed request caused
+// its pod to be ejected from load balancing.
+type PodEjectedError struct {
+	// Dest is the address of the ejected pod.
+	Dest string
+	// Duration is how long the pod is ejected for.
+	Duration time.Duration
+	// Err is the error the request failed with.
+	Err error
+}
+
+// Error implements error.
+func (e *PodEjectedError) Error() string {
+	return fmt.Sprintf("pod %s ejected for %v", e.Dest, e.Duration)
+}
+
+// Unwrap returns the error the request failed with.
+func (e *PodEjectedError) Unwrap() error {
+	return e.Err
+}
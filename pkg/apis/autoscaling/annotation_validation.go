@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 )
@@ -53,11 +55,21 @@ func getIntGE0(m map[string]string, k string) (int32, *apis.FieldError) {
 func ValidateAnnotations(ctx context.Context, config *autoscalerconfig.Config, anns map[string]string) *apis.FieldError {
 	return validateClass(anns).
 		Also(validateMinMaxScale(ctx, config, anns)).
+		Also(validateMinScaleSchedule(anns)).
+		Also(validateWarmPool(anns)).
 		Also(validateFloats(anns)).
 		Also(validateWindow(anns)).
+		Also(validateWindowHalfLife(anns)).
 		Also(validateLastPodRetention(anns)).
+		Also(validateIdleTimeout(anns)).
 		Also(validateScaleDownDelay(anns)).
+		Also(validatePanicDurations(anns)).
 		Also(validateMetric(anns)).
+		Also(validateMetricEndpoint(anns)).
+		Also(validateMetricType(anns)).
+		Also(validateScrape(config, anns)).
+		Also(validateLatency(anns)).
+		Also(validateAlgorithm(anns)).
 		Also(validateInitialScale(config, anns))
 }
 
@@ -87,6 +99,29 @@ func validateFloats(annotations map[string]string) (errs *apis.FieldError) {
 				PanicThresholdPercentageMax, PanicThresholdPercentageAnnotationKey))
 		}
 	}
+	if v, ok := annotations[PanicExitThresholdPercentageAnnotationKey]; ok {
+		if fv, err := strconv.ParseFloat(v, 64); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, PanicExitThresholdPercentageAnnotationKey))
+		} else if fv < 0 || fv > PanicThresholdPercentageMax {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(v, 0,
+				PanicThresholdPercentageMax, PanicExitThresholdPercentageAnnotationKey))
+		} else if t, err := strconv.ParseFloat(annotations[PanicThresholdPercentageAnnotationKey], 64); err == nil && fv > t {
+			errs = errs.Also(apis.ErrGeneric(
+				fmt.Sprintf("%s=%v must not exceed %s=%v", PanicExitThresholdPercentageAnnotationKey, fv,
+					PanicThresholdPercentageAnnotationKey, t),
+				PanicExitThresholdPercentageAnnotationKey))
+		}
+	}
+
+	for _, k := range []string{MaxScaleUpRateAnnotationKey, MaxScaleDownRateAnnotationKey} {
+		if v, ok := annotations[k]; ok {
+			if fv, err := strconv.ParseFloat(v, 64); err != nil || math.IsNaN(fv) {
+				errs = errs.Also(apis.ErrInvalidValue(v, k))
+			} else if fv <= MaxScaleRateMin {
+				errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("%s should be greater than %g", v, MaxScaleRateMin), k))
+			}
+		}
+	}
 
 	if v, ok := annotations[TargetAnnotationKey]; ok {
 		if fv, err := strconv.ParseFloat(v, 64); err != nil || fv < TargetMin {
@@ -126,6 +161,19 @@ func validateScaleDownDelay(annotations map[string]string) *apis.FieldError {
 	return errs
 }
 
+func validatePanicDurations(annotations map[string]string) (errs *apis.FieldError) {
+	for _, k := range []string{PanicMinDurationAnnotationKey, PanicCooldownAnnotationKey} {
+		if w, ok := annotations[k]; ok {
+			if d, err := time.ParseDuration(w); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(w, k))
+			} else if d < 0 || d > WindowMax {
+				errs = errs.Also(apis.ErrOutOfBoundsValue(w, time.Duration(0), WindowMax, k))
+			}
+		}
+	}
+	return errs
+}
+
 func validateLastPodRetention(annotations map[string]string) *apis.FieldError {
 	if w, ok := annotations[ScaleToZeroPodRetentionPeriodKey]; ok {
 		if d, err := time.ParseDuration(w); err != nil {
@@ -139,6 +187,30 @@ func validateLastPodRetention(annotations map[string]string) *apis.FieldError {
 	return nil
 }
 
+func validateIdleTimeout(annotations map[string]string) *apis.FieldError {
+	w, ok := annotations[IdleTimeoutAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if annotations[ClassAnnotationKey] == HPA {
+		return apis.ErrInvalidKeyName(IdleTimeoutAnnotationKey, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA))
+	}
+	// Only the stable/panic algorithm scales idle revisions to zero.
+	switch a := annotations[AlgorithmAnnotationKey]; a {
+	case AlgorithmEWMA, AlgorithmPID:
+		return apis.ErrGeneric(fmt.Sprintf("not supported with %s %s", AlgorithmAnnotationKey, a), IdleTimeoutAnnotationKey)
+	}
+	switch d, err := time.ParseDuration(w); {
+	case err != nil:
+		return apis.ErrInvalidValue(w, IdleTimeoutAnnotationKey)
+	case d <= 0 || d > WindowMax:
+		return apis.ErrOutOfBoundsValue(w, time.Second, WindowMax, IdleTimeoutAnnotationKey)
+	case d.Truncate(time.Second) != d:
+		return apis.ErrGeneric("must be specified with at most second precision", IdleTimeoutAnnotationKey)
+	}
+	return nil
+}
+
 func validateWindow(annotations map[string]string) *apis.FieldError {
 	if w, ok := annotations[WindowAnnotationKey]; ok {
 		if annotations[ClassAnnotationKey] == HPA && annotations[MetricAnnotationKey] == CPU {
@@ -156,6 +228,20 @@ func validateWindow(annotations map[string]string) *apis.FieldError {
 	return nil
 }
 
+func validateWindowHalfLife(annotations map[string]string) *apis.FieldError {
+	if w, ok := annotations[WindowHalfLifeAnnotationKey]; ok {
+		switch d, err := time.ParseDuration(w); {
+		case err != nil:
+			return apis.ErrInvalidValue(w, WindowHalfLifeAnnotationKey)
+		case d < 0 || d > WindowMax:
+			return apis.ErrOutOfBoundsValue(w, time.Duration(0), WindowMax, WindowHalfLifeAnnotationKey)
+		case d.Truncate(time.Second) != d:
+			return apis.ErrGeneric("must be specified with at most second precision", WindowHalfLifeAnnotationKey)
+		}
+	}
+	return nil
+}
+
 func validateMinMaxScale(ctx context.Context, config *autoscalerconfig.Config, annotations map[string]string) *apis.FieldError {
 	min, errs := getIntGE0(annotations, MinScaleAnnotationKey)
 	max, err := getIntGE0(annotations, MaxScaleAnnotationKey)
@@ -185,6 +271,50 @@ func validateMinMaxScale(ctx context.Context, config *autoscalerconfig.Config, a
 	return errs
 }
 
+func validateWarmPool(annotations map[string]string) *apis.FieldError {
+	if _, ok := annotations[WarmPoolAnnotationKey]; !ok {
+		return nil
+	}
+	if annotations[ClassAnnotationKey] == HPA {
+		return apis.ErrInvalidKeyName(WarmPoolAnnotationKey, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA))
+	}
+	warm, errs := getIntGE0(annotations, WarmPoolAnnotationKey)
+	// Malformed maxScale is reported by validateMinMaxScale.
+	if max, _ := getIntGE0(annotations, MaxScaleAnnotationKey); max != 0 && warm > max {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("warmPool=%d is greater than maxScale=%d", warm, max),
+			Paths:   []string{WarmPoolAnnotationKey, MaxScaleAnnotationKey},
+		})
+	}
+	return errs
+}
+
+func validateMinScaleSchedule(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[MinScaleScheduleAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if annotations[ClassAnnotationKey] == HPA {
+		return apis.ErrInvalidKeyName(MinScaleScheduleAnnotationKey, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA))
+	}
+	schedules, err := ParseScaleSchedules(v)
+	if err != nil {
+		return apis.ErrGeneric(err.Error(), MinScaleScheduleAnnotationKey)
+	}
+	// Malformed maxScale is reported by validateMinMaxScale.
+	max, _ := getIntGE0(annotations, MaxScaleAnnotationKey)
+	var errs *apis.FieldError
+	for _, ss := range schedules {
+		if max != 0 && ss.MinScale > max {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("schedule entry %q minScale=%d is greater than maxScale=%d", ss.Name, ss.MinScale, max),
+				Paths:   []string{MinScaleScheduleAnnotationKey, MaxScaleAnnotationKey},
+			})
+		}
+	}
+	return errs
+}
+
 func validateMetric(annotations map[string]string) *apis.FieldError {
 	if metric, ok := annotations[MetricAnnotationKey]; ok {
 		classValue := KPA
@@ -194,10 +324,26 @@ func validateMetric(annotations map[string]string) *apis.FieldError {
 		switch classValue {
 		case KPA:
 			switch metric {
-			case Concurrency, RPS:
+			case Concurrency, RPS, CPU, Memory:
+				return nil
+			case Latency:
+				if _, ok := annotations[LatencyTargetAnnotationKey]; !ok {
+					return apis.ErrGeneric(fmt.Sprintf("latencyTarget is required for metric %s", metric), LatencyTargetAnnotationKey)
+				}
+				return nil
+			}
+			if IsValidCustomMetric(metric) {
+				// There is no sensible default target for an application-reported metric.
+				if _, ok := annotations[TargetAnnotationKey]; !ok {
+					return apis.ErrGeneric(fmt.Sprintf("target is required for custom metric %s", metric), TargetAnnotationKey)
+				}
 				return nil
 			}
 		case HPA:
+			if _, ok := annotations[MetricTypeAnnotationKey]; ok {
+				// The metric is served by a metrics adapter, which knows its name.
+				return nil
+			}
 			switch metric {
 			case CPU:
 				return nil
@@ -211,6 +357,169 @@ func validateMetric(annotations map[string]string) *apis.FieldError {
 	return nil
 }
 
+// validateMetricEndpoint verifies the annotations describing where the
+// queue-proxy scrapes the custom metric from.
+func validateMetricEndpoint(annotations map[string]string) (errs *apis.FieldError) {
+	for _, k := range []string{MetricPathAnnotationKey, MetricPortAnnotationKey} {
+		if _, ok := annotations[k]; !ok {
+			continue
+		}
+		if c, ok := annotations[ClassAnnotationKey]; ok && c != KPA {
+			if c == HPA {
+				errs = errs.Also(apis.ErrInvalidKeyName(k, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA)))
+			}
+			// Leave other classes of PodAutoscaler alone.
+			continue
+		}
+		if !IsCustomMetric(annotations[MetricAnnotationKey]) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("only supported with a custom %s", MetricAnnotationKey), k))
+		}
+	}
+
+	if v, ok := annotations[MetricPathAnnotationKey]; ok {
+		// The path is appended to the address of the user container, so it
+		// must be nothing but an absolute path.
+		if _, err := url.Parse(v); err != nil || !strings.HasPrefix(v, "/") ||
+			strings.HasPrefix(v, "//") || strings.ContainsAny(v, "?#") {
+			errs = errs.Also(apis.ErrInvalidValue(v, MetricPathAnnotationKey))
+		}
+	}
+	if v, ok := annotations[MetricPortAnnotationKey]; ok {
+		if port, err := strconv.Atoi(v); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, MetricPortAnnotationKey))
+		} else if port < 1 || port > math.MaxUint16 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(v, 1, math.MaxUint16, MetricPortAnnotationKey))
+		}
+	}
+	return errs
+}
+
+func validateMetricType(annotations map[string]string) (errs *apis.FieldError) {
+	mt, ok := annotations[MetricTypeAnnotationKey]
+	if !ok {
+		for _, k := range []string{MetricSelectorAnnotationKey, MetricObjectAnnotationKey, MetricTargetTypeAnnotationKey} {
+			if _, ok := annotations[k]; ok {
+				errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("requires %s", MetricTypeAnnotationKey), k))
+			}
+		}
+		return errs
+	}
+	if annotations[ClassAnnotationKey] != HPA {
+		return apis.ErrInvalidKeyName(MetricTypeAnnotationKey, apis.CurrentField, fmt.Sprintf("only supported by %s", HPA))
+	}
+	switch mt {
+	case MetricTypePods, MetricTypeObject, MetricTypeExternal:
+	default:
+		return apis.ErrInvalidValue(mt, MetricTypeAnnotationKey)
+	}
+
+	if annotations[MetricAnnotationKey] == "" {
+		errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("metric is required for metricType %s", mt), MetricAnnotationKey))
+	}
+	// There is no sensible default target for a metric served by an adapter.
+	if _, ok := annotations[TargetAnnotationKey]; !ok {
+		errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("target is required for metricType %s", mt), TargetAnnotationKey))
+	}
+	if s, ok := annotations[MetricSelectorAnnotationKey]; ok {
+		if _, err := metav1.ParseToLabelSelector(s); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(s, MetricSelectorAnnotationKey))
+		}
+	}
+	if o, ok := annotations[MetricObjectAnnotationKey]; ok {
+		if mt != MetricTypeObject {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("not supported with metricType %s", mt), MetricObjectAnnotationKey))
+		} else if _, err := ParseMetricObject(o); err != nil {
+			errs = errs.Also(apis.ErrGeneric(err.Error(), MetricObjectAnnotationKey))
+		}
+	}
+	if tt, ok := annotations[MetricTargetTypeAnnotationKey]; ok {
+		switch tt {
+		case MetricTargetAverageValue:
+		case MetricTargetValue:
+			if mt == MetricTypePods {
+				errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("metricType %s is always averaged", mt), MetricTargetTypeAnnotationKey))
+			}
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(tt, MetricTargetTypeAnnotationKey))
+		}
+	}
+	return errs
+}
+
+func validateScrape(config *autoscalerconfig.Config, annotations map[string]string) (errs *apis.FieldError) {
+	strategy, ok := annotations[ScrapeStrategyAnnotationKey]
+	if !ok {
+		strategy = config.ScrapeStrategy
+	} else {
+		if annotations[ClassAnnotationKey] == HPA {
+			return apis.ErrInvalidKeyName(ScrapeStrategyAnnotationKey, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA))
+		}
+		switch strategy {
+		case ScrapeStrategyAdaptive, ScrapeStrategyFull, ScrapeStrategyPercentage:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(strategy, ScrapeStrategyAnnotationKey))
+		}
+	}
+	if v, ok := annotations[ScrapeSamplePercentageAnnotationKey]; ok {
+		if strategy != ScrapeStrategyPercentage {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("requires %s %s", ScrapeStrategyAnnotationKey, ScrapeStrategyPercentage),
+				ScrapeSamplePercentageAnnotationKey))
+		} else if fv, err := strconv.ParseFloat(v, 64); err != nil || math.IsNaN(fv) {
+			errs = errs.Also(apis.ErrInvalidValue(v, ScrapeSamplePercentageAnnotationKey))
+		} else if fv <= 0 || fv > 100 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(v, 0, 100, ScrapeSamplePercentageAnnotationKey))
+		}
+	}
+	return errs
+}
+
+func validateLatency(annotations map[string]string) (errs *apis.FieldError) {
+	if v, ok := annotations[LatencyTargetAnnotationKey]; ok {
+		switch d, err := time.ParseDuration(v); {
+		case err != nil:
+			errs = errs.Also(apis.ErrInvalidValue(v, LatencyTargetAnnotationKey))
+		case d <= 0:
+			errs = errs.Also(apis.ErrGeneric("must be positive", LatencyTargetAnnotationKey))
+		case d.Truncate(time.Millisecond) != d:
+			errs = errs.Also(apis.ErrGeneric("must be specified with at most millisecond precision", LatencyTargetAnnotationKey))
+		}
+	}
+	if v, ok := annotations[LatencyPercentileAnnotationKey]; ok {
+		if fv, err := strconv.ParseFloat(v, 64); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, LatencyPercentileAnnotationKey))
+		} else if fv < LatencyPercentileMin || fv > LatencyPercentileMax {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(v, LatencyPercentileMin,
+				LatencyPercentileMax, LatencyPercentileAnnotationKey))
+		}
+	}
+	return errs
+}
+
+func validateAlgorithm(annotations map[string]string) *apis.FieldError {
+	if algorithm, ok := annotations[AlgorithmAnnotationKey]; ok {
+		if c, ok := annotations[ClassAnnotationKey]; ok && c != KPA {
+			if c == HPA {
+				return apis.ErrInvalidKeyName(AlgorithmAnnotationKey, apis.CurrentField, fmt.Sprintf("not supported by %s", HPA))
+			}
+			// Leave other classes of PodAutoscaler alone.
+			return nil
+		}
+		switch algorithm {
+		case AlgorithmStablePanic:
+			return nil
+		case AlgorithmEWMA, AlgorithmPID:
+			// Latency does not add up over the pods, which only the
+			// stable/panic algorithm accounts for.
+			if annotations[MetricAnnotationKey] == Latency {
+				return apis.ErrGeneric(fmt.Sprintf("not supported with %s %s", MetricAnnotationKey, Latency), AlgorithmAnnotationKey)
+			}
+			return nil
+		}
+		return apis.ErrInvalidValue(algorithm, AlgorithmAnnotationKey)
+	}
+	return nil
+}
+
 func validateInitialScale(config *autoscalerconfig.Config, annotations map[string]string) *apis.FieldError {
 	if initialScale, ok := annotations[InitialScaleAnnotationKey]; ok {
 		initScaleInt, err := strconv.Atoi(initialScale)
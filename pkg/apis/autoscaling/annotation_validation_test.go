@@ -178,6 +178,63 @@ func TestValidateAnnotations(t *testing.T) {
 		name:        "panic threshold percentage bad3",
 		annotations: map[string]string{PanicThresholdPercentageAnnotationKey: "fifty"},
 		expectErr:   "invalid value: fifty: " + PanicThresholdPercentageAnnotationKey,
+	}, {
+		name: "valid panic exit threshold",
+		annotations: map[string]string{
+			PanicThresholdPercentageAnnotationKey:     "200",
+			PanicExitThresholdPercentageAnnotationKey: "120",
+		},
+	}, {
+		name:        "panic exit threshold out of range",
+		annotations: map[string]string{PanicExitThresholdPercentageAnnotationKey: "-1"},
+		expectErr:   "expected 0 <= -1 <= 1000: " + PanicExitThresholdPercentageAnnotationKey,
+	}, {
+		name:        "invalid panic exit threshold",
+		annotations: map[string]string{PanicExitThresholdPercentageAnnotationKey: "fifty"},
+		expectErr:   "invalid value: fifty: " + PanicExitThresholdPercentageAnnotationKey,
+	}, {
+		name: "panic exit threshold over panic threshold",
+		annotations: map[string]string{
+			PanicThresholdPercentageAnnotationKey:     "150",
+			PanicExitThresholdPercentageAnnotationKey: "160",
+		},
+		expectErr: PanicExitThresholdPercentageAnnotationKey + "=160 must not exceed " +
+			PanicThresholdPercentageAnnotationKey + "=150: " + PanicExitThresholdPercentageAnnotationKey,
+	}, {
+		name: "valid panic min duration and cooldown",
+		annotations: map[string]string{
+			PanicMinDurationAnnotationKey: "2m",
+			PanicCooldownAnnotationKey:    "30s",
+		},
+	}, {
+		name:        "panic min duration too long",
+		annotations: map[string]string{PanicMinDurationAnnotationKey: "2h"},
+		expectErr:   "expected 0s <= 2h <= 1h0m0s: " + PanicMinDurationAnnotationKey,
+	}, {
+		name:        "invalid panic cooldown",
+		annotations: map[string]string{PanicCooldownAnnotationKey: "a-while"},
+		expectErr:   "invalid value: a-while: " + PanicCooldownAnnotationKey,
+	}, {
+		name: "scale rates good",
+		annotations: map[string]string{
+			MaxScaleUpRateAnnotationKey:   "100",
+			MaxScaleDownRateAnnotationKey: "1.01",
+		},
+	}, {
+		name:        "scale up rate too low",
+		annotations: map[string]string{MaxScaleUpRateAnnotationKey: "1"},
+		expectErr:   "1 should be greater than 1: " + MaxScaleUpRateAnnotationKey,
+	}, {
+		name:        "scale down rate too low",
+		annotations: map[string]string{MaxScaleDownRateAnnotationKey: "0.5"},
+		expectErr:   "0.5 should be greater than 1: " + MaxScaleDownRateAnnotationKey,
+	}, {
+		name: "scale rates invalid",
+		annotations: map[string]string{
+			MaxScaleUpRateAnnotationKey:   "fast",
+			MaxScaleDownRateAnnotationKey: "NaN",
+		},
+		expectErr: "invalid value: NaN: " + MaxScaleDownRateAnnotationKey + "\ninvalid value: fast: " + MaxScaleUpRateAnnotationKey,
 	}, {
 		name:        "target negative",
 		annotations: map[string]string{TargetAnnotationKey: "-11"},
@@ -234,6 +291,21 @@ func TestValidateAnnotations(t *testing.T) {
 		name:        "window too precise",
 		annotations: map[string]string{WindowAnnotationKey: "1m9s82ms"},
 		expectErr:   "must be specified with at most second precision: " + WindowAnnotationKey,
+	}, {
+		name:        "window half-life",
+		annotations: map[string]string{WindowHalfLifeAnnotationKey: "10s"},
+	}, {
+		name:        "window half-life invalid",
+		annotations: map[string]string{WindowHalfLifeAnnotationKey: "soon"},
+		expectErr:   "invalid value: soon: " + WindowHalfLifeAnnotationKey,
+	}, {
+		name:        "window half-life too long",
+		annotations: map[string]string{WindowHalfLifeAnnotationKey: "2h"},
+		expectErr:   "expected 0s <= 2h <= 1h0m0s: " + WindowHalfLifeAnnotationKey,
+	}, {
+		name:        "window half-life too precise",
+		annotations: map[string]string{WindowHalfLifeAnnotationKey: "1s500ms"},
+		expectErr:   "must be specified with at most second precision: " + WindowHalfLifeAnnotationKey,
 	}, {
 		name:        "annotation /window is invalid for class HPA and metric CPU",
 		annotations: map[string]string{WindowAnnotationKey: "7s", ClassAnnotationKey: HPA, MetricAnnotationKey: CPU},
@@ -276,6 +348,53 @@ func TestValidateAnnotations(t *testing.T) {
 		name:        "invalid last pod scaledown timeout",
 		annotations: map[string]string{ScaleToZeroPodRetentionPeriodKey: "twenty-two-minutes-and-five-seconds"},
 		expectErr:   "invalid value: twenty-two-minutes-and-five-seconds: " + ScaleToZeroPodRetentionPeriodKey,
+	}, {
+		name:        "valid idle timeout",
+		annotations: map[string]string{IdleTimeoutAnnotationKey: "30s"},
+	}, {
+		name:        "zero idle timeout",
+		annotations: map[string]string{IdleTimeoutAnnotationKey: "0s"},
+		expectErr:   "expected 1s <= 0s <= 1h0m0s: " + IdleTimeoutAnnotationKey,
+	}, {
+		name:        "idle timeout too long",
+		annotations: map[string]string{IdleTimeoutAnnotationKey: "2h"},
+		expectErr:   "expected 1s <= 2h <= 1h0m0s: " + IdleTimeoutAnnotationKey,
+	}, {
+		name:        "idle timeout with subsecond precision",
+		annotations: map[string]string{IdleTimeoutAnnotationKey: "30.5s"},
+		expectErr:   "must be specified with at most second precision: " + IdleTimeoutAnnotationKey,
+	}, {
+		name:        "invalid idle timeout",
+		annotations: map[string]string{IdleTimeoutAnnotationKey: "soon"},
+		expectErr:   "invalid value: soon: " + IdleTimeoutAnnotationKey,
+	}, {
+		name: "idle timeout with HPA",
+		annotations: map[string]string{
+			ClassAnnotationKey:       HPA,
+			MetricAnnotationKey:      CPU,
+			IdleTimeoutAnnotationKey: "30s",
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", IdleTimeoutAnnotationKey, HPA),
+	}, {
+		name: "idle timeout with stable/panic algorithm",
+		annotations: map[string]string{
+			AlgorithmAnnotationKey:   AlgorithmStablePanic,
+			IdleTimeoutAnnotationKey: "30s",
+		},
+	}, {
+		name: "idle timeout with ewma algorithm",
+		annotations: map[string]string{
+			AlgorithmAnnotationKey:   AlgorithmEWMA,
+			IdleTimeoutAnnotationKey: "30s",
+		},
+		expectErr: fmt.Sprintf("not supported with %s %s: %s", AlgorithmAnnotationKey, AlgorithmEWMA, IdleTimeoutAnnotationKey),
+	}, {
+		name: "idle timeout with pid algorithm",
+		annotations: map[string]string{
+			AlgorithmAnnotationKey:   AlgorithmPID,
+			IdleTimeoutAnnotationKey: "30s",
+		},
+		expectErr: fmt.Sprintf("not supported with %s %s: %s", AlgorithmAnnotationKey, AlgorithmPID, IdleTimeoutAnnotationKey),
 	}, {
 		name:        "valid 0 scale down delay",
 		annotations: map[string]string{ScaleDownDelayAnnotationKey: "0"},
@@ -318,8 +437,49 @@ func TestValidateAnnotations(t *testing.T) {
 		},
 	}, {
 		name:        "invalid metric for default class(KPA)",
+		annotations: map[string]string{MetricAnnotationKey: "cpu-"},
+		expectErr:   "invalid value: cpu-: " + MetricAnnotationKey,
+	}, {
+		name:        "valid class KPA with metric CPU",
 		annotations: map[string]string{MetricAnnotationKey: CPU},
-		expectErr:   "invalid value: cpu: " + MetricAnnotationKey,
+	}, {
+		name:        "valid class KPA with metric Memory",
+		annotations: map[string]string{MetricAnnotationKey: Memory, TargetAnnotationKey: "60"},
+	}, {
+		name:        "invalid metric Memory for HPA class",
+		annotations: map[string]string{MetricAnnotationKey: Memory, ClassAnnotationKey: HPA},
+		expectErr:   "invalid value: memory: " + MetricAnnotationKey,
+	}, {
+		name:        "valid class KPA with metric latency",
+		annotations: map[string]string{MetricAnnotationKey: Latency, LatencyTargetAnnotationKey: "250ms", LatencyPercentileAnnotationKey: "99"},
+	}, {
+		name:        "metric latency with ewma algorithm",
+		annotations: map[string]string{MetricAnnotationKey: Latency, LatencyTargetAnnotationKey: "250ms", AlgorithmAnnotationKey: AlgorithmEWMA},
+		expectErr:   "not supported with " + MetricAnnotationKey + " latency: " + AlgorithmAnnotationKey,
+	}, {
+		name:        "metric latency without target",
+		annotations: map[string]string{MetricAnnotationKey: Latency},
+		expectErr:   "latencyTarget is required for metric latency: " + LatencyTargetAnnotationKey,
+	}, {
+		name:        "invalid metric latency for HPA class",
+		annotations: map[string]string{MetricAnnotationKey: Latency, ClassAnnotationKey: HPA, LatencyTargetAnnotationKey: "1s"},
+		expectErr:   "invalid value: latency: " + MetricAnnotationKey,
+	}, {
+		name:        "malformed latency target",
+		annotations: map[string]string{LatencyTargetAnnotationKey: "fast"},
+		expectErr:   "invalid value: fast: " + LatencyTargetAnnotationKey,
+	}, {
+		name:        "negative latency target",
+		annotations: map[string]string{LatencyTargetAnnotationKey: "-1s"},
+		expectErr:   "must be positive: " + LatencyTargetAnnotationKey,
+	}, {
+		name:        "latency target too precise",
+		annotations: map[string]string{LatencyTargetAnnotationKey: "1500us"},
+		expectErr:   "must be specified with at most millisecond precision: " + LatencyTargetAnnotationKey,
+	}, {
+		name:        "latency percentile out of bounds",
+		annotations: map[string]string{LatencyPercentileAnnotationKey: "100"},
+		expectErr:   "expected 1 <= 100 <= 99.9: " + LatencyPercentileAnnotationKey,
 	}, {
 		name:        "invalid metric for HPA class",
 		annotations: map[string]string{MetricAnnotationKey: "metrics", ClassAnnotationKey: HPA},
@@ -330,9 +490,202 @@ func TestValidateAnnotations(t *testing.T) {
 	}, {
 		name:        "valid class KPA with metric Concurrency",
 		annotations: map[string]string{MetricAnnotationKey: Concurrency},
+	}, {
+		name:        "valid class KPA with custom metric",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100"},
+	}, {
+		name:        "custom metric without target",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog"},
+		expectErr:   "target is required for custom metric queue_backlog: " + TargetAnnotationKey,
+	}, {
+		name:        "invalid custom metric name",
+		annotations: map[string]string{MetricAnnotationKey: "queue-backlog", TargetAnnotationKey: "100"},
+		expectErr:   "invalid value: queue-backlog: " + MetricAnnotationKey,
+	}, {
+		name: "custom metric with path and port",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100",
+			MetricPathAnnotationKey: "/stats/prometheus", MetricPortAnnotationKey: "9090"},
+	}, {
+		name:        "metric path without custom metric",
+		annotations: map[string]string{MetricAnnotationKey: Concurrency, MetricPathAnnotationKey: "/stats/prometheus"},
+		expectErr:   "only supported with a custom " + MetricAnnotationKey + ": " + MetricPathAnnotationKey,
+	}, {
+		name:        "metric port without metric",
+		annotations: map[string]string{MetricPortAnnotationKey: "9090"},
+		expectErr:   "only supported with a custom " + MetricAnnotationKey + ": " + MetricPortAnnotationKey,
+	}, {
+		name: "metric path for HPA class",
+		annotations: map[string]string{ClassAnnotationKey: HPA, MetricAnnotationKey: "queue_backlog",
+			MetricTypeAnnotationKey: MetricTypePods, TargetAnnotationKey: "100", MetricPathAnnotationKey: "/stats"},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", MetricPathAnnotationKey, HPA),
+	}, {
+		name:        "relative metric path",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", MetricPathAnnotationKey: "metrics"},
+		expectErr:   "invalid value: metrics: " + MetricPathAnnotationKey,
+	}, {
+		name:        "metric path with host",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", MetricPathAnnotationKey: "//example.com/metrics"},
+		expectErr:   "invalid value: //example.com/metrics: " + MetricPathAnnotationKey,
+	}, {
+		name:        "metric path with query",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", MetricPathAnnotationKey: "/metrics?format=text"},
+		expectErr:   "invalid value: /metrics?format=text: " + MetricPathAnnotationKey,
+	}, {
+		name:        "malformed metric port",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", MetricPortAnnotationKey: "http"},
+		expectErr:   "invalid value: http: " + MetricPortAnnotationKey,
+	}, {
+		name:        "metric port out of range",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", MetricPortAnnotationKey: "70000"},
+		expectErr:   "expected 1 <= 70000 <= 65535: " + MetricPortAnnotationKey,
+	}, {
+		name:        "custom metric for HPA class",
+		annotations: map[string]string{MetricAnnotationKey: "queue_backlog", TargetAnnotationKey: "100", ClassAnnotationKey: HPA},
+		expectErr:   "invalid value: queue_backlog: " + MetricAnnotationKey,
 	}, {
 		name:        "valid class HPA with metric CPU",
 		annotations: map[string]string{ClassAnnotationKey: HPA, MetricAnnotationKey: CPU},
+	}, {
+		name: "external metric for HPA class",
+		annotations: map[string]string{
+			ClassAnnotationKey:            HPA,
+			MetricTypeAnnotationKey:       MetricTypeExternal,
+			MetricAnnotationKey:           "queue-backlog",
+			MetricSelectorAnnotationKey:   "queue=orders,tier in (gold)",
+			MetricTargetTypeAnnotationKey: MetricTargetAverageValue,
+			TargetAnnotationKey:           "30",
+		},
+	}, {
+		name: "object metric for HPA class",
+		annotations: map[string]string{
+			ClassAnnotationKey:        HPA,
+			MetricTypeAnnotationKey:   MetricTypeObject,
+			MetricAnnotationKey:       "queue_backlog",
+			MetricObjectAnnotationKey: "v1/Service/broker",
+			TargetAnnotationKey:       "30",
+		},
+	}, {
+		name: "metric type for KPA class",
+		annotations: map[string]string{
+			MetricTypeAnnotationKey: MetricTypePods,
+			MetricAnnotationKey:     "queue_backlog",
+			TargetAnnotationKey:     "30",
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nonly supported by %s", MetricTypeAnnotationKey, HPA),
+	}, {
+		name: "invalid metric type",
+		annotations: map[string]string{
+			ClassAnnotationKey:      HPA,
+			MetricTypeAnnotationKey: "resource",
+			MetricAnnotationKey:     "queue_backlog",
+			TargetAnnotationKey:     "30",
+		},
+		expectErr: "invalid value: resource: " + MetricTypeAnnotationKey,
+	}, {
+		name: "metric type without metric and target",
+		annotations: map[string]string{
+			ClassAnnotationKey:      HPA,
+			MetricTypeAnnotationKey: MetricTypePods,
+		},
+		expectErr: "metric is required for metricType pods: " + MetricAnnotationKey +
+			"\ntarget is required for metricType pods: " + TargetAnnotationKey,
+	}, {
+		name: "invalid metric selector",
+		annotations: map[string]string{
+			ClassAnnotationKey:          HPA,
+			MetricTypeAnnotationKey:     MetricTypeExternal,
+			MetricAnnotationKey:         "queue_backlog",
+			MetricSelectorAnnotationKey: "queue in (",
+			TargetAnnotationKey:         "30",
+		},
+		expectErr: "invalid value: queue in (: " + MetricSelectorAnnotationKey,
+	}, {
+		name: "metric object for external metric",
+		annotations: map[string]string{
+			ClassAnnotationKey:        HPA,
+			MetricTypeAnnotationKey:   MetricTypeExternal,
+			MetricAnnotationKey:       "queue_backlog",
+			MetricObjectAnnotationKey: "v1/Service/broker",
+			TargetAnnotationKey:       "30",
+		},
+		expectErr: "not supported with metricType external: " + MetricObjectAnnotationKey,
+	}, {
+		name: "invalid metric object",
+		annotations: map[string]string{
+			ClassAnnotationKey:        HPA,
+			MetricTypeAnnotationKey:   MetricTypeObject,
+			MetricAnnotationKey:       "queue_backlog",
+			MetricObjectAnnotationKey: "broker",
+			TargetAnnotationKey:       "30",
+		},
+		expectErr: `metric object "broker" is not of the form [apiVersion/]kind/name: ` + MetricObjectAnnotationKey,
+	}, {
+		name: "value target for pods metric",
+		annotations: map[string]string{
+			ClassAnnotationKey:            HPA,
+			MetricTypeAnnotationKey:       MetricTypePods,
+			MetricAnnotationKey:           "queue_backlog",
+			MetricTargetTypeAnnotationKey: MetricTargetValue,
+			TargetAnnotationKey:           "30",
+		},
+		expectErr: "metricType pods is always averaged: " + MetricTargetTypeAnnotationKey,
+	}, {
+		name: "invalid metric target type",
+		annotations: map[string]string{
+			ClassAnnotationKey:            HPA,
+			MetricTypeAnnotationKey:       MetricTypeExternal,
+			MetricAnnotationKey:           "queue_backlog",
+			MetricTargetTypeAnnotationKey: "utilization",
+			TargetAnnotationKey:           "30",
+		},
+		expectErr: "invalid value: utilization: " + MetricTargetTypeAnnotationKey,
+	}, {
+		name: "metric selector without metric type",
+		annotations: map[string]string{
+			ClassAnnotationKey:          HPA,
+			MetricSelectorAnnotationKey: "queue=orders",
+		},
+		expectErr: "requires " + MetricTypeAnnotationKey + ": " + MetricSelectorAnnotationKey,
+	}, {
+		name: "scrape sample percentage",
+		annotations: map[string]string{
+			ScrapeStrategyAnnotationKey:         ScrapeStrategyPercentage,
+			ScrapeSamplePercentageAnnotationKey: "25",
+		},
+	}, {
+		name: "scrape sample percentage with the configured strategy",
+		configMutator: func(config *autoscalerconfig.Config) {
+			config.ScrapeStrategy = ScrapeStrategyPercentage
+		},
+		annotations: map[string]string{ScrapeSamplePercentageAnnotationKey: "25"},
+	}, {
+		name:        "full scrape",
+		annotations: map[string]string{ScrapeStrategyAnnotationKey: ScrapeStrategyFull},
+	}, {
+		name:        "invalid scrape strategy",
+		annotations: map[string]string{ScrapeStrategyAnnotationKey: "random"},
+		expectErr:   "invalid value: random: " + ScrapeStrategyAnnotationKey,
+	}, {
+		name: "scrape strategy for HPA class",
+		annotations: map[string]string{
+			ScrapeStrategyAnnotationKey: ScrapeStrategyFull,
+			ClassAnnotationKey:          HPA,
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", ScrapeStrategyAnnotationKey, HPA),
+	}, {
+		name: "scrape sample percentage without percentage strategy",
+		annotations: map[string]string{
+			ScrapeStrategyAnnotationKey:         ScrapeStrategyAdaptive,
+			ScrapeSamplePercentageAnnotationKey: "25",
+		},
+		expectErr: "requires " + ScrapeStrategyAnnotationKey + " percentage: " + ScrapeSamplePercentageAnnotationKey,
+	}, {
+		name: "scrape sample percentage out of bounds",
+		annotations: map[string]string{
+			ScrapeStrategyAnnotationKey:         ScrapeStrategyPercentage,
+			ScrapeSamplePercentageAnnotationKey: "0",
+		},
+		expectErr: "expected 0 <= 0 <= 100: " + ScrapeSamplePercentageAnnotationKey,
 	}, {
 		name:        "other than HPA and KPA class",
 		annotations: map[string]string{ClassAnnotationKey: "other", MetricAnnotationKey: RPS},
@@ -353,6 +706,68 @@ func TestValidateAnnotations(t *testing.T) {
 		name:        "initial scale non-parseable",
 		annotations: map[string]string{InitialScaleAnnotationKey: "invalid"},
 		expectErr:   "invalid value: invalid: autoscaling.knative.dev/initialScale",
+	}, {
+		name:        "valid min scale schedule",
+		annotations: map[string]string{MinScaleScheduleAnnotationKey: "morning 0 9 * * 1-5 2h 10; batch 30 1 * * * 45m 5"},
+	}, {
+		name:        "invalid min scale schedule",
+		annotations: map[string]string{MinScaleScheduleAnnotationKey: "morning 0 25 * * 1-5 2h 10"},
+		expectErr:   `schedule entry "morning": hour "25" must be in [0, 23] range: ` + MinScaleScheduleAnnotationKey,
+	}, {
+		name: "min scale schedule above max scale",
+		annotations: map[string]string{
+			MinScaleScheduleAnnotationKey: "morning 0 9 * * 1-5 2h 10",
+			MaxScaleAnnotationKey:         "5",
+		},
+		expectErr: `schedule entry "morning" minScale=10 is greater than maxScale=5: ` + MaxScaleAnnotationKey + ", " + MinScaleScheduleAnnotationKey,
+	}, {
+		name: "min scale schedule for HPA class",
+		annotations: map[string]string{
+			MinScaleScheduleAnnotationKey: "morning 0 9 * * 1-5 2h 10",
+			ClassAnnotationKey:            HPA,
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", MinScaleScheduleAnnotationKey, HPA),
+	}, {
+		name:        "valid warm pool",
+		annotations: map[string]string{WarmPoolAnnotationKey: "2"},
+	}, {
+		name:        "negative warm pool",
+		annotations: map[string]string{WarmPoolAnnotationKey: "-1"},
+		expectErr:   "expected 0 <= -1 <= 2147483647: " + WarmPoolAnnotationKey,
+	}, {
+		name: "warm pool above max scale",
+		annotations: map[string]string{
+			WarmPoolAnnotationKey: "6",
+			MaxScaleAnnotationKey: "5",
+		},
+		expectErr: "warmPool=6 is greater than maxScale=5: " + MaxScaleAnnotationKey + ", " + WarmPoolAnnotationKey,
+	}, {
+		name: "warm pool for HPA class",
+		annotations: map[string]string{
+			WarmPoolAnnotationKey: "1",
+			ClassAnnotationKey:    HPA,
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", WarmPoolAnnotationKey, HPA),
+	}, {
+		name:        "valid algorithm",
+		annotations: map[string]string{AlgorithmAnnotationKey: AlgorithmEWMA},
+	}, {
+		name:        "invalid algorithm",
+		annotations: map[string]string{AlgorithmAnnotationKey: "magic"},
+		expectErr:   "invalid value: magic: " + AlgorithmAnnotationKey,
+	}, {
+		name: "algorithm for HPA class",
+		annotations: map[string]string{
+			AlgorithmAnnotationKey: AlgorithmPID,
+			ClassAnnotationKey:     HPA,
+		},
+		expectErr: fmt.Sprintf("invalid key name %q: \nnot supported by %s", AlgorithmAnnotationKey, HPA),
+	}, {
+		name: "algorithm for other class",
+		annotations: map[string]string{
+			AlgorithmAnnotationKey: "magic",
+			ClassAnnotationKey:     "other",
+		},
 	}}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
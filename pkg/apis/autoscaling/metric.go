@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// customMetricRegexp matches the valid Prometheus metric names, which the
+// custom metrics are scraped as from the user container.
+var customMetricRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// IsCustomMetric returns true if the metric is not one of the metrics built
+// into the autoscalers, but a metric reported by the application.
+func IsCustomMetric(metric string) bool {
+	switch metric {
+	case "", Concurrency, RPS, CPU, Memory, Latency:
+		return false
+	}
+	return true
+}
+
+// IsValidCustomMetric returns true if the metric is a custom metric with a
+// valid name.
+func IsValidCustomMetric(metric string) bool {
+	return IsCustomMetric(metric) && customMetricRegexp.MatchString(metric)
+}
+
+// MetricObject is the object described by an object metric.
+type MetricObject struct {
+	APIVersion string
+	Kind       string
+	Name       string
+}
+
+// ParseMetricObject parses the value of the metricObject annotation, of the
+// form [apiVersion/]kind/name. The API version may contain a slash, as in
+// apps/v1/Deployment/name.
+func ParseMetricObject(s string) (MetricObject, error) {
+	parts := strings.Split(s, "/")
+	n := len(parts)
+	if n < 2 {
+		return MetricObject{}, fmt.Errorf("metric object %q is not of the form [apiVersion/]kind/name", s)
+	}
+	o := MetricObject{
+		APIVersion: strings.Join(parts[:n-2], "/"),
+		Kind:       parts[n-2],
+		Name:       parts[n-1],
+	}
+	if o.Kind == "" || o.Name == "" {
+		return MetricObject{}, fmt.Errorf("metric object %q is missing its kind or name", s)
+	}
+	return o, nil
+}
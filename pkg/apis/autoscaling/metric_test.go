@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import "testing"
+
+func TestIsCustomMetric(t *testing.T) {
+	for metric, want := range map[string]struct{ custom, valid bool }{
+		"":                  {},
+		Concurrency:         {},
+		RPS:                 {},
+		CPU:                 {},
+		Memory:              {},
+		"queue_backlog":     {custom: true, valid: true},
+		"app:queue_backlog": {custom: true, valid: true},
+		"queue-backlog":     {custom: true},
+		"1queue_backlog":    {custom: true},
+	} {
+		if got := IsCustomMetric(metric); got != want.custom {
+			t.Errorf("IsCustomMetric(%q) = %v, want: %v", metric, got, want.custom)
+		}
+		if got := IsValidCustomMetric(metric); got != want.valid {
+			t.Errorf("IsValidCustomMetric(%q) = %v, want: %v", metric, got, want.valid)
+		}
+	}
+}
+
+func TestParseMetricObject(t *testing.T) {
+	for s, want := range map[string]MetricObject{
+		"Service/broker":            {Kind: "Service", Name: "broker"},
+		"v1/Service/broker":         {APIVersion: "v1", Kind: "Service", Name: "broker"},
+		"apps/v1/Deployment/broker": {APIVersion: "apps/v1", Kind: "Deployment", Name: "broker"},
+	} {
+		got, err := ParseMetricObject(s)
+		if err != nil {
+			t.Errorf("ParseMetricObject(%q) = %v", s, err)
+		} else if got != want {
+			t.Errorf("ParseMetricObject(%q) = %v, want: %v", s, got, want)
+		}
+	}
+	for _, s := range []string{"", "broker", "Service/", "/broker"} {
+		if got, err := ParseMetricObject(s); err == nil {
+			t.Errorf("ParseMetricObject(%q) = %v, want an error", s, got)
+		}
+	}
+}
@@ -45,25 +45,151 @@ const (
 	// the PodAutoscaler should provision. For example,
 	//   autoscaling.knative.dev/maxScale: "10"
 	MaxScaleAnnotationKey = GroupName + "/maxScale"
+	// MinScaleScheduleAnnotationKey is the annotation to specify recurring time
+	// windows during which the minimum scale of the PodAutoscaler is raised,
+	// e.g. to pre-scale the revision before known traffic peaks. Entries are
+	// separated by semicolons and consist of a name, a 5 field cron expression
+	// evaluated in UTC, the window duration and the minimum scale. For example,
+	//   autoscaling.knative.dev/minScaleSchedule: "morning 0 9 * * 1-5 2h 10"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the minScaleSchedule annotation.
+	MinScaleScheduleAnnotationKey = GroupName + "/minScaleSchedule"
 
 	// InitialScaleAnnotationKey is the annotation to specify the initial scale of
 	// a revision when a service is initially deployed. This number can be set to 0 iff
 	// allow-zero-initial-scale of config-autoscaler is true.
 	InitialScaleAnnotationKey = GroupName + "/initialScale"
 
+	// NamespaceMaxScaleAnnotationKey is the annotation of a Namespace to specify
+	// the maximum number of pods the revisions of the namespace can scale to
+	// in total. For example,
+	//   autoscaling.knative.dev/namespaceMaxScale: "100"
+	// The pods of all the revisions count towards the budget, but only the
+	// kpa.autoscaling.knative.dev class autoscaler limits its scale by it,
+	// and never below the revision's min-scale.
+	NamespaceMaxScaleAnnotationKey = GroupName + "/namespaceMaxScale"
+
+	// WarmPoolAnnotationKey is the annotation to specify the number of pods
+	// kept ready while the revision is scaled to zero. The warm pods don't
+	// receive traffic directly, requests are still proxied by the activator,
+	// which avoids the cold start of the first requests. For example,
+	//   autoscaling.knative.dev/warmPool: "1"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the warmPool annotation.
+	WarmPoolAnnotationKey = GroupName + "/warmPool"
+
 	// ScaleDownDelayAnnotationKey is the annotation to specify a scale down delay.
 	ScaleDownDelayAnnotationKey = GroupName + "/scaleDownDelay"
 
 	// MetricAnnotationKey is the annotation to specify what metric the PodAutoscaler
 	// should be scaled on. For example,
 	//   autoscaling.knative.dev/metric: cpu
+	// The KPA also accepts the name of a gauge the application exposes in the
+	// Prometheus format, which requires the target annotation to be set.
+	// To scale on cpu or memory the KPA reads the usage of the user containers
+	// from the resource metrics API, which requires the metrics-server.
 	MetricAnnotationKey = GroupName + "/metric"
 	// Concurrency is the number of requests in-flight at any given time.
 	Concurrency = "concurrency"
 	// CPU is the amount of the requested cpu actually being consumed by the Pod.
 	CPU = "cpu"
+	// Memory is the amount of the requested memory actually being consumed by the Pod.
+	Memory = "memory"
 	// RPS is the requests per second reaching the Pod.
 	RPS = "rps"
+	// Latency is a percentile of the time the Pods take to respond to requests.
+	// The KPA adds Pods while it exceeds the latencyTarget annotation.
+	Latency = "latency"
+
+	// MetricPathAnnotationKey is the annotation to specify the path the
+	// application exposes the custom metric of the metric annotation on,
+	// "/metrics" by default. For example,
+	//   autoscaling.knative.dev/metricPath: /stats/prometheus
+	MetricPathAnnotationKey = GroupName + "/metricPath"
+	// MetricPortAnnotationKey is the annotation to specify the port the
+	// application exposes the custom metric of the metric annotation on,
+	// the port of the user container by default. For example,
+	//   autoscaling.knative.dev/metricPort: "9090"
+	MetricPortAnnotationKey = GroupName + "/metricPort"
+
+	// MetricTypeAnnotationKey is the annotation to specify the type of the
+	// metric source the HPA scales on, for the metrics served by a metrics
+	// adapter. The metric annotation then holds the name of the metric and
+	// the target annotation the value to maintain. For example,
+	//   autoscaling.knative.dev/metricType: external
+	//   autoscaling.knative.dev/metric: queue_messages_ready
+	//   autoscaling.knative.dev/metricSelector: queue=orders
+	//   autoscaling.knative.dev/target: "30"
+	// Only the hpa.autoscaling.knative.dev class autoscaler supports
+	// the metricType annotation.
+	MetricTypeAnnotationKey = GroupName + "/metricType"
+	// MetricTypePods is a metric describing each of the Pods of the revision.
+	MetricTypePods = "pods"
+	// MetricTypeObject is a metric describing a single Kubernetes object,
+	// the revision unless specified with the metricObject annotation.
+	MetricTypeObject = "object"
+	// MetricTypeExternal is a metric not associated with any Kubernetes object.
+	MetricTypeExternal = "external"
+
+	// MetricSelectorAnnotationKey is the annotation to specify the label
+	// selector of the metric of the metricType annotation. For example,
+	//   autoscaling.knative.dev/metricSelector: "queue=orders,tier in (gold)"
+	MetricSelectorAnnotationKey = GroupName + "/metricSelector"
+
+	// MetricObjectAnnotationKey is the annotation to specify the object
+	// described by an object metric, as [apiVersion/]kind/name. For example,
+	//   autoscaling.knative.dev/metricType: object
+	//   autoscaling.knative.dev/metricObject: v1/Service/broker
+	MetricObjectAnnotationKey = GroupName + "/metricObject"
+
+	// MetricTargetTypeAnnotationKey is the annotation to specify whether the
+	// target is compared to the value of the metric of the metricType
+	// annotation, or to its value divided by the number of Pods. Pods
+	// metrics are always averaged, object and external metrics default to
+	// the value. For example,
+	//   autoscaling.knative.dev/metricTargetType: averageValue
+	MetricTargetTypeAnnotationKey = GroupName + "/metricTargetType"
+	// MetricTargetValue compares the target to the value of the metric.
+	MetricTargetValue = "value"
+	// MetricTargetAverageValue compares the target to the value of the metric
+	// divided by the number of Pods.
+	MetricTargetAverageValue = "averageValue"
+
+	// LatencyTargetAnnotationKey is the annotation to specify the latency the
+	// KPA should keep the latency percentile of the revision below when
+	// scaling on the latency metric. For example,
+	//   autoscaling.knative.dev/metric: latency
+	//   autoscaling.knative.dev/latencyTarget: "250ms"
+	LatencyTargetAnnotationKey = GroupName + "/latencyTarget"
+	// LatencyPercentileAnnotationKey is the annotation to specify the percentile
+	// of the request latencies the latencyTarget applies to. For example,
+	//   autoscaling.knative.dev/latencyPercentile: "99"
+	LatencyPercentileAnnotationKey = GroupName + "/latencyPercentile"
+	// LatencyPercentileDefault is the percentile used if the
+	// latencyPercentile annotation is not set.
+	LatencyPercentileDefault = 95.0
+	// LatencyPercentileMin is the minimum allowable latency percentile.
+	LatencyPercentileMin = 1.0
+	// LatencyPercentileMax is the maximum allowable latency percentile.
+	LatencyPercentileMax = 99.9
+
+	// AlgorithmAnnotationKey is the annotation to specify the algorithm the
+	// autoscaler uses to compute the desired scale from the observed metric.
+	// For example,
+	//   autoscaling.knative.dev/algorithm: ewma
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the algorithm annotation.
+	AlgorithmAnnotationKey = GroupName + "/algorithm"
+	// AlgorithmStablePanic is the default algorithm, which scales on the
+	// average over the stable window and switches to the shorter panic
+	// window when the load spikes.
+	AlgorithmStablePanic = "stable-panic"
+	// AlgorithmEWMA scales on an exponentially weighted moving average of
+	// the observed metric, whose time constant is the stable window.
+	AlgorithmEWMA = "ewma"
+	// AlgorithmPID scales using a PID controller that drives the observed
+	// metric per pod towards the target.
+	AlgorithmPID = "pid"
 
 	// TargetAnnotationKey is the annotation to specify what metric value the
 	// PodAutoscaler should attempt to maintain. For example,
@@ -83,6 +209,17 @@ const (
 	// scale-to-zero-pod-retention-period global setting.
 	ScaleToZeroPodRetentionPeriodKey = GroupName + "/scaleToZeroPodRetentionPeriod"
 
+	// IdleTimeoutAnnotationKey is the annotation to specify how long a revision
+	// must go without any request, as seen by the activator and the
+	// queue-proxies, before it is scaled to zero. When set, it rather than the
+	// stable window decides when the revision is idle, so a long stable window
+	// can be used along with a fast scale to zero. For example,
+	//   autoscaling.knative.dev/window: "10m"
+	//   autoscaling.knative.dev/idleTimeout: "30s"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the idleTimeout annotation, with the stable/panic algorithm.
+	IdleTimeoutAnnotationKey = GroupName + "/idleTimeout"
+
 	// WindowAnnotationKey is the annotation to specify the time
 	// interval over which to calculate the average metric.  Larger
 	// values result in more smoothing. For example,
@@ -91,6 +228,15 @@ const (
 	// Only the kpa.autoscaling.knative.dev class autoscaler supports
 	// the window annotation.
 	WindowAnnotationKey = GroupName + "/window"
+	// WindowHalfLifeAnnotationKey is the annotation to specify the
+	// half-life of the exponential decay of the weights of the values
+	// averaged over the window of concurrency and requests per second.
+	// With a half-life, the recent values matter more than the older ones,
+	// so the average reacts faster to a change of the load. For example,
+	//   autoscaling.knative.dev/window: "60s"
+	//   autoscaling.knative.dev/windowHalfLife: "10s"
+	// Zero means all the values weigh the same.
+	WindowHalfLifeAnnotationKey = GroupName + "/windowHalfLife"
 	// WindowMin is the minimum allowable stable autoscaling
 	// window. KPA-class autoscalers calculate the desired replica
 	// count every 2 seconds (tick-interval in config-autoscaler) so
@@ -103,6 +249,28 @@ const (
 	// This keeps the event horizon to a reasonable enough limit.
 	WindowMax = 1 * time.Hour
 
+	// ScrapeStrategyAnnotationKey is the annotation to specify how many of the
+	// pods of the revision the autoscaler scrapes the stats of. For example,
+	//   autoscaling.knative.dev/scrapeStrategy: percentage
+	//   autoscaling.knative.dev/scrapeSamplePercentage: "20"
+	// The revisions with no more pods than scrape-full-threshold in
+	// config-autoscaler are always fully scraped.
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the scrapeStrategy annotation.
+	ScrapeStrategyAnnotationKey = GroupName + "/scrapeStrategy"
+	// ScrapeStrategyAdaptive scrapes the sample of pods needed to estimate
+	// the mean of their stats with a 95% confidence. This is the default.
+	ScrapeStrategyAdaptive = "adaptive"
+	// ScrapeStrategyFull scrapes all the pods.
+	ScrapeStrategyFull = "full"
+	// ScrapeStrategyPercentage scrapes a fixed percentage of the pods, given
+	// by the scrapeSamplePercentage annotation.
+	ScrapeStrategyPercentage = "percentage"
+	// ScrapeSamplePercentageAnnotationKey is the annotation to specify the
+	// percentage of the pods scraped with the percentage scrape strategy.
+	// The percentage is in the (0, 100] range.
+	ScrapeSamplePercentageAnnotationKey = GroupName + "/scrapeSamplePercentage"
+
 	// TargetUtilizationPercentageKey is the annotation which specifies the
 	// desired target resource utilization for the revision.
 	// TargetUtilization is a percentage in the 1 <= TU <= 100 range.
@@ -172,4 +340,51 @@ const (
 	// PanicThresholdPercentageMax is the counterpart to the PanicThresholdPercentageMin
 	// but bounding from above.
 	PanicThresholdPercentageMax = 1000.0
+
+	// PanicExitThresholdPercentageAnnotationKey is the annotation to specify
+	// the level below which the observed metric must stay for the panic mode
+	// to end, as a percentage of the metric target. Leaving the panic mode
+	// at a lower level than it is entered at keeps bursty workloads from
+	// flapping in and out of it. Zero, the default, exits at the panic
+	// threshold. For example,
+	//   autoscaling.knative.dev/panicThresholdPercentage: "200.0"
+	//   autoscaling.knative.dev/panicExitThresholdPercentage: "120.0"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the panicExitThresholdPercentage annotation
+	PanicExitThresholdPercentageAnnotationKey = GroupName + "/panicExitThresholdPercentage"
+
+	// PanicMinDurationAnnotationKey is the annotation to specify the minimum
+	// time the autoscaler stays in panic mode once entered. For example,
+	//   autoscaling.knative.dev/panicMinDuration: "2m"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the panicMinDuration annotation
+	PanicMinDurationAnnotationKey = GroupName + "/panicMinDuration"
+
+	// PanicCooldownAnnotationKey is the annotation to specify the time after
+	// leaving panic mode during which the autoscaler does not enter it again.
+	// For example,
+	//   autoscaling.knative.dev/panicCooldown: "30s"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the panicCooldown annotation
+	PanicCooldownAnnotationKey = GroupName + "/panicCooldown"
+
+	// MaxScaleUpRateAnnotationKey is the annotation to specify the maximum
+	// ratio of desired to ready pods the revision can scale up to in one
+	// decision. It overrides max-scale-up-rate in config-autoscaler.
+	// For example,
+	//   autoscaling.knative.dev/maxScaleUpRate: "10.0"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the maxScaleUpRate annotation.
+	MaxScaleUpRateAnnotationKey = GroupName + "/maxScaleUpRate"
+	// MaxScaleDownRateAnnotationKey is the annotation to specify the maximum
+	// ratio of ready to desired pods the revision can scale down to in one
+	// decision. It overrides max-scale-down-rate in config-autoscaler.
+	// For example,
+	//   autoscaling.knative.dev/maxScaleDownRate: "1.5"
+	// Only the kpa.autoscaling.knative.dev class autoscaler supports
+	// the maxScaleDownRate annotation.
+	MaxScaleDownRateAnnotationKey = GroupName + "/maxScaleDownRate"
+	// MaxScaleRateMin is the bound the scale rates have to be greater than.
+	// A rate of 1 or less would never allow the revision to scale.
+	MaxScaleRateMin = 1.0
 )
@@ -0,0 +1,211 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScaleSchedule is a single entry of the minScaleSchedule annotation.
+// While the entry is active the revision will not be scaled below MinScale.
+type ScaleSchedule struct {
+	// Name identifies the entry in the PodAutoscaler status.
+	Name string
+	// Duration is how long the window stays open after each cron match.
+	Duration time.Duration
+	// MinScale is the minimum scale enforced while the window is open.
+	MinScale int32
+
+	cron cronSpec
+}
+
+// cronField describes the permitted range of a single cron field.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cronSpec is a parsed 5 field cron expression. Each field is stored
+// as a bit set of the matching values.
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day fields were unrestricted,
+	// which changes how they are combined, as in the classic cron.
+	domStar, dowStar bool
+}
+
+// ParseScaleSchedules parses the value of the minScaleSchedule annotation.
+// Entries are separated by semicolons or new lines and consist of a name,
+// the five cron fields (minute, hour, day of month, month, day of week),
+// the window duration and the minimum scale, e.g. "morning 0 9 * * 1-5 2h 10".
+// Cron expressions are evaluated in UTC.
+func ParseScaleSchedules(s string) ([]ScaleSchedule, error) {
+	var (
+		ret   []ScaleSchedule
+		names = map[string]struct{}{}
+	)
+	for _, e := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(e)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("schedule entry %q must have 8 fields: name, 5 cron fields, duration and minScale", strings.TrimSpace(e))
+		}
+		name := fields[0]
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("duplicate schedule entry name %q", name)
+		}
+		names[name] = struct{}{}
+
+		cron, err := parseCron(fields[1:6])
+		if err != nil {
+			return nil, fmt.Errorf("schedule entry %q: %w", name, err)
+		}
+		d, err := time.ParseDuration(fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("schedule entry %q: invalid duration %q", name, fields[6])
+		}
+		if d < time.Minute || d > 24*time.Hour {
+			return nil, fmt.Errorf("schedule entry %q: duration %v must be in [1m, 24h] range", name, d)
+		}
+		min, err := strconv.ParseInt(fields[7], 10, 32)
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("schedule entry %q: minScale %q must be an integer in [0, %d] range", name, fields[7], math.MaxInt32)
+		}
+		ret = append(ret, ScaleSchedule{
+			Name:     name,
+			Duration: d,
+			MinScale: int32(min),
+			cron:     cron,
+		})
+	}
+	return ret, nil
+}
+
+func parseCron(fields []string) (cronSpec, error) {
+	var (
+		cs   cronSpec
+		sets [5]uint64
+	)
+	for i, f := range fields {
+		set, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return cs, err
+		}
+		sets[i] = set
+	}
+	cs.minute, cs.hour, cs.dom, cs.month, cs.dow = sets[0], sets[1], sets[2], sets[3], sets[4]
+	// Sunday can be specified both as 0 and 7.
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+	cs.domStar = fields[2] == "*"
+	cs.dowStar = fields[4] == "*"
+	return cs, nil
+}
+
+// parseCronField parses a comma separated list of `*`, `a`, `a-b`
+// optionally followed by a `/step`.
+func parseCronField(s string, f cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %s %q", f.name, part)
+			}
+		}
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			var err error
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid %s %q", f.name, part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid %s %q", f.name, part)
+				}
+			}
+			if lo < f.min || hi > f.max || lo > hi {
+				return 0, fmt.Errorf("%s %q must be in [%d, %d] range", f.name, part, f.min, f.max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// matches returns true if the cron expression fires at the minute t belongs to.
+func (cs *cronSpec) matches(t time.Time) bool {
+	if cs.minute&(1<<uint(t.Minute())) == 0 || cs.hour&(1<<uint(t.Hour())) == 0 ||
+		cs.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := cs.dom&(1<<uint(t.Day())) != 0
+	dowMatch := cs.dow&(1<<uint(t.Weekday())) != 0
+	// As in the classic cron, if both day fields are restricted, matching
+	// either of them is enough.
+	if !cs.domStar && !cs.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// ActiveUntil returns the time the window of the entry that contains `now`
+// ends, or false if no window of the entry is open at `now`.
+func (ss *ScaleSchedule) ActiveUntil(now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	// Walk back from the newest possible window start, so that among the
+	// overlapping windows we find the one that ends last.
+	for start := now.Truncate(time.Minute); now.Sub(start) < ss.Duration; start = start.Add(-time.Minute) {
+		if ss.cron.matches(start) {
+			return start.Add(ss.Duration), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextStart returns the next time after `now` that a window of the entry
+// opens, or false if it does not open within the next `horizon`.
+func (ss *ScaleSchedule) NextStart(now time.Time, horizon time.Duration) (time.Time, bool) {
+	now = now.UTC()
+	for start := now.Truncate(time.Minute).Add(time.Minute); start.Sub(now) <= horizon; start = start.Add(time.Minute) {
+		if ss.cron.matches(start) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScaleSchedules(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     string
+		wantNames []string
+		wantErr   string
+	}{{
+		name:  "empty",
+		value: "",
+	}, {
+		name:      "single",
+		value:     "morning 0 9 * * 1-5 2h 10",
+		wantNames: []string{"morning"},
+	}, {
+		name:      "multiple, semicolons and new lines",
+		value:     "morning 0 9 * * 1-5 2h 10;\nbatch */15 1,13 1-15/2 * 7 45m 5\n",
+		wantNames: []string{"morning", "batch"},
+	}, {
+		name:    "too few fields",
+		value:   "morning 0 9 * * 2h 10",
+		wantErr: `schedule entry "morning 0 9 * * 2h 10" must have 8 fields: name, 5 cron fields, duration and minScale`,
+	}, {
+		name:    "duplicate names",
+		value:   "a * * * * * 1m 1; a * * * * * 1m 1",
+		wantErr: `duplicate schedule entry name "a"`,
+	}, {
+		name:    "minute out of range",
+		value:   "a 60 * * * * 1m 1",
+		wantErr: `schedule entry "a": minute "60" must be in [0, 59] range`,
+	}, {
+		name:    "inverted range",
+		value:   "a * 5-3 * * * 1m 1",
+		wantErr: `schedule entry "a": hour "5-3" must be in [0, 23] range`,
+	}, {
+		name:    "bad step",
+		value:   "a */0 * * * * 1m 1",
+		wantErr: `schedule entry "a": invalid step in minute "*/0"`,
+	}, {
+		name:    "bad value",
+		value:   "a * * * jan * 1m 1",
+		wantErr: `schedule entry "a": invalid month "jan"`,
+	}, {
+		name:    "bad duration",
+		value:   "a * * * * * soon 1",
+		wantErr: `schedule entry "a": invalid duration "soon"`,
+	}, {
+		name:    "duration too long",
+		value:   "a * * * * * 25h 1",
+		wantErr: `schedule entry "a": duration 25h0m0s must be in [1m, 24h] range`,
+	}, {
+		name:    "negative min scale",
+		value:   "a * * * * * 1h -1",
+		wantErr: `schedule entry "a": minScale "-1" must be an integer in [0, 2147483647] range`,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseScaleSchedules(tc.value)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("Error = %v, want: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("Unexpected error:", err)
+			}
+			if len(got) != len(tc.wantNames) {
+				t.Fatalf("Got %d entries, want: %d", len(got), len(tc.wantNames))
+			}
+			for i, ss := range got {
+				if ss.Name != tc.wantNames[i] {
+					t.Errorf("Entry %d name = %s, want: %s", i, ss.Name, tc.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScaleScheduleWindows(t *testing.T) {
+	// 2021-01-04 is a Monday.
+	monday := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name      string
+		value     string
+		now       time.Time
+		wantEnd   time.Time
+		wantNext  time.Time
+		wantNoNxt bool
+	}{{
+		name:     "inside window",
+		value:    "a 0 9 * * 1-5 2h 10",
+		now:      monday.Add(10 * time.Hour),
+		wantEnd:  monday.Add(11 * time.Hour),
+		wantNext: monday.Add(24*time.Hour + 9*time.Hour),
+	}, {
+		name:     "right at the start",
+		value:    "a 0 9 * * 1-5 2h 10",
+		now:      monday.Add(9 * time.Hour),
+		wantEnd:  monday.Add(11 * time.Hour),
+		wantNext: monday.Add(24*time.Hour + 9*time.Hour),
+	}, {
+		name:     "right at the end",
+		value:    "a 0 9 * * 1-5 2h 10",
+		now:      monday.Add(11 * time.Hour),
+		wantNext: monday.Add(24*time.Hour + 9*time.Hour),
+	}, {
+		name:     "before window",
+		value:    "a 0 9 * * 1-5 2h 10",
+		now:      monday.Add(8*time.Hour + 30*time.Second),
+		wantNext: monday.Add(9 * time.Hour),
+	}, {
+		name:      "weekend",
+		value:     "a 0 9 * * 1-5 2h 10",
+		now:       monday.Add(-36 * time.Hour), // Saturday noon.
+		wantNoNxt: true,
+	}, {
+		name:      "sunday as 7",
+		value:     "a 0 9 * * 7 1h 10",
+		now:       monday.Add(-15*time.Hour + 30*time.Minute), // Sunday 9:30.
+		wantEnd:   monday.Add(-14 * time.Hour),
+		wantNoNxt: true,
+	}, {
+		name:     "overlapping windows pick the latest end",
+		value:    "a */10 * * * * 1h 10",
+		now:      monday.Add(5 * time.Minute),
+		wantEnd:  monday.Add(time.Hour),
+		wantNext: monday.Add(10 * time.Minute),
+	}, {
+		name:     "day of month or day of week",
+		value:    "a 0 0 5 * 1 1h 10",
+		now:      monday.Add(30 * time.Minute),
+		wantEnd:  monday.Add(time.Hour),
+		wantNext: monday.Add(24 * time.Hour),
+	}, {
+		name:     "other time zone",
+		value:    "a 0 9 * * 1-5 2h 10",
+		now:      monday.Add(10 * time.Hour).In(time.FixedZone("UTC+5", 5*3600)),
+		wantEnd:  monday.Add(11 * time.Hour),
+		wantNext: monday.Add(24*time.Hour + 9*time.Hour),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ss, err := ParseScaleSchedules(tc.value)
+			if err != nil {
+				t.Fatal("ParseScaleSchedules() =", err)
+			}
+			end, ok := ss[0].ActiveUntil(tc.now)
+			if ok != !tc.wantEnd.IsZero() || !end.Equal(tc.wantEnd) {
+				t.Errorf("ActiveUntil() = %v, %v, want: %v", end, ok, tc.wantEnd)
+			}
+			next, ok := ss[0].NextStart(tc.now, 24*time.Hour)
+			if ok == tc.wantNoNxt || (ok && !next.Equal(tc.wantNext)) {
+				t.Errorf("NextStart() = %v, %v, want: %v", next, ok, tc.wantNext)
+			}
+		})
+	}
+}
@@ -65,6 +65,31 @@ type MetricSpec struct {
 	PanicWindow time.Duration `json:"panicWindow"`
 	// ScrapeTarget is the K8s service that publishes the metric endpoint.
 	ScrapeTarget string `json:"scrapeTarget"`
+	// WindowHalfLife is the half-life of the exponential decay of the weights
+	// of the concurrency and RPS values averaged over the windows.
+	// Zero means all the values weigh the same.
+	// +optional
+	WindowHalfLife time.Duration `json:"windowHalfLife,omitempty"`
+	// ScrapeStrategy is how many of the pods are scraped: all of them, a
+	// fixed percentage, or the sample adapted to the pod count by default.
+	// +optional
+	ScrapeStrategy string `json:"scrapeStrategy,omitempty"`
+	// ScrapeSamplePercentage is the percentage of the pods scraped with the
+	// percentage scrape strategy.
+	// +optional
+	ScrapeSamplePercentage float64 `json:"scrapeSamplePercentage,omitempty"`
+	// ScrapeFullThreshold is the pod count up to which all the pods are
+	// scraped, whatever the strategy.
+	// +optional
+	ScrapeFullThreshold int32 `json:"scrapeFullThreshold,omitempty"`
+	// ScrapeTimeout is the timeout of the scrape of a pod.
+	// Zero means the default timeout.
+	// +optional
+	ScrapeTimeout time.Duration `json:"scrapeTimeout,omitempty"`
+	// ScrapeConcurrency is the maximum number of pods scraped at the same
+	// time. Zero means no limit.
+	// +optional
+	ScrapeConcurrency int32 `json:"scrapeConcurrency,omitempty"`
 }
 
 // MetricStatus reflects the status of metric collection for this specific entity.
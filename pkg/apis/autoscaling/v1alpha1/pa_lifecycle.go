@@ -63,6 +63,21 @@ func (pa *PodAutoscaler) Metric() string {
 	return defaultMetric(pa.Class())
 }
 
+// MetricType returns the contents of the metricType annotation, or the empty
+// string if the metric is not served by a metrics adapter.
+func (pa *PodAutoscaler) MetricType() string {
+	return pa.Annotations[autoscaling.MetricTypeAnnotationKey]
+}
+
+// Algorithm returns the contents of the algorithm annotation or the default
+// stable/panic algorithm.
+func (pa *PodAutoscaler) Algorithm() string {
+	if a, ok := pa.Annotations[autoscaling.AlgorithmAnnotationKey]; ok {
+		return a
+	}
+	return autoscaling.AlgorithmStablePanic
+}
+
 func (pa *PodAutoscaler) annotationInt32(key string) (int32, bool) {
 	if s, ok := pa.Annotations[key]; ok {
 		i, err := strconv.ParseInt(s, 10, 32)
@@ -97,6 +112,16 @@ func (pa *PodAutoscaler) ScaleBounds(asConfig *autoscalerconfig.Config) (int32,
 	return min, max
 }
 
+// MinScaleSchedules returns the parsed minScaleSchedule annotation value,
+// or false if not present or invalid.
+func (pa *PodAutoscaler) MinScaleSchedules() ([]autoscaling.ScaleSchedule, bool) {
+	if s, ok := pa.Annotations[autoscaling.MinScaleScheduleAnnotationKey]; ok {
+		ss, err := autoscaling.ParseScaleSchedules(s)
+		return ss, err == nil
+	}
+	return nil, false
+}
+
 // Target returns the target annotation value or false if not present, or invalid.
 func (pa *PodAutoscaler) Target() (float64, bool) {
 	return pa.annotationFloat64(autoscaling.TargetAnnotationKey)
@@ -132,18 +157,59 @@ func (pa *PodAutoscaler) ScaleToZeroPodRetention() (time.Duration, bool) {
 	return pa.annotationDuration(autoscaling.ScaleToZeroPodRetentionPeriodKey)
 }
 
+// IdleTimeout returns the idle timeout annotation value, or false if not present.
+func (pa *PodAutoscaler) IdleTimeout() (time.Duration, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationDuration(autoscaling.IdleTimeoutAnnotationKey)
+}
+
 // Window returns the window annotation value, or false if not present.
 func (pa *PodAutoscaler) Window() (time.Duration, bool) {
 	// The value is validated in the webhook.
 	return pa.annotationDuration(autoscaling.WindowAnnotationKey)
 }
 
+// WindowHalfLife returns the window half-life annotation value, or false if not present.
+func (pa *PodAutoscaler) WindowHalfLife() (time.Duration, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationDuration(autoscaling.WindowHalfLifeAnnotationKey)
+}
+
+// ScrapeStrategy returns the scrape strategy annotation, or false if not present.
+func (pa *PodAutoscaler) ScrapeStrategy() (string, bool) {
+	// The value is validated in the webhook.
+	s, ok := pa.Annotations[autoscaling.ScrapeStrategyAnnotationKey]
+	return s, ok
+}
+
+// ScrapeSamplePercentage returns the scrape sample percentage annotation, or
+// false if not present or invalid.
+func (pa *PodAutoscaler) ScrapeSamplePercentage() (float64, bool) {
+	return pa.annotationFloat64(autoscaling.ScrapeSamplePercentageAnnotationKey)
+}
+
 // ScaleDownDelay returns the scale down delay annotation, or false if not present.
 func (pa *PodAutoscaler) ScaleDownDelay() (time.Duration, bool) {
 	// The value is validated in the webhook.
 	return pa.annotationDuration(autoscaling.ScaleDownDelayAnnotationKey)
 }
 
+// LatencyTarget returns the latency target annotation value or false if not
+// present, or invalid.
+func (pa *PodAutoscaler) LatencyTarget() (time.Duration, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationDuration(autoscaling.LatencyTargetAnnotationKey)
+}
+
+// LatencyPercentile returns the latency percentile annotation value or the
+// default percentile, if not present or invalid.
+func (pa *PodAutoscaler) LatencyPercentile() float64 {
+	if p, ok := pa.annotationFloat64(autoscaling.LatencyPercentileAnnotationKey); ok {
+		return p
+	}
+	return autoscaling.LatencyPercentileDefault
+}
+
 // PanicWindowPercentage returns the panic window annotation value, or false if not present.
 func (pa *PodAutoscaler) PanicWindowPercentage() (percentage float64, ok bool) {
 	// The value is validated in the webhook.
@@ -156,12 +222,51 @@ func (pa *PodAutoscaler) PanicThresholdPercentage() (percentage float64, ok bool
 	return pa.annotationFloat64(autoscaling.PanicThresholdPercentageAnnotationKey)
 }
 
+// PanicExitThresholdPercentage returns the panic exit threshold annotation value,
+// or false if not present.
+func (pa *PodAutoscaler) PanicExitThresholdPercentage() (percentage float64, ok bool) {
+	// The value is validated in the webhook.
+	return pa.annotationFloat64(autoscaling.PanicExitThresholdPercentageAnnotationKey)
+}
+
+// PanicMinDuration returns the panic minimum duration annotation value,
+// or false if not present.
+func (pa *PodAutoscaler) PanicMinDuration() (time.Duration, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationDuration(autoscaling.PanicMinDurationAnnotationKey)
+}
+
+// PanicCooldown returns the panic cooldown annotation value, or false if not present.
+func (pa *PodAutoscaler) PanicCooldown() (time.Duration, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationDuration(autoscaling.PanicCooldownAnnotationKey)
+}
+
+// MaxScaleUpRate returns the max scale up rate annotation value, or false if not present.
+func (pa *PodAutoscaler) MaxScaleUpRate() (float64, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationFloat64(autoscaling.MaxScaleUpRateAnnotationKey)
+}
+
+// MaxScaleDownRate returns the max scale down rate annotation value, or false if not present.
+func (pa *PodAutoscaler) MaxScaleDownRate() (float64, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationFloat64(autoscaling.MaxScaleDownRateAnnotationKey)
+}
+
 // InitialScale returns the initial scale on the revision if present, or false if not present.
 func (pa *PodAutoscaler) InitialScale() (int32, bool) {
 	// The value is validated in the webhook.
 	return pa.annotationInt32(autoscaling.InitialScaleAnnotationKey)
 }
 
+// WarmPool returns the number of pods to keep ready while scaled to zero
+// if present, or false if not present.
+func (pa *PodAutoscaler) WarmPool() (int32, bool) {
+	// The value is validated in the webhook.
+	return pa.annotationInt32(autoscaling.WarmPoolAnnotationKey)
+}
+
 // IsReady returns true if the Status condition PodAutoscalerConditionReady
 // is true and the latest spec has been observed.
 func (pa *PodAutoscaler) IsReady() bool {
@@ -208,6 +313,25 @@ func (pas *PodAutoscalerStatus) MarkSKSNotReady(mes string) {
 	podCondSet.Manage(pas).MarkUnknown(PodAutoscalerConditionSKSReady, "NotReady", mes)
 }
 
+// MarkScaleLimited marks the PA condition denoting that its scale is limited
+// by the pod budget of its namespace.
+func (pas *PodAutoscalerStatus) MarkScaleLimited(reason, message string) {
+	podCondSet.Manage(pas).MarkTrueWithReason(PodAutoscalerConditionScaleLimited, reason, message)
+}
+
+// MarkScaleNotLimited removes the PA condition denoting that its scale is
+// limited by the pod budget of its namespace.
+func (pas *PodAutoscalerStatus) MarkScaleNotLimited() {
+	// ScaleLimited is not a terminal condition, so this does not fail.
+	podCondSet.Manage(pas).ClearCondition(PodAutoscalerConditionScaleLimited)
+}
+
+// IsScaleLimited returns true if the PA's scale is limited by the pod budget
+// of its namespace.
+func (pas *PodAutoscalerStatus) IsScaleLimited() bool {
+	return pas.GetCondition(PodAutoscalerConditionScaleLimited).IsTrue()
+}
+
 // GetCondition gets the condition `t`.
 func (pas *PodAutoscalerStatus) GetCondition(t apis.ConditionType) *apis.Condition {
 	return podCondSet.Manage(pas).GetCondition(t)
@@ -283,6 +407,22 @@ func (pas *PodAutoscalerStatus) GetDesiredScale() int32 {
 	return -1
 }
 
+// PodCount returns the number of pods the PA holds as of its last
+// reconciliation, including the warm ones.
+func (pas *PodAutoscalerStatus) PodCount() int32 {
+	n := pas.GetDesiredScale()
+	if a := pas.GetActualScale(); a > n {
+		n = a
+	}
+	if n < 0 {
+		n = 0
+	}
+	if pas.WarmScale != nil {
+		n += *pas.WarmScale
+	}
+	return n
+}
+
 // GetActualScale returns the actual scale if ever set, or -1.
 func (pas *PodAutoscalerStatus) GetActualScale() int32 {
 	if pas.ActualScale != nil {
@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -821,6 +822,67 @@ func TestWindowAnnotation(t *testing.T) {
 	}
 }
 
+func TestWindowHalfLifeAnnotation(t *testing.T) {
+	cases := []struct {
+		name         string
+		pa           *PodAutoscaler
+		wantHalfLife time.Duration
+		wantOK       bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.WindowHalfLifeAnnotationKey: "10s",
+		}),
+		wantHalfLife: 10 * time.Second,
+		wantOK:       true,
+	}, {
+		name: "invalid",
+		pa: pa(map[string]string{
+			autoscaling.WindowHalfLifeAnnotationKey: "soon",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotHalfLife, gotOK := tc.pa.WindowHalfLife()
+			if gotHalfLife != tc.wantHalfLife || gotOK != tc.wantOK {
+				t.Errorf("WindowHalfLife() = %v, %v; want: %v, %v", gotHalfLife, gotOK, tc.wantHalfLife, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestIdleTimeoutAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		pa          *PodAutoscaler
+		wantTimeout time.Duration
+		wantOK      bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.IdleTimeoutAnnotationKey: "30s",
+		}),
+		wantTimeout: 30 * time.Second,
+		wantOK:      true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotTimeout, gotOK := tc.pa.IdleTimeout()
+			if gotTimeout != tc.wantTimeout || gotOK != tc.wantOK {
+				t.Errorf("IdleTimeout() = %v, %v; want: %v, %v", gotTimeout, gotOK, tc.wantTimeout, tc.wantOK)
+			}
+		})
+	}
+}
+
 func TestPanicWindowPercentageAnnotation(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -934,6 +996,27 @@ func TestPanicThresholdPercentage(t *testing.T) {
 	}
 }
 
+func TestMaxScaleRates(t *testing.T) {
+	p := pa(map[string]string{})
+	if _, ok := p.MaxScaleUpRate(); ok {
+		t.Error("MaxScaleUpRate() = true without the annotation")
+	}
+	if _, ok := p.MaxScaleDownRate(); ok {
+		t.Error("MaxScaleDownRate() = true without the annotation")
+	}
+
+	p = pa(map[string]string{
+		autoscaling.MaxScaleUpRateAnnotationKey:   "100",
+		autoscaling.MaxScaleDownRateAnnotationKey: "1.5",
+	})
+	if got, ok := p.MaxScaleUpRate(); !ok || got != 100 {
+		t.Errorf("MaxScaleUpRate() = %v, %v; want 100, true", got, ok)
+	}
+	if got, ok := p.MaxScaleDownRate(); !ok || got != 1.5 {
+		t.Errorf("MaxScaleDownRate() = %v, %v; want 1.5, true", got, ok)
+	}
+}
+
 func pa(annotations map[string]string) *PodAutoscaler {
 	return &PodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1085,6 +1168,48 @@ func TestScaleStatus(t *testing.T) {
 	}
 }
 
+func TestPodCount(t *testing.T) {
+	pas := &PodAutoscalerStatus{}
+	if got, want := pas.PodCount(), int32(0); got != want {
+		t.Errorf("PodCount = %d, want: %d", got, want)
+	}
+	pas.DesiredScale, pas.ActualScale = ptr.Int32(3), ptr.Int32(5)
+	if got, want := pas.PodCount(), int32(5); got != want {
+		t.Errorf("PodCount = %d, want: %d", got, want)
+	}
+	pas.DesiredScale, pas.ActualScale, pas.WarmScale = ptr.Int32(0), ptr.Int32(0), ptr.Int32(2)
+	if got, want := pas.PodCount(), int32(2); got != want {
+		t.Errorf("PodCount = %d, want: %d", got, want)
+	}
+}
+
+func TestScaleLimited(t *testing.T) {
+	pas := &PodAutoscalerStatus{}
+	pas.InitializeConditions()
+	pas.MarkActive()
+	pas.MarkSKSReady()
+	pas.MarkScaleTargetInitialized()
+
+	pas.MarkScaleLimited("Reason", "message")
+	if !pas.IsScaleLimited() {
+		t.Error("IsScaleLimited = false after MarkScaleLimited")
+	}
+	if cond := pas.GetCondition(PodAutoscalerConditionScaleLimited); cond.Severity != apis.ConditionSeverityInfo {
+		t.Errorf("Severity = %q, want: %q", cond.Severity, apis.ConditionSeverityInfo)
+	}
+	// Limiting the scale doesn't affect the readiness.
+	apistest.CheckConditionSucceeded(pas, PodAutoscalerConditionReady, t)
+
+	pas.MarkScaleNotLimited()
+	if pas.IsScaleLimited() {
+		t.Error("IsScaleLimited = true after MarkScaleNotLimited")
+	}
+	if cond := pas.GetCondition(PodAutoscalerConditionScaleLimited); cond != nil {
+		t.Errorf("ScaleLimited = %v, want removed", cond)
+	}
+	apistest.CheckConditionSucceeded(pas, PodAutoscalerConditionReady, t)
+}
+
 func TestPodAutoscalerGetGroupVersionKind(t *testing.T) {
 	p := &PodAutoscaler{}
 	want := schema.GroupVersionKind{
@@ -1177,6 +1302,148 @@ func TestInitialScale(t *testing.T) {
 	}
 }
 
+func TestWarmPool(t *testing.T) {
+	cases := []struct {
+		name   string
+		pa     *PodAutoscaler
+		want   int32
+		wantOK bool
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.WarmPoolAnnotationKey: "3",
+		}),
+		want:   3,
+		wantOK: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK := tc.pa.WarmPool()
+			if got != tc.want {
+				t.Errorf("WarmPool = %v, want: %v", got, tc.want)
+			}
+			if gotOK != tc.wantOK {
+				t.Errorf("OK = %v, want: %v", gotOK, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestAlgorithm(t *testing.T) {
+	cases := []struct {
+		name string
+		pa   *PodAutoscaler
+		want string
+	}{{
+		name: "not present",
+		pa:   pa(map[string]string{}),
+		want: autoscaling.AlgorithmStablePanic,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.AlgorithmAnnotationKey: autoscaling.AlgorithmPID,
+		}),
+		want: autoscaling.AlgorithmPID,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pa.Algorithm(); got != tc.want {
+				t.Errorf("Algorithm = %q, want: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLatencyAnnotations(t *testing.T) {
+	cases := []struct {
+		name           string
+		pa             *PodAutoscaler
+		wantTarget     time.Duration
+		wantOK         bool
+		wantPercentile float64
+	}{{
+		name:           "not present",
+		pa:             pa(map[string]string{}),
+		wantPercentile: autoscaling.LatencyPercentileDefault,
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.LatencyTargetAnnotationKey:     "250ms",
+			autoscaling.LatencyPercentileAnnotationKey: "99.5",
+		}),
+		wantTarget:     250 * time.Millisecond,
+		wantOK:         true,
+		wantPercentile: 99.5,
+	}, {
+		name: "invalid",
+		pa: pa(map[string]string{
+			autoscaling.LatencyTargetAnnotationKey:     "fast",
+			autoscaling.LatencyPercentileAnnotationKey: "p99",
+		}),
+		wantPercentile: autoscaling.LatencyPercentileDefault,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotTarget, gotOK := tc.pa.LatencyTarget()
+			if gotTarget != tc.wantTarget || gotOK != tc.wantOK {
+				t.Errorf("LatencyTarget = %v, %v, want: %v, %v", gotTarget, gotOK, tc.wantTarget, tc.wantOK)
+			}
+			if got := tc.pa.LatencyPercentile(); got != tc.wantPercentile {
+				t.Errorf("LatencyPercentile = %v, want: %v", got, tc.wantPercentile)
+			}
+		})
+	}
+}
+
+func TestMinScaleSchedules(t *testing.T) {
+	cases := []struct {
+		name      string
+		pa        *PodAutoscaler
+		wantNames []string
+		wantOK    bool
+	}{{
+		name: "nil",
+		pa:   pa(nil),
+	}, {
+		name: "not present",
+		pa:   pa(map[string]string{}),
+	}, {
+		name: "present",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleScheduleAnnotationKey: "morning 0 9 * * 1-5 2h 10; batch 30 1 * * * 45m 5",
+		}),
+		wantNames: []string{"morning", "batch"},
+		wantOK:    true,
+	}, {
+		name: "malformed",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleScheduleAnnotationKey: "morning 0 9 * * 1-5 2h",
+		}),
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK := tc.pa.MinScaleSchedules()
+			var gotNames []string
+			for _, ss := range got {
+				gotNames = append(gotNames, ss.Name)
+			}
+			if !cmp.Equal(gotNames, tc.wantNames) {
+				t.Errorf("MinScaleSchedules names = %v, want: %v", gotNames, tc.wantNames)
+			}
+			if gotOK != tc.wantOK {
+				t.Errorf("OK = %v, want: %v", gotOK, tc.wantOK)
+			}
+		})
+	}
+}
+
 func TestIsScaleTargetInitialized(t *testing.T) {
 	p := PodAutoscaler{}
 	if got, want := p.Status.IsScaleTargetInitialized(), false; got != want {
@@ -1187,3 +1454,31 @@ func TestIsScaleTargetInitialized(t *testing.T) {
 		t.Errorf("after marking initially active: got: %v, want: %v", got, want)
 	}
 }
+
+func TestPanicHysteresisAnnotations(t *testing.T) {
+	p := pa(map[string]string{
+		autoscaling.PanicExitThresholdPercentageAnnotationKey: "150.0",
+		autoscaling.PanicMinDurationAnnotationKey:             "10s",
+		autoscaling.PanicCooldownAnnotationKey:                "30s",
+	})
+	if got, ok := p.PanicExitThresholdPercentage(); !ok || got != 150.0 {
+		t.Errorf("PanicExitThresholdPercentage = %v, %v, want: 150, true", got, ok)
+	}
+	if got, ok := p.PanicMinDuration(); !ok || got != 10*time.Second {
+		t.Errorf("PanicMinDuration = %v, %v, want: 10s, true", got, ok)
+	}
+	if got, ok := p.PanicCooldown(); !ok || got != 30*time.Second {
+		t.Errorf("PanicCooldown = %v, %v, want: 30s, true", got, ok)
+	}
+
+	p = pa(map[string]string{})
+	if _, ok := p.PanicExitThresholdPercentage(); ok {
+		t.Error("PanicExitThresholdPercentage returned ok for a missing annotation")
+	}
+	if _, ok := p.PanicMinDuration(); ok {
+		t.Error("PanicMinDuration returned ok for a missing annotation")
+	}
+	if _, ok := p.PanicCooldown(); ok {
+		t.Error("PanicCooldown returned ok for a missing annotation")
+	}
+}
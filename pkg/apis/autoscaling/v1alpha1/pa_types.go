@@ -108,6 +108,10 @@ const (
 	PodAutoscalerConditionActive apis.ConditionType = "Active"
 	// PodAutoscalerConditionSKSReady is set when SKS is ready.
 	PodAutoscalerConditionSKSReady = "SKSReady"
+	// PodAutoscalerConditionScaleLimited is set when the scale of the PodAutoscaler's
+	// ScaleTargetRef is limited by the pod budget of its namespace. It does not
+	// affect the readiness of the PodAutoscaler.
+	PodAutoscalerConditionScaleLimited apis.ConditionType = "ScaleLimited"
 )
 
 // PodAutoscalerStatus communicates the observed state of the PodAutoscaler (from the controller).
@@ -127,6 +131,22 @@ type PodAutoscalerStatus struct {
 
 	// ActualScale shows the actual number of replicas for the revision.
 	ActualScale *int32 `json:"actualScale,omitempty"`
+
+	// WarmScale shows the number of ready replicas kept warm while the
+	// revision is scaled to zero. These replicas are not serving traffic
+	// directly and are not included in ActualScale.
+	// +optional
+	WarmScale *int32 `json:"warmScale,omitempty"`
+
+	// ActiveMinScaleSchedule is the name of the minScaleSchedule entry
+	// that currently determines the minimum scale of the revision, if any.
+	// +optional
+	ActiveMinScaleSchedule string `json:"activeMinScaleSchedule,omitempty"`
+
+	// LastRequestTime is the last time the activator or the queue-proxies
+	// reported any request to the revision.
+	// +optional
+	LastRequestTime *metav1.Time `json:"lastRequestTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
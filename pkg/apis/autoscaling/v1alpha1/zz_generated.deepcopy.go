@@ -211,6 +211,15 @@ func (in *PodAutoscalerStatus) DeepCopyInto(out *PodAutoscalerStatus) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.WarmScale != nil {
+		in, out := &in.WarmScale, &out.WarmScale
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastRequestTime != nil {
+		in, out := &in.LastRequestTime, &out.LastRequestTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
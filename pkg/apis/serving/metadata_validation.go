@@ -19,6 +19,7 @@ package serving
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -94,6 +95,20 @@ func ValidateQueueSidecarAnnotation(annotations map[string]string) *apis.FieldEr
 	return nil
 }
 
+// ValidateLoadBalancingPolicyAnnotation validates LoadBalancingPolicyAnnotationKey
+func ValidateLoadBalancingPolicyAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[LoadBalancingPolicyAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch v {
+	case LoadBalancingPolicyRandomChoice2, LoadBalancingPolicyFirstAvailable,
+		LoadBalancingPolicyRoundRobin, LoadBalancingPolicyLeastRequests:
+		return nil
+	}
+	return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(LoadBalancingPolicyAnnotationKey)
+}
+
 // ValidateTimeoutSeconds validates timeout by comparing MaxRevisionTimeoutSeconds
 func ValidateTimeoutSeconds(ctx context.Context, timeoutSeconds int64) *apis.FieldError {
 	if timeoutSeconds != 0 {
@@ -193,3 +208,138 @@ func ValidateRevisionName(ctx context.Context, name, generateName string) *apis.
 	}
 	return nil
 }
+
+// ParseSessionAffinity splits a SessionAffinityAnnotationKey value into the
+// source of the session affinity key and its name.
+func ParseSessionAffinity(v string) (source, name string, ok bool) {
+	parts := strings.SplitN(v, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", false
+	}
+	switch parts[0] {
+	case SessionAffinitySourceHeader, SessionAffinitySourceCookie:
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+// ValidateSessionAffinityAnnotation validates SessionAffinityAnnotationKey
+func ValidateSessionAffinityAnnotation(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[SessionAffinityAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, _, ok := ParseSessionAffinity(v); !ok {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(SessionAffinityAnnotationKey)
+	}
+	return nil
+}
+
+const (
+	// MaxRetryAttempts is the upper bound of RetryAttemptsAnnotationKey.
+	MaxRetryAttempts = 5
+	// DefaultRetryBudgetPercentage is the default of RetryBudgetPercentageAnnotationKey.
+	DefaultRetryBudgetPercentage = 20.0
+	// DefaultRetryMaxBodyBytes is the default of RetryMaxBodyBytesAnnotationKey.
+	DefaultRetryMaxBodyBytes = 64 * 1024
+)
+
+var (
+	// defaultRetryMethods are the idempotent methods of RFC 7231.
+	defaultRetryMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodOptions,
+		http.MethodPut, http.MethodDelete, http.MethodTrace,
+	}
+	defaultRetryStatusCodes = []int{
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+	}
+	retryableMethods = sets.NewString(append(defaultRetryMethods, http.MethodPost, http.MethodPatch)...)
+)
+
+// RetryPolicy describes which requests the activator retries on another pod
+// of the revision.
+type RetryPolicy struct {
+	// Attempts is the maximum number of times a request is sent, including
+	// the first attempt.
+	Attempts int
+	// Methods are the request methods that are retried.
+	Methods sets.String
+	// StatusCodes are the response status codes that are retried.
+	StatusCodes sets.Int
+	// BudgetPercentage is the percentage of the requests in flight that may
+	// be retries.
+	BudgetPercentage float64
+	// MaxBodyBytes is the size limit of the request bodies that are buffered
+	// for replay.
+	MaxBodyBytes int64
+}
+
+// ParseRetryPolicy builds the RetryPolicy described by the retry annotations.
+// It returns a nil policy if retries are not enabled.
+func ParseRetryPolicy(annotations map[string]string) (*RetryPolicy, *apis.FieldError) {
+	p := &RetryPolicy{
+		Attempts:         1,
+		Methods:          sets.NewString(defaultRetryMethods...),
+		StatusCodes:      sets.NewInt(defaultRetryStatusCodes...),
+		BudgetPercentage: DefaultRetryBudgetPercentage,
+		MaxBodyBytes:     DefaultRetryMaxBodyBytes,
+	}
+	var errs *apis.FieldError
+	if v, ok := annotations[RetryAttemptsAnnotationKey]; ok {
+		if i, err := strconv.Atoi(v); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RetryAttemptsAnnotationKey))
+		} else if i < 1 || i > MaxRetryAttempts {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(i, 1, MaxRetryAttempts, apis.CurrentField).ViaKey(RetryAttemptsAnnotationKey))
+		} else {
+			p.Attempts = i
+		}
+	}
+	if v, ok := annotations[RetryMethodsAnnotationKey]; ok {
+		p.Methods = sets.NewString()
+		for _, m := range strings.Split(v, ",") {
+			m = strings.ToUpper(strings.TrimSpace(m))
+			if !retryableMethods.Has(m) {
+				errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RetryMethodsAnnotationKey))
+				break
+			}
+			p.Methods.Insert(m)
+		}
+	}
+	if v, ok := annotations[RetryStatusCodesAnnotationKey]; ok {
+		p.StatusCodes = sets.NewInt()
+		for _, s := range strings.Split(v, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || code < http.StatusBadRequest || code > 599 {
+				errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RetryStatusCodesAnnotationKey))
+				break
+			}
+			p.StatusCodes.Insert(code)
+		}
+	}
+	if v, ok := annotations[RetryBudgetPercentageAnnotationKey]; ok {
+		if f, err := strconv.ParseFloat(v, 64); err != nil {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RetryBudgetPercentageAnnotationKey))
+		} else if f <= 0 || f > 100 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(f, 0, 100.0, apis.CurrentField).ViaKey(RetryBudgetPercentageAnnotationKey))
+		} else {
+			p.BudgetPercentage = f
+		}
+	}
+	if v, ok := annotations[RetryMaxBodyBytesAnnotationKey]; ok {
+		if i, err := strconv.ParseInt(v, 10, 64); err != nil || i < 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(RetryMaxBodyBytesAnnotationKey))
+		} else {
+			p.MaxBodyBytes = i
+		}
+	}
+	if errs != nil || p.Attempts < 2 {
+		return nil, errs
+	}
+	return p, nil
+}
+
+// ValidateRetryAnnotations validates the retry annotations.
+func ValidateRetryAnnotations(annotations map[string]string) *apis.FieldError {
+	_, errs := ParseRetryPolicy(annotations)
+	return errs
+}
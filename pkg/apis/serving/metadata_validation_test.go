@@ -26,6 +26,7 @@ import (
 	authv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
@@ -311,6 +312,220 @@ func TestValidateQueueSidecarAnnotation(t *testing.T) {
 	}
 }
 
+func TestValidateLoadBalancingPolicyAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name:       "empty annotation",
+		annotation: map[string]string{},
+	}, {
+		name: "random choice 2",
+		annotation: map[string]string{
+			LoadBalancingPolicyAnnotationKey: LoadBalancingPolicyRandomChoice2,
+		},
+	}, {
+		name: "first available",
+		annotation: map[string]string{
+			LoadBalancingPolicyAnnotationKey: LoadBalancingPolicyFirstAvailable,
+		},
+	}, {
+		name: "round robin",
+		annotation: map[string]string{
+			LoadBalancingPolicyAnnotationKey: LoadBalancingPolicyRoundRobin,
+		},
+	}, {
+		name: "least requests",
+		annotation: map[string]string{
+			LoadBalancingPolicyAnnotationKey: LoadBalancingPolicyLeastRequests,
+		},
+	}, {
+		name: "unknown policy",
+		annotation: map[string]string{
+			LoadBalancingPolicyAnnotationKey: "fastest",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: fastest",
+			Paths:   []string{fmt.Sprintf("[%s]", LoadBalancingPolicyAnnotationKey)},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateLoadBalancingPolicyAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateSessionAffinityAnnotation(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		expectErr  *apis.FieldError
+	}{{
+		name:       "empty annotation",
+		annotation: map[string]string{},
+	}, {
+		name: "header",
+		annotation: map[string]string{
+			SessionAffinityAnnotationKey: "header:X-User",
+		},
+	}, {
+		name: "cookie",
+		annotation: map[string]string{
+			SessionAffinityAnnotationKey: "cookie:session",
+		},
+	}, {
+		name: "unknown source",
+		annotation: map[string]string{
+			SessionAffinityAnnotationKey: "query:user",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: query:user",
+			Paths:   []string{fmt.Sprintf("[%s]", SessionAffinityAnnotationKey)},
+		},
+	}, {
+		name: "missing name",
+		annotation: map[string]string{
+			SessionAffinityAnnotationKey: "header:",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: header:",
+			Paths:   []string{fmt.Sprintf("[%s]", SessionAffinityAnnotationKey)},
+		},
+	}, {
+		name: "missing source",
+		annotation: map[string]string{
+			SessionAffinityAnnotationKey: "X-User",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: X-User",
+			Paths:   []string{fmt.Sprintf("[%s]", SessionAffinityAnnotationKey)},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateSessionAffinityAnnotation(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation map[string]string
+		want       *RetryPolicy
+		expectErr  *apis.FieldError
+	}{{
+		name:       "empty annotation",
+		annotation: map[string]string{},
+	}, {
+		name: "single attempt",
+		annotation: map[string]string{
+			RetryAttemptsAnnotationKey: "1",
+		},
+	}, {
+		name: "defaults",
+		annotation: map[string]string{
+			RetryAttemptsAnnotationKey: "3",
+		},
+		want: &RetryPolicy{
+			Attempts:         3,
+			Methods:          sets.NewString("GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE"),
+			StatusCodes:      sets.NewInt(502, 503, 504),
+			BudgetPercentage: DefaultRetryBudgetPercentage,
+			MaxBodyBytes:     DefaultRetryMaxBodyBytes,
+		},
+	}, {
+		name: "all set",
+		annotation: map[string]string{
+			RetryAttemptsAnnotationKey:         "2",
+			RetryMethodsAnnotationKey:          "get, post",
+			RetryStatusCodesAnnotationKey:      "429,503",
+			RetryBudgetPercentageAnnotationKey: "50",
+			RetryMaxBodyBytesAnnotationKey:     "0",
+		},
+		want: &RetryPolicy{
+			Attempts:         2,
+			Methods:          sets.NewString("GET", "POST"),
+			StatusCodes:      sets.NewInt(429, 503),
+			BudgetPercentage: 50,
+			MaxBodyBytes:     0,
+		},
+	}, {
+		name: "too many attempts",
+		annotation: map[string]string{
+			RetryAttemptsAnnotationKey: "6",
+		},
+		expectErr: apis.ErrOutOfBoundsValue(6, 1, MaxRetryAttempts, apis.CurrentField).ViaKey(RetryAttemptsAnnotationKey),
+	}, {
+		name: "invalid attempts",
+		annotation: map[string]string{
+			RetryAttemptsAnnotationKey: "many",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: many",
+			Paths:   []string{fmt.Sprintf("[%s]", RetryAttemptsAnnotationKey)},
+		},
+	}, {
+		name: "connect is not retryable",
+		annotation: map[string]string{
+			RetryMethodsAnnotationKey: "GET,CONNECT",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: GET,CONNECT",
+			Paths:   []string{fmt.Sprintf("[%s]", RetryMethodsAnnotationKey)},
+		},
+	}, {
+		name: "successful status code",
+		annotation: map[string]string{
+			RetryStatusCodesAnnotationKey: "200",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: 200",
+			Paths:   []string{fmt.Sprintf("[%s]", RetryStatusCodesAnnotationKey)},
+		},
+	}, {
+		name: "zero budget",
+		annotation: map[string]string{
+			RetryBudgetPercentageAnnotationKey: "0",
+		},
+		expectErr: apis.ErrOutOfBoundsValue(0.0, 0, 100.0, apis.CurrentField).ViaKey(RetryBudgetPercentageAnnotationKey),
+	}, {
+		name: "negative body size",
+		annotation: map[string]string{
+			RetryMaxBodyBytesAnnotationKey: "-1",
+		},
+		expectErr: &apis.FieldError{
+			Message: "invalid value: -1",
+			Paths:   []string{fmt.Sprintf("[%s]", RetryMaxBodyBytesAnnotationKey)},
+		},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseRetryPolicy(c.annotation)
+			if got, want := err.Error(), c.expectErr.Error(); got != want {
+				t.Errorf("\nGot:  %q\nwant: %q", got, want)
+			}
+			if !cmp.Equal(got, c.want) {
+				t.Error("ParseRetryPolicy (-want, +got):", cmp.Diff(c.want, got))
+			}
+			if err := ValidateRetryAnnotations(c.annotation); err.Error() != c.expectErr.Error() {
+				t.Errorf("ValidateRetryAnnotations = %v, want: %v", err, c.expectErr)
+			}
+		})
+	}
+}
+
 func TestValidateTimeoutSecond(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -100,6 +100,52 @@ const (
 	// It has to be in [0.1,100]
 	QueueSideCarResourcePercentageAnnotation = "queue.sidecar." + GroupName + "/resourcePercentage"
 
+	// LoadBalancingPolicyAnnotationKey is the annotation that selects the policy the
+	// activator uses to pick a pod of the revision for each request.
+	// When unset the policy is chosen based on the container concurrency.
+	LoadBalancingPolicyAnnotationKey = "activator." + GroupName + "/loadBalancingPolicy"
+
+	// LoadBalancingPolicyRandomChoice2 picks the less loaded of two random pods.
+	LoadBalancingPolicyRandomChoice2 = "random-choice-2"
+	// LoadBalancingPolicyFirstAvailable picks the first pod with free capacity.
+	LoadBalancingPolicyFirstAvailable = "first-available"
+	// LoadBalancingPolicyRoundRobin cycles through the pods with free capacity.
+	LoadBalancingPolicyRoundRobin = "round-robin"
+	// LoadBalancingPolicyLeastRequests picks the pod with the fewest outstanding
+	// requests from this activator.
+	LoadBalancingPolicyLeastRequests = "least-requests"
+
+	// SessionAffinityAnnotationKey is the annotation that makes the activator route
+	// requests carrying the same key to the same pod of the revision, as long as
+	// that pod has capacity. The value is "header:<name>" or "cookie:<name>",
+	// naming where the key is read from.
+	SessionAffinityAnnotationKey = "activator." + GroupName + "/sessionAffinity"
+
+	// SessionAffinitySourceHeader reads the session affinity key from a request header.
+	SessionAffinitySourceHeader = "header"
+	// SessionAffinitySourceCookie reads the session affinity key from a cookie.
+	SessionAffinitySourceCookie = "cookie"
+
+	// RetryAttemptsAnnotationKey is the annotation that makes the activator retry
+	// failed requests on another pod of the revision. The value is the maximum
+	// number of times a request is sent, including the first attempt, and
+	// values above 1 enable retries.
+	RetryAttemptsAnnotationKey = "activator." + GroupName + "/retryAttempts"
+	// RetryMethodsAnnotationKey is the comma separated list of request methods
+	// the activator retries. Defaults to the idempotent methods.
+	RetryMethodsAnnotationKey = "activator." + GroupName + "/retryMethods"
+	// RetryStatusCodesAnnotationKey is the comma separated list of response
+	// status codes the activator retries, on top of failures to reach the pod.
+	// Defaults to 502, 503 and 504.
+	RetryStatusCodesAnnotationKey = "activator." + GroupName + "/retryStatusCodes"
+	// RetryBudgetPercentageAnnotationKey is the percentage of the revision's
+	// requests in flight that may be retries at any given time.
+	RetryBudgetPercentageAnnotationKey = "activator." + GroupName + "/retryBudgetPercentage"
+	// RetryMaxBodyBytesAnnotationKey is the size limit of the request bodies the
+	// activator buffers so the request can be replayed. Requests with larger
+	// bodies are not retried.
+	RetryMaxBodyBytesAnnotationKey = "activator." + GroupName + "/retryMaxBodyBytes"
+
 	// VisibilityLabelKeyObsolete is the obsolete VisibilityLabelKey.
 	// This will move over to VisibilityLabelKey in networking repo..
 	VisibilityLabelKeyObsolete = "serving.knative.dev/visibility"
@@ -82,6 +82,22 @@ func (r *Revision) IsFailed() bool {
 		rs.GetCondition(RevisionConditionReady).IsFalse()
 }
 
+// SessionAffinity returns where the activator reads the session affinity key
+// of a request from, and whether session affinity is enabled at all.
+func (r *Revision) SessionAffinity() (source, name string, ok bool) {
+	return serving.ParseSessionAffinity(r.Annotations[serving.SessionAffinityAnnotationKey])
+}
+
+// RetryPolicy returns the policy the activator retries the requests of the
+// revision with, or nil if retries are not enabled or are misconfigured.
+func (r *Revision) RetryPolicy() *serving.RetryPolicy {
+	if _, ok := r.Annotations[serving.RetryAttemptsAnnotationKey]; !ok {
+		return nil
+	}
+	p, _ := serving.ParseRetryPolicy(r.Annotations)
+	return p
+}
+
 // GetContainerConcurrency returns the container concurrency. If
 // container concurrency is not set, the default value will be returned.
 // We use the original default (0) here for backwards compatibility.
@@ -33,6 +33,7 @@ import (
 	"knative.dev/pkg/ptr"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 func TestRevisionDuckTypes(t *testing.T) {
@@ -100,6 +101,78 @@ func TestGetContainerConcurrency(t *testing.T) {
 
 }
 
+func TestRevisionSessionAffinity(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantSource string
+		wantName   string
+		wantOK     bool
+	}{{
+		name: "not present",
+	}, {
+		name:       "header",
+		annotation: "header:X-User",
+		wantSource: serving.SessionAffinitySourceHeader,
+		wantName:   "X-User",
+		wantOK:     true,
+	}, {
+		name:       "cookie",
+		annotation: "cookie:session",
+		wantSource: serving.SessionAffinitySourceCookie,
+		wantName:   "session",
+		wantOK:     true,
+	}, {
+		name:       "malformed",
+		annotation: "query:user",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Revision{}
+			if test.annotation != "" {
+				r.Annotations = map[string]string{serving.SessionAffinityAnnotationKey: test.annotation}
+			}
+			source, name, ok := r.SessionAffinity()
+			if source != test.wantSource || name != test.wantName || ok != test.wantOK {
+				t.Errorf("SessionAffinity() = %q, %q, %v, want: %q, %q, %v",
+					source, name, ok, test.wantSource, test.wantName, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantNil     bool
+	}{{
+		name:    "not present",
+		wantNil: true,
+	}, {
+		name:        "enabled",
+		annotations: map[string]string{serving.RetryAttemptsAnnotationKey: "3"},
+	}, {
+		name: "misconfigured",
+		annotations: map[string]string{
+			serving.RetryAttemptsAnnotationKey:    "3",
+			serving.RetryStatusCodesAnnotationKey: "ok",
+		},
+		wantNil: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &Revision{}
+			r.Annotations = test.annotations
+			if got := r.RetryPolicy(); (got == nil) != test.wantNil {
+				t.Errorf("RetryPolicy() = %v, want nil: %v", got, test.wantNil)
+			}
+		})
+	}
+}
+
 func TestRevisionIsReady(t *testing.T) {
 	cases := []struct {
 		name    string
@@ -65,6 +65,9 @@ func (rts *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError
 	// it follows the requirements on the name.
 	errs = errs.Also(serving.ValidateRevisionName(ctx, rts.Name, rts.GenerateName))
 	errs = errs.Also(serving.ValidateQueueSidecarAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateLoadBalancingPolicyAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateSessionAffinityAnnotation(rts.Annotations).ViaField("metadata.annotations"))
+	errs = errs.Also(serving.ValidateRetryAnnotations(rts.Annotations).ViaField("metadata.annotations"))
 	return errs
 }
 
@@ -61,6 +61,88 @@ func (t *TimedFloat64Buckets) String() string {
 	return spew.Sdump(t.buckets)
 }
 
+// TimedFloat64BucketsState is a point in time copy of the state of
+// TimedFloat64Buckets, intended for debugging.
+type TimedFloat64BucketsState struct {
+	Window      time.Duration
+	Granularity time.Duration
+	FirstWrite  time.Time
+	LastWrite   time.Time
+	// Buckets are the bucket values in time order, the last one
+	// being the bucket LastWrite belongs to.
+	Buckets     []float64
+	WindowTotal float64
+}
+
+// State returns a copy of the current state of the buckets.
+func (t *TimedFloat64Buckets) State() TimedFloat64BucketsState {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	ret := TimedFloat64BucketsState{
+		Window:      t.window,
+		Granularity: t.granularity,
+		FirstWrite:  t.firstWrite,
+		LastWrite:   t.lastWrite,
+		Buckets:     make([]float64, 0, len(t.buckets)),
+		WindowTotal: t.windowTotal,
+	}
+	if t.lastWrite.IsZero() {
+		// Nothing was ever recorded.
+		ret.Buckets = append(ret.Buckets, t.buckets...)
+		return ret
+	}
+	lastIdx := t.timeToIndex(t.lastWrite)
+	for i := lastIdx - len(t.buckets) + 1; i <= lastIdx; i++ {
+		ret.Buckets = append(ret.Buckets, t.buckets[i%len(t.buckets)])
+	}
+	return ret
+}
+
+// Restore fills the buckets with the data of the given state, e.g. one taken
+// by another process before it stopped recording. Only the data older than
+// anything already recorded and still within the window is restored.
+// States with a different granularity are ignored.
+func (t *TimedFloat64Buckets) Restore(s TimedFloat64BucketsState) {
+	if s.LastWrite.IsZero() || s.Granularity != t.granularity {
+		return
+	}
+
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+
+	if t.lastWrite.IsZero() || s.LastWrite.Sub(t.lastWrite) >= t.window {
+		// What we have is older than a window ago as of the restored
+		// data, so the restored data replaces it.
+		for i := range t.buckets {
+			t.buckets[i] = 0
+		}
+		t.windowTotal = 0
+		t.firstWrite = time.Time{}
+		t.lastWrite = s.LastWrite
+	}
+
+	for i, v := range s.Buckets {
+		bucketTime := s.LastWrite.Add(-time.Duration(len(s.Buckets)-1-i) * t.granularity)
+		if bucketTime.Before(s.FirstWrite) || t.lastWrite.Sub(bucketTime) >= t.window ||
+			(!t.firstWrite.IsZero() && !bucketTime.Before(t.firstWrite)) {
+			continue
+		}
+		// The buckets older than the first write are zero, so the
+		// value can be just set.
+		t.buckets[t.timeToIndex(bucketTime)%len(t.buckets)] = v
+		t.windowTotal += v
+	}
+
+	// The restored data starts at its first write, unless it is out of the window.
+	first := s.FirstWrite
+	if oldest := t.lastWrite.Add(-t.window + t.granularity); first.Before(oldest) {
+		first = oldest
+	}
+	if t.firstWrite.IsZero() || first.Before(t.firstWrite) {
+		t.firstWrite = first
+	}
+}
+
 // NewTimedFloat64Buckets generates a new TimedFloat64Buckets with the given
 // granularity.
 func NewTimedFloat64Buckets(window, granularity time.Duration) *TimedFloat64Buckets {
@@ -270,6 +270,80 @@ func TestDescendingRecord(t *testing.T) {
 	}
 }
 
+func TestTimedFloat64BucketsState(t *testing.T) {
+	buckets := NewTimedFloat64Buckets(5*time.Second, granularity)
+	if got, want := buckets.State().Buckets, []float64{0, 0, 0, 0, 0}; !cmp.Equal(got, want) {
+		t.Errorf("Empty Buckets = %v, want: %v", got, want)
+	}
+
+	start := time.Now().Truncate(granularity)
+	for i := 0; i < 7; i++ {
+		buckets.Record(start.Add(time.Duration(i)*time.Second), float64(i+1))
+	}
+	want := TimedFloat64BucketsState{
+		Window:      5 * time.Second,
+		Granularity: granularity,
+		FirstWrite:  start,
+		LastWrite:   start.Add(6 * time.Second),
+		Buckets:     []float64{3, 4, 5, 6, 7},
+		WindowTotal: 25,
+	}
+	if got := buckets.State(); !cmp.Equal(got, want) {
+		t.Error("State mismatch (-want,+got):", cmp.Diff(want, got))
+	}
+}
+
+func TestTimedFloat64BucketsRestore(t *testing.T) {
+	start := time.Now().Truncate(granularity)
+	old := NewTimedFloat64Buckets(5*time.Second, granularity)
+	for i := 0; i < 4; i++ {
+		old.Record(start.Add(time.Duration(i)*time.Second), float64(i+1))
+	}
+
+	// Restoring into empty buckets yields the same averages.
+	buckets := NewTimedFloat64Buckets(5*time.Second, granularity)
+	buckets.Restore(old.State())
+	now := start.Add(3 * time.Second)
+	if got, want := buckets.WindowAverage(now), old.WindowAverage(now); got != want {
+		t.Errorf("WindowAverage = %v, want: %v", got, want)
+	}
+
+	// The recorded data is kept, the restored data fills in the time before.
+	buckets = NewTimedFloat64Buckets(5*time.Second, granularity)
+	buckets.Record(start.Add(3*time.Second), 10)
+	buckets.Record(start.Add(4*time.Second), 10)
+	buckets.Restore(old.State())
+	want := TimedFloat64BucketsState{
+		Window:      5 * time.Second,
+		Granularity: granularity,
+		FirstWrite:  start,
+		LastWrite:   start.Add(4 * time.Second),
+		Buckets:     []float64{1, 2, 3, 10, 10},
+		WindowTotal: 26,
+	}
+	if got := buckets.State(); !cmp.Equal(got, want) {
+		t.Error("State mismatch (-want,+got):", cmp.Diff(want, got))
+	}
+
+	// Data older than the window is not restored.
+	buckets = NewTimedFloat64Buckets(5*time.Second, granularity)
+	buckets.Record(start.Add(6*time.Second), 10)
+	buckets.Restore(old.State())
+	if got, want := buckets.State().Buckets, []float64{3, 4, 0, 0, 10}; !cmp.Equal(got, want) {
+		t.Errorf("Buckets = %v, want: %v", got, want)
+	}
+	if got, want := buckets.WindowAverage(start.Add(6*time.Second)), 17./5; got != want {
+		t.Errorf("WindowAverage = %v, want: %v", got, want)
+	}
+
+	// Different granularities don't mix.
+	buckets = NewTimedFloat64Buckets(6*time.Second, 2*time.Second)
+	buckets.Restore(old.State())
+	if !buckets.IsEmpty(now) {
+		t.Error("Restore() with a different granularity restored data")
+	}
+}
+
 func TestTimedFloat64BucketsHoles(t *testing.T) {
 	now := time.Now()
 	buckets := NewTimedFloat64Buckets(5*time.Second, granularity)
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"time"
+)
+
+// TimedHistogram keeps the counts of a histogram over a time window.
+// Each bucket of the histogram is kept in its own TimedFloat64Buckets.
+type TimedHistogram struct {
+	// bounds are the upper bounds of the histogram buckets in increasing
+	// order. The last bucket counts the observations exceeding all bounds.
+	bounds []float64
+	counts []*TimedFloat64Buckets
+}
+
+// NewTimedHistogram generates a new TimedHistogram with the given bucket
+// bounds and granularity.
+func NewTimedHistogram(window, granularity time.Duration, bounds []float64) *TimedHistogram {
+	counts := make([]*TimedFloat64Buckets, len(bounds)+1)
+	for i := range counts {
+		counts[i] = NewTimedFloat64Buckets(window, granularity)
+	}
+	return &TimedHistogram{
+		bounds: bounds,
+		counts: counts,
+	}
+}
+
+// Record adds the histogram counts at the given time. Missing counts are
+// recorded as zero, excess counts are ignored.
+func (h *TimedHistogram) Record(now time.Time, counts []float64) {
+	for i, b := range h.counts {
+		var c float64
+		if i < len(counts) {
+			c = counts[i]
+		}
+		b.Record(now, c)
+	}
+}
+
+// IsEmpty returns true if no data has been recorded for the `window` period.
+func (h *TimedHistogram) IsEmpty(now time.Time) bool {
+	return h.counts[0].IsEmpty(now)
+}
+
+// Quantile returns the q-quantile (0 <= q <= 1) of the observations over
+// the window, or 0 if nothing was observed.
+func (h *TimedHistogram) Quantile(now time.Time, q float64) float64 {
+	counts := make([]float64, len(h.counts))
+	for i, b := range h.counts {
+		counts[i] = b.WindowAverage(now)
+	}
+	return HistogramQuantile(q, h.bounds, counts)
+}
+
+// ResizeWindow resizes the window of all the histogram buckets.
+func (h *TimedHistogram) ResizeWindow(w time.Duration) {
+	for _, b := range h.counts {
+		b.ResizeWindow(w)
+	}
+}
+
+// State returns a copy of the current state of the buckets of each
+// histogram bucket.
+func (h *TimedHistogram) State() []TimedFloat64BucketsState {
+	ret := make([]TimedFloat64BucketsState, len(h.counts))
+	for i, b := range h.counts {
+		ret[i] = b.State()
+	}
+	return ret
+}
+
+// Restore fills the histogram buckets with the data of the given states,
+// as TimedFloat64Buckets.Restore does. States of a histogram with different
+// bounds are ignored.
+func (h *TimedHistogram) Restore(s []TimedFloat64BucketsState) {
+	if len(s) != len(h.counts) {
+		return
+	}
+	for i, b := range h.counts {
+		b.Restore(s[i])
+	}
+}
+
+// HistogramQuantile returns the q-quantile (0 <= q <= 1) of the observations
+// counted in the histogram buckets with the given upper bounds. The value is
+// interpolated linearly within the bucket it falls into, the lowest bucket
+// starting at 0. Quantiles falling into the bucket above the last bound
+// return the last bound.
+func HistogramQuantile(q float64, bounds, counts []float64) float64 {
+	var total float64
+	for _, c := range counts {
+		total += c
+	}
+	if total <= 0 || len(bounds) == 0 {
+		return 0
+	}
+
+	rank := q * total
+	var cumulative, lower float64
+	for i, upper := range bounds {
+		if i >= len(counts) {
+			break
+		}
+		if c := counts[i]; c > 0 && cumulative+c >= rank {
+			return lower + (upper-lower)*(rank-cumulative)/c
+		}
+		cumulative += counts[i]
+		lower = upper
+	}
+	return bounds[len(bounds)-1]
+}
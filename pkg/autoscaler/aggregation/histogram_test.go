@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	bounds := []float64{10, 100, 1000}
+	tests := []struct {
+		name   string
+		q      float64
+		counts []float64
+		want   float64
+	}{{
+		name:   "empty",
+		q:      0.95,
+		counts: []float64{0, 0, 0, 0},
+		want:   0,
+	}, {
+		name:   "lowest bucket starts at zero",
+		q:      0.5,
+		counts: []float64{10, 0, 0, 0},
+		want:   5,
+	}, {
+		name:   "interpolated",
+		q:      0.95,
+		counts: []float64{50, 40, 10, 0},
+		want:   550,
+	}, {
+		name:   "skips empty buckets",
+		q:      0.5,
+		counts: []float64{0, 0, 4, 0},
+		want:   550,
+	}, {
+		name:   "above the last bound",
+		q:      0.99,
+		counts: []float64{90, 0, 0, 10},
+		want:   1000,
+	}, {
+		name:   "missing counts",
+		q:      0.5,
+		counts: []float64{2},
+		want:   5,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HistogramQuantile(test.q, bounds, test.counts); got != test.want {
+				t.Errorf("HistogramQuantile(%v) = %v, want: %v", test.q, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTimedHistogram(t *testing.T) {
+	now := time.Now()
+	h := NewTimedHistogram(5*time.Second, granularity, []float64{100, 200})
+	if !h.IsEmpty(now) {
+		t.Error("IsEmpty() = false for a new histogram")
+	}
+	if got := h.Quantile(now, 0.5); got != 0 {
+		t.Errorf("Quantile() = %v for a new histogram, want: 0", got)
+	}
+
+	// The counts of the seconds add up over the window.
+	h.Record(now, []float64{10, 0, 0})
+	h.Record(now.Add(time.Second), []float64{0, 10, 0})
+	now = now.Add(time.Second)
+	if h.IsEmpty(now) {
+		t.Error("IsEmpty() = true after recording")
+	}
+	if got, want := h.Quantile(now, 0.5), 100.; got != want {
+		t.Errorf("Quantile(0.5) = %v, want: %v", got, want)
+	}
+	if got, want := h.Quantile(now, 0.75), 150.; got != want {
+		t.Errorf("Quantile(0.75) = %v, want: %v", got, want)
+	}
+
+	// The older counts drop out of the smaller window.
+	h.ResizeWindow(time.Second)
+	if got, want := h.Quantile(now, 0.5), 150.; got != want {
+		t.Errorf("Quantile(0.5) after resize = %v, want: %v", got, want)
+	}
+}
@@ -18,6 +18,7 @@ package max
 
 import (
 	"math"
+	"sort"
 	"time"
 )
 
@@ -45,3 +46,36 @@ func (t *TimeWindow) Record(now time.Time, value int32) {
 func (t *TimeWindow) Current() int32 {
 	return t.window.Current()
 }
+
+// Observation is a value recorded in a TimeWindow at a given time.
+type Observation struct {
+	Time  time.Time `json:"time"`
+	Value int32     `json:"value"`
+}
+
+// State returns the recorded observations that still can become the
+// maximum of the window, the oldest first.
+func (t *TimeWindow) State() []Observation {
+	ret := make([]Observation, 0, t.window.length)
+	for i := 0; i < t.window.length; i++ {
+		e := t.window.maxima[t.window.index(t.window.first+i)]
+		ret = append(ret, Observation{
+			Time:  time.Unix(int64(e.index)*int64(t.granularity.Seconds()), 0),
+			Value: e.value,
+		})
+	}
+	return ret
+}
+
+// Restore merges the given observations, e.g. the State of another
+// TimeWindow, into the window.
+func (t *TimeWindow) Restore(obs []Observation) {
+	merged := append(t.State(), obs...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	t.window = newWindow(len(t.window.maxima))
+	for _, o := range merged {
+		t.Record(o.Time, o.Value)
+	}
+}
@@ -133,3 +133,28 @@ func BenchmarkLargeTimeWindowDescendingRecord(b *testing.B) {
 		})
 	}
 }
+
+func TestTimeWindowRestore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	old := NewTimeWindow(5*time.Second, time.Second)
+	old.Record(now, 7)
+	old.Record(now.Add(time.Second), 5)
+	old.Record(now.Add(2*time.Second), 3)
+
+	m := NewTimeWindow(5*time.Second, time.Second)
+	m.Record(now.Add(3*time.Second), 4)
+	m.Restore(old.State())
+	if got, want := m.Current(), int32(7); got != want {
+		t.Errorf("Current() = %d, want: %d", got, want)
+	}
+
+	// The restored maximum falls out of the window like a recorded one.
+	m.Record(now.Add(5*time.Second), 1)
+	if got, want := m.Current(), int32(5); got != want {
+		t.Errorf("Current() = %d, want: %d", got, want)
+	}
+	m.Record(now.Add(7*time.Second), 1)
+	if got, want := m.Current(), int32(4); got != want {
+		t.Errorf("Current() = %d, want: %d", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"math"
+	"time"
+)
+
+// WeightedFloat64Buckets is a TimedFloat64Buckets whose window average
+// weighs the buckets with an exponential decay, so that the recent values
+// matter more than the older ones.
+type WeightedFloat64Buckets struct {
+	*TimedFloat64Buckets
+
+	// halfLife is the age at which the weight of a bucket is half the weight
+	// of the most recent one. Zero means all the buckets weigh the same.
+	halfLife time.Duration
+}
+
+// NewWeightedFloat64Buckets generates a new WeightedFloat64Buckets with the
+// given granularity and half-life.
+func NewWeightedFloat64Buckets(window, granularity, halfLife time.Duration) *WeightedFloat64Buckets {
+	return &WeightedFloat64Buckets{
+		TimedFloat64Buckets: NewTimedFloat64Buckets(window, granularity),
+		halfLife:            halfLife,
+	}
+}
+
+// SetHalfLife changes the half-life of the weights of the buckets.
+func (t *WeightedFloat64Buckets) SetHalfLife(halfLife time.Duration) {
+	t.bucketsMutex.Lock()
+	defer t.bucketsMutex.Unlock()
+	t.halfLife = halfLife
+}
+
+func (t *WeightedFloat64Buckets) getHalfLife() time.Duration {
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	return t.halfLife
+}
+
+// WindowAverage returns the weighted average bucket value over the window.
+// The buckets taken into account are the same as for the flat average of
+// TimedFloat64Buckets.WindowAverage, and the weight of each is halved
+// every half-life of its age relative to the last write.
+func (t *WeightedFloat64Buckets) WindowAverage(now time.Time) float64 {
+	if t.getHalfLife() <= 0 {
+		return t.TimedFloat64Buckets.WindowAverage(now)
+	}
+
+	const precision = 6
+	now = now.Truncate(t.granularity)
+	t.bucketsMutex.RLock()
+	defer t.bucketsMutex.RUnlock()
+	d := now.Sub(t.lastWrite)
+	if d >= t.window {
+		// Nothing for more than a window time, just 0.
+		return 0
+	}
+	if d < 0 {
+		d = 0
+	}
+	// As for the flat average, the buckets since the last write are not
+	// taken into account.
+	numB := min(
+		int(t.lastWrite.Sub(t.firstWrite)/t.granularity)+1, // +1 since the times are inclusive.
+		len(t.buckets)-int(d/t.granularity))
+	decay := math.Exp2(-float64(t.granularity) / float64(t.halfLife))
+
+	lastIdx := t.timeToIndex(t.lastWrite)
+	var total, weights float64
+	weight := 1.
+	for i := 0; i < numB; i++ {
+		total += weight * t.buckets[(lastIdx-i)%len(t.buckets)]
+		weights += weight
+		weight *= decay
+	}
+	return roundToNDigits(precision, total/weights)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeightedFloat64BucketsWindowAverage(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name     string
+		halfLife time.Duration
+		values   []float64
+		at       time.Duration
+		want     float64
+	}{{
+		name:   "no half-life is a flat average",
+		values: []float64{2, 4},
+		want:   3,
+	}, {
+		name:     "constant values",
+		halfLife: 2 * time.Second,
+		values:   []float64{5, 5, 5, 5},
+		want:     5,
+	}, {
+		name:     "the last bucket weighs double after one half-life",
+		halfLife: time.Second,
+		values:   []float64{2, 4},
+		want:     3.333333,
+	}, {
+		name:     "the age is relative to the last write",
+		halfLife: time.Second,
+		values:   []float64{2, 4},
+		at:       2 * time.Second,
+		want:     3.333333,
+	}, {
+		name:     "the buckets past the window are ignored",
+		halfLife: time.Second,
+		values:   []float64{100, 100, 100, 100, 2, 4},
+		at:       2 * time.Second,
+		want:     3.333333,
+	}, {
+		name:     "nothing for a window",
+		halfLife: time.Second,
+		values:   []float64{2, 4},
+		at:       5 * time.Second,
+		want:     0,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buckets := NewWeightedFloat64Buckets(4*time.Second, time.Second, tc.halfLife)
+			for i, v := range tc.values {
+				buckets.Record(now.Add(time.Duration(i)*time.Second), v)
+			}
+			last := now.Add(time.Duration(len(tc.values)-1) * time.Second)
+			if got := buckets.WindowAverage(last.Add(tc.at)); got != tc.want {
+				t.Errorf("WindowAverage() = %v, want: %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWeightedFloat64BucketsSpike(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	buckets := NewWeightedFloat64Buckets(60*time.Second, time.Second, 0)
+	for i := 0; i < 59; i++ {
+		buckets.Record(now.Add(time.Duration(i)*time.Second), 1)
+	}
+	last := now.Add(59 * time.Second)
+	buckets.Record(last, 61)
+
+	flat := buckets.WindowAverage(last)
+	if flat != 2 {
+		t.Errorf("WindowAverage() without half-life = %v, want: 2", flat)
+	}
+	buckets.SetHalfLife(5 * time.Second)
+	if got := buckets.WindowAverage(last); got < 5 {
+		t.Errorf("WindowAverage() with a half-life = %v, want: at least 5", got)
+	}
+}
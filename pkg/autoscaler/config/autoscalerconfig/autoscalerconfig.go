@@ -66,6 +66,26 @@ type Config struct {
 	PanicWindowPercentage    float64
 	PanicThresholdPercentage float64
 
+	// PanicExitThresholdPercentage is the percentage of the target the
+	// observed metric must stay below for the panic mode to end.
+	// Zero means the panic mode ends below the panic threshold.
+	PanicExitThresholdPercentage float64
+	// PanicMinDuration is the minimum time the panic mode lasts once entered.
+	PanicMinDuration time.Duration
+	// PanicCooldown is the time after the panic mode ends during which it
+	// is not entered again.
+	PanicCooldown time.Duration
+
+	// PanicQueueDepth is the average number of requests per ready pod waiting
+	// for a free slot within the concurrency limit over the panic window at
+	// which panic mode is entered. Zero disables it.
+	PanicQueueDepth float64
+
+	// WindowHalfLife is the half-life of the exponential decay of the weights
+	// of the concurrency and RPS values averaged over the windows. Zero means
+	// all the values weigh the same.
+	WindowHalfLife time.Duration
+
 	// ScaleToZeroGracePeriod is the time we will wait for networking to
 	// propagate before scaling down. We may wait less than this if it is safe to
 	// do so, for example if the Activator has already been in the path for
@@ -84,5 +104,22 @@ type Config struct {
 	// add an additional delay to the very last pod, if required.
 	ScaleDownDelay time.Duration
 
+	// EnableStatsPush makes the queue-proxies push their stats to the
+	// autoscaler, which scrapes the pods of a revision only while they don't.
+	EnableStatsPush bool
+
+	// ScrapeStrategy is how many of the pods of a revision are scraped: all
+	// of them, ScrapeSamplePercentage of them, or an adaptive sample.
+	ScrapeStrategy         string
+	ScrapeSamplePercentage float64
+	// ScrapeFullThreshold is the pod count up to which all the pods of a
+	// revision are scraped, whatever the strategy.
+	ScrapeFullThreshold int32
+	// ScrapeTimeout is the timeout of the scrape of a pod.
+	ScrapeTimeout time.Duration
+	// ScrapeConcurrency is the maximum number of pods of a revision scraped
+	// at the same time. Zero means no limit.
+	ScrapeConcurrency int32
+
 	PodAutoscalerClass string
 }
@@ -53,6 +53,10 @@ func defaultConfig() *autoscalerconfig.Config {
 		PanicWindowPercentage:         10,
 		ActivatorCapacity:             100,
 		PanicThresholdPercentage:      200,
+		PanicQueueDepth:               1,
+		PanicExitThresholdPercentage:  0,
+		PanicMinDuration:              0 * time.Second,
+		PanicCooldown:                 0 * time.Second,
 		StableWindow:                  60 * time.Second,
 		ScaleToZeroGracePeriod:        30 * time.Second,
 		ScaleToZeroPodRetentionPeriod: 0 * time.Second,
@@ -62,6 +66,11 @@ func defaultConfig() *autoscalerconfig.Config {
 		InitialScale:                  1,
 		MaxScale:                      0,
 		MaxScaleLimit:                 0,
+		ScrapeStrategy:                autoscaling.ScrapeStrategyAdaptive,
+		ScrapeSamplePercentage:        10,
+		ScrapeFullThreshold:           3,
+		ScrapeTimeout:                 3 * time.Second,
+		ScrapeConcurrency:             0,
 	}
 }
 
@@ -71,9 +80,11 @@ func NewConfigFromMap(data map[string]string) (*autoscalerconfig.Config, error)
 
 	if err := cm.Parse(data,
 		cm.AsString("pod-autoscaler-class", &lc.PodAutoscalerClass),
+		cm.AsString("scrape-strategy", &lc.ScrapeStrategy),
 
 		cm.AsBool("enable-scale-to-zero", &lc.EnableScaleToZero),
 		cm.AsBool("allow-zero-initial-scale", &lc.AllowZeroInitialScale),
+		cm.AsBool("enable-stats-push", &lc.EnableStatsPush),
 
 		cm.AsFloat64("max-scale-up-rate", &lc.MaxScaleUpRate),
 		cm.AsFloat64("max-scale-down-rate", &lc.MaxScaleDownRate),
@@ -84,15 +95,24 @@ func NewConfigFromMap(data map[string]string) (*autoscalerconfig.Config, error)
 		cm.AsFloat64("panic-window-percentage", &lc.PanicWindowPercentage),
 		cm.AsFloat64("activator-capacity", &lc.ActivatorCapacity),
 		cm.AsFloat64("panic-threshold-percentage", &lc.PanicThresholdPercentage),
+		cm.AsFloat64("panic-queue-depth", &lc.PanicQueueDepth),
+		cm.AsFloat64("panic-exit-threshold-percentage", &lc.PanicExitThresholdPercentage),
+		cm.AsFloat64("scrape-sample-percentage", &lc.ScrapeSamplePercentage),
 
 		cm.AsInt32("initial-scale", &lc.InitialScale),
 		cm.AsInt32("max-scale", &lc.MaxScale),
 		cm.AsInt32("max-scale-limit", &lc.MaxScaleLimit),
+		cm.AsInt32("scrape-full-threshold", &lc.ScrapeFullThreshold),
+		cm.AsInt32("scrape-concurrency", &lc.ScrapeConcurrency),
 
 		cm.AsDuration("stable-window", &lc.StableWindow),
+		cm.AsDuration("window-half-life", &lc.WindowHalfLife),
 		cm.AsDuration("scale-down-delay", &lc.ScaleDownDelay),
 		cm.AsDuration("scale-to-zero-grace-period", &lc.ScaleToZeroGracePeriod),
 		cm.AsDuration("scale-to-zero-pod-retention-period", &lc.ScaleToZeroPodRetentionPeriod),
+		cm.AsDuration("scrape-timeout", &lc.ScrapeTimeout),
+		cm.AsDuration("panic-min-duration", &lc.PanicMinDuration),
+		cm.AsDuration("panic-cooldown", &lc.PanicCooldown),
 	); err != nil {
 		return nil, fmt.Errorf("failed to parse data: %w", err)
 	}
@@ -145,6 +165,23 @@ func validate(lc *autoscalerconfig.Config) (*autoscalerconfig.Config, error) {
 		return nil, fmt.Errorf("activator-capacity = %v, must be at least 1", lc.ActivatorCapacity)
 	}
 
+	if lc.PanicQueueDepth < 0 {
+		return nil, fmt.Errorf("panic-queue-depth = %v, must be at least 0", lc.PanicQueueDepth)
+	}
+
+	if lc.PanicExitThresholdPercentage < 0 || lc.PanicExitThresholdPercentage > lc.PanicThresholdPercentage {
+		return nil, fmt.Errorf("panic-exit-threshold-percentage = %v, must be in [0, panic-threshold-percentage] range",
+			lc.PanicExitThresholdPercentage)
+	}
+
+	if lc.PanicMinDuration < 0 || lc.PanicMinDuration > autoscaling.WindowMax {
+		return nil, fmt.Errorf("panic-min-duration = %v, must be in [0s; %v] range", lc.PanicMinDuration, autoscaling.WindowMax)
+	}
+
+	if lc.PanicCooldown < 0 || lc.PanicCooldown > autoscaling.WindowMax {
+		return nil, fmt.Errorf("panic-cooldown = %v, must be in [0s; %v] range", lc.PanicCooldown, autoscaling.WindowMax)
+	}
+
 	if lc.MaxScaleUpRate <= 1.0 {
 		return nil, fmt.Errorf("max-scale-up-rate = %v, must be greater than 1.0", lc.MaxScaleUpRate)
 	}
@@ -164,6 +201,14 @@ func validate(lc *autoscalerconfig.Config) (*autoscalerconfig.Config, error) {
 		return nil, fmt.Errorf("stable-window = %v, must be specified with at most second precision", lc.StableWindow)
 	}
 
+	if lc.WindowHalfLife < 0 || lc.WindowHalfLife > autoscaling.WindowMax {
+		return nil, fmt.Errorf("window-half-life = %v, must be in [0s; %v] range", lc.WindowHalfLife, autoscaling.WindowMax)
+	}
+
+	if lc.WindowHalfLife.Round(time.Second) != lc.WindowHalfLife {
+		return nil, fmt.Errorf("window-half-life = %v, must be specified with at most second precision", lc.WindowHalfLife)
+	}
+
 	// We ensure BucketSize in the `MakeMetric`, so just ensure percentage is in the correct region.
 	if lc.PanicWindowPercentage < autoscaling.PanicWindowPercentageMin ||
 		lc.PanicWindowPercentage > autoscaling.PanicWindowPercentageMax {
@@ -180,6 +225,29 @@ func validate(lc *autoscalerconfig.Config) (*autoscalerconfig.Config, error) {
 		return nil, fmt.Errorf("max-scale = %v, must be in [0, max-scale-limit] range", lc.MaxScale)
 	}
 
+	switch lc.ScrapeStrategy {
+	case autoscaling.ScrapeStrategyAdaptive, autoscaling.ScrapeStrategyFull, autoscaling.ScrapeStrategyPercentage:
+	default:
+		return nil, fmt.Errorf("scrape-strategy = %q, must be one of %s, %s or %s", lc.ScrapeStrategy,
+			autoscaling.ScrapeStrategyAdaptive, autoscaling.ScrapeStrategyFull, autoscaling.ScrapeStrategyPercentage)
+	}
+
+	if lc.ScrapeSamplePercentage <= 0 || lc.ScrapeSamplePercentage > 100 {
+		return nil, fmt.Errorf("scrape-sample-percentage = %v, must be in (0, 100] interval", lc.ScrapeSamplePercentage)
+	}
+
+	if lc.ScrapeFullThreshold < 0 {
+		return nil, fmt.Errorf("scrape-full-threshold = %v, must be at least 0", lc.ScrapeFullThreshold)
+	}
+
+	if lc.ScrapeTimeout <= 0 {
+		return nil, fmt.Errorf("scrape-timeout = %v, must be positive", lc.ScrapeTimeout)
+	}
+
+	if lc.ScrapeConcurrency < 0 {
+		return nil, fmt.Errorf("scrape-concurrency = %v, must be at least 0", lc.ScrapeConcurrency)
+	}
+
 	if lc.MaxScaleLimit < 0 {
 		return nil, fmt.Errorf("max-scale-limit = %v, must be at least 0", lc.MaxScaleLimit)
 	}
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	. "knative.dev/pkg/configmap/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 )
 
@@ -235,6 +236,136 @@ func TestNewConfig(t *testing.T) {
 			"stable-window": "61984ms",
 		},
 		wantErr: true,
+	}, {
+		name: "with window half-life",
+		input: map[string]string{
+			"window-half-life": "10s",
+		},
+		want: func() *autoscalerconfig.Config {
+			c := defaultConfig()
+			c.WindowHalfLife = 10 * time.Second
+			return c
+		}(),
+	}, {
+		name: "with scrape settings",
+		input: map[string]string{
+			"scrape-strategy":          "percentage",
+			"scrape-sample-percentage": "25",
+			"scrape-full-threshold":    "5",
+			"scrape-timeout":           "1500ms",
+			"scrape-concurrency":       "10",
+		},
+		want: func() *autoscalerconfig.Config {
+			c := defaultConfig()
+			c.ScrapeStrategy = autoscaling.ScrapeStrategyPercentage
+			c.ScrapeSamplePercentage = 25
+			c.ScrapeFullThreshold = 5
+			c.ScrapeTimeout = 1500 * time.Millisecond
+			c.ScrapeConcurrency = 10
+			return c
+		}(),
+	}, {
+		name: "invalid scrape strategy",
+		input: map[string]string{
+			"scrape-strategy": "random",
+		},
+		wantErr: true,
+	}, {
+		name: "scrape sample percentage out of bounds",
+		input: map[string]string{
+			"scrape-sample-percentage": "101",
+		},
+		wantErr: true,
+	}, {
+		name: "scrape full threshold negative",
+		input: map[string]string{
+			"scrape-full-threshold": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "scrape timeout zero",
+		input: map[string]string{
+			"scrape-timeout": "0s",
+		},
+		wantErr: true,
+	}, {
+		name: "scrape concurrency negative",
+		input: map[string]string{
+			"scrape-concurrency": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "with panic queue depth",
+		input: map[string]string{
+			"panic-queue-depth": "2.5",
+		},
+		want: func() *autoscalerconfig.Config {
+			c := defaultConfig()
+			c.PanicQueueDepth = 2.5
+			return c
+		}(),
+	}, {
+		name: "panic queue depth negative",
+		input: map[string]string{
+			"panic-queue-depth": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "with panic hysteresis and cooldown",
+		input: map[string]string{
+			"panic-exit-threshold-percentage": "120",
+			"panic-min-duration":              "2m",
+			"panic-cooldown":                  "30s",
+		},
+		want: func() *autoscalerconfig.Config {
+			c := defaultConfig()
+			c.PanicExitThresholdPercentage = 120
+			c.PanicMinDuration = 2 * time.Minute
+			c.PanicCooldown = 30 * time.Second
+			return c
+		}(),
+	}, {
+		name: "panic exit threshold over panic threshold",
+		input: map[string]string{
+			"panic-exit-threshold-percentage": "201",
+		},
+		wantErr: true,
+	}, {
+		name: "panic exit threshold negative",
+		input: map[string]string{
+			"panic-exit-threshold-percentage": "-1",
+		},
+		wantErr: true,
+	}, {
+		name: "panic min duration negative",
+		input: map[string]string{
+			"panic-min-duration": "-1s",
+		},
+		wantErr: true,
+	}, {
+		name: "panic cooldown too big",
+		input: map[string]string{
+			"panic-cooldown": "1h1s",
+		},
+		wantErr: true,
+	}, {
+		name: "window half-life negative",
+		input: map[string]string{
+			"window-half-life": "-1s",
+		},
+		wantErr: true,
+	}, {
+		name: "window half-life too big",
+		input: map[string]string{
+			"window-half-life": "1h1s",
+		},
+		wantErr: true,
+	}, {
+		name: "window half-life not seconds",
+		input: map[string]string{
+			"window-half-life": "1500ms",
+		},
+		wantErr: true,
 	}, {
 		name: "scale-down-delay not seconds",
 		input: map[string]string{
@@ -314,6 +445,22 @@ func TestNewConfig(t *testing.T) {
 			"allow-zero-initial-scale": "invalid",
 		},
 		wantErr: true,
+	}, {
+		name: "with stats push",
+		input: map[string]string{
+			"enable-stats-push": "true",
+		},
+		want: func() *autoscalerconfig.Config {
+			c := defaultConfig()
+			c.EnableStatsPush = true
+			return c
+		}(),
+	}, {
+		name: "with non-parseable enable-stats-push",
+		input: map[string]string{
+			"enable-stats-push": "invalid",
+		},
+		wantErr: true,
 	}, {
 		name: "with negative default max scale",
 		input: map[string]string{
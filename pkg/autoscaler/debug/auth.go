@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// ErrUnauthenticated is returned when the caller's identity can't be established.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is returned when the caller is not permitted to access the state.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authorizer decides whether a request may access the debug state.
+type Authorizer interface {
+	// Authorize returns nil if the request is allowed, ErrUnauthenticated or
+	// ErrForbidden if it is not, or another error if the decision failed.
+	Authorize(r *http.Request) error
+}
+
+type kubeAuthorizer struct {
+	client kubernetes.Interface
+}
+
+// NewKubeAuthorizer returns an Authorizer which authenticates the bearer
+// token of the request using a TokenReview and then checks the caller may
+// `get` the requested path as a non-resource URL using a SubjectAccessReview.
+// E.g. a ClusterRole rule permitting `get` on the `/debug/autoscaler/*`
+// nonResourceURLs grants access to the state of all the revisions.
+func NewKubeAuthorizer(client kubernetes.Interface) Authorizer {
+	return &kubeAuthorizer{client: client}
+}
+
+func (a *kubeAuthorizer) Authorize(r *http.Request) error {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) || len(auth) == len(prefix) {
+		return fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+
+	tr, err := a.client.AuthenticationV1().TokenReviews().Create(r.Context(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: strings.TrimPrefix(auth, prefix)},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tr.Status.Authenticated {
+		return fmt.Errorf("%w: %s", ErrUnauthenticated, tr.Status.Error)
+	}
+
+	user := tr.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(r.Context(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: "get",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to review access: %w", err)
+	}
+	if !sar.Status.Allowed {
+		return fmt.Errorf("%w: %s may not get %s", ErrForbidden, user.Username, r.URL.Path)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+func TestKubeAuthorizer(t *testing.T) {
+	const (
+		token = "secret-token"
+		user  = "oncall"
+	)
+	tests := []struct {
+		name          string
+		header        string
+		authenticated bool
+		allowed       bool
+		reviewErr     error
+		wantErr       error
+		wantAnyErr    bool
+	}{{
+		name:          "allowed",
+		header:        "Bearer " + token,
+		authenticated: true,
+		allowed:       true,
+	}, {
+		name:    "no token",
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:    "basic auth",
+		header:  "Basic dXNlcjpwYXNz",
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:    "bad token",
+		header:  "Bearer bad-token",
+		wantErr: ErrUnauthenticated,
+	}, {
+		name:          "forbidden",
+		header:        "Bearer " + token,
+		authenticated: true,
+		wantErr:       ErrForbidden,
+	}, {
+		name:       "review failure",
+		header:     "Bearer " + token,
+		reviewErr:  errors.New("apiserver unavailable"),
+		wantAnyErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fakekubeclient.NewSimpleClientset()
+			client.PrependReactor("create", "tokenreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				if test.reviewErr != nil {
+					return true, nil, test.reviewErr
+				}
+				tr := action.(clientgotesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+				tr.Status.Authenticated = test.authenticated && tr.Spec.Token == token
+				tr.Status.User.Username = user
+				return true, tr, nil
+			})
+			client.PrependReactor("create", "subjectaccessreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				sar := action.(clientgotesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				attrs := sar.Spec.NonResourceAttributes
+				sar.Status.Allowed = test.allowed && sar.Spec.User == user &&
+					attrs.Path == Path+"ns/rev" && attrs.Verb == "get"
+				return true, sar, nil
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "http://autoscaler"+Path+"ns/rev", nil)
+			if test.header != "" {
+				r.Header.Set("Authorization", test.header)
+			}
+			err := NewKubeAuthorizer(client).Authorize(r)
+			switch {
+			case test.wantAnyErr:
+				if err == nil {
+					t.Error("Authorize() = nil, want an error")
+				}
+			case !errors.Is(err, test.wantErr):
+				t.Errorf("Authorize() = %v, want: %v", err, test.wantErr)
+			}
+		})
+	}
+}
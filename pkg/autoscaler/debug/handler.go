@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
+)
+
+// Path is the path the handler is served under. The state of a revision
+// is available at Path + "<namespace>/<revision>".
+const Path = "/debug/autoscaler/"
+
+// DeciderStater returns the state of the Decider of a revision.
+type DeciderStater interface {
+	State(types.NamespacedName) (*scaling.DeciderState, bool)
+}
+
+// CollectionStater returns the state of the metric collection of a revision.
+type CollectionStater interface {
+	State(types.NamespacedName) (*metrics.CollectionState, bool)
+}
+
+// BucketOwner returns the statforwarder bucket of a revision and its holder.
+type BucketOwner interface {
+	Owner(rev string) (bkt, holder string, local bool)
+}
+
+// RevisionState is the state of a single revision returned by the handler.
+type RevisionState struct {
+	Key string `json:"key"`
+
+	// Bucket is the statforwarder bucket owning the revision and
+	// BucketHolder the autoscaler pod holding that bucket.
+	// Decider state is only available on the holder.
+	Bucket       string `json:"bucket"`
+	BucketHolder string `json:"bucketHolder,omitempty"`
+	IsLocal      bool   `json:"isLocal"`
+
+	Decider    *scaling.DeciderState `json:"decider,omitempty"`
+	Collection *CollectionState      `json:"collection,omitempty"`
+}
+
+// CollectionState is metrics.CollectionState with the last scraper
+// error rendered as a string.
+type CollectionState struct {
+	*metrics.CollectionState
+	LastError string `json:"LastError,omitempty"`
+}
+
+type handler struct {
+	logger      *zap.SugaredLogger
+	authorizer  Authorizer
+	deciders    DeciderStater
+	collections CollectionStater
+	owner       BucketOwner
+}
+
+// NewHandler creates a handler dumping the state of the revisions to the
+// requests authorized by the authorizer.
+func NewHandler(logger *zap.SugaredLogger, authorizer Authorizer, deciders DeciderStater,
+	collections CollectionStater, owner BucketOwner) http.Handler {
+	return &handler{
+		logger:      logger.Named("debug-handler"),
+		authorizer:  authorizer,
+		deciders:    deciders,
+		collections: collections,
+		owner:       owner,
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.authorizer.Authorize(r); err != nil {
+		switch {
+		case errors.Is(err, ErrUnauthenticated):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, ErrForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			h.logger.Errorw("Failed to authorize request", zap.Error(err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, Path), "/")
+	if !strings.HasPrefix(r.URL.Path, Path) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "path must be "+Path+"<namespace>/<revision>", http.StatusBadRequest)
+		return
+	}
+	key := types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+
+	state := RevisionState{Key: key.String()}
+	state.Bucket, state.BucketHolder, state.IsLocal = h.owner.Owner(key.String())
+	if ds, ok := h.deciders.State(key); ok {
+		state.Decider = ds
+	}
+	if cs, ok := h.collections.State(key); ok {
+		state.Collection = &CollectionState{CollectionState: cs}
+		if cs.LastError != nil {
+			state.Collection.LastError = cs.LastError.Error()
+		}
+	}
+	if state.Decider == nil && state.Collection == nil && state.IsLocal {
+		http.Error(w, "revision "+key.String()+" is not known", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(state); err != nil {
+		h.logger.Errorw("Failed to write the state of "+key.String(), zap.Error(err))
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/autoscaler/aggregation"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
+)
+
+const (
+	testBucket = "autoscaler-bucket-00-of-01"
+	testHolder = "autoscaler-0_10.0.0.1"
+)
+
+var testKey = types.NamespacedName{Namespace: "ns", Name: "rev"}
+
+type fakeDeciders map[types.NamespacedName]*scaling.DeciderState
+
+func (f fakeDeciders) State(key types.NamespacedName) (*scaling.DeciderState, bool) {
+	s, ok := f[key]
+	return s, ok
+}
+
+type fakeCollections map[types.NamespacedName]*metrics.CollectionState
+
+func (f fakeCollections) State(key types.NamespacedName) (*metrics.CollectionState, bool) {
+	s, ok := f[key]
+	return s, ok
+}
+
+type fakeOwner struct {
+	local bool
+}
+
+func (f fakeOwner) Owner(string) (string, string, bool) {
+	return testBucket, testHolder, f.local
+}
+
+type fakeAuthorizer struct {
+	err error
+}
+
+func (f fakeAuthorizer) Authorize(*http.Request) error {
+	return f.err
+}
+
+func TestHandler(t *testing.T) {
+	panicTime := time.Unix(1600000000, 0).UTC()
+	deciders := fakeDeciders{
+		testKey: {
+			Decider: &scaling.Decider{
+				Spec: scaling.DeciderSpec{
+					Algorithm:   "stable-panic",
+					TargetValue: 10,
+				},
+				Status: scaling.DeciderStatus{
+					DesiredScale: 3,
+				},
+			},
+			PanicTime: panicTime,
+		},
+	}
+	collections := fakeCollections{
+		testKey: {
+			ConcurrencyBuckets: aggregation.TimedFloat64BucketsState{
+				Buckets:     []float64{1, 2, 3},
+				WindowTotal: 6,
+			},
+			LastError: errors.New("scrape failed"),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		authErr    error
+		local      bool
+		wantStatus int
+		want       *RevisionState
+	}{{
+		name:       "local revision",
+		path:       Path + "ns/rev",
+		local:      true,
+		wantStatus: http.StatusOK,
+		want: &RevisionState{
+			Key:          testKey.String(),
+			Bucket:       testBucket,
+			BucketHolder: testHolder,
+			IsLocal:      true,
+			Decider:      deciders[testKey],
+			Collection: &CollectionState{
+				CollectionState: collections[testKey],
+				LastError:       "scrape failed",
+			},
+		},
+	}, {
+		name:       "unknown local revision",
+		path:       Path + "ns/other",
+		local:      true,
+		wantStatus: http.StatusNotFound,
+	}, {
+		name:       "unknown revision owned by another pod",
+		path:       Path + "ns/other",
+		wantStatus: http.StatusOK,
+		want: &RevisionState{
+			Key:          "ns/other",
+			Bucket:       testBucket,
+			BucketHolder: testHolder,
+		},
+	}, {
+		name:       "bad path",
+		path:       Path + "ns",
+		wantStatus: http.StatusBadRequest,
+	}, {
+		name:       "bad method",
+		method:     http.MethodPost,
+		path:       Path + "ns/rev",
+		wantStatus: http.StatusMethodNotAllowed,
+	}, {
+		name:       "unauthenticated",
+		path:       Path + "ns/rev",
+		authErr:    fmt.Errorf("%w: missing bearer token", ErrUnauthenticated),
+		wantStatus: http.StatusUnauthorized,
+	}, {
+		name:       "forbidden",
+		path:       Path + "ns/rev",
+		authErr:    ErrForbidden,
+		wantStatus: http.StatusForbidden,
+	}, {
+		name:       "authorization failure",
+		path:       Path + "ns/rev",
+		authErr:    errors.New("apiserver unavailable"),
+		wantStatus: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewHandler(TestLogger(t), fakeAuthorizer{err: test.authErr}, deciders, collections,
+				fakeOwner{local: test.local})
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, httptest.NewRequest(method, "http://autoscaler"+test.path, nil))
+
+			if resp.Code != test.wantStatus {
+				t.Fatalf("StatusCode = %d, want: %d, body: %s", resp.Code, test.wantStatus, resp.Body)
+			}
+			if test.want == nil {
+				return
+			}
+			// Round trip the expectation, to compare it with the response.
+			b, err := json.Marshal(test.want)
+			if err != nil {
+				t.Fatal("Failed to marshal the expected state:", err)
+			}
+			var want, got map[string]interface{}
+			if err := json.Unmarshal(b, &want); err != nil {
+				t.Fatal("Failed to unmarshal the expected state:", err)
+			}
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatal("Failed to unmarshal the response:", err)
+			}
+			if !cmp.Equal(got, want) {
+				t.Error("State mismatch (-want,+got):", cmp.Diff(want, got))
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
@@ -34,6 +35,11 @@ const (
 	// scrapeTickInterval is the interval of time between triggering StatsScraper.Scrape()
 	// to get metrics across all pods of a revision.
 	scrapeTickInterval = time.Second
+
+	// pushTimeout is how long after the last stat pushed by one of its pods
+	// the pods of a revision are deemed to push their stats. Scraping resumes
+	// past it.
+	pushTimeout = 3 * scrapeTickInterval
 )
 
 var (
@@ -49,6 +55,11 @@ type StatsScraperFactory func(*av1alpha1.Metric, *zap.SugaredLogger) (StatsScrap
 
 var emptyStat = Stat{}
 
+// isEmptyStat returns true if the stat holds no data.
+func isEmptyStat(stat *Stat) bool {
+	return proto.Equal(stat, &emptyStat)
+}
+
 // StatMessage wraps a Stat with identifying information so it can be routed
 // to the correct receiver.
 type StatMessage struct {
@@ -79,6 +90,40 @@ type MetricClient interface {
 	// StableAndPanicRPS returns both the stable and the panic RPS
 	// for the given replica as of the given time.
 	StableAndPanicRPS(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicCustomMetric returns both the stable and the panic value
+	// of the application-reported metric for the given replica as of the given time.
+	StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicCPU returns both the stable and the panic CPU utilization
+	// in percent for the given replica as of the given time.
+	StableAndPanicCPU(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicMemory returns both the stable and the panic memory utilization
+	// in percent for the given replica as of the given time.
+	StableAndPanicMemory(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// StableAndPanicLatency returns both the stable and the panic percentile of
+	// the request latencies in milliseconds for the given replica as of the given time.
+	StableAndPanicLatency(key types.NamespacedName, now time.Time, percentile float64) (float64, float64, error)
+
+	// StableAndPanicQueueDepth returns both the stable and the panic number
+	// of requests waiting for a free slot within the concurrency limit
+	StableAndPanicQueueDepth(key types.NamespacedName, now time.Time) (float64, float64, error)
+
+	// LastRequestTime returns the last time a stat of the given replica showed
+	// any requests, or the zero time if none did yet.
+	LastRequestTime(key types.NamespacedName) (time.Time, error)
+}
+
+// PodMetricClient surfaces the metrics of the individual pods obtained via
+// the collector.
+type PodMetricClient interface {
+	// PodConcurrency returns the average concurrency by pod name of the pods
+	// of the given replica as of their last pushed stats or, if they don't push
+	// them, as of the last scrape, or false if unknown.
+	// The returned map must not be modified.
+	PodConcurrency(key types.NamespacedName) (map[string]float64, bool)
 }
 
 // MetricCollector manages collection of metrics for many entities.
@@ -90,6 +135,9 @@ type MetricCollector struct {
 
 	collectionsMutex sync.RWMutex
 	collections      map[types.NamespacedName]*collection
+	// restored holds the snapshots restored before their collection
+	// was created.
+	restored map[types.NamespacedName]*CollectionSnapshot
 
 	watcherMutex sync.RWMutex
 	watcher      func(types.NamespacedName)
@@ -97,12 +145,14 @@ type MetricCollector struct {
 
 var _ Collector = (*MetricCollector)(nil)
 var _ MetricClient = (*MetricCollector)(nil)
+var _ PodMetricClient = (*MetricCollector)(nil)
 
 // NewMetricCollector creates a new metric collector.
 func NewMetricCollector(statsScraperFactory StatsScraperFactory, logger *zap.SugaredLogger) *MetricCollector {
 	return &MetricCollector{
 		logger:              logger,
 		collections:         make(map[types.NamespacedName]*collection),
+		restored:            make(map[types.NamespacedName]*CollectionSnapshot),
 		statsScraperFactory: statsScraperFactory,
 		clock:               clock.RealClock{},
 	}
@@ -131,7 +181,12 @@ func (c *MetricCollector) CreateOrUpdate(metric *av1alpha1.Metric) error {
 		return collection.lastError()
 	}
 
-	c.collections[key] = newCollection(metric, scraper, c.clock, c.Inform, logger)
+	collection = newCollection(metric, scraper, c.clock, c.Inform, logger)
+	if snapshot, ok := c.restored[key]; ok {
+		collection.restore(snapshot)
+		delete(c.restored, key)
+	}
+	c.collections[key] = collection
 	return nil
 }
 
@@ -145,6 +200,7 @@ func (c *MetricCollector) Delete(namespace, name string) {
 		collection.close()
 		delete(c.collections, key)
 	}
+	delete(c.restored, key)
 }
 
 // Record records a stat that's been generated outside of the metric collector.
@@ -153,6 +209,9 @@ func (c *MetricCollector) Record(key types.NamespacedName, now time.Time, stat S
 	defer c.collectionsMutex.RUnlock()
 
 	if collection, exists := c.collections[key]; exists {
+		if stat.PushedByPod {
+			collection.recordPush(c.clock.Now(), stat)
+		}
 		collection.record(now, stat)
 	}
 }
@@ -216,6 +275,224 @@ func (c *MetricCollector) StableAndPanicRPS(key types.NamespacedName, now time.T
 		nil
 }
 
+// StableAndPanicCustomMetric returns both the stable and the panic value of
+// the application-reported metric.
+// It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(key, now, func(c *collection) (*aggregation.TimedFloat64Buckets, *aggregation.TimedFloat64Buckets) {
+		return c.customMetricBuckets, c.customMetricPanicBuckets
+	})
+}
+
+// StableAndPanicCPU returns both the stable and the panic CPU utilization.
+// It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicCPU(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(key, now, func(c *collection) (*aggregation.TimedFloat64Buckets, *aggregation.TimedFloat64Buckets) {
+		return c.cpuBuckets, c.cpuPanicBuckets
+	})
+}
+
+// StableAndPanicMemory returns both the stable and the panic memory utilization.
+// It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicMemory(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(key, now, func(c *collection) (*aggregation.TimedFloat64Buckets, *aggregation.TimedFloat64Buckets) {
+		return c.memoryBuckets, c.memoryPanicBuckets
+	})
+}
+
+// StableAndPanicQueueDepth returns both the stable and the panic number of
+// requests waiting for a free slot within the concurrency limit.
+// It may truncate metric buckets as a side-effect.
+func (c *MetricCollector) StableAndPanicQueueDepth(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	return c.stableAndPanic(key, now, func(c *collection) (*aggregation.TimedFloat64Buckets, *aggregation.TimedFloat64Buckets) {
+		return c.queueBuckets, c.queuePanicBuckets
+	})
+}
+
+// LastRequestTime returns the time of the last recorded stat showing
+// any requests.
+func (c *MetricCollector) LastRequestTime(key types.NamespacedName) (time.Time, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return time.Time{}, ErrNotCollecting
+	}
+	return collection.lastRequestTime(), nil
+}
+
+// StableAndPanicLatency returns both the stable and the panic percentile of
+// the request latencies in milliseconds.
+func (c *MetricCollector) StableAndPanicLatency(key types.NamespacedName, now time.Time, percentile float64) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, ErrNotCollecting
+	}
+
+	if collection.latencyHistogram.IsEmpty(now) && collection.currentMetric().Spec.ScrapeTarget != "" {
+		return 0, 0, ErrNoData
+	}
+	return collection.latencyHistogram.Quantile(now, percentile/100),
+		collection.latencyPanicHistogram.Quantile(now, percentile/100),
+		nil
+}
+
+// stableAndPanic returns the window averages of the stable and panic buckets
+// picked from the collection for the given key.
+func (c *MetricCollector) stableAndPanic(key types.NamespacedName, now time.Time,
+	pick func(*collection) (*aggregation.TimedFloat64Buckets, *aggregation.TimedFloat64Buckets)) (float64, float64, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return 0, 0, ErrNotCollecting
+	}
+
+	stable, panic := pick(collection)
+	if stable.IsEmpty(now) && collection.currentMetric().Spec.ScrapeTarget != "" {
+		return 0, 0, ErrNoData
+	}
+	return stable.WindowAverage(now), panic.WindowAverage(now), nil
+}
+
+// PodConcurrency implements PodMetricClient.
+func (c *MetricCollector) PodConcurrency(key types.NamespacedName) (map[string]float64, bool) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return nil, false
+	}
+	if pc := collection.pushedConcurrency(c.clock.Now()); pc != nil {
+		return pc, true
+	}
+	pcs, ok := collection.getScraper().(PodConcurrencyScraper)
+	if !ok {
+		return nil, false
+	}
+	pc := pcs.PodConcurrency()
+	return pc, pc != nil
+}
+
+// CollectionState is a point in time copy of the state of the metric
+// collection for one entity, intended for debugging.
+type CollectionState struct {
+	// Metric is the Metric the collection was configured with.
+	Metric *av1alpha1.Metric
+
+	ConcurrencyBuckets      aggregation.TimedFloat64BucketsState
+	ConcurrencyPanicBuckets aggregation.TimedFloat64BucketsState
+	RPSBuckets              aggregation.TimedFloat64BucketsState
+	RPSPanicBuckets         aggregation.TimedFloat64BucketsState
+
+	CustomMetricBuckets      aggregation.TimedFloat64BucketsState
+	CustomMetricPanicBuckets aggregation.TimedFloat64BucketsState
+
+	CPUBuckets         aggregation.TimedFloat64BucketsState
+	CPUPanicBuckets    aggregation.TimedFloat64BucketsState
+	MemoryBuckets      aggregation.TimedFloat64BucketsState
+	MemoryPanicBuckets aggregation.TimedFloat64BucketsState
+
+	QueueBuckets      aggregation.TimedFloat64BucketsState
+	QueuePanicBuckets aggregation.TimedFloat64BucketsState
+
+	// LastRequest is the last time a recorded stat showed any requests.
+	LastRequest time.Time
+
+	// LastError is the last error the scraper returned, if any.
+	LastError error
+}
+
+// State returns the current state of the collection for the given key
+// or false if the key is not being collected.
+func (c *MetricCollector) State(key types.NamespacedName) (*CollectionState, bool) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return nil, false
+	}
+	return &CollectionState{
+		Metric:                  collection.currentMetric().DeepCopy(),
+		ConcurrencyBuckets:      collection.concurrencyBuckets.State(),
+		ConcurrencyPanicBuckets: collection.concurrencyPanicBuckets.State(),
+		RPSBuckets:              collection.rpsBuckets.State(),
+		RPSPanicBuckets:         collection.rpsPanicBuckets.State(),
+
+		CustomMetricBuckets:      collection.customMetricBuckets.State(),
+		CustomMetricPanicBuckets: collection.customMetricPanicBuckets.State(),
+
+		CPUBuckets:         collection.cpuBuckets.State(),
+		CPUPanicBuckets:    collection.cpuPanicBuckets.State(),
+		MemoryBuckets:      collection.memoryBuckets.State(),
+		MemoryPanicBuckets: collection.memoryPanicBuckets.State(),
+
+		QueueBuckets:      collection.queueBuckets.State(),
+		QueuePanicBuckets: collection.queuePanicBuckets.State(),
+
+		LastRequest: collection.lastRequestTime(),
+
+		LastError: collection.lastError(),
+	}, true
+}
+
+// CollectionSnapshot is the data of the metric collection for one entity
+// needed to resume it elsewhere. Windows without any data are omitted.
+type CollectionSnapshot struct {
+	// Windows are the states of the metric windows by their name.
+	Windows map[string]aggregation.TimedFloat64BucketsState `json:"windows,omitempty"`
+	// Histograms are the states of the histogram windows by their name.
+	Histograms map[string][]aggregation.TimedFloat64BucketsState `json:"histograms,omitempty"`
+	// LastRequest is the last time a recorded stat showed any requests.
+	LastRequest time.Time `json:"lastRequest,omitempty"`
+}
+
+// Keys returns the keys of the entities being collected.
+func (c *MetricCollector) Keys() []types.NamespacedName {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	ret := make([]types.NamespacedName, 0, len(c.collections))
+	for key := range c.collections {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+// Snapshot returns the snapshot of the collection for the given key
+// or false if the key is not being collected.
+func (c *MetricCollector) Snapshot(key types.NamespacedName) (*CollectionSnapshot, bool) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return nil, false
+	}
+	return collection.snapshot(), true
+}
+
+// Restore restores the given snapshot into the collection for the given key.
+// If the key is not being collected yet, the snapshot is restored when its
+// collection is created.
+func (c *MetricCollector) Restore(key types.NamespacedName, snapshot *CollectionSnapshot) {
+	c.collectionsMutex.Lock()
+	defer c.collectionsMutex.Unlock()
+
+	if collection, exists := c.collections[key]; exists {
+		collection.restore(snapshot)
+		return
+	}
+	c.restored[key] = snapshot
+}
+
 // collection represents the collection of metrics for one specific entity.
 type collection struct {
 	// mux guards access to all of the collection's state.
@@ -224,10 +501,30 @@ type collection struct {
 	metric *av1alpha1.Metric
 
 	// Fields relevant to metric collection in general.
-	concurrencyBuckets      *aggregation.TimedFloat64Buckets
-	concurrencyPanicBuckets *aggregation.TimedFloat64Buckets
-	rpsBuckets              *aggregation.TimedFloat64Buckets
-	rpsPanicBuckets         *aggregation.TimedFloat64Buckets
+	concurrencyBuckets      *aggregation.WeightedFloat64Buckets
+	concurrencyPanicBuckets *aggregation.WeightedFloat64Buckets
+	rpsBuckets              *aggregation.WeightedFloat64Buckets
+	rpsPanicBuckets         *aggregation.WeightedFloat64Buckets
+
+	customMetricBuckets      *aggregation.TimedFloat64Buckets
+	customMetricPanicBuckets *aggregation.TimedFloat64Buckets
+
+	cpuBuckets         *aggregation.TimedFloat64Buckets
+	cpuPanicBuckets    *aggregation.TimedFloat64Buckets
+	memoryBuckets      *aggregation.TimedFloat64Buckets
+	memoryPanicBuckets *aggregation.TimedFloat64Buckets
+
+	queueBuckets      *aggregation.TimedFloat64Buckets
+	queuePanicBuckets *aggregation.TimedFloat64Buckets
+
+	latencyHistogram      *aggregation.TimedHistogram
+	latencyPanicHistogram *aggregation.TimedHistogram
+
+	// pushes holds the last stat pushed by each of the pods, by pod name.
+	pushes map[string]pushedStat
+
+	// lastRequest is the last time a recorded stat showed any requests.
+	lastRequest time.Time
 
 	// Fields relevant for metric scraping specifically.
 	scraper StatsScraper
@@ -236,6 +533,12 @@ type collection struct {
 	stopCh  chan struct{}
 }
 
+// pushedStat is the part of a stat pushed by a pod the collection keeps track of.
+type pushedStat struct {
+	time        time.Time
+	concurrency float64
+}
+
 func (c *collection) updateScraper(ss StatsScraper) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
@@ -254,14 +557,34 @@ func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, clock clock.C
 	callback func(types.NamespacedName), logger *zap.SugaredLogger) *collection {
 	c := &collection{
 		metric: metric,
-		concurrencyBuckets: aggregation.NewTimedFloat64Buckets(
+		concurrencyBuckets: aggregation.NewWeightedFloat64Buckets(
+			metric.Spec.StableWindow, config.BucketSize, metric.Spec.WindowHalfLife),
+		concurrencyPanicBuckets: aggregation.NewWeightedFloat64Buckets(
+			metric.Spec.PanicWindow, config.BucketSize, metric.Spec.WindowHalfLife),
+		rpsBuckets: aggregation.NewWeightedFloat64Buckets(
+			metric.Spec.StableWindow, config.BucketSize, metric.Spec.WindowHalfLife),
+		rpsPanicBuckets: aggregation.NewWeightedFloat64Buckets(
+			metric.Spec.PanicWindow, config.BucketSize, metric.Spec.WindowHalfLife),
+		customMetricBuckets: aggregation.NewTimedFloat64Buckets(
+			metric.Spec.StableWindow, config.BucketSize),
+		customMetricPanicBuckets: aggregation.NewTimedFloat64Buckets(
+			metric.Spec.PanicWindow, config.BucketSize),
+		cpuBuckets: aggregation.NewTimedFloat64Buckets(
+			metric.Spec.StableWindow, config.BucketSize),
+		cpuPanicBuckets: aggregation.NewTimedFloat64Buckets(
+			metric.Spec.PanicWindow, config.BucketSize),
+		memoryBuckets: aggregation.NewTimedFloat64Buckets(
 			metric.Spec.StableWindow, config.BucketSize),
-		concurrencyPanicBuckets: aggregation.NewTimedFloat64Buckets(
+		memoryPanicBuckets: aggregation.NewTimedFloat64Buckets(
 			metric.Spec.PanicWindow, config.BucketSize),
-		rpsBuckets: aggregation.NewTimedFloat64Buckets(
+		queueBuckets: aggregation.NewTimedFloat64Buckets(
 			metric.Spec.StableWindow, config.BucketSize),
-		rpsPanicBuckets: aggregation.NewTimedFloat64Buckets(
+		queuePanicBuckets: aggregation.NewTimedFloat64Buckets(
 			metric.Spec.PanicWindow, config.BucketSize),
+		latencyHistogram: aggregation.NewTimedHistogram(
+			metric.Spec.StableWindow, config.BucketSize, LatencyBucketBounds),
+		latencyPanicHistogram: aggregation.NewTimedHistogram(
+			metric.Spec.PanicWindow, config.BucketSize, LatencyBucketBounds),
 		scraper: scraper,
 
 		stopCh: make(chan struct{}),
@@ -282,8 +605,8 @@ func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, clock clock.C
 				return
 			case <-scrapeTicker.C():
 				scraper := c.getScraper()
-				if scraper == nil {
-					// Don't scrape empty target service.
+				if scraper == nil || c.pushed(clock.Now()) {
+					// Don't scrape empty target service, nor pods pushing their stats.
 					if c.updateLastError(nil) {
 						callback(key)
 					}
@@ -297,7 +620,7 @@ func newCollection(metric *av1alpha1.Metric, scraper StatsScraper, clock clock.C
 				if c.updateLastError(err) {
 					callback(key)
 				}
-				if stat != emptyStat {
+				if !isEmptyStat(&stat) {
 					c.record(clock.Now(), stat)
 				}
 			}
@@ -323,6 +646,20 @@ func (c *collection) updateMetric(metric *av1alpha1.Metric) {
 	c.concurrencyPanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
 	c.rpsBuckets.ResizeWindow(metric.Spec.StableWindow)
 	c.rpsPanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
+	for _, b := range []*aggregation.WeightedFloat64Buckets{c.concurrencyBuckets,
+		c.concurrencyPanicBuckets, c.rpsBuckets, c.rpsPanicBuckets} {
+		b.SetHalfLife(metric.Spec.WindowHalfLife)
+	}
+	c.customMetricBuckets.ResizeWindow(metric.Spec.StableWindow)
+	c.customMetricPanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
+	c.cpuBuckets.ResizeWindow(metric.Spec.StableWindow)
+	c.cpuPanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
+	c.memoryBuckets.ResizeWindow(metric.Spec.StableWindow)
+	c.memoryPanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
+	c.queueBuckets.ResizeWindow(metric.Spec.StableWindow)
+	c.queuePanicBuckets.ResizeWindow(metric.Spec.PanicWindow)
+	c.latencyHistogram.ResizeWindow(metric.Spec.StableWindow)
+	c.latencyPanicHistogram.ResizeWindow(metric.Spec.PanicWindow)
 }
 
 // currentMetric safely returns the current metric stored in the collection.
@@ -353,6 +690,146 @@ func (c *collection) lastError() error {
 	return c.lastErr
 }
 
+// updateLastRequest moves the time of the last request forward to the given time.
+func (c *collection) updateLastRequest(now time.Time) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if now.After(c.lastRequest) {
+		c.lastRequest = now
+	}
+}
+
+func (c *collection) lastRequestTime() time.Time {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.lastRequest
+}
+
+// recordPush keeps track of a stat pushed by a pod at the given time.
+func (c *collection) recordPush(now time.Time, stat Stat) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.pushes == nil {
+		c.pushes = make(map[string]pushedStat, 1)
+	}
+	c.pushes[stat.PodName] = pushedStat{
+		time:        now,
+		concurrency: stat.AverageConcurrentRequests,
+	}
+}
+
+// pushed returns true if any of the pods pushed a stat within pushTimeout
+// of the given time, forgetting the pods that stopped pushing. While they do,
+// the pods are not scraped, so during a rollout enabling the pushes the pods
+// that don't push yet are not accounted for.
+func (c *collection) pushed(now time.Time) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for pod, p := range c.pushes {
+		if now.Sub(p.time) >= pushTimeout {
+			delete(c.pushes, pod)
+		}
+	}
+	return len(c.pushes) > 0
+}
+
+// pushedConcurrency returns the average concurrency by pod name of the pods
+// that pushed a stat within pushTimeout of the given time, or nil if none did.
+func (c *collection) pushedConcurrency(now time.Time) map[string]float64 {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	var ret map[string]float64
+	for pod, p := range c.pushes {
+		if now.Sub(p.time) < pushTimeout {
+			if ret == nil {
+				ret = make(map[string]float64, len(c.pushes))
+			}
+			ret[pod] = p.concurrency
+		}
+	}
+	return ret
+}
+
+// windows returns the metric windows of the collection by their name.
+func (c *collection) windows() map[string]*aggregation.TimedFloat64Buckets {
+	return map[string]*aggregation.TimedFloat64Buckets{
+		"concurrency":       c.concurrencyBuckets.TimedFloat64Buckets,
+		"concurrency-panic": c.concurrencyPanicBuckets.TimedFloat64Buckets,
+		"rps":               c.rpsBuckets.TimedFloat64Buckets,
+		"rps-panic":         c.rpsPanicBuckets.TimedFloat64Buckets,
+		"custom":            c.customMetricBuckets,
+		"custom-panic":      c.customMetricPanicBuckets,
+		"cpu":               c.cpuBuckets,
+		"cpu-panic":         c.cpuPanicBuckets,
+		"memory":            c.memoryBuckets,
+		"memory-panic":      c.memoryPanicBuckets,
+		"queue":             c.queueBuckets,
+		"queue-panic":       c.queuePanicBuckets,
+	}
+}
+
+// histograms returns the histogram windows of the collection by their name.
+func (c *collection) histograms() map[string]*aggregation.TimedHistogram {
+	return map[string]*aggregation.TimedHistogram{
+		"latency":       c.latencyHistogram,
+		"latency-panic": c.latencyPanicHistogram,
+	}
+}
+
+func (c *collection) snapshot() *CollectionSnapshot {
+	ret := &CollectionSnapshot{
+		Windows:     make(map[string]aggregation.TimedFloat64BucketsState),
+		Histograms:  make(map[string][]aggregation.TimedFloat64BucketsState),
+		LastRequest: c.lastRequestTime(),
+	}
+	for name, w := range c.windows() {
+		if s := w.State(); hasData(s) {
+			ret.Windows[name] = s
+		}
+	}
+	for name, h := range c.histograms() {
+		states := h.State()
+		for _, s := range states {
+			if hasData(s) {
+				ret.Histograms[name] = states
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func (c *collection) restore(snapshot *CollectionSnapshot) {
+	for name, w := range c.windows() {
+		if s, ok := snapshot.Windows[name]; ok {
+			w.Restore(s)
+		}
+	}
+	for name, h := range c.histograms() {
+		if s, ok := snapshot.Histograms[name]; ok {
+			h.Restore(s)
+		}
+	}
+	c.updateLastRequest(snapshot.LastRequest)
+}
+
+// hasData returns true if any non-zero value was recorded in the buckets.
+// The windows holding only zeros are left out to keep the snapshots compact,
+// most of them are of the metrics the revision is not scaled on.
+func hasData(s aggregation.TimedFloat64BucketsState) bool {
+	for _, v := range s.Buckets {
+		if v != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // record adds a stat to the current collection.
 func (c *collection) record(now time.Time, stat Stat) {
 	// Proxied requests have been counted at the activator. Subtract
@@ -363,6 +840,21 @@ func (c *collection) record(now time.Time, stat Stat) {
 	rps := stat.RequestCount - stat.ProxiedRequestCount
 	c.rpsBuckets.Record(now, rps)
 	c.rpsPanicBuckets.Record(now, rps)
+	c.customMetricBuckets.Record(now, stat.CustomMetric)
+	c.customMetricPanicBuckets.Record(now, stat.CustomMetric)
+	c.cpuBuckets.Record(now, stat.CpuUtilization)
+	c.cpuPanicBuckets.Record(now, stat.CpuUtilization)
+	c.memoryBuckets.Record(now, stat.MemoryUtilization)
+	c.memoryPanicBuckets.Record(now, stat.MemoryUtilization)
+	c.queueBuckets.Record(now, stat.AverageQueuedRequests)
+	c.queuePanicBuckets.Record(now, stat.AverageQueuedRequests)
+	c.latencyHistogram.Record(now, stat.LatencyHistogram)
+	c.latencyPanicHistogram.Record(now, stat.LatencyHistogram)
+	// The activator reports the requests it holds, so this also covers the
+	// revisions scaled to zero.
+	if stat.RequestCount > 0 || stat.AverageConcurrentRequests > 0 {
+		c.updateLastRequest(now)
+	}
 }
 
 // add adds the stats from `src` to `dst`.
@@ -371,6 +863,16 @@ func (dst *Stat) add(src Stat) {
 	dst.AverageProxiedConcurrentRequests += src.AverageProxiedConcurrentRequests
 	dst.RequestCount += src.RequestCount
 	dst.ProxiedRequestCount += src.ProxiedRequestCount
+	dst.CustomMetric += src.CustomMetric
+	dst.CpuUtilization += src.CpuUtilization
+	dst.MemoryUtilization += src.MemoryUtilization
+	dst.AverageQueuedRequests += src.AverageQueuedRequests
+	for i, v := range src.LatencyHistogram {
+		if i == len(dst.LatencyHistogram) {
+			dst.LatencyHistogram = append(dst.LatencyHistogram, 0)
+		}
+		dst.LatencyHistogram[i] += v
+	}
 }
 
 // average reduces the aggregate stat from `sample` pods to an averaged one over
@@ -389,4 +891,11 @@ func (dst *Stat) average(sample, total float64) {
 	dst.AverageProxiedConcurrentRequests = dst.AverageProxiedConcurrentRequests / sample * total
 	dst.RequestCount = dst.RequestCount / sample * total
 	dst.ProxiedRequestCount = dst.ProxiedRequestCount / sample * total
+	dst.CustomMetric = dst.CustomMetric / sample * total
+	dst.CpuUtilization = dst.CpuUtilization / sample * total
+	dst.MemoryUtilization = dst.MemoryUtilization / sample * total
+	dst.AverageQueuedRequests = dst.AverageQueuedRequests / sample * total
+	for i := range dst.LatencyHistogram {
+		dst.LatencyHistogram[i] = dst.LatencyHistogram[i] / sample * total
+	}
 }
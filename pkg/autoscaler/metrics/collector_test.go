@@ -18,7 +18,9 @@ package metrics
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -469,6 +471,328 @@ func TestMetricCollectorRecord(t *testing.T) {
 	}
 }
 
+func TestMetricCollectorRecordCustomMetric(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+
+	// Without a scraper only the recorded stats count.
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+	coll.CreateOrUpdate(&m)
+	for i, v := range []float64{3, 5} {
+		coll.Record(metricKey, now, Stat{
+			PodName:      fmt.Sprint("pod-", i),
+			CustomMetric: v,
+		})
+	}
+
+	stable, panic, err := coll.StableAndPanicCustomMetric(metricKey, now)
+	if err != nil {
+		t.Fatal("StableAndPanicCustomMetric:", err)
+	}
+	// The values of the pods are summed up in the bucket.
+	if stable != 8 || panic != 8 {
+		t.Errorf("StableAndPanicCustomMetric() = %v, %v; want 8, 8", stable, panic)
+	}
+
+	coll.Delete(defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicCustomMetric(metricKey, now); !errors.Is(err, ErrNotCollecting) {
+		t.Errorf("StableAndPanicCustomMetric() = %v, want %v", err, ErrNotCollecting)
+	}
+}
+
+func TestMetricCollectorRecordResourceUtilization(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+	coll.CreateOrUpdate(&m)
+	for i, v := range []float64{40, 90} {
+		coll.Record(metricKey, now, Stat{
+			PodName:           fmt.Sprint("pod-", i),
+			CpuUtilization:    v,
+			MemoryUtilization: v / 2,
+		})
+	}
+
+	stable, panic, err := coll.StableAndPanicCPU(metricKey, now)
+	if err != nil {
+		t.Fatal("StableAndPanicCPU:", err)
+	}
+	if stable != 130 || panic != 130 {
+		t.Errorf("StableAndPanicCPU() = %v, %v; want 130, 130", stable, panic)
+	}
+	stable, panic, err = coll.StableAndPanicMemory(metricKey, now)
+	if err != nil {
+		t.Fatal("StableAndPanicMemory:", err)
+	}
+	if stable != 65 || panic != 65 {
+		t.Errorf("StableAndPanicMemory() = %v, %v; want 65, 65", stable, panic)
+	}
+
+	coll.Delete(defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicCPU(metricKey, now); !errors.Is(err, ErrNotCollecting) {
+		t.Errorf("StableAndPanicCPU() = %v, want %v", err, ErrNotCollecting)
+	}
+}
+
+func TestMetricCollectorRecordQueueDepth(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+	coll.CreateOrUpdate(&m)
+	for i, v := range []float64{2, 3} {
+		coll.Record(metricKey, now, Stat{
+			PodName:               fmt.Sprint("pod-", i),
+			AverageQueuedRequests: v,
+			AverageQueueWait:      100,
+		})
+	}
+
+	stable, panic, err := coll.StableAndPanicQueueDepth(metricKey, now)
+	if err != nil {
+		t.Fatal("StableAndPanicQueueDepth:", err)
+	}
+	if stable != 5 || panic != 5 {
+		t.Errorf("StableAndPanicQueueDepth() = %v, %v; want 5, 5", stable, panic)
+	}
+
+	coll.Delete(defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicQueueDepth(metricKey, now); !errors.Is(err, ErrNotCollecting) {
+		t.Errorf("StableAndPanicQueueDepth() = %v, want %v", err, ErrNotCollecting)
+	}
+}
+
+func TestMetricCollectorLastRequestTime(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+	coll.CreateOrUpdate(&m)
+	if got, err := coll.LastRequestTime(metricKey); err != nil || !got.IsZero() {
+		t.Errorf("LastRequestTime() = %v, %v; want zero time", got, err)
+	}
+
+	// The activator reports the requests it holds.
+	coll.Record(metricKey, now, Stat{PodName: "activator", AverageConcurrentRequests: 1})
+	// Stats without requests and older stats don't move the time back.
+	coll.Record(metricKey, now.Add(time.Second), Stat{PodName: "testPod", CpuUtilization: 10})
+	coll.Record(metricKey, now.Add(-time.Second), Stat{PodName: "testPod", RequestCount: 1})
+	if got, err := coll.LastRequestTime(metricKey); err != nil || !got.Equal(now) {
+		t.Errorf("LastRequestTime() = %v, %v; want: %v", got, err, now)
+	}
+
+	later := now.Add(2 * time.Second)
+	coll.Record(metricKey, later, Stat{PodName: "testPod", RequestCount: 1})
+	if got, err := coll.LastRequestTime(metricKey); err != nil || !got.Equal(later) {
+		t.Errorf("LastRequestTime() = %v, %v; want: %v", got, err, later)
+	}
+
+	coll.Delete(defaultNamespace, defaultName)
+	if _, err := coll.LastRequestTime(metricKey); !errors.Is(err, ErrNotCollecting) {
+		t.Errorf("LastRequestTime() = %v, want %v", err, ErrNotCollecting)
+	}
+}
+
+func TestMetricCollectorRecordLatency(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+	coll.CreateOrUpdate(&m)
+	// 10 requests up to 5ms, 10 requests between 5ms and 10ms in the first
+	// pod and 20 requests between 10ms and 20ms in the second one.
+	coll.Record(metricKey, now, Stat{PodName: "pod-1", LatencyHistogram: []float64{10, 10}})
+	coll.Record(metricKey, now, Stat{PodName: "pod-2", LatencyHistogram: []float64{0, 0, 20}})
+
+	stable, panic, err := coll.StableAndPanicLatency(metricKey, now, 75)
+	if err != nil {
+		t.Fatal("StableAndPanicLatency:", err)
+	}
+	if stable != 15 || panic != 15 {
+		t.Errorf("StableAndPanicLatency() = %v, %v; want 15, 15", stable, panic)
+	}
+
+	coll.Delete(defaultNamespace, defaultName)
+	if _, _, err := coll.StableAndPanicLatency(metricKey, now, 75); !errors.Is(err, ErrNotCollecting) {
+		t.Errorf("StableAndPanicLatency() = %v, want %v", err, ErrNotCollecting)
+	}
+}
+
+func TestStatAddAverageLatencyHistogram(t *testing.T) {
+	src := Stat{LatencyHistogram: []float64{1, 2}}
+	var dst Stat
+	dst.add(src)
+	dst.add(Stat{LatencyHistogram: []float64{1, 2, 3}})
+	dst.average(2, 4)
+	if want := []float64{4, 8, 6}; !cmp.Equal(dst.LatencyHistogram, want) {
+		t.Errorf("LatencyHistogram = %v, want: %v", dst.LatencyHistogram, want)
+	}
+	// The source must not have been modified.
+	if want := []float64{1, 2}; !cmp.Equal(src.LatencyHistogram, want) {
+		t.Errorf("Source LatencyHistogram = %v, want: %v", src.LatencyHistogram, want)
+	}
+}
+
+func TestMetricCollectorState(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	scraper := &testScraper{
+		s: func() (Stat, error) {
+			return emptyStat, nil
+		},
+	}
+	coll := NewMetricCollector(scraperFactory(scraper, nil), TestLogger(t))
+	mtp := &fake.ManualTickProvider{
+		Channel: make(chan time.Time),
+	}
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(now),
+		TP:        mtp,
+	}
+
+	if _, ok := coll.State(metricKey); ok {
+		t.Error("State() = true for a key that is not collected")
+	}
+
+	coll.CreateOrUpdate(&defaultMetric)
+	coll.Record(metricKey, now, Stat{
+		PodName:                   "testPod",
+		AverageConcurrentRequests: 3,
+		RequestCount:              5,
+	})
+
+	state, ok := coll.State(metricKey)
+	if !ok {
+		t.Fatal("State() = false, want true")
+	}
+	if !cmp.Equal(state.Metric, &defaultMetric) {
+		t.Error("Metric mismatch (-want,+got):", cmp.Diff(&defaultMetric, state.Metric))
+	}
+	if got, want := state.ConcurrencyBuckets.WindowTotal, 3.; got != want {
+		t.Errorf("ConcurrencyBuckets.WindowTotal = %v, want: %v", got, want)
+	}
+	if got, want := state.ConcurrencyPanicBuckets.Window, defaultMetric.Spec.PanicWindow; got != want {
+		t.Errorf("ConcurrencyPanicBuckets.Window = %v, want: %v", got, want)
+	}
+	if got, want := state.RPSBuckets.WindowTotal, 5.; got != want {
+		t.Errorf("RPSBuckets.WindowTotal = %v, want: %v", got, want)
+	}
+	if state.LastError != nil {
+		t.Error("LastError =", state.LastError)
+	}
+}
+
+func TestMetricCollectorSnapshotRestore(t *testing.T) {
+	now := time.Now()
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	newCollector := func() *MetricCollector {
+		coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+		coll.clock = fake.Clock{
+			FakeClock: clock.NewFakeClock(now),
+			TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+		}
+		return coll
+	}
+	m := defaultMetric
+	m.Spec.ScrapeTarget = ""
+
+	old := newCollector()
+	if _, ok := old.Snapshot(metricKey); ok {
+		t.Error("Snapshot() = true for a key that is not collected")
+	}
+	old.CreateOrUpdate(&m)
+	old.Record(metricKey, now, Stat{
+		PodName:                   "testPod",
+		AverageConcurrentRequests: 3,
+		LatencyHistogram:          []float64{1},
+	})
+	if got, want := old.Keys(), []types.NamespacedName{metricKey}; !cmp.Equal(got, want) {
+		t.Errorf("Keys() = %v, want: %v", got, want)
+	}
+	snapshot, ok := old.Snapshot(metricKey)
+	if !ok {
+		t.Fatal("Snapshot() = false, want true")
+	}
+	// Only the windows with data are in the snapshot.
+	for _, name := range []string{"concurrency", "concurrency-panic"} {
+		if _, ok := snapshot.Windows[name]; !ok {
+			t.Errorf("Window %q is missing in the snapshot", name)
+		}
+	}
+	if len(snapshot.Windows) != 2 {
+		t.Errorf("Windows = %v, want only the concurrency ones", snapshot.Windows)
+	}
+	if len(snapshot.Histograms) != 2 {
+		t.Errorf("Histograms = %v, want only the latency ones", snapshot.Histograms)
+	}
+
+	// The snapshot is kept until the collection is created.
+	coll := newCollector()
+	coll.Restore(metricKey, snapshot)
+	coll.CreateOrUpdate(&m)
+	stable, panic, err := coll.StableAndPanicConcurrency(metricKey, now)
+	if err != nil {
+		t.Fatal("StableAndPanicConcurrency:", err)
+	}
+	if stable != 3 || panic != 3 {
+		t.Errorf("StableAndPanicConcurrency() = %v, %v; want 3, 3", stable, panic)
+	}
+	if stable, _, _ := coll.StableAndPanicLatency(metricKey, now, 50); stable != 2.5 {
+		t.Errorf("StableAndPanicLatency() = %v, want 2.5", stable)
+	}
+	if got, _ := coll.LastRequestTime(metricKey); !got.Equal(now) {
+		t.Errorf("LastRequestTime() = %v, want: %v", got, now)
+	}
+
+	// Without a restored state the last request time stays unknown.
+	coll = newCollector()
+	coll.CreateOrUpdate(&m)
+	coll.Restore(metricKey, &CollectionSnapshot{Windows: snapshot.Windows})
+	if got, _ := coll.LastRequestTime(metricKey); !got.IsZero() {
+		t.Errorf("LastRequestTime() = %v, want zero time", got)
+	}
+
+	// Restoring into an existing collection keeps the data recorded there.
+	coll = newCollector()
+	coll.CreateOrUpdate(&m)
+	coll.Record(metricKey, now, Stat{PodName: "testPod", AverageConcurrentRequests: 5})
+	coll.Restore(metricKey, snapshot)
+	if stable, _, _ := coll.StableAndPanicConcurrency(metricKey, now); stable != 5 {
+		t.Errorf("StableAndPanicConcurrency() = %v, want 5", stable)
+	}
+}
+
 func TestDoubleWatch(t *testing.T) {
 	defer func() {
 		if x := recover(); x == nil {
@@ -584,16 +908,157 @@ func (s *testScraper) Scrape(time.Duration) (Stat, error) {
 	return s.s()
 }
 
+type testPodConcurrencyScraper struct {
+	testScraper
+	podConcurrency map[string]float64
+}
+
+func (s *testPodConcurrencyScraper) PodConcurrency() map[string]float64 {
+	return s.podConcurrency
+}
+
+func TestMetricCollectorPodConcurrency(t *testing.T) {
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	want := map[string]float64{"pod-1": 1, "pod-2": 0.5}
+	scraper := &testPodConcurrencyScraper{
+		testScraper: testScraper{
+			s: func() (Stat, error) {
+				return emptyStat, nil
+			},
+		},
+		podConcurrency: want,
+	}
+	coll := NewMetricCollector(scraperFactory(scraper, nil), TestLogger(t))
+	coll.clock = fake.Clock{
+		FakeClock: clock.NewFakeClock(time.Now()),
+		TP:        &fake.ManualTickProvider{Channel: make(chan time.Time)},
+	}
+	t.Cleanup(func() { coll.Delete(defaultNamespace, defaultName) })
+
+	if _, ok := coll.PodConcurrency(metricKey); ok {
+		t.Error("PodConcurrency() = true for a key that is not collected")
+	}
+	coll.CreateOrUpdate(&defaultMetric)
+	if got, ok := coll.PodConcurrency(metricKey); !ok || !cmp.Equal(got, want) {
+		t.Errorf("PodConcurrency() = %v, %v; want %v, true", got, ok, want)
+	}
+
+	// Scrapers not reporting the pods don't have the pod concurrency.
+	scraper.podConcurrency = nil
+	if _, ok := coll.PodConcurrency(metricKey); ok {
+		t.Error("PodConcurrency() = true without pod concurrency")
+	}
+}
+
+func TestMetricCollectorWindowHalfLife(t *testing.T) {
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	coll := NewMetricCollector(scraperFactory(nil, nil), TestLogger(t))
+	coll.CreateOrUpdate(&defaultMetric)
+	t.Cleanup(func() { coll.Delete(defaultNamespace, defaultName) })
+
+	now := time.Now()
+	coll.Record(metricKey, now.Add(-time.Second), Stat{AverageConcurrentRequests: 2, RequestCount: 2})
+	coll.Record(metricKey, now, Stat{AverageConcurrentRequests: 4, RequestCount: 4})
+	if got, _, _ := coll.StableAndPanicConcurrency(metricKey, now); got != 3 {
+		t.Errorf("StableConcurrency() = %v, want: 3", got)
+	}
+
+	// With a half-life of a second, the last value weighs twice the previous one.
+	m := defaultMetric
+	m.Spec.WindowHalfLife = time.Second
+	coll.CreateOrUpdate(&m)
+	const want = 3.333333
+	if got, panic, _ := coll.StableAndPanicConcurrency(metricKey, now); got != want || panic != want {
+		t.Errorf("StableAndPanicConcurrency() = %v, %v; want: %v, %v", got, panic, want, want)
+	}
+	if got, panic, _ := coll.StableAndPanicRPS(metricKey, now); got != want || panic != want {
+		t.Errorf("StableAndPanicRPS() = %v, %v; want: %v, %v", got, panic, want, want)
+	}
+}
+
+func TestMetricCollectorPushedStats(t *testing.T) {
+	metricKey := types.NamespacedName{Namespace: defaultNamespace, Name: defaultName}
+	var scrapes int32
+	scraped := map[string]float64{"pod-1": 1, "pod-2": 2}
+	scraper := &testPodConcurrencyScraper{
+		testScraper: testScraper{
+			s: func() (Stat, error) {
+				atomic.AddInt32(&scrapes, 1)
+				return emptyStat, nil
+			},
+		},
+		podConcurrency: scraped,
+	}
+	mtp := &fake.ManualTickProvider{Channel: make(chan time.Time)}
+	now := time.Now()
+	fc := clock.NewFakeClock(now)
+	coll := NewMetricCollector(scraperFactory(scraper, nil), TestLogger(t))
+	coll.clock = fake.Clock{FakeClock: fc, TP: mtp}
+	coll.CreateOrUpdate(&defaultMetric)
+	t.Cleanup(func() { coll.Delete(defaultNamespace, defaultName) })
+
+	// The second tick returns once the first one has been handled.
+	tick := func() {
+		mtp.Channel <- now
+		mtp.Channel <- now
+	}
+
+	coll.Record(metricKey, now, Stat{
+		PodName:                   "pod-1",
+		AverageConcurrentRequests: 3,
+		PushedByPod:               true,
+	})
+	tick()
+	if got := atomic.LoadInt32(&scrapes); got != 0 {
+		t.Errorf("Scrapes = %d while the pods push their stats, want 0", got)
+	}
+	if got, _, err := coll.StableAndPanicConcurrency(metricKey, now); err != nil || got != 3 {
+		t.Errorf("StableAndPanicConcurrency() = %v, %v; want 3, nil", got, err)
+	}
+	want := map[string]float64{"pod-1": 3}
+	if got, ok := coll.PodConcurrency(metricKey); !ok || !cmp.Equal(got, want) {
+		t.Errorf("PodConcurrency() = %v, %v; want %v, true", got, ok, want)
+	}
+
+	// Activator stats don't stop the scraping.
+	fc.Step(pushTimeout)
+	coll.Record(metricKey, fc.Now(), Stat{
+		PodName:                   "activator",
+		AverageConcurrentRequests: 1,
+	})
+	tick()
+	if got := atomic.LoadInt32(&scrapes); got == 0 {
+		t.Error("Scrapes = 0 once the pods stopped pushing their stats")
+	}
+	if got, ok := coll.PodConcurrency(metricKey); !ok || !cmp.Equal(got, scraped) {
+		t.Errorf("PodConcurrency() = %v, %v; want %v, true", got, ok, scraped)
+	}
+}
+
 func TestMetricCollectorAggregate(t *testing.T) {
 	m := defaultMetric
 	m.Spec.StableWindow = 6 * time.Second
 	m.Spec.PanicWindow = 2 * time.Second
 	c := &collection{
 		metric:                  &m,
-		concurrencyBuckets:      aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
-		concurrencyPanicBuckets: aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
-		rpsBuckets:              aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
-		rpsPanicBuckets:         aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
+		concurrencyBuckets:      aggregation.NewWeightedFloat64Buckets(m.Spec.StableWindow, config.BucketSize, 0),
+		concurrencyPanicBuckets: aggregation.NewWeightedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize, 0),
+		rpsBuckets:              aggregation.NewWeightedFloat64Buckets(m.Spec.StableWindow, config.BucketSize, 0),
+		rpsPanicBuckets:         aggregation.NewWeightedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize, 0),
+
+		customMetricBuckets:      aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
+		customMetricPanicBuckets: aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
+
+		cpuBuckets:         aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
+		cpuPanicBuckets:    aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
+		memoryBuckets:      aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
+		memoryPanicBuckets: aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
+
+		queueBuckets:      aggregation.NewTimedFloat64Buckets(m.Spec.StableWindow, config.BucketSize),
+		queuePanicBuckets: aggregation.NewTimedFloat64Buckets(m.Spec.PanicWindow, config.BucketSize),
+
+		latencyHistogram:      aggregation.NewTimedHistogram(m.Spec.StableWindow, config.BucketSize, LatencyBucketBounds),
+		latencyPanicHistogram: aggregation.NewTimedHistogram(m.Spec.PanicWindow, config.BucketSize, LatencyBucketBounds),
 	}
 	now := time.Now()
 	for i := time.Duration(0); i < 10; i++ {
@@ -601,6 +1066,10 @@ func TestMetricCollectorAggregate(t *testing.T) {
 			PodName:                   "testPod",
 			AverageConcurrentRequests: float64(i + 5),
 			RequestCount:              float64(i + 5),
+			CustomMetric:              float64(2 * (i + 5)),
+			CpuUtilization:            float64(i + 5),
+			MemoryUtilization:         float64(3 * (i + 5)),
+			AverageQueuedRequests:     float64(i + 5),
 		}
 		c.record(now.Add(i*time.Second), stat)
 	}
@@ -615,4 +1084,22 @@ func TestMetricCollectorAggregate(t *testing.T) {
 	if got, want := c.concurrencyPanicBuckets.WindowAverage(now), 13.5; got != want {
 		t.Errorf("Stable Concurrency = %f, want: %f", got, want)
 	}
+	if got, want := c.customMetricBuckets.WindowAverage(now), 23.; got != want {
+		t.Errorf("Stable CustomMetric = %f, want: %f", got, want)
+	}
+	if got, want := c.customMetricPanicBuckets.WindowAverage(now), 27.; got != want {
+		t.Errorf("Panic CustomMetric = %f, want: %f", got, want)
+	}
+	if got, want := c.cpuBuckets.WindowAverage(now), 11.5; got != want {
+		t.Errorf("Stable CPU = %f, want: %f", got, want)
+	}
+	if got, want := c.memoryPanicBuckets.WindowAverage(now), 40.5; got != want {
+		t.Errorf("Panic Memory = %f, want: %f", got, want)
+	}
+	if got, want := c.queueBuckets.WindowAverage(now), 11.5; got != want {
+		t.Errorf("Stable QueueDepth = %f, want: %f", got, want)
+	}
+	if got, want := c.queuePanicBuckets.WindowAverage(now), 13.5; got != want {
+		t.Errorf("Panic QueueDepth = %f, want: %f", got, want)
+	}
 }
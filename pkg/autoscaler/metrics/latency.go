@@ -0,0 +1,24 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+// LatencyBucketBounds are the upper bounds in milliseconds of the buckets of
+// the latency histogram in the Stat. The pods and the autoscaler must agree
+// on them, so they must only ever be appended to.
+var LatencyBucketBounds = []float64{
+	5, 10, 20, 30, 40, 50, 75, 100, 150, 200, 250, 300, 400, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 10000, 30000, 60000}
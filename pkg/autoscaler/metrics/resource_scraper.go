@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// queueContainerName is the name of the queue-proxy container, which the
+// containers of the users can't have.
+const queueContainerName = "queue-proxy"
+
+// podMetricsResource is the resource of the usage of the pods in the resource
+// metrics API, which is usually served by the metrics-server.
+var podMetricsResource = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+// errNoResourceMetrics is returned when the resource metrics API has no
+// metrics for any of the pods of the revision yet.
+var errNoResourceMetrics = errors.New("no resource metrics for the pods of the revision")
+
+// podMetrics is the part of the PodMetrics of the resource metrics API we read.
+type podMetrics struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Containers        []containerMetrics `json:"containers"`
+}
+
+type containerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// resourceScraper adds the utilization of a resource by the user containers,
+// read from the resource metrics API, to the stats scraped by the wrapped
+// StatsScraper. This way the pods don't need access to the cgroups of the node.
+type resourceScraper struct {
+	StatsScraper
+
+	client    dynamic.Interface
+	podLister corev1listers.PodLister
+	namespace string
+	selector  labels.Selector
+	resource  corev1.ResourceName
+	timeout   time.Duration
+}
+
+var _ PodConcurrencyScraper = (*resourceScraper)(nil)
+
+// NewResourceScraper wraps the scraper of the Revision to add the utilization
+// of the resource the given Metric scales on, if it scales on cpu or memory.
+// Otherwise the scraper is returned as is.
+func NewResourceScraper(scraper StatsScraper, metric *av1alpha1.Metric, revisionName string,
+	client dynamic.Interface, podLister corev1listers.PodLister) StatsScraper {
+	var name corev1.ResourceName
+	switch metric.Annotations[autoscaling.MetricAnnotationKey] {
+	case autoscaling.CPU:
+		name = corev1.ResourceCPU
+	case autoscaling.Memory:
+		name = corev1.ResourceMemory
+	default:
+		return scraper
+	}
+
+	timeout := metric.Spec.ScrapeTimeout
+	if timeout <= 0 {
+		timeout = defaultScrapeTimeout
+	}
+	return &resourceScraper{
+		StatsScraper: scraper,
+		client:       client,
+		podLister:    podLister,
+		namespace:    metric.Namespace,
+		selector:     labels.SelectorFromSet(labels.Set{serving.RevisionLabelKey: revisionName}),
+		resource:     name,
+		timeout:      timeout,
+	}
+}
+
+// Scrape implements StatsScraper.
+func (s *resourceScraper) Scrape(window time.Duration) (Stat, error) {
+	stat, err := s.StatsScraper.Scrape(window)
+	// No pods, no usage.
+	if err != nil || isEmptyStat(&stat) {
+		return stat, err
+	}
+	utilization, err := s.utilization()
+	if err != nil {
+		return emptyStat, err
+	}
+	if s.resource == corev1.ResourceCPU {
+		stat.CpuUtilization = utilization
+	} else {
+		stat.MemoryUtilization = utilization
+	}
+	return stat, nil
+}
+
+// PodConcurrency implements PodConcurrencyScraper.
+func (s *resourceScraper) PodConcurrency() map[string]float64 {
+	if pcs, ok := s.StatsScraper.(PodConcurrencyScraper); ok {
+		return pcs.PodConcurrency()
+	}
+	return nil
+}
+
+// utilization returns the usage of the resource by the user containers in
+// percent of their requests, summed over the running pods of the revision.
+// The pods without metrics yet are assumed to be as utilized as the others.
+func (s *resourceScraper) utilization() (float64, error) {
+	pods, err := s.podLister.Pods(s.namespace).List(s.selector)
+	if err != nil {
+		return 0, err
+	}
+	requests := make(map[string]float64, len(pods))
+	for _, p := range pods {
+		if p.Status.Phase != corev1.PodRunning || p.DeletionTimestamp != nil {
+			continue
+		}
+		if r := userContainersRequest(p, s.resource); r > 0 {
+			requests[p.Name] = r
+		}
+	}
+	if len(requests) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	list, err := s.client.Resource(podMetricsResource).Namespace(s.namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: s.selector.String()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the pod metrics: %w", err)
+	}
+
+	var sum float64
+	measured := 0
+	for _, item := range list.Items {
+		request, ok := requests[item.GetName()]
+		if !ok {
+			continue
+		}
+		var pm podMetrics
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pm); err != nil {
+			return 0, fmt.Errorf("failed to parse the metrics of pod %s: %w", item.GetName(), err)
+		}
+		var usage float64
+		for _, c := range pm.Containers {
+			if c.Name != queueContainerName {
+				usage += quantityValue(c.Usage[s.resource], s.resource)
+			}
+		}
+		sum += usage / request * 100
+		measured++
+	}
+	if measured == 0 {
+		return 0, errNoResourceMetrics
+	}
+	return sum / float64(measured) * float64(len(requests)), nil
+}
+
+// userContainersRequest returns the amount of the resource requested by the
+// user containers of the pod. The limit is used if no request is set, as
+// Kubernetes does.
+func userContainersRequest(pod *corev1.Pod, name corev1.ResourceName) float64 {
+	var total float64
+	for _, c := range pod.Spec.Containers {
+		if c.Name == queueContainerName {
+			continue
+		}
+		q, ok := c.Resources.Requests[name]
+		if !ok {
+			q = c.Resources.Limits[name]
+		}
+		total += quantityValue(q, name)
+	}
+	return total
+}
+
+// quantityValue returns the quantity in cores for cpu and in bytes otherwise.
+func quantityValue(q resource.Quantity, name corev1.ResourceName) float64 {
+	if name == corev1.ResourceCPU {
+		return float64(q.MilliValue()) / 1000
+	}
+	return float64(q.Value())
+}
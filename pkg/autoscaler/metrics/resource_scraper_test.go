@@ -0,0 +1,255 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	fakepodsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// resourcePod returns a running pod of the test revision whose user container
+// has the given resources.
+func resourcePod(name string, res corev1.ResourceRequirements) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels:    map[string]string{serving.RevisionLabelKey: testRevision},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:      "user-container",
+				Resources: res,
+			}, {
+				Name: queueContainerName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("25m"),
+						corev1.ResourceMemory: resource.MustParse("50Mi"),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+// podMetricsList returns the resource metrics API list of the usage of the
+// containers by pod name.
+func podMetricsList(usage map[string]map[string]corev1.ResourceList) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetricsList",
+	}}
+	for pod, containers := range usage {
+		pm := podMetrics{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod,
+				Namespace: testNamespace,
+				Labels:    map[string]string{serving.RevisionLabelKey: testRevision},
+			},
+		}
+		for name, u := range containers {
+			pm.Containers = append(pm.Containers, containerMetrics{Name: name, Usage: u})
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&pm)
+		if err != nil {
+			panic(err)
+		}
+		item := unstructured.Unstructured{Object: obj}
+		item.SetAPIVersion("metrics.k8s.io/v1beta1")
+		item.SetKind("PodMetrics")
+		list.Items = append(list.Items, item)
+	}
+	return list
+}
+
+func newResourceScraper(t *testing.T, metric string, pods []*corev1.Pod,
+	list *unstructured.UnstructuredList, scraper StatsScraper) (StatsScraper, *dynamicfake.FakeDynamicClient) {
+	ctx, _ := SetupFakeContext(t)
+	for _, p := range pods {
+		fakepodsinformer.Get(ctx).Informer().GetIndexer().Add(p)
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client.PrependReactor("list", "pods", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if got, want := action.GetResource(), podMetricsResource; got != want {
+			t.Errorf("Listed resource = %v, want: %v", got, want)
+		}
+		if list == nil {
+			return true, nil, errors.New("metrics API unavailable")
+		}
+		return true, list, nil
+	})
+	m := testMetric()
+	m.Annotations = map[string]string{autoscaling.MetricAnnotationKey: metric}
+	return NewResourceScraper(scraper, m, testRevision, client, fakepodsinformer.Get(ctx).Lister()), client
+}
+
+func TestResourceScraperCPU(t *testing.T) {
+	cpuRequest := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+	}
+	terminating := resourcePod("terminating", cpuRequest)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	pods := []*corev1.Pod{
+		resourcePod("pod-0", cpuRequest),
+		resourcePod("pod-1", cpuRequest),
+		// The metrics of the pod aren't available yet.
+		resourcePod("pod-2", cpuRequest),
+		terminating,
+	}
+	list := podMetricsList(map[string]map[string]corev1.ResourceList{
+		// The usage of the queue-proxy doesn't count.
+		"pod-0": {
+			"user-container":   {corev1.ResourceCPU: resource.MustParse("250m")},
+			queueContainerName: {corev1.ResourceCPU: resource.MustParse("100m")},
+		},
+		"pod-1": {
+			"user-container": {corev1.ResourceCPU: resource.MustParse("400m")},
+		},
+		"terminating": {
+			"user-container": {corev1.ResourceCPU: resource.MustParse("5")},
+		},
+	})
+	inner := &testPodConcurrencyScraper{
+		testScraper: testScraper{s: func() (Stat, error) {
+			return Stat{PodName: scraperPodName, AverageConcurrentRequests: 3}, nil
+		}},
+		podConcurrency: map[string]float64{"pod-0": 1},
+	}
+	scraper, client := newResourceScraper(t, autoscaling.CPU, pods, list, inner)
+
+	got, err := scraper.Scrape(time.Minute)
+	if err != nil {
+		t.Fatal("Scrape() =", err)
+	}
+	// 50% and 80%, and the average for the pod without metrics.
+	if want := 195.; got.CpuUtilization != want {
+		t.Errorf("CpuUtilization = %v, want: %v", got.CpuUtilization, want)
+	}
+	if got.MemoryUtilization != 0 || got.AverageConcurrentRequests != 3 {
+		t.Errorf("Scrape() = %#v, want the stat of the wrapped scraper", got)
+	}
+	if pc := scraper.(PodConcurrencyScraper).PodConcurrency(); pc["pod-0"] != 1 {
+		t.Errorf("PodConcurrency() = %v, want the wrapped scraper's", pc)
+	}
+	if got, want := client.Actions()[0].(clientgotesting.ListAction).GetListRestrictions().Labels.String(),
+		serving.RevisionLabelKey+"="+testRevision; got != want {
+		t.Errorf("Label selector = %q, want: %q", got, want)
+	}
+}
+
+func TestResourceScraperMemoryLimit(t *testing.T) {
+	// The limit counts as the request if no request is set.
+	pods := []*corev1.Pod{resourcePod("pod-0", corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+	})}
+	list := podMetricsList(map[string]map[string]corev1.ResourceList{
+		"pod-0": {"user-container": {corev1.ResourceMemory: resource.MustParse("64Mi")}},
+	})
+	scraper, _ := newResourceScraper(t, autoscaling.Memory, pods, list, &testScraper{s: func() (Stat, error) {
+		return Stat{PodName: scraperPodName, AverageConcurrentRequests: 1}, nil
+	}})
+
+	got, err := scraper.Scrape(time.Minute)
+	if err != nil {
+		t.Fatal("Scrape() =", err)
+	}
+	if want := 50.; got.MemoryUtilization != want || got.CpuUtilization != 0 {
+		t.Errorf("Utilization = %v cpu, %v memory, want: 0 cpu, %v memory", got.CpuUtilization, got.MemoryUtilization, want)
+	}
+}
+
+func TestResourceScraperErrors(t *testing.T) {
+	cpuRequest := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+	}
+	stat := func() (Stat, error) {
+		return Stat{PodName: scraperPodName, AverageConcurrentRequests: 1}, nil
+	}
+	tests := []struct {
+		name string
+		list *unstructured.UnstructuredList
+		want error
+	}{{
+		name: "metrics API unavailable",
+	}, {
+		name: "no metrics yet",
+		list: podMetricsList(nil),
+		want: errNoResourceMetrics,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scraper, _ := newResourceScraper(t, autoscaling.CPU, []*corev1.Pod{resourcePod("pod-0", cpuRequest)},
+				test.list, &testScraper{s: stat})
+			_, err := scraper.Scrape(time.Minute)
+			if err == nil {
+				t.Fatal("Scrape() = nil, wanted an error")
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("Scrape() = %v, want: %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestResourceScraperPassThrough(t *testing.T) {
+	inner := &testScraper{s: func() (Stat, error) { return emptyStat, nil }}
+
+	// Only the cpu and memory metrics read the resource metrics API.
+	if scraper, _ := newResourceScraper(t, autoscaling.Concurrency, nil, nil, inner); scraper != inner {
+		t.Errorf("NewResourceScraper() = %T, want the wrapped scraper", scraper)
+	}
+
+	// Without pods there's no usage to read.
+	scraper, client := newResourceScraper(t, autoscaling.CPU, nil, nil, inner)
+	if got, err := scraper.Scrape(time.Minute); err != nil || !isEmptyStat(&got) {
+		t.Errorf("Scrape() = %v, %v, want an empty stat", got, err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("Actions = %v, want none", client.Actions())
+	}
+
+	// Pods without requests can't be utilized.
+	inner.s = func() (Stat, error) { return Stat{PodName: scraperPodName, AverageConcurrentRequests: 1}, nil }
+	scraper, client = newResourceScraper(t, autoscaling.CPU,
+		[]*corev1.Pod{resourcePod("pod-0", corev1.ResourceRequirements{})}, nil, inner)
+	if got, err := scraper.Scrape(time.Minute); err != nil || got.CpuUtilization != 0 {
+		t.Errorf("Scrape() = %v, %v, want no utilization", got, err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("Actions = %v, want none", client.Actions())
+	}
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package metrics
 
-import "math"
+import (
+	"math"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
 
 const (
 	// criticalValueSquared is the square of the critical value of the Normal distribution
@@ -51,3 +55,20 @@ func populationMeanSampleSize(population float64) float64 {
 	}
 	return math.Ceil(population * sampleSize / (population + sampleSize - 1))
 }
+
+// scrapeSampleSize returns the number of pods to scrape out of the population
+// with the given strategy. The population up to fullThreshold is always fully
+// scraped.
+func scrapeSampleSize(strategy string, percentage float64, fullThreshold int32, population float64) float64 {
+	if population <= float64(fullThreshold) {
+		return math.Max(population, 0)
+	}
+	switch strategy {
+	case autoscaling.ScrapeStrategyFull:
+		return population
+	case autoscaling.ScrapeStrategyPercentage:
+		return math.Min(population, math.Max(1, math.Ceil(population*percentage/100)))
+	default:
+		return populationMeanSampleSize(population)
+	}
+}
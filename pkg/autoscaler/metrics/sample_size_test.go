@@ -18,6 +18,8 @@ package metrics
 
 import (
 	"testing"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
 )
 
 func TestPopulationMeanSampleSize(t *testing.T) {
@@ -53,3 +55,66 @@ func TestPopulationMeanSampleSize(t *testing.T) {
 		}
 	}
 }
+
+func TestScrapeSampleSize(t *testing.T) {
+	testCases := []struct {
+		name           string
+		strategy       string
+		percentage     float64
+		fullThreshold  int32
+		popSize        float64
+		wantSampleSize float64
+	}{{
+		name:           "adaptive",
+		strategy:       autoscaling.ScrapeStrategyAdaptive,
+		popSize:        100,
+		wantSampleSize: 14,
+	}, {
+		name:           "no strategy is adaptive",
+		popSize:        100,
+		wantSampleSize: 14,
+	}, {
+		name:           "full",
+		strategy:       autoscaling.ScrapeStrategyFull,
+		popSize:        100,
+		wantSampleSize: 100,
+	}, {
+		name:           "percentage",
+		strategy:       autoscaling.ScrapeStrategyPercentage,
+		percentage:     25,
+		popSize:        10,
+		wantSampleSize: 3,
+	}, {
+		name:           "percentage of at least one pod",
+		strategy:       autoscaling.ScrapeStrategyPercentage,
+		percentage:     1,
+		popSize:        10,
+		wantSampleSize: 1,
+	}, {
+		name:           "under the full threshold",
+		strategy:       autoscaling.ScrapeStrategyPercentage,
+		percentage:     10,
+		fullThreshold:  5,
+		popSize:        5,
+		wantSampleSize: 5,
+	}, {
+		name:           "over the full threshold",
+		strategy:       autoscaling.ScrapeStrategyAdaptive,
+		fullThreshold:  5,
+		popSize:        10,
+		wantSampleSize: 7,
+	}, {
+		name:           "no pods",
+		strategy:       autoscaling.ScrapeStrategyFull,
+		popSize:        0,
+		wantSampleSize: 0,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := scrapeSampleSize(tc.strategy, tc.percentage, tc.fullThreshold, tc.popSize); got != tc.wantSampleSize {
+				t.Errorf("scrapeSampleSize(%v) = %v, want %v", tc.popSize, got, tc.wantSampleSize)
+			}
+		})
+	}
+}
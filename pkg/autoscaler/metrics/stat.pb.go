@@ -57,6 +57,31 @@ type Stat struct {
 	// Time/date that the stat was generated in seconds since
 	// 1970-01-01 00:00:00.000 UTC.
 	Timestamp int64 `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Average value of the application-reported metric the revision scales
+	// on, if it declares one.
+	CustomMetric float64 `protobuf:"fixed64,8,opt,name=custom_metric,json=customMetric,proto3" json:"custom_metric,omitempty"`
+	// Total CPU usage of the user containers in percent of their requests,
+	// added by the autoscaler from the resource metrics API.
+	CpuUtilization float64 `protobuf:"fixed64,9,opt,name=cpu_utilization,json=cpuUtilization,proto3" json:"cpu_utilization,omitempty"`
+	// Total memory usage of the user containers in percent of their requests,
+	// added by the autoscaler from the resource metrics API.
+	MemoryUtilization float64 `protobuf:"fixed64,10,opt,name=memory_utilization,json=memoryUtilization,proto3" json:"memory_utilization,omitempty"`
+	// Number of requests per second that completed with a latency in
+	// milliseconds up to the respective bound of LatencyBucketBounds. The last
+	// count is for the requests exceeding all bounds. Only reported by pods
+	// of revisions scaling on latency.
+	LatencyHistogram []float64 `protobuf:"fixed64,11,rep,packed,name=latency_histogram,json=latencyHistogram,proto3" json:"latency_histogram,omitempty"`
+	// Whether the stat was pushed by the queue-proxy of the pod it measures,
+	// rather than reported by an activator or scraped by the autoscaler.
+	PushedByPod bool `protobuf:"varint,12,opt,name=pushed_by_pod,json=pushedByPod,proto3" json:"pushed_by_pod,omitempty"`
+	// Average number of requests waiting for a free slot within the
+	// concurrency limit of the revision: in the queue of the queue-proxy
+	// or buffered in the activator.
+	AverageQueuedRequests float64 `protobuf:"fixed64,13,opt,name=average_queued_requests,json=averageQueuedRequests,proto3" json:"average_queued_requests,omitempty"`
+	// Average time in milliseconds the requests that stopped waiting since
+	// the last Stat spent waiting. This is not aggregated across pods by the
+	// autoscaler.
+	AverageQueueWait float64 `protobuf:"fixed64,14,opt,name=average_queue_wait,json=averageQueueWait,proto3" json:"average_queue_wait,omitempty"`
 }
 
 func (m *Stat) Reset()         { *m = Stat{} }
@@ -141,6 +166,55 @@ func (m *Stat) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *Stat) GetCustomMetric() float64 {
+	if m != nil {
+		return m.CustomMetric
+	}
+	return 0
+}
+
+func (m *Stat) GetCpuUtilization() float64 {
+	if m != nil {
+		return m.CpuUtilization
+	}
+	return 0
+}
+
+func (m *Stat) GetMemoryUtilization() float64 {
+	if m != nil {
+		return m.MemoryUtilization
+	}
+	return 0
+}
+
+func (m *Stat) GetLatencyHistogram() []float64 {
+	if m != nil {
+		return m.LatencyHistogram
+	}
+	return nil
+}
+
+func (m *Stat) GetPushedByPod() bool {
+	if m != nil {
+		return m.PushedByPod
+	}
+	return false
+}
+
+func (m *Stat) GetAverageQueuedRequests() float64 {
+	if m != nil {
+		return m.AverageQueuedRequests
+	}
+	return 0
+}
+
+func (m *Stat) GetAverageQueueWait() float64 {
+	if m != nil {
+		return m.AverageQueueWait
+	}
+	return 0
+}
+
 // WireStatMessage is a copy of the StatMessage Golang type, exploding the fields of
 // `types.NamespacedName` to make it compatible with protobufs.
 type WireStatMessage struct {
@@ -261,30 +335,39 @@ func init() {
 func init() { proto.RegisterFile("pkg/autoscaler/metrics/stat.proto", fileDescriptor_cf216df9f6fff44c) }
 
 var fileDescriptor_cf216df9f6fff44c = []byte{
-	// 359 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0x4f, 0x4b, 0xc3, 0x30,
-	0x18, 0xc6, 0x97, 0xad, 0xee, 0x4f, 0xe6, 0x54, 0x22, 0x42, 0x86, 0x52, 0xba, 0x0d, 0xa1, 0xa7,
-	0x0d, 0xa6, 0x67, 0x0f, 0xee, 0xe2, 0x65, 0x22, 0x15, 0xf1, 0x58, 0x62, 0xf6, 0x3a, 0x8a, 0xb6,
-	0x89, 0x49, 0x2a, 0x7e, 0x0c, 0x3f, 0x96, 0x27, 0xd9, 0xd1, 0xa3, 0x6c, 0x5f, 0x44, 0x1a, 0xb3,
-	0x4d, 0xc7, 0x4e, 0x0d, 0xcf, 0xfb, 0x7b, 0x9e, 0x17, 0x9e, 0xb7, 0xb8, 0x23, 0x9f, 0xa6, 0x03,
-	0x96, 0x1b, 0xa1, 0x39, 0x7b, 0x06, 0x35, 0x48, 0xc1, 0xa8, 0x84, 0xeb, 0x81, 0x36, 0xcc, 0xf4,
-	0xa5, 0x12, 0x46, 0x90, 0x9a, 0xd3, 0xba, 0x9f, 0x65, 0xec, 0xdd, 0x1a, 0x66, 0x48, 0x1b, 0xd7,
-	0xa5, 0x98, 0xc4, 0x19, 0x4b, 0x81, 0xa2, 0x00, 0x85, 0x8d, 0xa8, 0x26, 0xc5, 0xe4, 0x9a, 0xa5,
-	0x40, 0x2e, 0xf0, 0x31, 0x7b, 0x05, 0xc5, 0xa6, 0x10, 0x73, 0x91, 0xf1, 0x5c, 0x29, 0xc8, 0x4c,
-	0xac, 0xe0, 0x25, 0x07, 0x6d, 0x34, 0x2d, 0x07, 0x28, 0x44, 0x51, 0xdb, 0x21, 0xa3, 0x15, 0x11,
-	0x39, 0x80, 0x8c, 0x71, 0x6f, 0xe9, 0x97, 0x4a, 0xbc, 0x25, 0x30, 0xd9, 0x9a, 0x53, 0xb1, 0x39,
-	0x81, 0x43, 0x6f, 0x7e, 0xc9, 0x2d, 0x71, 0x3d, 0xdc, 0x72, 0x9e, 0x98, 0x8b, 0x3c, 0x33, 0xd4,
-	0xb3, 0xc6, 0x5d, 0x27, 0x8e, 0x0a, 0x8d, 0x0c, 0xf1, 0xd1, 0x72, 0xd7, 0x7f, 0x78, 0xc7, 0xc2,
-	0x87, 0x6e, 0x18, 0xfd, 0xf5, 0x9c, 0xe2, 0x3d, 0xa9, 0x04, 0x07, 0xad, 0xe3, 0x5c, 0x9a, 0x24,
-	0x05, 0x5a, 0xb5, 0x70, 0xcb, 0xa9, 0x77, 0x56, 0x24, 0x27, 0xb8, 0x51, 0x7c, 0xb5, 0x61, 0xa9,
-	0xa4, 0xb5, 0x00, 0x85, 0x95, 0x68, 0x2d, 0x74, 0x1f, 0xf1, 0xfe, 0x7d, 0xa2, 0xa0, 0xe8, 0x74,
-	0x0c, 0x5a, 0xb3, 0xa9, 0x35, 0x14, 0xb5, 0x6a, 0xc9, 0xf8, 0xb2, 0xdb, 0xb5, 0x40, 0x08, 0xf6,
-	0x6c, 0xe9, 0x65, 0x3b, 0xb0, 0x6f, 0xd2, 0xc1, 0x5e, 0x71, 0x2c, 0x5b, 0x49, 0x73, 0xd8, 0xea,
-	0xbb, 0x6b, 0xf5, 0x8b, 0xd4, 0xc8, 0x8e, 0xba, 0x57, 0xf8, 0x60, 0x63, 0x8f, 0x26, 0xe7, 0xb8,
-	0x9e, 0xba, 0x37, 0x45, 0x41, 0x25, 0x6c, 0x0e, 0xe9, 0xca, 0xba, 0x01, 0x47, 0x2b, 0xf2, 0x92,
-	0x7e, 0xcc, 0x7d, 0x34, 0x9b, 0xfb, 0xe8, 0x7b, 0xee, 0xa3, 0xf7, 0x85, 0x5f, 0x9a, 0x2d, 0xfc,
-	0xd2, 0xd7, 0xc2, 0x2f, 0x3d, 0x54, 0xed, 0xcf, 0x72, 0xf6, 0x13, 0x00, 0x00, 0xff, 0xff, 0x6d,
-	0xc3, 0xa5, 0x6a, 0x51, 0x02, 0x00, 0x00,
+	// 512 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x93, 0x4d, 0x6f, 0xd3, 0x30,
+	0x1c, 0xc6, 0x6b, 0xda, 0xad, 0xad, 0xbb, 0x76, 0x9d, 0xd1, 0x84, 0x27, 0x50, 0x94, 0x75, 0x42,
+	0x44, 0x02, 0x5a, 0xa9, 0x20, 0x8e, 0x1c, 0xb6, 0xcb, 0x2e, 0x45, 0x23, 0x68, 0xda, 0xd1, 0xf2,
+	0x1c, 0xd3, 0x45, 0x34, 0xb1, 0xe7, 0x17, 0xa0, 0x1c, 0xf9, 0x04, 0x7c, 0x2c, 0x8e, 0x3b, 0x72,
+	0x44, 0xed, 0x17, 0x99, 0xe2, 0x38, 0x7d, 0x99, 0x76, 0xaa, 0xfb, 0x3c, 0xbf, 0xe7, 0x6f, 0xcb,
+	0x7e, 0x02, 0x8f, 0xe5, 0xb7, 0xe9, 0x88, 0x5a, 0x23, 0x34, 0xa3, 0x33, 0xae, 0x46, 0x19, 0x37,
+	0x2a, 0x65, 0x7a, 0xa4, 0x0d, 0x35, 0x43, 0xa9, 0x84, 0x11, 0xa8, 0xe9, 0xb5, 0xc1, 0xef, 0x1d,
+	0xd8, 0xf8, 0x62, 0xa8, 0x41, 0x47, 0xb0, 0x25, 0x45, 0x42, 0x72, 0x9a, 0x71, 0x0c, 0x42, 0x10,
+	0xb5, 0xe3, 0xa6, 0x14, 0xc9, 0x27, 0x9a, 0x71, 0xf4, 0x11, 0x3e, 0xa7, 0xdf, 0xb9, 0xa2, 0x53,
+	0x4e, 0x98, 0xc8, 0x99, 0x55, 0x8a, 0xe7, 0x86, 0x28, 0x7e, 0x6b, 0xb9, 0x36, 0x1a, 0x3f, 0x09,
+	0x41, 0x04, 0xe2, 0x23, 0x8f, 0x9c, 0xad, 0x88, 0xd8, 0x03, 0x68, 0x02, 0x4f, 0xaa, 0xbc, 0x54,
+	0xe2, 0x67, 0xca, 0x93, 0x47, 0xe7, 0xd4, 0xdd, 0x9c, 0xd0, 0xa3, 0x17, 0x25, 0xf9, 0xc8, 0xb8,
+	0x13, 0xd8, 0xf5, 0x19, 0xc2, 0x84, 0xcd, 0x0d, 0x6e, 0xb8, 0xe0, 0x9e, 0x17, 0xcf, 0x0a, 0x0d,
+	0x8d, 0xe1, 0x61, 0xb5, 0xd7, 0x36, 0xbc, 0xe3, 0xe0, 0xa7, 0xde, 0x8c, 0x37, 0x33, 0x2f, 0x61,
+	0x4f, 0x2a, 0xc1, 0xb8, 0xd6, 0xc4, 0x4a, 0x93, 0x66, 0x1c, 0xef, 0x3a, 0xb8, 0xeb, 0xd5, 0x4b,
+	0x27, 0xa2, 0x17, 0xb0, 0x5d, 0xfc, 0x6a, 0x43, 0x33, 0x89, 0x9b, 0x21, 0x88, 0xea, 0xf1, 0x5a,
+	0x28, 0x4e, 0xc7, 0xac, 0x36, 0x22, 0x23, 0xe5, 0x15, 0xe3, 0x56, 0x79, 0xba, 0x52, 0x9c, 0x38,
+	0x0d, 0xbd, 0x82, 0xfb, 0x4c, 0x5a, 0x62, 0x4d, 0x3a, 0x4b, 0x7f, 0x51, 0x93, 0x8a, 0x1c, 0xb7,
+	0x1d, 0xd6, 0x63, 0xd2, 0x5e, 0xae, 0x55, 0xf4, 0x16, 0xa2, 0x8c, 0x67, 0x42, 0xcd, 0xb7, 0x58,
+	0xe8, 0xd8, 0x83, 0xd2, 0xd9, 0xc4, 0x5f, 0xc3, 0x83, 0x19, 0x35, 0x3c, 0x67, 0x73, 0x72, 0x93,
+	0x6a, 0x23, 0xa6, 0x8a, 0x66, 0xb8, 0x13, 0xd6, 0x23, 0x10, 0xf7, 0xbd, 0x71, 0x5e, 0xe9, 0x68,
+	0x00, 0xbb, 0xd2, 0xea, 0x1b, 0x9e, 0x90, 0xeb, 0x39, 0x91, 0x22, 0xc1, 0x7b, 0x21, 0x88, 0x5a,
+	0x71, 0xa7, 0x14, 0x4f, 0xe7, 0x17, 0x22, 0x41, 0x1f, 0xe0, 0xb3, 0xea, 0xe9, 0x6e, 0x2d, 0xb7,
+	0xeb, 0xdb, 0xd4, 0xb8, 0xeb, 0x0e, 0x71, 0xe8, 0xed, 0xcf, 0xce, 0x5d, 0xbd, 0xd1, 0x1b, 0x88,
+	0xb6, 0x72, 0xe4, 0x07, 0x4d, 0x0d, 0xee, 0xb9, 0x48, 0x7f, 0x33, 0x72, 0x45, 0x53, 0x33, 0xf8,
+	0x0a, 0xf7, 0xaf, 0x52, 0xc5, 0x8b, 0x1e, 0x4e, 0xb8, 0xd6, 0x74, 0xea, 0x2e, 0xb9, 0xa8, 0xa2,
+	0x96, 0x94, 0x55, 0x7d, 0x5c, 0x0b, 0x08, 0xc1, 0x46, 0xf1, 0xc7, 0x55, 0xaf, 0x1d, 0xbb, 0x35,
+	0x3a, 0x86, 0x8d, 0xa2, 0xe0, 0xae, 0x46, 0x9d, 0x71, 0x77, 0xe8, 0x1b, 0x3e, 0x2c, 0xa6, 0xc6,
+	0xce, 0x1a, 0x9c, 0xc3, 0xfe, 0x83, 0x7d, 0x34, 0x7a, 0x0f, 0x5b, 0x99, 0x5f, 0x63, 0x10, 0xd6,
+	0xa3, 0xce, 0x18, 0xaf, 0xa2, 0x0f, 0xe0, 0x78, 0x45, 0x9e, 0xe2, 0xbf, 0x8b, 0x00, 0xdc, 0x2d,
+	0x02, 0xf0, 0x7f, 0x11, 0x80, 0x3f, 0xcb, 0xa0, 0x76, 0xb7, 0x0c, 0x6a, 0xff, 0x96, 0x41, 0xed,
+	0x7a, 0xd7, 0x7d, 0x60, 0xef, 0xee, 0x07, 0x00, 0xff, 0xa7, 0xf2, 0x8e, 0x85, 0x03, 0x00, 0x00,
 }
 
 func (m *Stat) Marshal() (dAtA []byte, err error) {
@@ -307,6 +390,56 @@ func (m *Stat) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.AverageQueueWait != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.AverageQueueWait))))
+		i--
+		dAtA[i] = 0x71
+	}
+	if m.AverageQueuedRequests != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.AverageQueuedRequests))))
+		i--
+		dAtA[i] = 0x69
+	}
+	if m.PushedByPod {
+		i--
+		if m.PushedByPod {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x60
+	}
+	if len(m.LatencyHistogram) > 0 {
+		for iNdEx := len(m.LatencyHistogram) - 1; iNdEx >= 0; iNdEx-- {
+			f1 := math.Float64bits(float64(m.LatencyHistogram[iNdEx]))
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f1))
+		}
+		i = encodeVarintStat(dAtA, i, uint64(len(m.LatencyHistogram)*8))
+		i--
+		dAtA[i] = 0x5a
+	}
+	if m.MemoryUtilization != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.MemoryUtilization))))
+		i--
+		dAtA[i] = 0x51
+	}
+	if m.CpuUtilization != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.CpuUtilization))))
+		i--
+		dAtA[i] = 0x49
+	}
+	if m.CustomMetric != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.CustomMetric))))
+		i--
+		dAtA[i] = 0x41
+	}
 	if m.Timestamp != 0 {
 		i = encodeVarintStat(dAtA, i, uint64(m.Timestamp))
 		i--
@@ -477,6 +610,27 @@ func (m *Stat) Size() (n int) {
 	if m.Timestamp != 0 {
 		n += 1 + sovStat(uint64(m.Timestamp))
 	}
+	if m.CustomMetric != 0 {
+		n += 9
+	}
+	if m.CpuUtilization != 0 {
+		n += 9
+	}
+	if m.MemoryUtilization != 0 {
+		n += 9
+	}
+	if len(m.LatencyHistogram) > 0 {
+		n += 1 + sovStat(uint64(len(m.LatencyHistogram)*8)) + len(m.LatencyHistogram)*8
+	}
+	if m.PushedByPod {
+		n += 2
+	}
+	if m.AverageQueuedRequests != 0 {
+		n += 9
+	}
+	if m.AverageQueueWait != 0 {
+		n += 9
+	}
 	return n
 }
 
@@ -657,6 +811,135 @@ func (m *Stat) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 8:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CustomMetric", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.CustomMetric = float64(math.Float64frombits(v))
+		case 9:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CpuUtilization", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.CpuUtilization = float64(math.Float64frombits(v))
+		case 10:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryUtilization", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.MemoryUtilization = float64(math.Float64frombits(v))
+		case 11:
+			if wireType == 1 {
+				var v uint64
+				if (iNdEx + 8) > l {
+					return io.ErrUnexpectedEOF
+				}
+				v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+				iNdEx += 8
+				v2 := float64(math.Float64frombits(v))
+				m.LatencyHistogram = append(m.LatencyHistogram, v2)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowStat
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthStat
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthStat
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				elementCount = packedLen / 8
+				if elementCount != 0 && len(m.LatencyHistogram) == 0 {
+					m.LatencyHistogram = make([]float64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					v2 := float64(math.Float64frombits(v))
+					m.LatencyHistogram = append(m.LatencyHistogram, v2)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field LatencyHistogram", wireType)
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PushedByPod", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.PushedByPod = bool(v != 0)
+		case 13:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AverageQueuedRequests", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.AverageQueuedRequests = float64(math.Float64frombits(v))
+		case 14:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AverageQueueWait", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.AverageQueueWait = float64(math.Float64frombits(v))
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStat(dAtA[iNdEx:])
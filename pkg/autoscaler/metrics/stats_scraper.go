@@ -41,7 +41,9 @@ import (
 )
 
 const (
-	httpClientTimeout = 3 * time.Second
+	// defaultScrapeTimeout is the timeout of the scrape of a pod if the
+	// metric does not specify one.
+	defaultScrapeTimeout = 3 * time.Second
 
 	// scraperPodName is the name used in all stats sent from the scraper to
 	// the autoscaler. The actual customer pods are hidden behind the scraper. The
@@ -74,6 +76,10 @@ var (
 		"scrape_time",
 		"Time to scrape metrics in milliseconds",
 		stats.UnitMilliseconds)
+	scrapeErrorsM = stats.Int64(
+		"scrape_errors",
+		"Number of failed scrapes of metrics",
+		stats.UnitDimensionless)
 )
 
 func init() {
@@ -83,6 +89,11 @@ func init() {
 			Measure:     scrapeTimeM,
 			Aggregation: view.Distribution(pkgmetrics.Buckets125(1, 100000)...),
 		},
+		&view.View{
+			Description: "The number of failed scrapes of metrics",
+			Measure:     scrapeErrorsM,
+			Aggregation: view.Count(),
+		},
 	); err != nil {
 		panic(err)
 	}
@@ -95,6 +106,14 @@ type StatsScraper interface {
 	Scrape(time.Duration) (Stat, error)
 }
 
+// PodConcurrencyScraper is implemented by the StatsScrapers that can report
+// the concurrency of the individual pods they scraped.
+type PodConcurrencyScraper interface {
+	// PodConcurrency returns the average concurrency by pod name of the pods
+	// scraped by the last successful scrape. The returned map must not be modified.
+	PodConcurrency() map[string]float64
+}
+
 // scrapeClient defines the interface for collecting Revision metrics for a given
 // URL. Internal used only.
 type scrapeClient interface {
@@ -123,17 +142,17 @@ var keepAliveTransport = func() *http.Transport {
 // noKeepaliveClient is a http client with HTTP Keep-Alive disabled.
 // This client is used in the mesh case since we want to get a new connection -
 // and therefore, hopefully, host - on every scrape of the service.
+// The scrapes are timed out by the scrapers, per revision.
 var noKeepaliveClient = &http.Client{
 	Transport: noKeepAliveTransport,
-	Timeout:   httpClientTimeout,
 }
 
 // client is a normal http client with HTTP Keep-Alive enabled.
 // This client is used in the direct pod scraping (no mesh) case where we want
 // to take advantage of HTTP Keep-Alive to avoid connection creation overhead
 // between scrapes of the same pod.
+// The scrapes are timed out by the scrapers, per revision.
 var client = &http.Client{
-	Timeout:   httpClientTimeout,
 	Transport: keepAliveTransport,
 }
 
@@ -151,8 +170,25 @@ type serviceScraper struct {
 
 	podAccessor     resources.PodAccessor
 	podsAddressable bool
+
+	// The scrape strategy of the metric, see scrapeSampleSize.
+	strategy      string
+	percentage    float64
+	fullThreshold int32
+	// timeout is the timeout of the scrape of a pod.
+	timeout time.Duration
+	// scrapeSlots limits the number of pods scraped at the same time,
+	// if not nil.
+	scrapeSlots chan struct{}
+
+	// podConcurrencyMux guards podConcurrency.
+	podConcurrencyMux sync.RWMutex
+	// podConcurrency is the concurrency of the pods scraped by the last scrape.
+	podConcurrency map[string]float64
 }
 
+var _ PodConcurrencyScraper = (*serviceScraper)(nil)
+
 // NewStatsScraper creates a new StatsScraper for the Revision which
 // the given Metric is responsible for.
 func NewStatsScraper(metric *av1alpha1.Metric, revisionName string, podAccessor resources.PodAccessor,
@@ -173,17 +209,52 @@ func newServiceScraperWithClient(
 
 	ctx := metrics.RevisionContext(metric.ObjectMeta.Namespace, svcName, cfgName, revisionName)
 
+	timeout := metric.Spec.ScrapeTimeout
+	if timeout <= 0 {
+		timeout = defaultScrapeTimeout
+	}
+	var scrapeSlots chan struct{}
+	if metric.Spec.ScrapeConcurrency > 0 {
+		scrapeSlots = make(chan struct{}, metric.Spec.ScrapeConcurrency)
+	}
+
 	return &serviceScraper{
 		directClient:    directClient,
 		meshClient:      meshClient,
 		url:             urlFromTarget(metric.Spec.ScrapeTarget, metric.ObjectMeta.Namespace),
 		podAccessor:     podAccessor,
 		podsAddressable: true,
+		strategy:        metric.Spec.ScrapeStrategy,
+		percentage:      metric.Spec.ScrapeSamplePercentage,
+		fullThreshold:   metric.Spec.ScrapeFullThreshold,
+		timeout:         timeout,
+		scrapeSlots:     scrapeSlots,
 		statsCtx:        ctx,
 		logger:          logger,
 	}
 }
 
+// scrape scrapes the given URL with the given client, once a scrape slot
+// is free and within the scrape timeout.
+func (s *serviceScraper) scrape(ctx context.Context, client scrapeClient, url string) (Stat, error) {
+	if s.scrapeSlots != nil {
+		select {
+		case s.scrapeSlots <- struct{}{}:
+			defer func() { <-s.scrapeSlots }()
+		case <-ctx.Done():
+			return emptyStat, ctx.Err()
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return client.Scrape(ctx, url)
+}
+
+// sampleSize returns the number of pods to scrape out of the population.
+func (s *serviceScraper) sampleSize(population float64) float64 {
+	return scrapeSampleSize(s.strategy, s.percentage, s.fullThreshold, population)
+}
+
 var portAndPath = strconv.Itoa(networking.AutoscalingQueueMetricsPort) + "/metrics"
 
 func urlFromTarget(t, ns string) string {
@@ -197,11 +268,14 @@ func (s *serviceScraper) Scrape(window time.Duration) (stat Stat, err error) {
 	defer func() {
 		// No errors and an empty stat? We didn't scrape at all because
 		// we're scaled to 0.
-		if stat == emptyStat && err == nil {
+		if isEmptyStat(&stat) && err == nil {
 			return
 		}
 		scrapeTime := time.Since(startTime)
 		pkgmetrics.RecordBatch(s.statsCtx, scrapeTimeM.M(float64(scrapeTime.Milliseconds())))
+		if err != nil {
+			pkgmetrics.RecordBatch(s.statsCtx, scrapeErrorsM.M(1))
+		}
 	}()
 
 	if s.podsAddressable {
@@ -217,6 +291,7 @@ func (s *serviceScraper) Scrape(window time.Duration) (stat Stat, err error) {
 		return emptyStat, ErrFailedGetEndpoints
 	}
 	if readyPodsCount == 0 {
+		s.setPodConcurrency(nil)
 		return emptyStat, nil
 	}
 	stat, err = s.scrapeService(window, readyPodsCount)
@@ -240,11 +315,12 @@ func (s *serviceScraper) scrapePods(window time.Duration) (Stat, error) {
 	s.logger.Debugf("|OldPods| = %d, |YoungPods| = %d", lp, lyp)
 	total := lp + lyp
 	if total == 0 {
+		s.setPodConcurrency(nil)
 		return emptyStat, nil
 	}
 
 	frpc := float64(total)
-	sampleSizeF := populationMeanSampleSize(frpc)
+	sampleSizeF := s.sampleSize(frpc)
 	sampleSize := int(sampleSizeF)
 	results := make(chan Stat, sampleSize)
 
@@ -280,7 +356,7 @@ func (s *serviceScraper) scrapePods(window time.Duration) (Stat, error) {
 
 				// Scrape!
 				target := "http://" + pods[myIdx] + ":" + portAndPath
-				stat, err := s.directClient.Scrape(egCtx, target)
+				stat, err := s.scrape(egCtx, s.directClient, target)
 				if err == nil {
 					results <- stat
 					return nil
@@ -307,10 +383,15 @@ func (s *serviceScraper) scrapePods(window time.Duration) (Stat, error) {
 		return emptyStat, errNoPodsScraped
 	}
 
-	return computeAverages(results, sampleSizeF, frpc), nil
+	podConcurrency := make(map[string]float64, len(results))
+	ret := computeAverages(results, sampleSizeF, frpc, podConcurrency)
+	s.setPodConcurrency(podConcurrency)
+	return ret, nil
 }
 
-func computeAverages(results <-chan Stat, sample, total float64) Stat {
+// computeAverages averages the stats from the individual pods and, if
+// podConcurrency is not nil, stores their concurrency by pod name in it.
+func computeAverages(results <-chan Stat, sample, total float64, podConcurrency map[string]float64) Stat {
 	ret := Stat{
 		PodName: scraperPodName,
 	}
@@ -318,6 +399,9 @@ func computeAverages(results <-chan Stat, sample, total float64) Stat {
 	// Sum the stats from individual pods.
 	for stat := range results {
 		ret.add(stat)
+		if podConcurrency != nil && stat.PodName != "" {
+			podConcurrency[stat.PodName] = stat.AverageConcurrentRequests
+		}
 	}
 
 	ret.average(sample, total)
@@ -329,7 +413,7 @@ func computeAverages(results <-chan Stat, sample, total float64) Stat {
 func (s *serviceScraper) scrapeService(window time.Duration, readyPods int) (Stat, error) {
 	frpc := float64(readyPods)
 
-	sampleSizeF := populationMeanSampleSize(frpc)
+	sampleSizeF := s.sampleSize(frpc)
 	sampleSize := int(sampleSizeF)
 	oldStatCh := make(chan Stat, sampleSize)
 	youngStatCh := make(chan Stat, sampleSize)
@@ -397,23 +481,41 @@ func (s *serviceScraper) scrapeService(window time.Duration, readyPods int) (Sta
 	}
 
 	// Sum the stats from individual pods.
+	podConcurrency := make(map[string]float64, sampleSize)
 	oldCnt := len(oldStatCh)
 	for stat := range oldStatCh {
 		ret.add(stat)
+		podConcurrency[stat.PodName] = stat.AverageConcurrentRequests
 	}
 	for i := oldCnt; i < sampleSize; i++ {
 		// This will always succeed, see reasoning above.
-		ret.add(<-youngStatCh)
+		stat := <-youngStatCh
+		ret.add(stat)
+		podConcurrency[stat.PodName] = stat.AverageConcurrentRequests
 	}
+	s.setPodConcurrency(podConcurrency)
 
 	ret.average(sampleSizeF, frpc)
 	return ret, nil
 }
 
+// PodConcurrency implements PodConcurrencyScraper.
+func (s *serviceScraper) PodConcurrency() map[string]float64 {
+	s.podConcurrencyMux.RLock()
+	defer s.podConcurrencyMux.RUnlock()
+	return s.podConcurrency
+}
+
+func (s *serviceScraper) setPodConcurrency(podConcurrency map[string]float64) {
+	s.podConcurrencyMux.Lock()
+	defer s.podConcurrencyMux.Unlock()
+	s.podConcurrency = podConcurrency
+}
+
 // tryScrape runs a single scrape and returns stat if this is a pod that has not been
 // seen before. An error otherwise or if scraping failed.
 func (s *serviceScraper) tryScrape(ctx context.Context, scrapedPods *sync.Map) (Stat, error) {
-	stat, err := s.meshClient.Scrape(ctx, s.url)
+	stat, err := s.scrape(ctx, s.meshClient, s.url)
 	if err != nil {
 		return emptyStat, err
 	}
@@ -26,11 +26,13 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"go.uber.org/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 
 	fakepodsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
@@ -150,6 +152,10 @@ func TestPodDirectScrapeSuccess(t *testing.T) {
 		t.Errorf("Wanted empty stat got: %#v", stat)
 	}
 
+	if got := scraper.PodConcurrency(); got != nil {
+		t.Errorf("PodConcurrency() = %v, want nil", got)
+	}
+
 	makePods(ctx, "pods-", 3, metav1.Now())
 	if _, err := scraper.Scrape(defaultMetric.Spec.StableWindow); err != nil {
 		t.Fatal("Unexpected error from scraper.Scrape():", err)
@@ -158,6 +164,10 @@ func TestPodDirectScrapeSuccess(t *testing.T) {
 	if !scraper.podsAddressable {
 		t.Error("PodAddressable switched to false")
 	}
+	want := map[string]float64{"pod-1": 3, "pod-2": 5, "pod-3": 3}
+	if got := scraper.PodConcurrency(); !cmp.Equal(got, want) {
+		t.Error("PodConcurrency() mismatch (-want,+got):", cmp.Diff(want, got))
+	}
 }
 
 func TestPodDirectScrapeSomeFailButSuccess(t *testing.T) {
@@ -279,6 +289,10 @@ func TestScrapeReportStatWhenAllCallsSucceed(t *testing.T) {
 	}
 
 	checkBaseStat(t, got)
+	want := map[string]float64{"pod-1": 3, "pod-2": 5, "pod-3": 3}
+	if got := scraper.PodConcurrency(); !cmp.Equal(got, want) {
+		t.Error("PodConcurrency() mismatch (-want,+got):", cmp.Diff(want, got))
+	}
 }
 
 var youngPodCutOffDuration = defaultMetric.Spec.StableWindow
@@ -449,7 +463,7 @@ func TestScrapeDoNotScrapeIfNoPodsFound(t *testing.T) {
 	if err != nil {
 		t.Fatal("scraper.Scrape() returned error:", err)
 	}
-	if stat != emptyStat {
+	if !isEmptyStat(&stat) {
 		t.Error("Received unexpected Stat.")
 	}
 }
@@ -621,6 +635,117 @@ func TestOldPodsFallback(t *testing.T) {
 	}
 }
 
+func TestPodDirectScrapeStrategy(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+	wf, err := controller.RunInformers(ctx.Done(), informers...)
+	if err != nil {
+		cancel()
+		t.Fatal("StartInformers() =", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		wf()
+	})
+
+	const numPods = 20
+	makePods(ctx, "old-", numPods, metav1.NewTime(time.Now().Add(-time.Hour)))
+
+	for _, tc := range []struct {
+		name        string
+		strategy    string
+		percentage  float64
+		wantScrapes int
+	}{{
+		name:        "full",
+		strategy:    autoscaling.ScrapeStrategyFull,
+		wantScrapes: numPods,
+	}, {
+		name:        "percentage",
+		strategy:    autoscaling.ScrapeStrategyPercentage,
+		percentage:  25,
+		wantScrapes: 5,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			metric := testMetric()
+			metric.Spec.ScrapeStrategy = tc.strategy
+			metric.Spec.ScrapeSamplePercentage = tc.percentage
+			client := newTestScrapeClient(testStats, []error{nil})
+			scraper := newServiceScraperWithClient(metric, testRevision, resources.NewPodAccessor(
+				fakepodsinformer.Get(ctx).Lister(), testNamespace, testRevision),
+				client, client, logtesting.TestLogger(t))
+
+			if _, err := scraper.Scrape(metric.Spec.StableWindow); err != nil {
+				t.Fatal("scraper.Scrape() returned error:", err)
+			}
+			if got := len(client.urls); got != tc.wantScrapes {
+				t.Errorf("Got = %d unique URLS, want: %d", got, tc.wantScrapes)
+			}
+		})
+	}
+}
+
+func TestScrapeConcurrencyAndTimeout(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+	wf, err := controller.RunInformers(ctx.Done(), informers...)
+	if err != nil {
+		cancel()
+		t.Fatal("StartInformers() =", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		wf()
+	})
+
+	const numPods = 10
+	makePods(ctx, "old-", numPods, metav1.NewTime(time.Now().Add(-time.Hour)))
+
+	metric := testMetric()
+	metric.Spec.ScrapeStrategy = autoscaling.ScrapeStrategyFull
+	metric.Spec.ScrapeConcurrency = 2
+	metric.Spec.ScrapeTimeout = 10 * time.Millisecond
+	client := &slowScrapeClient{}
+	scraper := newServiceScraperWithClient(metric, testRevision, resources.NewPodAccessor(
+		fakepodsinformer.Get(ctx).Lister(), testNamespace, testRevision),
+		client, client, logtesting.TestLogger(t))
+
+	if _, err := scraper.Scrape(metric.Spec.StableWindow); err != nil {
+		t.Fatal("scraper.Scrape() returned error:", err)
+	}
+	if got := client.scrapes.Load(); got != numPods {
+		t.Errorf("Scrapes = %d, want: %d", got, numPods)
+	}
+	if got, want := client.maxInFlight.Load(), int32(2); got != want {
+		t.Errorf("Max scrapes in flight = %d, want: %d", got, want)
+	}
+	if !client.timedOut.Load() {
+		t.Error("The scrapes were not timed out")
+	}
+}
+
+// slowScrapeClient is a scrapeClient whose scrapes wait for their context to
+// be done, and keeps track of the number of concurrent scrapes.
+type slowScrapeClient struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	scrapes     atomic.Int32
+	timedOut    atomic.Bool
+}
+
+func (c *slowScrapeClient) Scrape(ctx context.Context, url string) (Stat, error) {
+	n := c.inFlight.Inc()
+	defer c.inFlight.Dec()
+	for max := c.maxInFlight.Load(); n > max && !c.maxInFlight.CAS(max, n); max = c.maxInFlight.Load() {
+	}
+	c.scrapes.Inc()
+
+	<-ctx.Done()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		c.timedOut.Store(true)
+	}
+	// The scrape succeeds once timed out, to count the pods scraped.
+	return Stat{PodName: url, AverageConcurrentRequests: 1}, nil
+}
+
 func serviceScraperForTest(ctx context.Context, t *testing.T, directClient, meshClient scrapeClient, podsAddressable bool) *serviceScraper {
 	metric := testMetric()
 	accessor := resources.NewPodAccessor(
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/autoscaler/aggregation/max"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AlgorithmFactory creates a UniScaler implementing a particular scaling
+// algorithm for the given revision.
+type AlgorithmFactory func(
+	reporterCtx context.Context,
+	namespace, revision string,
+	metricClient metrics.MetricClient,
+	podCounter resources.EndpointsCounter,
+	deciderSpec *DeciderSpec) UniScaler
+
+var (
+	// algorithmsMux guards algorithms.
+	algorithmsMux sync.RWMutex
+	algorithms    = map[string]AlgorithmFactory{
+		autoscaling.AlgorithmStablePanic: New,
+		autoscaling.AlgorithmEWMA:        NewEWMA,
+		autoscaling.AlgorithmPID:         NewPID,
+	}
+)
+
+// RegisterAlgorithm makes the algorithm available under the given name,
+// replacing any algorithm previously registered with that name.
+func RegisterAlgorithm(name string, factory AlgorithmFactory) {
+	algorithmsMux.Lock()
+	defer algorithmsMux.Unlock()
+	algorithms[name] = factory
+}
+
+// NewForAlgorithm creates a UniScaler using the algorithm named in the
+// DeciderSpec. An empty name selects the default stable/panic algorithm.
+func NewForAlgorithm(
+	reporterCtx context.Context,
+	namespace, revision string,
+	metricClient metrics.MetricClient,
+	podCounter resources.EndpointsCounter,
+	deciderSpec *DeciderSpec) (UniScaler, error) {
+	name := deciderSpec.Algorithm
+	if name == "" {
+		name = autoscaling.AlgorithmStablePanic
+	}
+
+	algorithmsMux.RLock()
+	factory, ok := algorithms[name]
+	algorithmsMux.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown scaling algorithm %q", name)
+	}
+	return factory(reporterCtx, namespace, revision, metricClient, podCounter, deciderSpec), nil
+}
+
+// baseScaler holds the state shared by the alternative scaling algorithms.
+type baseScaler struct {
+	namespace    string
+	revision     string
+	metricClient metrics.MetricClient
+	podCounter   podCounter
+	reporterCtx  context.Context
+
+	// delayWindow is used to defer scale-down decisions until a time
+	// window has passed at the reduced value.
+	delayWindow *max.TimeWindow
+
+	// specMux guards the current DeciderSpec.
+	specMux     sync.RWMutex
+	deciderSpec *DeciderSpec
+
+	// decisionHistory keeps the recent scale decisions.
+	decisionHistory
+}
+
+func newBaseScaler(
+	reporterCtx context.Context,
+	namespace, revision string,
+	metricClient metrics.MetricClient,
+	podCounter podCounter,
+	deciderSpec *DeciderSpec) baseScaler {
+	var delayer *max.TimeWindow
+	if deciderSpec.ScaleDownDelay > 0 {
+		delayer = max.NewTimeWindow(deciderSpec.ScaleDownDelay, tickInterval)
+	}
+	return baseScaler{
+		namespace:    namespace,
+		revision:     revision,
+		metricClient: metricClient,
+		podCounter:   podCounter,
+		reporterCtx:  reporterCtx,
+		delayWindow:  delayer,
+		deciderSpec:  deciderSpec,
+	}
+}
+
+// Update reconfigures the UniScaler according to the DeciderSpec.
+func (b *baseScaler) Update(deciderSpec *DeciderSpec) {
+	b.specMux.Lock()
+	defer b.specMux.Unlock()
+	b.deciderSpec = deciderSpec
+}
+
+func (b *baseScaler) currentSpec() *DeciderSpec {
+	b.specMux.RLock()
+	defer b.specMux.RUnlock()
+	return b.deciderSpec
+}
+
+// observe reads the ready pod count and the metric values of the revision.
+// It returns false if the values are not available and no decision can be made.
+func (b *baseScaler) observe(ctx context.Context, spec *DeciderSpec, now time.Time) (int, float64, float64, bool) {
+	logger := logging.FromContext(ctx)
+	readyPodsCount, err := readyPodCount(b.podCounter)
+	if err != nil {
+		logger.Errorw("Failed to get ready pod count via K8S Lister", zap.Error(err))
+		return 0, 0, 0, false
+	}
+	metricKey := types.NamespacedName{Namespace: b.namespace, Name: b.revision}
+	stable, panicValue, _, err := observedValues(b.metricClient, spec, metricKey, now)
+	if err != nil {
+		if errors.Is(err, metrics.ErrNoData) {
+			logger.Debug("No data to scale on yet")
+		} else {
+			logger.Errorw("Failed to obtain metrics", zap.Error(err))
+		}
+		return 0, 0, 0, false
+	}
+	return readyPodsCount, stable, panicValue, true
+}
+
+// finish applies the rate limits and the scale down delay to the raw desired
+// pod count, records the metrics and builds the final ScaleResult.
+func (b *baseScaler) finish(spec *DeciderSpec, now time.Time, readyPodsCount int,
+	desired float64, stable, panicValue float64) ScaleResult {
+	metricKey := types.NamespacedName{Namespace: b.namespace, Name: b.revision}
+	desired = math.Max(desired, scaleFromZeroFloor(b.metricClient, spec, metricKey, now, readyPodsCount))
+	maxScaleDown, maxScaleUp := scaleRateLimits(spec, math.Max(1, float64(readyPodsCount)))
+	recommendedPodCount := int32(math.Min(math.Max(math.Ceil(desired), maxScaleDown), maxScaleUp))
+	desiredPodCount := delayScaleDown(b.delayWindow, now, recommendedPodCount)
+
+	excessBCF, numAct := burstCapacity(spec, readyPodsCount, panicValue)
+	recordScaleMetrics(b.reporterCtx, spec, excessBCF, desiredPodCount, stable, panicValue)
+	b.record(ScaleDecision{
+		Time:                now,
+		ObservedStableValue: stable,
+		ObservedPanicValue:  panicValue,
+		TargetValue:         spec.TargetValue,
+		ReadyPodCount:       int32(readyPodsCount),
+		MaxScaleUp:          int32(maxScaleUp),
+		MaxScaleDown:        int32(maxScaleDown),
+		RecommendedPodCount: recommendedPodCount,
+		DesiredPodCount:     desiredPodCount,
+		ExcessBurstCapacity: int32(excessBCF),
+	})
+	return ScaleResult{
+		DesiredPodCount:     desiredPodCount,
+		ExcessBurstCapacity: int32(excessBCF),
+		NumActivators:       numAct,
+		ScaleValid:          true,
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/resources"
+)
+
+func TestNewForAlgorithm(t *testing.T) {
+	custom := &fakeUniScaler{}
+	RegisterAlgorithm("custom", func(context.Context, string, string, metrics.MetricClient,
+		resources.EndpointsCounter, *DeciderSpec) UniScaler {
+		return custom
+	})
+
+	tests := []struct {
+		name      string
+		algorithm string
+		check     func(UniScaler) bool
+		wantErr   bool
+	}{{
+		name: "default",
+		check: func(u UniScaler) bool {
+			_, ok := u.(*autoscaler)
+			return ok
+		},
+	}, {
+		name:      "stable panic",
+		algorithm: autoscaling.AlgorithmStablePanic,
+		check: func(u UniScaler) bool {
+			_, ok := u.(*autoscaler)
+			return ok
+		},
+	}, {
+		name:      "ewma",
+		algorithm: autoscaling.AlgorithmEWMA,
+		check: func(u UniScaler) bool {
+			_, ok := u.(*ewmaAutoscaler)
+			return ok
+		},
+	}, {
+		name:      "pid",
+		algorithm: autoscaling.AlgorithmPID,
+		check: func(u UniScaler) bool {
+			_, ok := u.(*pidAutoscaler)
+			return ok
+		},
+	}, {
+		name:      "registered",
+		algorithm: "custom",
+		check: func(u UniScaler) bool {
+			return u == custom
+		},
+	}, {
+		name:      "unknown",
+		algorithm: "magic",
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := &DeciderSpec{Algorithm: test.algorithm}
+			u, err := NewForAlgorithm(context.Background(), testNamespace, testRevision,
+				&staticMetricClient, &fakePodCounter{}, spec)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewForAlgorithm() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if err == nil && !test.check(u) {
+				t.Errorf("NewForAlgorithm() returned unexpected UniScaler %T", u)
+			}
+		})
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -53,17 +55,33 @@ type autoscaler struct {
 	podCounter   podCounter
 	reporterCtx  context.Context
 
+	// stateMux guards the panic state, the delayWindow and lastRequest,
+	// which Scale and Restore modify.
+	stateMux sync.Mutex
+
 	// State in panic mode.
-	panicTime    time.Time
-	maxPanicPods int32
+	panicTime      time.Time
+	panicStartTime time.Time
+	maxPanicPods   int32
+	// panicExitTime is when panic mode was last exited, for the cooldown.
+	panicExitTime time.Time
+	// publishedPanicTime is a copy of panicTime, which is safe
+	// to read concurrently with Scale.
+	publishedPanicTime atomic.Value
 
 	// delayWindow is used to defer scale-down decisions until a time
 	// window has passed at the reduced concurrency.
 	delayWindow *max.TimeWindow
 
+	// lastRequest is the last request time as of the latest Scale.
+	lastRequest time.Time
+
 	// specMux guards the current DeciderSpec.
 	specMux     sync.RWMutex
 	deciderSpec *DeciderSpec
+
+	// decisionHistory keeps the recent scale decisions.
+	decisionHistory
 }
 
 // New creates a new instance of default autoscaler implementation.
@@ -112,7 +130,7 @@ func newAutoscaler(
 		pkgmetrics.Record(reporterCtx, panicM.M(0))
 	}
 
-	return &autoscaler{
+	a := &autoscaler{
 		namespace:    namespace,
 		revision:     revision,
 		metricClient: metricClient,
@@ -123,9 +141,12 @@ func newAutoscaler(
 
 		delayWindow: delayWindow,
 
-		panicTime:    pt,
-		maxPanicPods: int32(curC),
+		panicTime:      pt,
+		panicStartTime: pt,
+		maxPanicPods:   int32(curC),
 	}
+	a.publishedPanicTime.Store(pt)
+	return a
 }
 
 // Update reconfigures the UniScaler according to the DeciderSpec.
@@ -139,17 +160,19 @@ func (a *autoscaler) Update(deciderSpec *DeciderSpec) {
 // Scale calculates the desired scale based on current statistics given the current time.
 // desiredPodCount is the calculated pod count the autoscaler would like to set.
 // validScale signifies whether the desiredPodCount should be applied or not.
-// Scale is not thread safe in regards to panic state, but it's thread safe in
-// regards to acquiring the decider spec.
+// Scale is thread safe in regards to both the panic state and acquiring
+// the decider spec.
 func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+
 	logger := logging.FromContext(ctx)
 	desugared := logger.Desugar()
 	debugEnabled := desugared.Core().Enabled(zapcore.DebugLevel)
 
 	spec := a.currentSpec()
-	originalReadyPodsCount, err := a.podCounter.ReadyCount()
-	// If the error is NotFound, then presume 0.
-	if err != nil && !apierrors.IsNotFound(err) {
+	originalReadyPodsCount, err := readyPodCount(a.podCounter)
+	if err != nil {
 		logger.Errorw("Failed to get ready pod count via K8S Lister", zap.Error(err))
 		return invalidSR
 	}
@@ -157,17 +180,7 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
 
 	metricKey := types.NamespacedName{Namespace: a.namespace, Name: a.revision}
-
-	metricName := spec.ScalingMetric
-	var observedStableValue, observedPanicValue float64
-	switch spec.ScalingMetric {
-	case autoscaling.RPS:
-		observedStableValue, observedPanicValue, err = a.metricClient.StableAndPanicRPS(metricKey, now)
-	default:
-		metricName = autoscaling.Concurrency // concurrency is used by default
-		observedStableValue, observedPanicValue, err = a.metricClient.StableAndPanicConcurrency(metricKey, now)
-	}
-
+	observedStableValue, observedPanicValue, metricName, err := observedValues(a.metricClient, spec, metricKey, now)
 	if err != nil {
 		if errors.Is(err, metrics.ErrNoData) {
 			logger.Debug("No data to scale on yet")
@@ -177,20 +190,12 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 		return invalidSR
 	}
 
-	// Make sure we don't get stuck with the same number of pods, if the scale up rate
-	// is too conservative and MaxScaleUp*RPC==RPC, so this permits us to grow at least by a single
-	// pod if we need to scale up.
-	// E.g. MSUR=1.1, OCC=3, RPC=2, TV=1 => OCC/TV=3, MSU=2.2 => DSPC=2, while we definitely, need
-	// 3 pods. See the unit test for this scenario in action.
-	maxScaleUp := math.Ceil(spec.MaxScaleUpRate * readyPodsCount)
-	// Same logic, opposite math applies here.
-	maxScaleDown := 0.
-	if spec.Reachable {
-		maxScaleDown = math.Floor(readyPodsCount / spec.MaxScaleDownRate)
-	}
-
-	dspc := math.Ceil(observedStableValue / spec.TargetValue)
-	dppc := math.Ceil(observedPanicValue / spec.TargetValue)
+	maxScaleDown, maxScaleUp := scaleRateLimits(spec, readyPodsCount)
+	stableLoad := metricLoad(spec, observedStableValue, readyPodsCount)
+	panicLoad := metricLoad(spec, observedPanicValue, readyPodsCount)
+	fromZero := scaleFromZeroFloor(a.metricClient, spec, metricKey, now, originalReadyPodsCount)
+	dspc := math.Max(math.Ceil(stableLoad/spec.TargetValue), fromZero)
+	dppc := math.Max(math.Ceil(panicLoad/spec.TargetValue), fromZero)
 	if debugEnabled {
 		desugared.Debug(
 			fmt.Sprintf("For metric %s observed values: stable = %0.3f; panic = %0.3f; target = %0.3f "+
@@ -203,22 +208,48 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 	desiredStablePodCount := int32(math.Min(math.Max(dspc, maxScaleDown), maxScaleUp))
 	desiredPanicPodCount := int32(math.Min(math.Max(dppc, maxScaleDown), maxScaleUp))
 
-	isOverPanicThreshold := dppc/readyPodsCount >= spec.PanicThreshold
+	// Once panicking, the lower exit threshold applies, so that the load
+	// hovering around the panic threshold does not flap the panic mode.
+	panicThreshold := spec.PanicThreshold
+	if !a.panicTime.IsZero() {
+		panicThreshold = panicExitThreshold(spec)
+	}
+	isOverPanicThreshold := dppc/readyPodsCount >= panicThreshold
+	panicReason := panicReasonThreshold
+	if !isOverPanicThreshold && spec.PanicQueueDepth > 0 {
+		// Sustained queuing means the pods cannot keep up, whatever the load.
+		if _, panicQueued, err := a.metricClient.StableAndPanicQueueDepth(metricKey, now); err == nil &&
+			panicQueued/readyPodsCount >= spec.PanicQueueDepth {
+			logger.Debugf("Queue depth over the panic window %0.3f is over the panic queue depth for %d pods",
+				panicQueued, originalReadyPodsCount)
+			isOverPanicThreshold = true
+			panicReason = panicReasonQueueDepth
+		}
+	}
 
 	if a.panicTime.IsZero() && isOverPanicThreshold {
-		// Begin panicking when we cross the threshold in the panic window.
-		logger.Info("PANICKING.")
-		a.panicTime = now
-		pkgmetrics.Record(a.reporterCtx, panicM.M(1))
+		if cooldownEnd := a.panicExitTime.Add(spec.PanicCooldown); now.Before(cooldownEnd) {
+			logger.Debugf("Not panicking, in panic cooldown until %v", cooldownEnd)
+		} else {
+			// Begin panicking when we cross the threshold in the panic window.
+			logger.Info("PANICKING, reason: ", panicReason)
+			a.panicTime = now
+			a.panicStartTime = now
+			a.recordPanicTransition(true, panicReason)
+		}
 	} else if isOverPanicThreshold {
 		// If we're still over panic threshold right now — extend the panic window.
 		a.panicTime = now
-	} else if !a.panicTime.IsZero() && !isOverPanicThreshold && a.panicTime.Add(spec.StableWindow).Before(now) {
-		// Stop panicking after the surge has made its way into the stable metric.
-		logger.Info("Un-panicking.")
+	} else if !a.panicTime.IsZero() && a.panicTime.Add(spec.StableWindow).Before(now) &&
+		!now.Before(a.panicStartTime.Add(spec.PanicMinDuration)) {
+		// Stop panicking after the surge has made its way into the stable metric
+		// and panic mode lasted long enough.
+		logger.Info("Un-panicking, reason: ", panicReasonStabilized)
 		a.panicTime = time.Time{}
+		a.panicStartTime = time.Time{}
+		a.panicExitTime = now
 		a.maxPanicPods = 0
-		pkgmetrics.Record(a.reporterCtx, panicM.M(0))
+		a.recordPanicTransition(false, panicReasonStabilized)
 	}
 
 	desiredPodCount := desiredStablePodCount
@@ -241,74 +272,338 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 	} else {
 		logger.Debug("Operating in stable mode.")
 	}
+	a.publishedPanicTime.Store(a.panicTime)
 
 	// Delay scale down decisions, if a ScaleDownDelay was specified.
+	recommendedPodCount := desiredPodCount
+	if delayedPodCount := delayScaleDown(a.delayWindow, now, desiredPodCount); delayedPodCount != desiredPodCount {
+		if debugEnabled {
+			desugared.Debug(
+				fmt.Sprintf("Delaying scale to %d, staying at %d",
+					desiredPodCount, delayedPodCount))
+		}
+		desiredPodCount = delayedPodCount
+	}
+
+	if lastRequest, err := a.metricClient.LastRequestTime(metricKey); err != nil {
+		logger.Errorw("Failed to obtain the last request time", zap.Error(err))
+	} else {
+		a.lastRequest = lastRequest
+		// The time of the last request is unknown until a stat with requests
+		// arrives, e.g. for a new revision or after a restart that lost the
+		// state of the collection. The load decides until then.
+		if spec.IdleTimeout > 0 && !lastRequest.IsZero() {
+			desiredPodCount = idleScale(desiredPodCount, now.Sub(lastRequest), spec.IdleTimeout)
+		}
+	}
+
+	excessBCF, numAct := burstCapacity(spec, originalReadyPodsCount, panicLoad)
+	if debugEnabled {
+		desugared.Debug(fmt.Sprintf("PodCount=%d Total1PodCapacity=%0.3f ObsStableValue=%0.3f ObsPanicValue=%0.3f TargetBC=%0.3f ExcessBC=%0.3f NumActivators=%d",
+			originalReadyPodsCount, spec.TotalValue, observedStableValue,
+			observedPanicValue, spec.TargetBurstCapacity, excessBCF, numAct))
+	}
+
+	recordScaleMetrics(a.reporterCtx, spec, excessBCF, desiredPodCount, observedStableValue, observedPanicValue)
+	a.record(ScaleDecision{
+		Time:                now,
+		ObservedStableValue: observedStableValue,
+		ObservedPanicValue:  observedPanicValue,
+		TargetValue:         spec.TargetValue,
+		ReadyPodCount:       int32(originalReadyPodsCount),
+		MaxScaleUp:          int32(maxScaleUp),
+		MaxScaleDown:        int32(maxScaleDown),
+		InPanic:             !a.panicTime.IsZero(),
+		RecommendedPodCount: recommendedPodCount,
+		DesiredPodCount:     desiredPodCount,
+		ExcessBurstCapacity: int32(excessBCF),
+	})
+
+	return ScaleResult{
+		DesiredPodCount:     desiredPodCount,
+		ExcessBurstCapacity: int32(excessBCF),
+		NumActivators:       numAct,
+		ScaleValid:          true,
+	}
+}
+
+// idleScale returns the desired pod count given how long the revision went
+// without any request. The revision is scaled to zero once idle for the idle
+// timeout, however long the stable window still shows traffic, and it keeps
+// at least one pod until then.
+func idleScale(desiredPodCount int32, idle, idleTimeout time.Duration) int32 {
+	if idle >= idleTimeout {
+		return 0
+	}
+	if desiredPodCount < 1 {
+		return 1
+	}
+	return desiredPodCount
+}
+
+// recordPanicTransition records the new panic mode along with the reason
+// of the transition.
+func (a *autoscaler) recordPanicTransition(panicking bool, reason string) {
+	if panicking {
+		pkgmetrics.Record(a.reporterCtx, panicM.M(1))
+	} else {
+		pkgmetrics.Record(a.reporterCtx, panicM.M(0))
+	}
+	ctx, _ := tag.New(a.reporterCtx, tag.Upsert(panicReasonKey, reason))
+	pkgmetrics.Record(ctx, panicTransitionsM.M(1))
+}
+
+// panicExitThreshold returns the threshold below which panic mode is exited.
+func panicExitThreshold(spec *DeciderSpec) float64 {
+	if spec.PanicExitThreshold <= 0 || spec.PanicExitThreshold > spec.PanicThreshold {
+		return spec.PanicThreshold
+	}
+	return spec.PanicExitThreshold
+}
+
+// LastRequestTime implements LastRequestReporter.
+func (a *autoscaler) LastRequestTime() time.Time {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+	return a.lastRequest
+}
+
+// PanicTime implements PanicReporter.
+func (a *autoscaler) PanicTime() time.Time {
+	return a.publishedPanicTime.Load().(time.Time)
+}
+
+// Snapshot implements StateSnapshotter.
+func (a *autoscaler) Snapshot() *ScalerState {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+
+	ret := &ScalerState{
+		PanicTime:      a.panicTime,
+		PanicStartTime: a.panicStartTime,
+		PanicExitTime:  a.panicExitTime,
+		MaxPanicPods:   a.maxPanicPods,
+	}
+	if a.delayWindow != nil {
+		ret.ScaleDownDelay = a.delayWindow.State()
+	}
+	return ret
+}
+
+// Restore implements StateSnapshotter. The restored panic state replaces
+// the one the autoscaler started with, since with the restored metric
+// history there is no need to start in panic mode.
+func (a *autoscaler) Restore(state *ScalerState) {
+	a.stateMux.Lock()
+	defer a.stateMux.Unlock()
+
+	a.panicTime = state.PanicTime
+	a.panicStartTime = state.PanicStartTime
+	a.panicExitTime = state.PanicExitTime
+	a.maxPanicPods = state.MaxPanicPods
+	a.publishedPanicTime.Store(a.panicTime)
+	if a.panicTime.IsZero() {
+		pkgmetrics.Record(a.reporterCtx, panicM.M(0))
+	} else {
+		pkgmetrics.Record(a.reporterCtx, panicM.M(1))
+	}
+	if a.delayWindow != nil {
+		a.delayWindow.Restore(state.ScaleDownDelay)
+	}
+}
+
+func (a *autoscaler) currentSpec() *DeciderSpec {
+	a.specMux.RLock()
+	defer a.specMux.RUnlock()
+	return a.deciderSpec
+}
+
+// readyPodCount returns the number of ready pods of the revision.
+// A NotFound error is presumed to mean zero pods.
+func readyPodCount(pc podCounter) (int, error) {
+	n, err := pc.ReadyCount()
+	if err != nil && !apierrors.IsNotFound(err) {
+		return 0, err
+	}
+	return n, nil
+}
+
+// observedValues returns the stable and panic values of the scaling metric
+// of the spec as well as the name of the metric that was actually used.
+func observedValues(mc metrics.MetricClient, spec *DeciderSpec, key types.NamespacedName,
+	now time.Time) (float64, float64, string, error) {
+	switch {
+	case spec.ScalingMetric == autoscaling.RPS:
+		sv, pv, err := mc.StableAndPanicRPS(key, now)
+		return sv, pv, autoscaling.RPS, err
+	case spec.ScalingMetric == autoscaling.Latency:
+		sv, pv, err := mc.StableAndPanicLatency(key, now, spec.LatencyPercentile)
+		return sv, pv, autoscaling.Latency, err
+	case spec.ScalingMetric == autoscaling.CPU:
+		sv, pv, err := mc.StableAndPanicCPU(key, now)
+		return sv, pv, autoscaling.CPU, err
+	case spec.ScalingMetric == autoscaling.Memory:
+		sv, pv, err := mc.StableAndPanicMemory(key, now)
+		return sv, pv, autoscaling.Memory, err
+	case autoscaling.IsCustomMetric(spec.ScalingMetric):
+		sv, pv, err := mc.StableAndPanicCustomMetric(key, now)
+		return sv, pv, spec.ScalingMetric, err
+	default:
+		// concurrency is used by default
+		sv, pv, err := mc.StableAndPanicConcurrency(key, now)
+		return sv, pv, autoscaling.Concurrency, err
+	}
+}
+
+// metricLoad returns the load the observed value of the scaling metric puts
+// on the revision, in units of the target value. Unlike the other metrics
+// latency does not add up over the pods, so the revision needs proportionally
+// more pods the further the latency exceeds its target.
+func metricLoad(spec *DeciderSpec, observedValue, readyPodsCount float64) float64 {
+	if spec.ScalingMetric == autoscaling.Latency {
+		return observedValue * readyPodsCount
+	}
+	return observedValue
+}
+
+// scaleFromZeroFloor returns the minimum desired pod count while the revision
+// has no ready pods. Metrics reported by the pods themselves, like resource
+// utilization, stay at zero without pods, so requests buffered in the
+// activator have to bring up the first pod.
+func scaleFromZeroFloor(mc metrics.MetricClient, spec *DeciderSpec, key types.NamespacedName,
+	now time.Time, readyPodsCount int) float64 {
+	if readyPodsCount > 0 || !podReportedMetric(spec.ScalingMetric) {
+		return 0
+	}
+	// The activator reports the concurrency of the requests it holds.
+	if _, pc, err := mc.StableAndPanicConcurrency(key, now); err != nil || pc <= 0 {
+		return 0
+	}
+	return 1
+}
+
+// podReportedMetric returns true if the metric is only reported by the pods
+// of the revision and not by the activator.
+func podReportedMetric(metric string) bool {
+	switch metric {
+	case autoscaling.CPU, autoscaling.Memory, autoscaling.Latency:
+		return true
+	}
+	return autoscaling.IsCustomMetric(metric)
+}
+
+// scaleRateLimits returns the [maxScaleDown, maxScaleUp] range the desired
+// pod count must be kept in, given the current number of ready pods.
+func scaleRateLimits(spec *DeciderSpec, readyPodsCount float64) (float64, float64) {
+	// Make sure we don't get stuck with the same number of pods, if the scale up rate
+	// is too conservative and MaxScaleUp*RPC==RPC, so this permits us to grow at least by a single
+	// pod if we need to scale up.
+	// E.g. MSUR=1.1, OCC=3, RPC=2, TV=1 => OCC/TV=3, MSU=2.2 => DSPC=2, while we definitely, need
+	// 3 pods. See the unit test for this scenario in action.
+	maxScaleUp := math.Ceil(spec.MaxScaleUpRate * readyPodsCount)
+	// Same logic, opposite math applies here.
+	maxScaleDown := 0.
+	if spec.Reachable {
+		maxScaleDown = math.Floor(readyPodsCount / spec.MaxScaleDownRate)
+	}
+	return maxScaleDown, maxScaleUp
+}
+
+// delayScaleDown records the desired pod count in the delay window, if there
+// is one, and returns the pod count that should be used instead.
+func delayScaleDown(delayWindow *max.TimeWindow, now time.Time, desiredPodCount int32) int32 {
 	// We only do this if there's a non-nil delayWindow because although a
 	// one-element delay window is _almost_ the same as no delay at all, it is
 	// not the same in the case where two Scale()s happen in the same time
 	// interval (because the largest will be picked rather than the most recent
 	// in that case).
-	if a.delayWindow != nil {
-		a.delayWindow.Record(now, desiredPodCount)
-		delayedPodCount := a.delayWindow.Current()
-		if delayedPodCount != desiredPodCount {
-			if debugEnabled {
-				desugared.Debug(
-					fmt.Sprintf("Delaying scale to %d, staying at %d",
-						desiredPodCount, delayedPodCount))
-			}
-			desiredPodCount = delayedPodCount
-		}
+	if delayWindow == nil {
+		return desiredPodCount
 	}
+	delayWindow.Record(now, desiredPodCount)
+	return delayWindow.Current()
+}
 
-	// Here we compute two numbers: excess burst capacity and number of activators
-	// for subsetting.
-	// - the excess burst capacity is based on panic value, since we don't want to
-	//   be making knee-jerk decisions about Activator in the request path.
-	//   Negative EBC means that the deployment does not have enough capacity to serve
-	//   the desired burst off hand.
-	//   EBC = TotCapacity - Cur#ReqInFlight - TargetBurstCapacity
-	// - number of activators is based on total capacity and TargetBurstCapacity values.
-	//   if tbc==0, then activators are in play only for scale from 0 and the revision gets
-	//   the default number.
-	//   if tbc > 0, then revision gets number of activators to support total capacity and
-	//   tbc additional units.
-	//   if tbc==-1, then revision gets the number of activators needed to support total capacity.
-	//   With default target utilization of 0.7, we're overprovisioning number of needed activators
-	//   by rate of 1/0.7=1.42.
+// burstCapacity computes two numbers: excess burst capacity and number of activators
+// for subsetting.
+//   - the excess burst capacity is based on panic value, since we don't want to
+//     be making knee-jerk decisions about Activator in the request path.
+//     Negative EBC means that the deployment does not have enough capacity to serve
+//     the desired burst off hand.
+//     EBC = TotCapacity - Cur#ReqInFlight - TargetBurstCapacity
+//   - number of activators is based on total capacity and TargetBurstCapacity values.
+//     if tbc==0, then activators are in play only for scale from 0 and the revision gets
+//     the default number.
+//     if tbc > 0, then revision gets number of activators to support total capacity and
+//     tbc additional units.
+//     if tbc==-1, then revision gets the number of activators needed to support total capacity.
+//     With default target utilization of 0.7, we're overprovisioning number of needed activators
+//     by rate of 1/0.7=1.42.
+func burstCapacity(spec *DeciderSpec, readyPodsCount int, observedPanicValue float64) (float64, int32) {
 	excessBCF := -1.
 	numAct := int32(MinActivators)
 	switch {
-	case a.deciderSpec.TargetBurstCapacity == 0:
+	case spec.TargetBurstCapacity == 0:
 		excessBCF = 0
 		// numAct stays at MinActivators, only needed to scale from 0.
-	case a.deciderSpec.TargetBurstCapacity > 0:
-		totCap := float64(originalReadyPodsCount) * a.deciderSpec.TotalValue
-		excessBCF = math.Floor(totCap - a.deciderSpec.TargetBurstCapacity - observedPanicValue)
+	case spec.TargetBurstCapacity > 0:
+		totCap := float64(readyPodsCount) * spec.TotalValue
+		excessBCF = math.Floor(totCap - spec.TargetBurstCapacity - observedPanicValue)
 		numAct = int32(math.Max(MinActivators,
-			math.Ceil((totCap+a.deciderSpec.TargetBurstCapacity)/a.deciderSpec.ActivatorCapacity)))
-	case a.deciderSpec.TargetBurstCapacity == -1:
+			math.Ceil((totCap+spec.TargetBurstCapacity)/spec.ActivatorCapacity)))
+	case spec.TargetBurstCapacity == -1:
 		numAct = int32(math.Max(MinActivators,
-			math.Ceil(float64(originalReadyPodsCount)*a.deciderSpec.TotalValue/a.deciderSpec.ActivatorCapacity)))
-	}
-
-	if debugEnabled {
-		desugared.Debug(fmt.Sprintf("PodCount=%d Total1PodCapacity=%0.3f ObsStableValue=%0.3f ObsPanicValue=%0.3f TargetBC=%0.3f ExcessBC=%0.3f NumActivators=%d",
-			originalReadyPodsCount, a.deciderSpec.TotalValue, observedStableValue,
-			observedPanicValue, a.deciderSpec.TargetBurstCapacity, excessBCF, numAct))
+			math.Ceil(float64(readyPodsCount)*spec.TotalValue/spec.ActivatorCapacity)))
 	}
+	return excessBCF, numAct
+}
 
-	switch spec.ScalingMetric {
-	case autoscaling.RPS:
-		pkgmetrics.RecordBatch(a.reporterCtx,
+// recordScaleMetrics records the results of a single scaling evaluation.
+func recordScaleMetrics(reporterCtx context.Context, spec *DeciderSpec, excessBCF float64,
+	desiredPodCount int32, observedStableValue, observedPanicValue float64) {
+	switch {
+	case spec.ScalingMetric == autoscaling.RPS:
+		pkgmetrics.RecordBatch(reporterCtx,
 			excessBurstCapacityM.M(excessBCF),
 			desiredPodCountM.M(int64(desiredPodCount)),
 			stableRPSM.M(observedStableValue),
 			panicRPSM.M(observedStableValue),
 			targetRPSM.M(spec.TargetValue),
 		)
+	case spec.ScalingMetric == autoscaling.Latency:
+		pkgmetrics.RecordBatch(reporterCtx,
+			excessBurstCapacityM.M(excessBCF),
+			desiredPodCountM.M(int64(desiredPodCount)),
+			stableLatencyM.M(observedStableValue),
+			panicLatencyM.M(observedPanicValue),
+			targetLatencyM.M(spec.TargetValue),
+		)
+	case spec.ScalingMetric == autoscaling.CPU:
+		pkgmetrics.RecordBatch(reporterCtx,
+			excessBurstCapacityM.M(excessBCF),
+			desiredPodCountM.M(int64(desiredPodCount)),
+			stableCPUUtilizationM.M(observedStableValue),
+			panicCPUUtilizationM.M(observedPanicValue),
+			targetCPUUtilizationM.M(spec.TargetValue),
+		)
+	case spec.ScalingMetric == autoscaling.Memory:
+		pkgmetrics.RecordBatch(reporterCtx,
+			excessBurstCapacityM.M(excessBCF),
+			desiredPodCountM.M(int64(desiredPodCount)),
+			stableMemoryUtilizationM.M(observedStableValue),
+			panicMemoryUtilizationM.M(observedPanicValue),
+			targetMemoryUtilizationM.M(spec.TargetValue),
+		)
+	case autoscaling.IsCustomMetric(spec.ScalingMetric):
+		pkgmetrics.RecordBatch(reporterCtx,
+			excessBurstCapacityM.M(excessBCF),
+			desiredPodCountM.M(int64(desiredPodCount)),
+			stableCustomMetricM.M(observedStableValue),
+			panicCustomMetricM.M(observedPanicValue),
+			targetCustomMetricM.M(spec.TargetValue),
+		)
 	default:
-		pkgmetrics.RecordBatch(a.reporterCtx,
+		pkgmetrics.RecordBatch(reporterCtx,
 			excessBurstCapacityM.M(excessBCF),
 			desiredPodCountM.M(int64(desiredPodCount)),
 			stableRequestConcurrencyM.M(observedStableValue),
@@ -316,17 +611,4 @@ func (a *autoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
 			targetRequestConcurrencyM.M(spec.TargetValue),
 		)
 	}
-
-	return ScaleResult{
-		DesiredPodCount:     desiredPodCount,
-		ExcessBurstCapacity: int32(excessBCF),
-		NumActivators:       numAct,
-		ScaleValid:          true,
-	}
-}
-
-func (a *autoscaler) currentSpec() *DeciderSpec {
-	a.specMux.RLock()
-	defer a.specMux.RUnlock()
-	return a.deciderSpec
 }
@@ -31,6 +31,7 @@ import (
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 
+	"knative.dev/serving/pkg/autoscaler/aggregation/max"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 	smetrics "knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/resources"
@@ -137,6 +138,61 @@ func TestAutoscalerScaleDownDelay(t *testing.T) {
 	})
 }
 
+func TestAutoscalerSnapshotRestore(t *testing.T) {
+	metrics := &metricClient{}
+	spec := &DeciderSpec{
+		TargetValue:      10,
+		MaxScaleDownRate: 10,
+		MaxScaleUpRate:   10,
+		PanicThreshold:   2,
+		StableWindow:     time.Minute,
+		ScaleDownDelay:   5 * time.Minute,
+	}
+	old := New(TestContextWithLogger(t), testNamespace, testRevision, metrics, &fakePodCounter{}, spec)
+
+	now := time.Unix(1000, 0)
+	metrics.SetStableAndPanicConcurrency(40, 40)
+	expectScale(t, old, now, ScaleResult{
+		ScaleValid:      true,
+		DesiredPodCount: 4,
+		NumActivators:   2,
+	})
+	want := &ScalerState{
+		PanicTime:      now,
+		PanicStartTime: now,
+		MaxPanicPods:   4,
+		ScaleDownDelay: []max.Observation{{
+			Time:  now,
+			Value: 4,
+		}},
+	}
+	state := old.(StateSnapshotter).Snapshot()
+	if !cmp.Equal(state, want) {
+		t.Error("Snapshot mismatch (-want,+got):", cmp.Diff(want, state))
+	}
+
+	// The new autoscaler keeps the panic and the scale down delay of the old one.
+	as := New(TestContextWithLogger(t), testNamespace, testRevision, metrics, &fakePodCounter{}, spec)
+	as.(StateSnapshotter).Restore(state)
+	if got := as.(PanicReporter).PanicTime(); got != now {
+		t.Errorf("PanicTime() = %v, want: %v", got, now)
+	}
+	metrics.SetStableAndPanicConcurrency(0, 0)
+	expectScale(t, as, now.Add(2*time.Minute), ScaleResult{
+		ScaleValid:      true,
+		DesiredPodCount: 4,
+		NumActivators:   2,
+	})
+	if got := as.(PanicReporter).PanicTime(); !got.IsZero() {
+		t.Errorf("PanicTime() = %v, want zero time after the stable window", got)
+	}
+	expectScale(t, as, now.Add(5*time.Minute+2*time.Second), ScaleResult{
+		ScaleValid:      true,
+		DesiredPodCount: 0,
+		NumActivators:   2,
+	})
+}
+
 func TestAutoscalerScaleDownDelayZero(t *testing.T) {
 	pc := &fakePodCounter{}
 	metrics := &metricClient{}
@@ -243,6 +299,89 @@ func TestAutoscalerMetricsWithRPS(t *testing.T) {
 	metricstest.AssertMetric(t, wantMetrics...)
 }
 
+func TestAutoscalerMetricsWithCustomMetric(t *testing.T) {
+	defer reset()
+	metrics := &metricClient{StableCustom: 90, PanicCustom: 80, StableConcurrency: 1, PanicConcurrency: 1}
+	a, _ := newTestAutoscalerWithScalingMetric(10, 100, metrics, "queue_backlog", false /*startInPanic*/)
+	ebc := expectedEBC(10, 100, 80, 1)
+	na := expectedNA(a, 1)
+	expectScale(t, a, time.Now(), ScaleResult{9, ebc, na, true})
+	spec := a.currentSpec()
+
+	wantMetrics := []metricstest.Metric{
+		metricstest.FloatMetric(stableCustomMetricM.Name(), 90, nil).WithResource(wantResource),
+		metricstest.FloatMetric(panicCustomMetricM.Name(), 80, nil).WithResource(wantResource),
+		metricstest.IntMetric(desiredPodCountM.Name(), 9, nil).WithResource(wantResource),
+		metricstest.FloatMetric(targetCustomMetricM.Name(), spec.TargetValue, nil).WithResource(wantResource),
+		metricstest.FloatMetric(excessBurstCapacityM.Name(), float64(ebc), nil).WithResource(wantResource),
+		metricstest.IntMetric(panicM.Name(), 1, nil).WithResource(wantResource),
+	}
+	metricstest.AssertMetric(t, wantMetrics...)
+}
+
+func TestAutoscalerResourceUtilization(t *testing.T) {
+	defer reset()
+	metrics := &metricClient{StableCPU: 240, PanicCPU: 240, StableMemory: 30, PanicMemory: 30}
+	a, _ := newTestAutoscalerWithScalingMetric(80, 100, metrics, "cpu", false /*startInPanic*/)
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 3 {
+		t.Errorf("DesiredPodCount for cpu = %d, want: 3", got)
+	}
+	metricstest.AssertMetric(t,
+		metricstest.FloatMetric(stableCPUUtilizationM.Name(), 240, nil).WithResource(wantResource),
+		metricstest.FloatMetric(targetCPUUtilizationM.Name(), 80, nil).WithResource(wantResource))
+
+	a, _ = newTestAutoscalerWithScalingMetric(10, 100, metrics, "memory", false /*startInPanic*/)
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 3 {
+		t.Errorf("DesiredPodCount for memory = %d, want: 3", got)
+	}
+	metricstest.AssertMetric(t,
+		metricstest.FloatMetric(stableMemoryUtilizationM.Name(), 30, nil).WithResource(wantResource))
+}
+
+func TestAutoscalerLatency(t *testing.T) {
+	defer reset()
+	metrics := &metricClient{StableLatency: 300, PanicLatency: 300}
+	a, pc := newTestAutoscalerWithScalingMetric(200, 100, metrics, "latency", false /*startInPanic*/)
+	pc.readyCount = 2
+
+	// The p95 latency is 1.5 times the target, so 1.5 times the pods are needed.
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 3 {
+		t.Errorf("DesiredPodCount = %d, want: 3", got)
+	}
+	metricstest.AssertMetric(t,
+		metricstest.FloatMetric(stableLatencyM.Name(), 300, nil).WithResource(wantResource),
+		metricstest.FloatMetric(targetLatencyM.Name(), 200, nil).WithResource(wantResource))
+
+	metrics.StableLatency, metrics.PanicLatency = 50, 50
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 1 {
+		t.Errorf("DesiredPodCount = %d, want: 1", got)
+	}
+
+	// No requests, no latency.
+	pc.readyCount = 0
+	metrics.StableLatency, metrics.PanicLatency = 0, 0
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 0 {
+		t.Errorf("DesiredPodCount = %d, want: 0", got)
+	}
+}
+
+func TestAutoscalerResourceUtilizationScaleFromZero(t *testing.T) {
+	metrics := &metricClient{PanicConcurrency: 3}
+	a, pc := newTestAutoscalerWithScalingMetric(80, 100, metrics, "cpu", false /*startInPanic*/)
+	pc.readyCount = 0
+
+	// The activator holds requests, so the first pod must come up although
+	// there is no utilization to scale on.
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 1 {
+		t.Errorf("DesiredPodCount = %d, want: 1", got)
+	}
+
+	metrics.PanicConcurrency = 0
+	if got := a.Scale(TestContextWithLogger(t), time.Now()).DesiredPodCount; got != 0 {
+		t.Errorf("DesiredPodCount = %d, want: 0", got)
+	}
+}
+
 func TestAutoscalerStableModeIncreaseWithConcurrencyDefault(t *testing.T) {
 	metrics := &metricClient{StableConcurrency: 50.0, PanicConcurrency: 10}
 	a := newTestAutoscalerNoPC(10, 101, metrics)
@@ -277,6 +416,9 @@ func TestAutoscalerUnpanicAfterSlowIncrease(t *testing.T) {
 	if a.panicTime != tm {
 		t.Errorf("PanicTime = %v, want: %v", a.panicTime, tm)
 	}
+	if got := a.PanicTime(); got != tm {
+		t.Errorf("PanicTime() = %v, want: %v", got, tm)
+	}
 	// Now the half of the stable window has passed, and we've been adding 1 pod per cycle.
 	// For window of 60s, that's +15 pods.
 	pc.readyCount = 25 + 15
@@ -328,6 +470,110 @@ func TestAutoscalerExtendPanicWindow(t *testing.T) {
 	}
 }
 
+func TestAutoscalerPanicExitThreshold(t *testing.T) {
+	// The transitions are counted from the start of the test.
+	reset()
+	defer reset()
+	metrics := &metricClient{StableConcurrency: 20, PanicConcurrency: 50}
+	a, pc := newTestAutoscaler(10, 100, metrics)
+	pc.readyCount = 2
+	spec := *a.currentSpec()
+	spec.PanicExitThreshold = 1.5
+	a.Update(&spec)
+	ctx := TestContextWithLogger(t)
+
+	// 5 pods needed for 2 is over the panic threshold of 2.
+	start := time.Now()
+	a.Scale(ctx, start)
+	if a.panicTime != start {
+		t.Fatalf("PanicTime = %v, want: %v", a.panicTime, start)
+	}
+
+	// 3 pods needed for 2 is below the panic threshold but not the exit threshold.
+	metrics.SetStableAndPanicConcurrency(20, 25)
+	tm := start.Add(stableWindow + tickInterval)
+	a.Scale(ctx, tm)
+	if a.panicTime != tm {
+		t.Errorf("PanicTime = %v, want: %v", a.panicTime, tm)
+	}
+
+	// Below the exit threshold for a stable window exits.
+	metrics.SetStableAndPanicConcurrency(20, 15)
+	a.Scale(ctx, tm.Add(tickInterval))
+	a.Scale(ctx, tm.Add(stableWindow+2*tickInterval))
+	if !a.panicTime.IsZero() {
+		t.Errorf("PanicTime = %v, want: 0", a.panicTime)
+	}
+
+	transitions := metricstest.IntMetric(panicTransitionsM.Name(), 1,
+		map[string]string{panicReasonKey.Name(): panicReasonThreshold}).WithResource(wantResource)
+	transitions.Values = append(transitions.Values, metricstest.IntMetric(panicTransitionsM.Name(), 1,
+		map[string]string{panicReasonKey.Name(): panicReasonStabilized}).Values...)
+	metricstest.AssertMetric(t,
+		metricstest.IntMetric(panicM.Name(), 0, nil).WithResource(wantResource),
+		transitions)
+}
+
+func TestAutoscalerPanicMinDuration(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 20, PanicConcurrency: 50}
+	a, pc := newTestAutoscaler(10, 100, metrics)
+	pc.readyCount = 2
+	spec := *a.currentSpec()
+	spec.PanicMinDuration = 3 * stableWindow
+	a.Update(&spec)
+	ctx := TestContextWithLogger(t)
+
+	start := time.Now()
+	a.Scale(ctx, start)
+	if a.panicTime != start {
+		t.Fatalf("PanicTime = %v, want: %v", a.panicTime, start)
+	}
+
+	// The stable window passed, but not the minimum duration.
+	metrics.SetStableAndPanicConcurrency(20, 10)
+	a.Scale(ctx, start.Add(stableWindow+tickInterval))
+	if a.panicTime != start {
+		t.Errorf("PanicTime = %v, want: %v", a.panicTime, start)
+	}
+
+	a.Scale(ctx, start.Add(3*stableWindow))
+	if !a.panicTime.IsZero() {
+		t.Errorf("PanicTime = %v, want: 0", a.panicTime)
+	}
+}
+
+func TestAutoscalerPanicCooldown(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 20, PanicConcurrency: 50}
+	a, pc := newTestAutoscaler(10, 100, metrics)
+	pc.readyCount = 2
+	spec := *a.currentSpec()
+	spec.PanicCooldown = 2 * time.Minute
+	a.Update(&spec)
+	ctx := TestContextWithLogger(t)
+
+	start := time.Now()
+	a.Scale(ctx, start)
+	metrics.SetStableAndPanicConcurrency(20, 10)
+	exit := start.Add(stableWindow + tickInterval)
+	a.Scale(ctx, exit)
+	if !a.panicTime.IsZero() {
+		t.Fatalf("PanicTime = %v, want: 0", a.panicTime)
+	}
+
+	// The surge is back, but within the cooldown.
+	metrics.SetStableAndPanicConcurrency(20, 50)
+	a.Scale(ctx, exit.Add(tickInterval))
+	if !a.panicTime.IsZero() {
+		t.Errorf("PanicTime = %v, want: 0 during the cooldown", a.panicTime)
+	}
+
+	tm := exit.Add(2 * time.Minute)
+	a.Scale(ctx, tm)
+	if a.panicTime != tm {
+		t.Errorf("PanicTime = %v, want: %v", a.panicTime, tm)
+	}
+}
+
 func TestAutoscalerStableModeDecrease(t *testing.T) {
 	metrics := &metricClient{StableConcurrency: 100.0, PanicConcurrency: 100}
 	a, pc := newTestAutoscaler(10, 98, metrics)
@@ -527,6 +773,108 @@ func TestAutoscalerPanicThenUnPanicScaleDown(t *testing.T) {
 	expectScale(t, a, panicTime.Add(61*time.Second), ScaleResult{1, expectedEBC(10, 93, 1, 10), na, true})
 }
 
+func TestAutoscalerPanicOnQueueDepth(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 10, PanicConcurrency: 10, StableQueueDepth: 2, PanicQueueDepth: 2}
+	a, pc := newTestAutoscaler(10, 100, metrics)
+	pc.readyCount = 2
+
+	// Queuing is not a panic trigger unless configured.
+	now := time.Now()
+	expectScale(t, a, now, ScaleResult{1, expectedEBC(10, 100, 10, 2), expectedNA(a, 2), true})
+	if !a.panicTime.IsZero() {
+		t.Errorf("PanicTime = %v, want: 0", a.panicTime)
+	}
+
+	spec := *a.currentSpec()
+	spec.PanicQueueDepth = 1
+	a.Update(&spec)
+
+	// 2 requests queued for 2 pods is not over the panic queue depth.
+	metrics.PanicQueueDepth = 1.9
+	expectScale(t, a, now, ScaleResult{1, expectedEBC(10, 100, 10, 2), expectedNA(a, 2), true})
+	if !a.panicTime.IsZero() {
+		t.Errorf("PanicTime = %v, want: 0", a.panicTime)
+	}
+
+	// But 4 are, even though the concurrency is below the panic threshold.
+	metrics.PanicQueueDepth = 4
+	expectScale(t, a, now, ScaleResult{1, expectedEBC(10, 100, 10, 2), expectedNA(a, 2), true})
+	if a.panicTime != now {
+		t.Errorf("PanicTime = %v, want: %v", a.panicTime, now)
+	}
+}
+
+func TestAutoscalerIdleTimeout(t *testing.T) {
+	now := time.Now()
+	metrics := &metricClient{StableConcurrency: 5, PanicConcurrency: 0, LastRequest: now.Add(-time.Minute)}
+	a, _ := newTestAutoscaler(10, 100, metrics)
+	na := expectedNA(a, 1)
+
+	// Without an idle timeout the stable window decides.
+	expectScale(t, a, now, ScaleResult{1, expectedEBC(10, 100, 0, 1), na, true})
+	if got, want := a.LastRequestTime(), metrics.LastRequest; !got.Equal(want) {
+		t.Errorf("LastRequestTime = %v, want: %v", got, want)
+	}
+
+	spec := *a.currentSpec()
+	spec.IdleTimeout = 30 * time.Second
+	a.Update(&spec)
+
+	// Idle for longer than the idle timeout, though the stable window still shows traffic.
+	expectScale(t, a, now, ScaleResult{0, expectedEBC(10, 100, 0, 1), na, true})
+
+	// Not idle for long enough, though the stable window shows no traffic.
+	metrics.StableConcurrency = 0
+	metrics.LastRequest = now.Add(-10 * time.Second)
+	expectScale(t, a, now, ScaleResult{1, expectedEBC(10, 100, 0, 1), na, true})
+
+	// The load decides as long as the revision is not idle.
+	metrics.StableConcurrency = 25
+	expectScale(t, a, now, ScaleResult{3, expectedEBC(10, 100, 0, 1), na, true})
+}
+
+func TestAutoscalerIdleTimeoutUnknownLastRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		readyPods int
+		stable    float64
+		want      int32
+	}{{
+		// No stat with requests arrived yet.
+		name:   "brand-new collection",
+		stable: 5,
+		want:   1,
+	}, {
+		// The state of the collection was not restored, but the new scrapes
+		// show the load of the running pods.
+		name:      "restart without restored state",
+		readyPods: 3,
+		stable:    25,
+		want:      3,
+	}, {
+		name:      "no load",
+		readyPods: 1,
+		want:      0,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Now()
+			metrics := &metricClient{StableConcurrency: test.stable, PanicConcurrency: test.stable}
+			a, pc := newTestAutoscaler(10, 100, metrics)
+			pc.readyCount = test.readyPods
+			spec := *a.currentSpec()
+			spec.IdleTimeout = 30 * time.Second
+			a.Update(&spec)
+
+			// The zero last request time doesn't count as idle since forever.
+			if got := a.Scale(TestContextWithLogger(t), now); got.DesiredPodCount != test.want {
+				t.Errorf("DesiredPodCount = %d, want: %d", got.DesiredPodCount, test.want)
+			}
+		})
+	}
+}
+
 func TestAutoscalerRateLimitScaleUp(t *testing.T) {
 	metrics := &metricClient{StableConcurrency: 1000, PanicConcurrency: 1001}
 	a, pc := newTestAutoscaler(10, 61, metrics)
@@ -712,7 +1060,13 @@ func reset() {
 		panicRequestConcurrencyM.Name(),
 		targetRequestConcurrencyM.Name(),
 		stableRPSM.Name(), panicRPSM.Name(),
-		targetRPSM.Name(), panicM.Name())
+		targetRPSM.Name(), stableCustomMetricM.Name(),
+		panicCustomMetricM.Name(), targetCustomMetricM.Name(),
+		stableCPUUtilizationM.Name(), panicCPUUtilizationM.Name(),
+		targetCPUUtilizationM.Name(), stableMemoryUtilizationM.Name(),
+		panicMemoryUtilizationM.Name(), targetMemoryUtilizationM.Name(),
+		stableLatencyM.Name(), panicLatencyM.Name(), targetLatencyM.Name(),
+		panicM.Name(), panicTransitionsM.Name())
 	register()
 }
 
@@ -730,6 +1084,17 @@ type metricClient struct {
 	PanicConcurrency  float64
 	StableRPS         float64
 	PanicRPS          float64
+	StableCustom      float64
+	PanicCustom       float64
+	StableCPU         float64
+	PanicCPU          float64
+	StableMemory      float64
+	PanicMemory       float64
+	StableLatency     float64
+	PanicLatency      float64
+	StableQueueDepth  float64
+	PanicQueueDepth   float64
+	LastRequest       time.Time
 	ErrF              func(key types.NamespacedName, now time.Time) error
 }
 
@@ -758,6 +1123,61 @@ func (mc *metricClient) StableAndPanicRPS(key types.NamespacedName, now time.Tim
 	return mc.StableRPS, mc.PanicRPS, err
 }
 
+// StableAndPanicCustomMetric returns stable/panic custom metric values stored
+// in the object and the result of Errf as the error.
+func (mc *metricClient) StableAndPanicCustomMetric(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	var err error
+	if mc.ErrF != nil {
+		err = mc.ErrF(key, now)
+	}
+	return mc.StableCustom, mc.PanicCustom, err
+}
+
+// StableAndPanicCPU returns stable/panic CPU utilization stored in the object
+// and the result of Errf as the error.
+func (mc *metricClient) StableAndPanicCPU(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	var err error
+	if mc.ErrF != nil {
+		err = mc.ErrF(key, now)
+	}
+	return mc.StableCPU, mc.PanicCPU, err
+}
+
+// StableAndPanicMemory returns stable/panic memory utilization stored in the
+// object and the result of Errf as the error.
+func (mc *metricClient) StableAndPanicMemory(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	var err error
+	if mc.ErrF != nil {
+		err = mc.ErrF(key, now)
+	}
+	return mc.StableMemory, mc.PanicMemory, err
+}
+
+// StableAndPanicLatency returns stable/panic latency stored in the object
+// and the result of Errf as the error.
+func (mc *metricClient) StableAndPanicLatency(key types.NamespacedName, now time.Time, _ float64) (float64, float64, error) {
+	var err error
+	if mc.ErrF != nil {
+		err = mc.ErrF(key, now)
+	}
+	return mc.StableLatency, mc.PanicLatency, err
+}
+
+// StableAndPanicQueueDepth returns stable/panic queue depth stored in the
+// object and the result of Errf as the error.
+func (mc *metricClient) StableAndPanicQueueDepth(key types.NamespacedName, now time.Time) (float64, float64, error) {
+	var err error
+	if mc.ErrF != nil {
+		err = mc.ErrF(key, now)
+	}
+	return mc.StableQueueDepth, mc.PanicQueueDepth, err
+}
+
+// LastRequestTime returns the last request time stored in the object.
+func (mc *metricClient) LastRequestTime(types.NamespacedName) (time.Time, error) {
+	return mc.LastRequest, nil
+}
+
 func BenchmarkAutoscaler(b *testing.B) {
 	metrics := &metricClient{StableConcurrency: 50.0, PanicConcurrency: 10}
 	a := newTestAutoscalerNoPC(10, 101, metrics)
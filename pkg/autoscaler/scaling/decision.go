@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxScaleDecisions is the number of the most recent scale decisions
+// that are retained for every revision.
+const MaxScaleDecisions = 10
+
+// ScaleDecision records the inputs and the outcome of a single
+// autoscaler evaluation.
+type ScaleDecision struct {
+	// Time is when the decision was made.
+	Time time.Time
+
+	// ObservedStableValue and ObservedPanicValue are the values of the
+	// scaling metric over the stable and the panic windows.
+	ObservedStableValue float64
+	ObservedPanicValue  float64
+	// TargetValue is the target value of the scaling metric per pod.
+	TargetValue float64
+
+	// ReadyPodCount is the number of ready pods at the time of the decision.
+	ReadyPodCount int32
+	// MaxScaleUp and MaxScaleDown are the bounds the rate limits permitted.
+	MaxScaleUp   int32
+	MaxScaleDown int32
+
+	// InPanic is true if the autoscaler was in panic mode.
+	InPanic bool
+
+	// RecommendedPodCount is the pod count computed before the scale down
+	// delay was applied, DesiredPodCount the final one.
+	RecommendedPodCount int32
+	DesiredPodCount     int32
+
+	// ExcessBurstCapacity is the computed excess burst capacity.
+	ExcessBurstCapacity int32
+}
+
+// String returns a human readable explanation of the decision.
+func (d ScaleDecision) String() string {
+	mode := "stable"
+	if d.InPanic {
+		mode = "panic"
+	}
+	s := fmt.Sprintf("desired=%d in %s mode: observed stable=%0.3f panic=%0.3f target=%0.3f readyPods=%d maxScaleUp=%d maxScaleDown=%d excessBurstCapacity=%d",
+		d.DesiredPodCount, mode, d.ObservedStableValue, d.ObservedPanicValue, d.TargetValue,
+		d.ReadyPodCount, d.MaxScaleUp, d.MaxScaleDown, d.ExcessBurstCapacity)
+	if d.RecommendedPodCount != d.DesiredPodCount {
+		s += fmt.Sprintf(" (scale down to %d delayed)", d.RecommendedPodCount)
+	}
+	return s
+}
+
+// DecisionRecorder is implemented by the UniScalers that keep
+// a history of their recent scale decisions.
+type DecisionRecorder interface {
+	// Decisions returns the recent decisions, the oldest first.
+	Decisions() []ScaleDecision
+}
+
+// decisionHistory is a ring buffer of the last MaxScaleDecisions decisions.
+type decisionHistory struct {
+	mux       sync.Mutex
+	decisions [MaxScaleDecisions]ScaleDecision
+	// next is the index the next decision is written to and
+	// count the number of the recorded decisions.
+	next, count int
+}
+
+func (h *decisionHistory) record(d ScaleDecision) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.decisions[h.next] = d
+	h.next = (h.next + 1) % MaxScaleDecisions
+	if h.count < MaxScaleDecisions {
+		h.count++
+	}
+}
+
+// Decisions implements DecisionRecorder.
+func (h *decisionHistory) Decisions() []ScaleDecision {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	ret := make([]ScaleDecision, 0, h.count)
+	for i := h.next - h.count; i < h.next; i++ {
+		ret = append(ret, h.decisions[(i+MaxScaleDecisions)%MaxScaleDecisions])
+	}
+	return ret
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestDecisionHistory(t *testing.T) {
+	var h decisionHistory
+	if got := h.Decisions(); len(got) != 0 {
+		t.Errorf("Decisions() = %v, want none", got)
+	}
+
+	var want []int32
+	for i := int32(1); i <= MaxScaleDecisions+3; i++ {
+		h.record(ScaleDecision{DesiredPodCount: i})
+		want = append(want, i)
+		if len(want) > MaxScaleDecisions {
+			want = want[1:]
+		}
+
+		var got []int32
+		for _, d := range h.Decisions() {
+			got = append(got, d.DesiredPodCount)
+		}
+		if !cmp.Equal(got, want) {
+			t.Fatalf("After %d decisions: Decisions() = %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestScaleDecisionString(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision ScaleDecision
+		want     string
+	}{{
+		name: "stable",
+		decision: ScaleDecision{
+			ObservedStableValue: 20,
+			ObservedPanicValue:  25,
+			TargetValue:         10,
+			ReadyPodCount:       2,
+			MaxScaleUp:          20,
+			MaxScaleDown:        1,
+			RecommendedPodCount: 2,
+			DesiredPodCount:     2,
+			ExcessBurstCapacity: -5,
+		},
+		want: "desired=2 in stable mode: observed stable=20.000 panic=25.000 target=10.000 " +
+			"readyPods=2 maxScaleUp=20 maxScaleDown=1 excessBurstCapacity=-5",
+	}, {
+		name: "panic and delayed",
+		decision: ScaleDecision{
+			ObservedStableValue: 10,
+			ObservedPanicValue:  10,
+			TargetValue:         10,
+			ReadyPodCount:       4,
+			MaxScaleUp:          40,
+			InPanic:             true,
+			RecommendedPodCount: 1,
+			DesiredPodCount:     4,
+		},
+		want: "desired=4 in panic mode: observed stable=10.000 panic=10.000 target=10.000 " +
+			"readyPods=4 maxScaleUp=40 maxScaleDown=0 excessBurstCapacity=0 (scale down to 1 delayed)",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.decision.String(); got != test.want {
+				t.Errorf("String() = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAutoscalerRecordsDecisions(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 50, PanicConcurrency: 50}
+	a, pc := newTestAutoscaler(10, 101, metrics)
+	now := time.Now()
+	expectScale(t, a, now, ScaleResult{5, expectedEBC(10, 101, 50, 1), expectedNA(a, 1), true})
+
+	// The first decision starts the panic mode, since 5 >= 2 * 1.
+	pc.readyCount = 5
+	metrics.SetStableAndPanicConcurrency(100, 120)
+	now = now.Add(tickInterval)
+	a.Scale(TestContextWithLogger(t), now)
+
+	got := a.Decisions()
+	if len(got) != 2 {
+		t.Fatalf("len(Decisions()) = %d, want: 2", len(got))
+	}
+	want := ScaleDecision{
+		Time:                now,
+		ObservedStableValue: 100,
+		ObservedPanicValue:  120,
+		TargetValue:         10,
+		ReadyPodCount:       5,
+		MaxScaleUp:          50,
+		MaxScaleDown:        0,
+		InPanic:             true,
+		RecommendedPodCount: 12,
+		DesiredPodCount:     12,
+		ExcessBurstCapacity: expectedEBC(10, 101, 120, 5),
+	}
+	if !cmp.Equal(got[1], want, approxEquateInt32("ExcessBurstCapacity")) {
+		t.Error("Decision mismatch (-want,+got):", cmp.Diff(want, got[1]))
+	}
+}
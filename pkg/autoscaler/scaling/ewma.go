@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"math"
+	"time"
+
+	pkgmetrics "knative.dev/pkg/metrics"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/resources"
+)
+
+// ewmaAutoscaler scales on an exponentially weighted moving average of the
+// panic window metric value. The stable window is used as the time constant
+// of the average, so recent samples dominate while spikes are smoothed out.
+// It has no panic mode.
+type ewmaAutoscaler struct {
+	baseScaler
+
+	// average is the current smoothed metric value and last the time
+	// it was updated. Zero last means there were no samples yet.
+	average float64
+	last    time.Time
+}
+
+// NewEWMA creates a new instance of the EWMA autoscaler.
+func NewEWMA(
+	reporterCtx context.Context,
+	namespace, revision string,
+	metricClient metrics.MetricClient,
+	podCounter resources.EndpointsCounter,
+	deciderSpec *DeciderSpec) UniScaler {
+	pkgmetrics.Record(reporterCtx, panicM.M(0))
+	return &ewmaAutoscaler{
+		baseScaler: newBaseScaler(reporterCtx, namespace, revision, metricClient, podCounter, deciderSpec),
+	}
+}
+
+// Scale implements UniScaler.
+func (a *ewmaAutoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
+	spec := a.currentSpec()
+	readyPodsCount, stable, panicValue, ok := a.observe(ctx, spec, now)
+	if !ok {
+		return invalidSR
+	}
+
+	if a.last.IsZero() {
+		// Seed the average with the stable value, which already
+		// averages over the whole window.
+		a.average = stable
+	} else if dt := now.Sub(a.last); dt > 0 {
+		alpha := 1 - math.Exp(-float64(dt)/float64(spec.StableWindow))
+		a.average += alpha * (panicValue - a.average)
+	}
+	a.last = now
+
+	return a.finish(spec, now, readyPodsCount, a.average/spec.TargetValue, stable, panicValue)
+}
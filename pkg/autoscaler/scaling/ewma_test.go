@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	smetrics "knative.dev/serving/pkg/metrics"
+)
+
+func newTestAlgorithmSpec(targetValue float64) *DeciderSpec {
+	return &DeciderSpec{
+		ScalingMetric:     "concurrency",
+		TargetValue:       targetValue,
+		TotalValue:        targetValue / targetUtilization,
+		MaxScaleUpRate:    10,
+		MaxScaleDownRate:  10,
+		ActivatorCapacity: activatorCapacity,
+		StableWindow:      stableWindow,
+		Reachable:         true,
+	}
+}
+
+func newTestEWMA(metrics *metricClient, pc *fakePodCounter) UniScaler {
+	ctx := smetrics.RevisionContext(testNamespace, "testSvc", "testConfig", testRevision)
+	return NewEWMA(ctx, testNamespace, testRevision, metrics, pc, newTestAlgorithmSpec(10))
+}
+
+func TestEWMAScale(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 50, PanicConcurrency: 80}
+	pc := &fakePodCounter{readyCount: 1}
+	a := newTestEWMA(metrics, pc)
+
+	// The first evaluation is seeded with the stable value.
+	now := time.Now()
+	expectScale(t, a, now, ScaleResult{5, 0, MinActivators, true})
+
+	// After a whole stable window the average closed 1-1/e of the gap
+	// to the panic value: 50 + 0.632*(100-50) = 81.6.
+	pc.readyCount = 5
+	metrics.SetStableAndPanicConcurrency(60, 100)
+	now = now.Add(stableWindow)
+	expectScale(t, a, now, ScaleResult{9, 0, MinActivators, true})
+
+	// A short burst barely moves the average: 81.6 + 0.049*(400-81.6) = 97.2.
+	pc.readyCount = 9
+	metrics.SetStableAndPanicConcurrency(60, 400)
+	now = now.Add(3 * time.Second)
+	expectScale(t, a, now, ScaleResult{10, 0, MinActivators, true})
+
+	// And so does a short drop, which in particular does not scale to zero.
+	pc.readyCount = 10
+	metrics.SetStableAndPanicConcurrency(0, 0)
+	now = now.Add(3 * time.Second)
+	expectScale(t, a, now, ScaleResult{10, 0, MinActivators, true})
+}
+
+func TestEWMANoData(t *testing.T) {
+	metrics := &metricClient{
+		ErrF: func(types.NamespacedName, time.Time) error {
+			return errors.New("no metrics")
+		},
+	}
+	a := newTestEWMA(metrics, &fakePodCounter{readyCount: 1})
+	expectScale(t, a, time.Now(), invalidSR)
+}
@@ -21,8 +21,25 @@ import (
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 )
 
+// The reasons of the panic mode transitions.
+const (
+	// panicReasonThreshold is entering panic mode because the observed
+	// metric reached the panic threshold.
+	panicReasonThreshold = "threshold"
+	// panicReasonQueueDepth is entering panic mode because of sustained
+	// queuing of the requests.
+	panicReasonQueueDepth = "queue-depth"
+	// panicReasonStabilized is exiting panic mode because the observed
+	// metric stayed below the exit threshold for a stable window.
+	panicReasonStabilized = "stabilized"
+)
+
+// panicReasonKey is the tag key of the reason of a panic mode transition.
+var panicReasonKey = tag.MustNewKey("reason")
+
 var (
 	desiredPodCountM = stats.Int64(
 		"desired_pods",
@@ -56,10 +73,62 @@ var (
 		"target_requests_per_second",
 		"The desired requests-per-second for each pod",
 		stats.UnitDimensionless)
+	stableCustomMetricM = stats.Float64(
+		"stable_custom_metric",
+		"Total of the application-reported metric over the stable window",
+		stats.UnitDimensionless)
+	panicCustomMetricM = stats.Float64(
+		"panic_custom_metric",
+		"Total of the application-reported metric over the panic window",
+		stats.UnitDimensionless)
+	targetCustomMetricM = stats.Float64(
+		"target_custom_metric_per_pod",
+		"The desired value of the application-reported metric for each pod",
+		stats.UnitDimensionless)
+	stableCPUUtilizationM = stats.Float64(
+		"stable_cpu_utilization",
+		"Average CPU utilization in percent of the request per observed pod over the stable window",
+		stats.UnitDimensionless)
+	panicCPUUtilizationM = stats.Float64(
+		"panic_cpu_utilization",
+		"Average CPU utilization in percent of the request per observed pod over the panic window",
+		stats.UnitDimensionless)
+	targetCPUUtilizationM = stats.Float64(
+		"target_cpu_utilization",
+		"The desired CPU utilization in percent of the request for each pod",
+		stats.UnitDimensionless)
+	stableMemoryUtilizationM = stats.Float64(
+		"stable_memory_utilization",
+		"Average memory utilization in percent of the request per observed pod over the stable window",
+		stats.UnitDimensionless)
+	panicMemoryUtilizationM = stats.Float64(
+		"panic_memory_utilization",
+		"Average memory utilization in percent of the request per observed pod over the panic window",
+		stats.UnitDimensionless)
+	targetMemoryUtilizationM = stats.Float64(
+		"target_memory_utilization",
+		"The desired memory utilization in percent of the request for each pod",
+		stats.UnitDimensionless)
+	stableLatencyM = stats.Float64(
+		"stable_request_latency",
+		"Percentile of the request latencies over the stable window",
+		stats.UnitMilliseconds)
+	panicLatencyM = stats.Float64(
+		"panic_request_latency",
+		"Percentile of the request latencies over the panic window",
+		stats.UnitMilliseconds)
+	targetLatencyM = stats.Float64(
+		"target_request_latency",
+		"The desired percentile of the request latencies",
+		stats.UnitMilliseconds)
 	panicM = stats.Int64(
 		"panic_mode",
 		"1 if autoscaler is in panic mode, 0 otherwise",
 		stats.UnitDimensionless)
+	panicTransitionsM = stats.Int64(
+		"panic_mode_transitions",
+		"Number of times the autoscaler entered or exited panic mode, by reason",
+		stats.UnitDimensionless)
 )
 
 func init() {
@@ -101,6 +170,12 @@ func register() {
 			Measure:     panicM,
 			Aggregation: view.LastValue(),
 		},
+		&view.View{
+			Description: "Number of times the autoscaler entered or exited panic mode, by reason",
+			Measure:     panicTransitionsM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{panicReasonKey},
+		},
 		&view.View{
 			Description: "Average requests-per-second over the stable window",
 			Measure:     stableRPSM,
@@ -116,6 +191,66 @@ func register() {
 			Measure:     targetRPSM,
 			Aggregation: view.LastValue(),
 		},
+		&view.View{
+			Description: "Total of the application-reported metric over the stable window",
+			Measure:     stableCustomMetricM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Total of the application-reported metric over the panic window",
+			Measure:     panicCustomMetricM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "The desired value of the application-reported metric for each pod",
+			Measure:     targetCustomMetricM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Average CPU utilization per pod over the stable window",
+			Measure:     stableCPUUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Average CPU utilization per pod over the panic window",
+			Measure:     panicCPUUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "The desired CPU utilization for each pod",
+			Measure:     targetCPUUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Average memory utilization per pod over the stable window",
+			Measure:     stableMemoryUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Average memory utilization per pod over the panic window",
+			Measure:     panicMemoryUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "The desired memory utilization for each pod",
+			Measure:     targetMemoryUtilizationM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Percentile of the request latencies over the stable window",
+			Measure:     stableLatencyM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "Percentile of the request latencies over the panic window",
+			Measure:     panicLatencyM,
+			Aggregation: view.LastValue(),
+		},
+		&view.View{
+			Description: "The desired percentile of the request latencies",
+			Measure:     targetLatencyM,
+			Aggregation: view.LastValue(),
+		},
 	); err != nil {
 		panic(err)
 	}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler/aggregation/max"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 )
 
@@ -45,6 +46,9 @@ type Decider struct {
 
 // DeciderSpec is the parameters by which the Revision should be scaled.
 type DeciderSpec struct {
+	// Algorithm is the name of the registered scaling algorithm to use,
+	// i.e. stable-panic, ewma, pid.
+	Algorithm        string
 	MaxScaleUpRate   float64
 	MaxScaleDownRate float64
 	// The metric used for scaling, i.e. concurrency, rps.
@@ -54,6 +58,9 @@ type DeciderSpec struct {
 	TargetValue float64
 	// The total value of scaling metric that a pod can maintain.
 	TotalValue float64
+	// LatencyPercentile is the percentile of the request latencies the
+	// latency metric observes, i.e. 95 for the p95 latency.
+	LatencyPercentile float64
 	// The burst capacity that user wants to maintain without queuing at the POD level.
 	// Note, that queueing still might happen due to the non-ideal load balancing.
 	TargetBurstCapacity float64
@@ -64,11 +71,28 @@ type DeciderSpec struct {
 	// pods. I.e. if this is 2, panic mode will be entered if the observed metric
 	// is twice as high as the current population can handle.
 	PanicThreshold float64
+	// PanicExitThreshold is the ratio of the observed metric to the capacity
+	// of the pods below which panic mode is exited. Zero or a value over
+	// PanicThreshold means PanicThreshold.
+	PanicExitThreshold float64
+	// PanicMinDuration is the minimum time panic mode lasts once entered.
+	PanicMinDuration time.Duration
+	// PanicCooldown is the time after exiting panic mode during which
+	// it is not entered again.
+	PanicCooldown time.Duration
+	// PanicQueueDepth is the average number of requests per ready pod waiting
+	// for a free slot over the panic window at which panic mode is entered.
+	// Zero disables it.
+	PanicQueueDepth float64
 	// StableWindow is needed to determine when to exit panic mode.
 	StableWindow time.Duration
 	// ScaleDownDelay is the time that must pass at reduced concurrency before a
 	// scale-down decision is applied.
 	ScaleDownDelay time.Duration
+	// IdleTimeout is the time without any request after which the revision
+	// is scaled to zero. When non-zero, it rather than the observed metric
+	// decides whether the revision can be scaled to zero.
+	IdleTimeout time.Duration
 	// InitialScale is the calculated initial scale of the revision, taking both
 	// revision initial scale and cluster initial scale into account. Revision initial
 	// scale overrides cluster initial scale.
@@ -93,6 +117,67 @@ type DeciderStatus struct {
 	// NumActivators is the computed number of activators
 	// necessary to back the revision.
 	NumActivators int32
+
+	// LastRequestTime is the last time any request to the revision was
+	// observed, or the zero time if none was yet.
+	// Only populated if the UniScaler is a LastRequestReporter.
+	LastRequestTime metav1.Time
+
+	// Decisions are the most recent scale decisions, the oldest first.
+	// Only populated if the UniScaler is a DecisionRecorder.
+	Decisions []ScaleDecision
+}
+
+// PanicReporter is implemented by the UniScalers that have a panic mode.
+type PanicReporter interface {
+	// PanicTime returns the time the current panic mode was last extended,
+	// or zero time if the UniScaler is not panicking.
+	PanicTime() time.Time
+}
+
+// LastRequestReporter is implemented by the UniScalers that keep track of
+// the requests to the revision.
+type LastRequestReporter interface {
+	// LastRequestTime returns the last time any request to the revision
+	// was observed, or zero time if none was yet.
+	LastRequestTime() time.Time
+}
+
+// ScalerState is the state of a UniScaler needed to resume its decisions
+// elsewhere, e.g. when another autoscaler takes over the revision.
+type ScalerState struct {
+	// PanicTime is the time panic mode was last extended, zero time if
+	// the UniScaler is not panicking.
+	PanicTime time.Time `json:"panicTime,omitempty"`
+	// PanicStartTime is the time the current panic mode was entered, zero
+	// time if the UniScaler is not panicking.
+	PanicStartTime time.Time `json:"panicStartTime,omitempty"`
+	// PanicExitTime is the time panic mode was last exited.
+	PanicExitTime time.Time `json:"panicExitTime,omitempty"`
+	// MaxPanicPods is the highest pod count decided on while panicking.
+	MaxPanicPods int32 `json:"maxPanicPods,omitempty"`
+	// ScaleDownDelay are the pod counts decided on that can still
+	// delay a scale down.
+	ScaleDownDelay []max.Observation `json:"scaleDownDelay,omitempty"`
+}
+
+// StateSnapshotter is implemented by the UniScalers whose state can be
+// carried over to another instance of them.
+type StateSnapshotter interface {
+	// Snapshot returns a copy of the current state.
+	Snapshot() *ScalerState
+	// Restore resumes from the given state.
+	Restore(*ScalerState)
+}
+
+// DeciderState is a point in time copy of the state of a Decider,
+// intended for debugging.
+type DeciderState struct {
+	// Decider holds the current spec and the latest scale.
+	Decider *Decider
+	// PanicTime is the time panic mode was last extended, zero time if
+	// the Decider is not panicking or its algorithm has no panic mode.
+	PanicTime time.Time
 }
 
 // ScaleResult holds the scale result of the UniScaler evaluation cycle.
@@ -143,6 +228,26 @@ func (sr *scalerRunner) latestScale() int32 {
 	return sr.decider.Status.DesiredScale
 }
 
+func (sr *scalerRunner) algorithm() string {
+	sr.mux.RLock()
+	defer sr.mux.RUnlock()
+	return sr.decider.Spec.Algorithm
+}
+
+func (sr *scalerRunner) updateDecisions(decisions []ScaleDecision) {
+	sr.mux.Lock()
+	defer sr.mux.Unlock()
+	sr.decider.Status.Decisions = decisions
+}
+
+// updateLastRequestTime does not inform the watcher, the time of the last
+// request is reported the next time the PA is reconciled anyway.
+func (sr *scalerRunner) updateLastRequestTime(t time.Time) {
+	sr.mux.Lock()
+	defer sr.mux.Unlock()
+	sr.decider.Status.LastRequestTime = metav1.NewTime(t)
+}
+
 func sameSign(a, b int32) bool {
 	return (a&math.MinInt32)^(b&math.MinInt32) == 0
 }
@@ -172,6 +277,8 @@ func (sr *scalerRunner) updateLatestScale(sRes ScaleResult) bool {
 type MultiScaler struct {
 	scalersMutex sync.RWMutex
 	scalers      map[types.NamespacedName]*scalerRunner
+	// restored holds the states restored before their scaler was created.
+	restored map[types.NamespacedName]*ScalerState
 
 	scalersStopCh <-chan struct{}
 
@@ -192,6 +299,7 @@ func NewMultiScaler(
 	logger *zap.SugaredLogger) *MultiScaler {
 	return &MultiScaler{
 		scalers:          make(map[types.NamespacedName]*scalerRunner),
+		restored:         make(map[types.NamespacedName]*ScalerState),
 		scalersStopCh:    stopCh,
 		uniScalerFactory: uniScalerFactory,
 		logger:           logger,
@@ -214,6 +322,24 @@ func (m *MultiScaler) Get(_ context.Context, namespace, name string) (*Decider,
 	return scaler.decider.DeepCopy(), nil
 }
 
+// State returns the current state of the Decider with the given key
+// or false if there is no such Decider.
+func (m *MultiScaler) State(key types.NamespacedName) (*DeciderState, bool) {
+	m.scalersMutex.RLock()
+	defer m.scalersMutex.RUnlock()
+	scaler, exists := m.scalers[key]
+	if !exists {
+		return nil, false
+	}
+	scaler.mux.RLock()
+	defer scaler.mux.RUnlock()
+	ret := &DeciderState{Decider: scaler.decider.DeepCopy()}
+	if pr, ok := scaler.scaler.(PanicReporter); ok {
+		ret.PanicTime = pr.PanicTime()
+	}
+	return ret, true
+}
+
 // Create instantiates the desired Decider.
 func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, error) {
 	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
@@ -226,6 +352,12 @@ func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, e
 		if err != nil {
 			return nil, err
 		}
+		if state, ok := m.restored[key]; ok {
+			if ss, ok := scaler.scaler.(StateSnapshotter); ok {
+				ss.Restore(state)
+			}
+			delete(m.restored, key)
+		}
 		m.scalers[key] = scaler
 	}
 	scaler.mux.RLock()
@@ -235,11 +367,25 @@ func (m *MultiScaler) Create(ctx context.Context, decider *Decider) (*Decider, e
 }
 
 // Update applies the desired DeciderSpec to a currently running Decider.
-func (m *MultiScaler) Update(_ context.Context, decider *Decider) (*Decider, error) {
+func (m *MultiScaler) Update(ctx context.Context, decider *Decider) (*Decider, error) {
 	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
 	m.scalersMutex.Lock()
 	defer m.scalersMutex.Unlock()
 	if scaler, exists := m.scalers[key]; exists {
+		if scaler.algorithm() != decider.Spec.Algorithm {
+			// The algorithm state is not transferable, so replace the scaler,
+			// but keep reporting the last decision until the new one makes one.
+			runner, err := m.createScaler(ctx, decider)
+			if err != nil {
+				return nil, err
+			}
+			scaler.mux.RLock()
+			runner.decider.Status = scaler.decider.Status
+			scaler.mux.RUnlock()
+			close(scaler.stopCh)
+			m.scalers[key] = runner
+			return decider, nil
+		}
 		scaler.mux.Lock()
 		defer scaler.mux.Unlock()
 		// Make sure we store the copy.
@@ -260,6 +406,39 @@ func (m *MultiScaler) Delete(_ context.Context, namespace, name string) {
 		close(scaler.stopCh)
 		delete(m.scalers, key)
 	}
+	delete(m.restored, key)
+}
+
+// Snapshot returns the state of the scaler of the Decider with the given key
+// or false if there is no such Decider or its scaler has no state to carry over.
+func (m *MultiScaler) Snapshot(key types.NamespacedName) (*ScalerState, bool) {
+	m.scalersMutex.RLock()
+	defer m.scalersMutex.RUnlock()
+	scaler, exists := m.scalers[key]
+	if !exists {
+		return nil, false
+	}
+	ss, ok := scaler.scaler.(StateSnapshotter)
+	if !ok {
+		return nil, false
+	}
+	return ss.Snapshot(), true
+}
+
+// Restore restores the given state into the scaler of the Decider with the
+// given key. If there is no such Decider yet, the state is restored when it
+// is created.
+func (m *MultiScaler) Restore(key types.NamespacedName, state *ScalerState) {
+	m.scalersMutex.Lock()
+	defer m.scalersMutex.Unlock()
+	scaler, exists := m.scalers[key]
+	if !exists {
+		m.restored[key] = state
+		return
+	}
+	if ss, ok := scaler.scaler.(StateSnapshotter); ok {
+		ss.Restore(state)
+	}
 }
 
 // Watch registers a singleton function to call when DeciderStatus is updated.
@@ -339,6 +518,12 @@ func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *
 		return
 	}
 
+	if dr, ok := scaler.(DecisionRecorder); ok {
+		runner.updateDecisions(dr.Decisions())
+	}
+	if lr, ok := scaler.(LastRequestReporter); ok {
+		runner.updateLastRequestTime(lr.LastRequestTime())
+	}
 	if runner.updateLatestScale(sr) {
 		m.Inform(metricKey)
 	}
@@ -24,12 +24,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/types"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler/fake"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 )
@@ -394,6 +396,177 @@ func TestMultiScalerUpdate(t *testing.T) {
 	ms.Delete(ctx, decider.Namespace, decider.Name)
 }
 
+func TestMultiScalerUpdateAlgorithm(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var created int
+	uniScaler := &fakeUniScaler{}
+	ms := NewMultiScaler(ctx.Done(), func(*Decider) (UniScaler, error) {
+		created++
+		return uniScaler, nil
+	}, TestLogger(t))
+
+	decider := newDecider()
+	decider.Spec.Algorithm = autoscaling.AlgorithmStablePanic
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatal("Create() =", err)
+	}
+
+	// Same algorithm, the scaler is updated in place.
+	decider.Spec.TargetValue = 10
+	if _, err := ms.Update(ctx, decider); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if created != 1 {
+		t.Errorf("Created %d UniScalers, want: 1", created)
+	}
+	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
+	ms.scalers[key].updateLatestScale(ScaleResult{DesiredPodCount: 5, ScaleValid: true})
+
+	// Different algorithm, the scaler is replaced, but the status is kept.
+	decider.Spec.Algorithm = autoscaling.AlgorithmEWMA
+	if _, err := ms.Update(ctx, decider); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if created != 2 {
+		t.Errorf("Created %d UniScalers, want: 2", created)
+	}
+	m, err := ms.Get(ctx, decider.Namespace, decider.Name)
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got, want := m.Spec.Algorithm, autoscaling.AlgorithmEWMA; got != want {
+		t.Errorf("Algorithm = %q, want: %q", got, want)
+	}
+	if got, want := m.Status.DesiredScale, int32(5); got != want {
+		t.Errorf("DesiredScale = %d, want: %d", got, want)
+	}
+	ms.Delete(ctx, decider.Namespace, decider.Name)
+}
+
+type fakePanickingUniScaler struct {
+	fakeUniScaler
+	panicTime time.Time
+}
+
+func (u *fakePanickingUniScaler) PanicTime() time.Time {
+	return u.panicTime
+}
+
+func TestMultiScalerState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	panicTime := time.Now()
+	uniScaler := &fakePanickingUniScaler{panicTime: panicTime}
+	ms := NewMultiScaler(ctx.Done(), func(*Decider) (UniScaler, error) {
+		return uniScaler, nil
+	}, TestLogger(t))
+
+	decider := newDecider()
+	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
+	if _, ok := ms.State(key); ok {
+		t.Error("State() = true for a Decider that does not exist")
+	}
+
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatal("Create() =", err)
+	}
+	state, ok := ms.State(key)
+	if !ok {
+		t.Fatal("State() = false, want true")
+	}
+	if got, want := state.Decider.Spec.TargetValue, decider.Spec.TargetValue; got != want {
+		t.Errorf("TargetValue = %v, want: %v", got, want)
+	}
+	if got, want := state.Decider.Status.DesiredScale, int32(-1); got != want {
+		t.Errorf("DesiredScale = %d, want: %d", got, want)
+	}
+	if got, want := state.PanicTime, panicTime; got != want {
+		t.Errorf("PanicTime = %v, want: %v", got, want)
+	}
+	ms.Delete(ctx, decider.Namespace, decider.Name)
+}
+
+type fakeLastRequestUniScaler struct {
+	fakeUniScaler
+	lastRequest time.Time
+}
+
+func (u *fakeLastRequestUniScaler) LastRequestTime() time.Time {
+	return u.lastRequest
+}
+
+func TestMultiScalerLastRequestTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lastRequest := time.Now().Add(-time.Minute)
+	uniScaler := &fakeLastRequestUniScaler{lastRequest: lastRequest}
+	uniScaler.setScaleResult(1, 1, 2, true)
+	ms := NewMultiScaler(ctx.Done(), uniScaler.fakeUniScalerFactory, TestLogger(t))
+
+	decider := newDecider()
+	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
+	runner := &scalerRunner{scaler: uniScaler, decider: decider}
+	ms.tickScaler(ctx, uniScaler, runner, key)
+
+	if got := runner.decider.Status.LastRequestTime; !got.Time.Equal(lastRequest) {
+		t.Errorf("LastRequestTime = %v, want: %v", got, lastRequest)
+	}
+}
+
+type fakeStatefulUniScaler struct {
+	fakeUniScaler
+	state *ScalerState
+}
+
+func (u *fakeStatefulUniScaler) Snapshot() *ScalerState {
+	return u.state
+}
+
+func (u *fakeStatefulUniScaler) Restore(state *ScalerState) {
+	u.state = state
+}
+
+func TestMultiScalerSnapshotRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uniScaler := &fakeStatefulUniScaler{}
+	ms := NewMultiScaler(ctx.Done(), func(*Decider) (UniScaler, error) {
+		return uniScaler, nil
+	}, TestLogger(t))
+
+	decider := newDecider()
+	key := types.NamespacedName{Namespace: decider.Namespace, Name: decider.Name}
+	if _, ok := ms.Snapshot(key); ok {
+		t.Error("Snapshot() = true for a Decider that does not exist")
+	}
+
+	// The state restored before the Decider exists is restored on creation.
+	want := &ScalerState{PanicTime: time.Now(), MaxPanicPods: 5}
+	ms.Restore(key, want)
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatal("Create() =", err)
+	}
+	state, ok := ms.Snapshot(key)
+	if !ok {
+		t.Fatal("Snapshot() = false, want true")
+	}
+	if !cmp.Equal(state, want) {
+		t.Error("Snapshot mismatch (-want,+got):", cmp.Diff(want, state))
+	}
+
+	want = &ScalerState{MaxPanicPods: 3}
+	ms.Restore(key, want)
+	if state, _ := ms.Snapshot(key); !cmp.Equal(state, want) {
+		t.Error("Snapshot mismatch (-want,+got):", cmp.Diff(want, state))
+	}
+	ms.Delete(ctx, decider.Namespace, decider.Name)
+}
+
 func createMultiScaler(ctx context.Context, l *zap.SugaredLogger) (*MultiScaler, *fakeUniScaler) {
 	uniscaler := &fakeUniScaler{}
 	ms := NewMultiScaler(ctx.Done(), uniscaler.fakeUniScalerFactory, l)
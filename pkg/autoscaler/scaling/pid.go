@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"math"
+	"time"
+
+	pkgmetrics "knative.dev/pkg/metrics"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/resources"
+)
+
+const (
+	// The gains of the PID controller. With pidKp = 1 and no history the
+	// controller reproduces the proportional observed/target computation.
+	pidKp = 1.0
+	pidKi = 0.1
+	pidKd = 0.05
+
+	// pidMaxIntegral bounds the accumulated error to prevent windup
+	// while the scale is pinned by the bounds or the rate limits.
+	pidMaxIntegral = 5.0
+)
+
+// pidAutoscaler computes the desired scale with a PID controller acting on
+// the relative error between the observed per pod value and the target.
+// It has no panic mode.
+type pidAutoscaler struct {
+	baseScaler
+
+	// integral is the accumulated error, prevErr the error and last
+	// the time of the previous evaluation.
+	integral float64
+	prevErr  float64
+	last     time.Time
+}
+
+// NewPID creates a new instance of the PID autoscaler.
+func NewPID(
+	reporterCtx context.Context,
+	namespace, revision string,
+	metricClient metrics.MetricClient,
+	podCounter resources.EndpointsCounter,
+	deciderSpec *DeciderSpec) UniScaler {
+	pkgmetrics.Record(reporterCtx, panicM.M(0))
+	return &pidAutoscaler{
+		baseScaler: newBaseScaler(reporterCtx, namespace, revision, metricClient, podCounter, deciderSpec),
+	}
+}
+
+// Scale implements UniScaler.
+func (a *pidAutoscaler) Scale(ctx context.Context, now time.Time) ScaleResult {
+	spec := a.currentSpec()
+	readyPodsCount, stable, panicValue, ok := a.observe(ctx, spec, now)
+	if !ok {
+		return invalidSR
+	}
+
+	if readyPodsCount == 0 {
+		// There is nothing to correct yet, size for the observed value directly.
+		a.integral, a.prevErr, a.last = 0, 0, now
+		return a.finish(spec, now, readyPodsCount, stable/spec.TargetValue, stable, panicValue)
+	}
+
+	pods := float64(readyPodsCount)
+	e := (stable/pods - spec.TargetValue) / spec.TargetValue
+	var derivative float64
+	if !a.last.IsZero() {
+		if dt := now.Sub(a.last).Seconds(); dt > 0 {
+			a.integral = math.Max(-pidMaxIntegral, math.Min(pidMaxIntegral, a.integral+e*dt))
+			derivative = (e - a.prevErr) / dt
+		}
+	}
+	a.prevErr, a.last = e, now
+
+	desired := pods * (1 + pidKp*e + pidKi*a.integral + pidKd*derivative)
+	return a.finish(spec, now, readyPodsCount, math.Max(0, desired), stable, panicValue)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	smetrics "knative.dev/serving/pkg/metrics"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func newTestPID(metrics *metricClient, pc *fakePodCounter) UniScaler {
+	ctx := smetrics.RevisionContext(testNamespace, "testSvc", "testConfig", testRevision)
+	return NewPID(ctx, testNamespace, testRevision, metrics, pc, newTestAlgorithmSpec(10))
+}
+
+func TestPIDScale(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 30, PanicConcurrency: 30}
+	pc := &fakePodCounter{}
+	a := newTestPID(metrics, pc)
+
+	// Without pods the observed value is used directly.
+	now := time.Now()
+	expectScale(t, a, now, ScaleResult{3, 0, MinActivators, true})
+
+	// At the target the scale is kept.
+	pc.readyCount = 5
+	metrics.SetStableAndPanicConcurrency(50, 50)
+	now = now.Add(time.Second)
+	expectScale(t, a, now, ScaleResult{5, 0, MinActivators, true})
+
+	// Doubling of the load: e=1, integral=1, derivative=1,
+	// 5 * (1 + 1 + 0.1 + 0.05) = 10.75.
+	metrics.SetStableAndPanicConcurrency(100, 100)
+	now = now.Add(time.Second)
+	expectScale(t, a, now, ScaleResult{11, 0, MinActivators, true})
+
+	// Back at the target the accumulated error keeps the scale above
+	// the proportional value for a while: 11 * (1 + 0.1 - 0.05) = 11.55.
+	pc.readyCount = 11
+	metrics.SetStableAndPanicConcurrency(110, 110)
+	now = now.Add(time.Second)
+	expectScale(t, a, now, ScaleResult{12, 0, MinActivators, true})
+}
+
+func TestPIDIntegralClamped(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 100, PanicConcurrency: 100}
+	pc := &fakePodCounter{readyCount: 1}
+	a := newTestPID(metrics, pc).(*pidAutoscaler)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		a.Scale(TestContextWithLogger(t), now)
+		now = now.Add(time.Minute)
+	}
+	if a.integral != pidMaxIntegral {
+		t.Errorf("integral = %v, want: %v", a.integral, pidMaxIntegral)
+	}
+}
+
+func TestPIDCantCountPods(t *testing.T) {
+	metrics := &metricClient{StableConcurrency: 100, PanicConcurrency: 100}
+	a := newTestPID(metrics, &fakePodCounter{err: errors.New("failed to get endpoints")})
+	expectScale(t, a, time.Now(), invalidSR)
+}
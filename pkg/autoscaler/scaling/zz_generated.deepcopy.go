@@ -25,7 +25,7 @@ func (in *Decider) DeepCopyInto(out *Decider) {
 	*out = *in
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -38,3 +38,24 @@ func (in *Decider) DeepCopy() *Decider {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeciderStatus) DeepCopyInto(out *DeciderStatus) {
+	*out = *in
+	if in.Decisions != nil {
+		in, out := &in.Decisions, &out.Decisions
+		*out = make([]ScaleDecision, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeciderStatus.
+func (in *DeciderStatus) DeepCopy() *DeciderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeciderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statestore
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	pkgmetrics "knative.dev/pkg/metrics"
+)
+
+var (
+	saveFailuresM = stats.Int64(
+		"state_save_failures",
+		"The number of times saving the state of a bucket failed",
+		stats.UnitDimensionless)
+	droppedRevisionsM = stats.Int64(
+		"state_dropped_revisions",
+		"The number of revisions left out of the saved state of their bucket to fit its ConfigMap",
+		stats.UnitDimensionless)
+)
+
+func init() {
+	register()
+}
+
+func register() {
+	// Create views to see our measurements. This can return an error if
+	// a previously-registered view has the same name with a different value.
+	// View name defaults to the measure name if unspecified.
+	if err := pkgmetrics.RegisterResourceView(
+		&view.View{
+			Description: "The number of times saving the state of a bucket failed",
+			Measure:     saveFailuresM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: "The number of revisions left out of the saved state of their bucket to fit its ConfigMap",
+			Measure:     droppedRevisionsM,
+			Aggregation: view.Sum(),
+		},
+	); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statestore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/hash"
+	"knative.dev/pkg/logging"
+	pkgmetrics "knative.dev/pkg/metrics"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
+)
+
+const (
+	// snapshotInterval is how often the state of the owned buckets is saved.
+	snapshotInterval = 10 * time.Second
+
+	// stateKey is the key of the ConfigMap binary data holding the
+	// gzipped JSON of the state of the revisions of a bucket.
+	stateKey = "state"
+
+	// maxStateSize is the most data saved in the ConfigMap of a bucket, to
+	// stay below the 1MiB limit of a ConfigMap along with its metadata.
+	maxStateSize = 1000 * 1024
+)
+
+// ConfigMapName returns the name of the ConfigMap holding the state
+// of the given bucket.
+func ConfigMapName(bkt string) string {
+	return bkt + "-state"
+}
+
+// CollectionSnapshotter snapshots and restores the metric collections.
+type CollectionSnapshotter interface {
+	// Keys returns the keys of the revisions being collected.
+	Keys() []types.NamespacedName
+	Snapshot(types.NamespacedName) (*metrics.CollectionSnapshot, bool)
+	Restore(types.NamespacedName, *metrics.CollectionSnapshot)
+}
+
+// ScalerSnapshotter snapshots and restores the state of the scalers.
+type ScalerSnapshotter interface {
+	Snapshot(types.NamespacedName) (*scaling.ScalerState, bool)
+	Restore(types.NamespacedName, *scaling.ScalerState)
+}
+
+// RevisionState is the autoscaling state of a revision.
+type RevisionState struct {
+	Collection *metrics.CollectionSnapshot `json:"collection,omitempty"`
+	Scaler     *scaling.ScalerState        `json:"scaler,omitempty"`
+}
+
+// Store saves the state of the revisions of the buckets this Autoscaler pod
+// owns to a ConfigMap per bucket and restores it when acquiring a bucket.
+type Store struct {
+	logger    *zap.SugaredLogger
+	kc        kubernetes.Interface
+	namespace string
+	// bs is the BucketSet including all Autoscaler buckets.
+	bs      *hash.BucketSet
+	isOwner func(bkt string) bool
+
+	collector CollectionSnapshotter
+	scaler    ScalerSnapshotter
+
+	// owned are the buckets owned as of the last sync.
+	owned sets.String
+}
+
+// New creates a new Store for the buckets of the given BucketSet.
+// isOwner returns whether this Autoscaler pod owns the given bucket.
+func New(ctx context.Context, bs *hash.BucketSet, isOwner func(bkt string) bool,
+	collector CollectionSnapshotter, scaler ScalerSnapshotter) *Store {
+	return &Store{
+		logger:    logging.FromContext(ctx).Named("statestore"),
+		kc:        kubeclient.Get(ctx),
+		namespace: system.Namespace(),
+		bs:        bs,
+		isOwner:   isOwner,
+		collector: collector,
+		scaler:    scaler,
+		owned:     sets.NewString(),
+	}
+}
+
+// Run saves and restores the state periodically until the stop channel
+// is closed.
+func (s *Store) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			s.sync(context.Background())
+		}
+	}
+}
+
+// sync restores the state of the buckets acquired since the last sync and
+// saves the state of the others this Autoscaler pod owns.
+func (s *Store) sync(ctx context.Context) {
+	var states map[string]map[string]RevisionState
+	for _, bkt := range s.bs.BucketList() {
+		logger := s.logger.With(zap.String("bucket", bkt))
+		if !s.isOwner(bkt) {
+			s.owned.Delete(bkt)
+			continue
+		}
+
+		if !s.owned.Has(bkt) {
+			// The restored states are applied as the revisions are reconciled,
+			// so the state is not saved before the next sync to not lose
+			// the revisions that haven't been reconciled yet.
+			if err := s.restore(ctx, bkt); err != nil {
+				logger.Errorw("Failed to restore the state of the bucket", zap.Error(err))
+				continue
+			}
+			s.owned.Insert(bkt)
+			continue
+		}
+
+		if states == nil {
+			states = s.snapshot()
+		}
+		if err := s.save(ctx, bkt, states[bkt]); err != nil {
+			logger.Errorw("Failed to save the state of the bucket", zap.Error(err))
+			pkgmetrics.Record(ctx, saveFailuresM.M(1))
+		}
+	}
+}
+
+// snapshot returns the state of the revisions by bucket and revision key.
+func (s *Store) snapshot() map[string]map[string]RevisionState {
+	ret := make(map[string]map[string]RevisionState)
+	for _, key := range s.collector.Keys() {
+		var state RevisionState
+		if cs, ok := s.collector.Snapshot(key); ok {
+			state.Collection = cs
+		}
+		if ss, ok := s.scaler.Snapshot(key); ok {
+			state.Scaler = ss
+		}
+
+		bkt := s.bs.Owner(key.String())
+		if ret[bkt] == nil {
+			ret[bkt] = make(map[string]RevisionState)
+		}
+		ret[bkt][key.String()] = state
+	}
+	return ret
+}
+
+func (s *Store) restore(ctx context.Context, bkt string) error {
+	cm, err := s.kc.CoreV1().ConfigMaps(s.namespace).Get(ctx, ConfigMapName(bkt), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	states, err := decode(cm.BinaryData[stateKey])
+	if err != nil {
+		return err
+	}
+	for k, state := range states {
+		ns, name, err := cache.SplitMetaNamespaceKey(k)
+		if err != nil {
+			return err
+		}
+		key := types.NamespacedName{Namespace: ns, Name: name}
+		if state.Collection != nil {
+			s.collector.Restore(key, state.Collection)
+		}
+		if state.Scaler != nil {
+			s.scaler.Restore(key, state.Scaler)
+		}
+	}
+	s.logger.Infof("Restored the state of %d revisions of bucket %s", len(states), bkt)
+	return nil
+}
+
+func (s *Store) save(ctx context.Context, bkt string, states map[string]RevisionState) error {
+	data, dropped, err := encodeBounded(states)
+	if err != nil {
+		return err
+	}
+	if len(dropped) > 0 {
+		// The left out revisions start afresh when the bucket moves.
+		s.logger.Warnw("The state of the bucket is too large, leaving out the largest revisions",
+			zap.String("bucket", bkt), zap.Strings("revisions", dropped))
+		pkgmetrics.Record(ctx, droppedRevisionsM.M(int64(len(dropped))))
+	}
+
+	cms := s.kc.CoreV1().ConfigMaps(s.namespace)
+	cm, err := cms.Get(ctx, ConfigMapName(bkt), metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		_, err = cms.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ConfigMapName(bkt),
+				Namespace: s.namespace,
+			},
+			BinaryData: map[string][]byte{stateKey: data},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	want := cm.DeepCopy()
+	want.BinaryData = map[string][]byte{stateKey: data}
+	_, err = cms.Update(ctx, want, metav1.UpdateOptions{})
+	return err
+}
+
+// encodeBounded encodes the states, leaving out the largest of them until
+// the data fits into maxStateSize. The keys of the states left out are
+// returned along with the data.
+func encodeBounded(states map[string]RevisionState) ([]byte, []string, error) {
+	data, err := encode(states)
+	if err != nil || len(data) <= maxStateSize {
+		return data, nil, err
+	}
+
+	// Keep as many revisions as possible by leaving out the largest states.
+	keys := make([]string, 0, len(states))
+	sizes := make(map[string]int, len(states))
+	for k, state := range states {
+		b, err := json.Marshal(state)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, k)
+		sizes[k] = len(b)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] < sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	subset := func(n int) map[string]RevisionState {
+		ret := make(map[string]RevisionState, n)
+		for _, k := range keys[:n] {
+			ret[k] = states[k]
+		}
+		return ret
+	}
+	// The most of the smallest states whose data fits, as the compressed
+	// size grows with the number of states.
+	n := sort.Search(len(keys), func(n int) bool {
+		if err != nil {
+			return true
+		}
+		var d []byte
+		d, err = encode(subset(n + 1))
+		return len(d) > maxStateSize
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = encode(subset(n)); err != nil {
+		return nil, nil, err
+	}
+	return data, keys[n:], nil
+}
+
+func encode(states map[string]RevisionState) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(states); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) (map[string]RevisionState, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var states map[string]RevisionState
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
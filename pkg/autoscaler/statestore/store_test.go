@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgotesting "k8s.io/client-go/testing"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	"knative.dev/pkg/hash"
+	"knative.dev/pkg/metrics/metricstest"
+	rtesting "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/autoscaler/aggregation"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
+
+	_ "knative.dev/pkg/metrics/testing"
+)
+
+const (
+	bucket1 = "as-bucket-00-of-02"
+	bucket2 = "as-bucket-01-of-02"
+)
+
+type fakeCollector struct {
+	snapshots map[types.NamespacedName]*metrics.CollectionSnapshot
+}
+
+func (c *fakeCollector) Keys() []types.NamespacedName {
+	ret := make([]types.NamespacedName, 0, len(c.snapshots))
+	for key := range c.snapshots {
+		ret = append(ret, key)
+	}
+	return ret
+}
+
+func (c *fakeCollector) Snapshot(key types.NamespacedName) (*metrics.CollectionSnapshot, bool) {
+	s, ok := c.snapshots[key]
+	return s, ok
+}
+
+func (c *fakeCollector) Restore(key types.NamespacedName, s *metrics.CollectionSnapshot) {
+	c.snapshots[key] = s
+}
+
+type fakeScaler struct {
+	states map[types.NamespacedName]*scaling.ScalerState
+}
+
+func (c *fakeScaler) Snapshot(key types.NamespacedName) (*scaling.ScalerState, bool) {
+	s, ok := c.states[key]
+	return s, ok
+}
+
+func (c *fakeScaler) Restore(key types.NamespacedName, s *scaling.ScalerState) {
+	c.states[key] = s
+}
+
+func TestStoreSaveRestore(t *testing.T) {
+	ctx, _ := rtesting.SetupFakeContext(t)
+	bs := hash.NewBucketSet(sets.NewString(bucket1, bucket2))
+
+	key := types.NamespacedName{Namespace: "test-ns", Name: "test-revision"}
+	bkt := bs.Owner(key.String())
+	now := time.Unix(1000, 0).UTC()
+	collection := &metrics.CollectionSnapshot{
+		Windows: map[string]aggregation.TimedFloat64BucketsState{
+			"concurrency": {
+				Window:      time.Minute,
+				Granularity: time.Second,
+				FirstWrite:  now,
+				LastWrite:   now,
+				Buckets:     []float64{0, 3},
+				WindowTotal: 3,
+			},
+		},
+	}
+	scaler := &scaling.ScalerState{PanicTime: now, MaxPanicPods: 4}
+
+	owned := sets.NewString(bucket1, bucket2)
+	oldCollector := &fakeCollector{snapshots: map[types.NamespacedName]*metrics.CollectionSnapshot{
+		key: collection,
+	}}
+	oldScaler := &fakeScaler{states: map[types.NamespacedName]*scaling.ScalerState{
+		key: scaler,
+	}}
+	old := New(ctx, bs, owned.Has, oldCollector, oldScaler)
+
+	// The first sync restores the acquired buckets, the next ones save them.
+	old.sync(ctx)
+	if cms, _ := fakekubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).List(
+		context.Background(), metav1.ListOptions{}); len(cms.Items) != 0 {
+		t.Errorf("Got %d ConfigMaps after the first sync, want none", len(cms.Items))
+	}
+	old.sync(ctx)
+	for _, b := range bs.BucketList() {
+		if _, err := fakekubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(
+			context.Background(), ConfigMapName(b), metav1.GetOptions{}); err != nil {
+			t.Errorf("Get(%s) = %v", ConfigMapName(b), err)
+		}
+	}
+
+	// Another pod acquiring the bucket restores the state.
+	newCollector := &fakeCollector{snapshots: map[types.NamespacedName]*metrics.CollectionSnapshot{}}
+	newScaler := &fakeScaler{states: map[types.NamespacedName]*scaling.ScalerState{}}
+	owned = sets.NewString()
+	store := New(ctx, bs, func(b string) bool { return owned.Has(b) }, newCollector, newScaler)
+	store.sync(ctx)
+	if len(newCollector.snapshots) != 0 || len(newScaler.states) != 0 {
+		t.Error("State of a bucket not owned was restored")
+	}
+
+	owned.Insert(bkt)
+	store.sync(ctx)
+	if got := newCollector.snapshots[key]; !cmp.Equal(got, collection) {
+		t.Error("Collection mismatch (-want,+got):", cmp.Diff(collection, got))
+	}
+	if got := newScaler.states[key]; !cmp.Equal(got, scaler) {
+		t.Error("Scaler mismatch (-want,+got):", cmp.Diff(scaler, got))
+	}
+}
+
+// randomCollection returns a collection snapshot with the given number of
+// random, and so barely compressible, bucket values.
+func randomCollection(r *rand.Rand, buckets int) *metrics.CollectionSnapshot {
+	values := make([]float64, buckets)
+	for i := range values {
+		values[i] = r.Float64()
+	}
+	return &metrics.CollectionSnapshot{
+		Windows: map[string]aggregation.TimedFloat64BucketsState{
+			"concurrency": {
+				Window:      time.Hour,
+				Granularity: time.Second,
+				Buckets:     values,
+			},
+		},
+	}
+}
+
+func TestStoreSaveOverLimit(t *testing.T) {
+	metricstest.Unregister(droppedRevisionsM.Name(), saveFailuresM.Name())
+	register()
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	bs := hash.NewBucketSet(sets.NewString(bucket1))
+
+	// Well over the limit, even compressed.
+	r := rand.New(rand.NewSource(42))
+	collector := &fakeCollector{snapshots: map[types.NamespacedName]*metrics.CollectionSnapshot{}}
+	for i := 0; i < 100; i++ {
+		key := types.NamespacedName{Namespace: "test-ns", Name: fmt.Sprint("test-revision-", i)}
+		collector.snapshots[key] = randomCollection(r, 2000+i)
+	}
+	largest := types.NamespacedName{Namespace: "test-ns", Name: "test-revision-99"}
+	store := New(ctx, bs, func(string) bool { return true }, collector,
+		&fakeScaler{states: map[types.NamespacedName]*scaling.ScalerState{}})
+	store.sync(ctx)
+	store.sync(ctx)
+
+	cm, err := fakekubeclient.Get(ctx).CoreV1().ConfigMaps(system.Namespace()).Get(
+		context.Background(), ConfigMapName(bucket1), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get(%s) = %v", ConfigMapName(bucket1), err)
+	}
+	data := cm.BinaryData[stateKey]
+	if len(data) > maxStateSize {
+		t.Errorf("Saved %d bytes, want at most %d", len(data), maxStateSize)
+	}
+	states, err := decode(data)
+	if err != nil {
+		t.Fatal("decode() =", err)
+	}
+	if len(states) == 0 || len(states) == len(collector.snapshots) {
+		t.Errorf("Saved the state of %d revisions, want some of %d", len(states), len(collector.snapshots))
+	}
+	if _, ok := states[largest.String()]; ok {
+		t.Errorf("Saved the state of the largest revision %s", largest)
+	}
+	metricstest.AssertMetric(t, metricstest.IntMetric(droppedRevisionsM.Name(),
+		int64(len(collector.snapshots)-len(states)), nil))
+	metricstest.AssertNoMetric(t, saveFailuresM.Name())
+}
+
+func TestStoreSaveFailure(t *testing.T) {
+	metricstest.Unregister(droppedRevisionsM.Name(), saveFailuresM.Name())
+	register()
+
+	ctx, _ := rtesting.SetupFakeContext(t)
+	bs := hash.NewBucketSet(sets.NewString(bucket1))
+	fakekubeclient.Get(ctx).PrependReactor("create", "configmaps",
+		func(clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("etcdserver: request is too large")
+		})
+
+	key := types.NamespacedName{Namespace: "test-ns", Name: "test-revision"}
+	store := New(ctx, bs, func(string) bool { return true },
+		&fakeCollector{snapshots: map[types.NamespacedName]*metrics.CollectionSnapshot{
+			key: randomCollection(rand.New(rand.NewSource(42)), 10),
+		}},
+		&fakeScaler{states: map[types.NamespacedName]*scaling.ScalerState{}})
+	store.sync(ctx)
+	store.sync(ctx)
+	store.sync(ctx)
+
+	metricstest.AssertMetric(t, metricstest.IntMetric(saveFailuresM.Name(), 2, nil))
+	metricstest.AssertNoMetric(t, droppedRevisionsM.Name())
+}
+
+func TestEncodeDecode(t *testing.T) {
+	want := map[string]RevisionState{
+		"ns/name": {Scaler: &scaling.ScalerState{MaxPanicPods: 3}},
+	}
+	data, err := encode(want)
+	if err != nil {
+		t.Fatal("encode() =", err)
+	}
+	got, err := decode(data)
+	if err != nil {
+		t.Fatal("decode() =", err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("State mismatch (-want,+got):", cmp.Diff(want, got))
+	}
+
+	if got, err := decode(nil); err != nil || got != nil {
+		t.Errorf("decode(nil) = %v, %v; want nil, nil", got, err)
+	}
+	if _, err := decode([]byte("garbage")); err == nil {
+		t.Error("decode() = nil error for garbage")
+	}
+}
@@ -180,6 +180,19 @@ func (f *Forwarder) Cancel() {
 	close(f.statCh)
 }
 
+// Owner returns the bucket the given revision key belongs to, the identity of
+// the Autoscaler pod holding that bucket, if known, and whether it is this pod.
+func (f *Forwarder) Owner(rev string) (bkt, holder string, local bool) {
+	bkt = f.bs.Owner(rev)
+	switch p := f.getProcessor(bkt).(type) {
+	case *localProcessor:
+		return bkt, p.holder, true
+	case *remoteProcessor:
+		return bkt, p.holder, false
+	}
+	return bkt, "", false
+}
+
 // IsBucketOwner returns true if this Autoscaler pod is the owner of the given bucket.
 func (f *Forwarder) IsBucketOwner(bkt string) bool {
 	_, owned := f.getProcessor(bkt).(*localProcessor)
@@ -495,3 +495,40 @@ func TestIsBucketOwner(t *testing.T) {
 		t.Errorf("IsBktOwner(not-in-record) = %v, want true", got)
 	}
 }
+
+func TestOwner(t *testing.T) {
+	bs := hash.NewBucketSet(sets.NewString(bucket1, bucket2))
+	f := Forwarder{
+		bs: bs,
+		processors: map[string]bucketProcessor{
+			bucket1: &localProcessor{
+				bkt:    bucket1,
+				holder: testHolder1,
+				accept: noOp,
+			},
+			bucket2: &remoteProcessor{
+				bkt:    bucket2,
+				holder: testHolder2,
+			},
+		},
+	}
+
+	for _, rev := range []string{"ns/a", "ns/b", "ns/c", "ns/d"} {
+		bkt, holder, local := f.Owner(rev)
+		if want := bs.Owner(rev); bkt != want {
+			t.Errorf("Owner(%s) bucket = %s, want: %s", rev, bkt, want)
+		}
+		wantHolder, wantLocal := testHolder2, false
+		if bkt == bucket1 {
+			wantHolder, wantLocal = testHolder1, true
+		}
+		if holder != wantHolder || local != wantLocal {
+			t.Errorf("Owner(%s) = %s, %v, want: %s, %v", rev, holder, local, wantHolder, wantLocal)
+		}
+	}
+
+	f.processors = map[string]bucketProcessor{}
+	if _, holder, local := f.Owner("ns/a"); holder != "" || local {
+		t.Errorf("Owner() without processors = %q, %v, want: \"\", false", holder, local)
+	}
+}
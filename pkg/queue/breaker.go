@@ -17,10 +17,12 @@ limitations under the License.
 package queue
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 
 	"go.uber.org/atomic"
 )
@@ -30,6 +32,10 @@ var (
 	ErrRelease = errors.New("semaphore release error: returned tokens must be <= acquired tokens")
 	// ErrRequestQueueFull indicates the breaker queue depth was exceeded.
 	ErrRequestQueueFull = errors.New("pending request queue full")
+
+	// errShed is returned by the semaphore to background goroutines that were
+	// shed in favor of an interactive one.
+	errShed = errors.New("shed for an interactive request")
 )
 
 // MaxBreakerCapacity is the largest valid value for the MaxConcurrency value of BreakerParams.
@@ -45,8 +51,10 @@ type BreakerParams struct {
 
 // Breaker is a component that enforces a concurrency limit on the
 // execution of a function. It also maintains a queue of function
-// executions in excess of the concurrency limit. Function call attempts
-// beyond the limit of the queue are failed immediately.
+// executions in excess of the concurrency limit, where interactive
+// executions are dequeued before background ones. Function call attempts
+// beyond the limit of the queue are failed immediately, unless they are
+// interactive and can take the place of a queued background execution.
 type Breaker struct {
 	inFlight   atomic.Int64
 	totalSlots int64
@@ -134,19 +142,25 @@ func (b *Breaker) Reserve(ctx context.Context) (func(), bool) {
 
 // Maybe conditionally executes thunk based on the Breaker concurrency
 // and queue parameters. If the concurrency limit and queue capacity are
-// already consumed, Maybe returns immediately without calling thunk. If
-// the thunk was executed, Maybe returns true, else false.
-func (b *Breaker) Maybe(ctx context.Context, thunk func()) error {
-	if !b.tryAcquirePending() {
+// already consumed, Maybe returns immediately without calling thunk, unless
+// an interactive thunk can shed a queued background one. If the thunk was
+// executed, Maybe returns nil.
+func (b *Breaker) Maybe(ctx context.Context, p Priority, thunk func()) error {
+	// Shedding a background request hands its pending slot over to us.
+	if !b.tryAcquirePending() && (p != PriorityInteractive || !b.sem.shedBackground()) {
 		return ErrRequestQueueFull
 	}
 
-	defer b.releasePending()
-
 	// Wait for capacity in the active queue.
-	if err := b.sem.acquire(ctx); err != nil {
+	if err := b.sem.acquire(ctx, p); err != nil {
+		if errors.Is(err, errShed) {
+			// Our pending slot belongs to the request that shed us now.
+			return ErrRequestQueueFull
+		}
+		b.releasePending()
 		return err
 	}
+	defer b.releasePending()
 	// Defer releasing capacity in the active.
 	// It's safe to ignore the error returned by release since we
 	// make sure the semaphore is only manipulated here and acquire
@@ -176,8 +190,10 @@ func (b *Breaker) Capacity() int {
 
 // newSemaphore creates a semaphore with the desired initial capacity.
 func newSemaphore(maxCapacity, initialCapacity int) *semaphore {
-	queue := make(chan struct{}, maxCapacity)
-	sem := &semaphore{queue: queue}
+	sem := &semaphore{background: list.New()}
+	for p := range sem.queues {
+		sem.queues[p] = make(chan struct{}, maxCapacity)
+	}
 	sem.updateCapacity(initialCapacity)
 	return sem
 }
@@ -188,13 +204,33 @@ func newSemaphore(maxCapacity, initialCapacity int) *semaphore {
 // while the latter refers to the currently in-flight requests.
 // Packing them both into one uint64 allows us to optimize access semantics using atomic
 // operations, which can't be guaranteed on 2 individual values.
-// The channel is merely used as a vehicle to be able to "wake up" individual goroutines
-// if capacity becomes free. It's not consistently used in accordance to actual capacity
-// but is rather a communication vehicle to ensure waiting routines are properly woken
-// up.
+// The channels are merely used as a vehicle to be able to "wake up" individual goroutines
+// if capacity becomes free. They're not consistently used in accordance to actual capacity
+// but are rather a communication vehicle to ensure waiting routines are properly woken
+// up. There is one channel per priority and wakeups go to the interactive goroutines
+// as long as any of them is waiting.
 type semaphore struct {
-	state atomic.Uint64
-	queue chan struct{}
+	state   atomic.Uint64
+	queues  [numPriorities]chan struct{}
+	waiting [numPriorities]atomic.Int64
+
+	// mu guards `background`.
+	mu sync.Mutex
+	// background holds the *shedWaiter of each waiting background goroutine,
+	// so that a specific one can be claimed for shedding.
+	background *list.List
+}
+
+// shedWaiter is a background goroutine waiting in the semaphore, that can be
+// made to give up.
+type shedWaiter struct {
+	// shed is set once the goroutine is claimed for shedding. Guarded by
+	// semaphore.mu.
+	shed bool
+	// ch is closed when the goroutine is claimed for shedding.
+	ch chan struct{}
+	// elem is the goroutine's element of semaphore.background.
+	elem *list.Element
 }
 
 // tryAcquire receives a token from the semaphore if there is one otherwise returns false.
@@ -213,28 +249,140 @@ func (s *semaphore) tryAcquire() bool {
 }
 
 // acquire acquires capacity from the semaphore.
-func (s *semaphore) acquire(ctx context.Context) error {
+func (s *semaphore) acquire(ctx context.Context, p Priority) error {
+	waited := false
 	for {
 		old := s.state.Load()
 		capacity, in := unpack(old)
 
-		if in >= capacity {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-s.queue:
+		if !s.mayAcquire(p, capacity, in) {
+			if err := s.wait(ctx, p); err != nil {
+				return err
 			}
+			waited = true
 			// Force reload state.
 			continue
 		}
 
 		in++
 		if s.state.CAS(old, pack(capacity, in)) {
+			if waited && in < capacity {
+				// Pass on the wakeup, in case it was meant for another priority.
+				s.wake()
+			}
 			return nil
 		}
 	}
 }
 
+// mayAcquire returns whether a goroutine of the given priority may take
+// capacity. Background goroutines leave it to waiting interactive ones.
+func (s *semaphore) mayAcquire(p Priority, capacity, in uint64) bool {
+	if in >= capacity {
+		return false
+	}
+	return p == PriorityInteractive || s.waiting[PriorityInteractive].Load() == 0
+}
+
+// wait blocks until the goroutine is woken up to try acquiring again. It
+// returns errShed if the goroutine was claimed for shedding.
+func (s *semaphore) wait(ctx context.Context, p Priority) error {
+	s.waiting[p].Inc()
+	defer s.waiting[p].Dec()
+
+	var w *shedWaiter
+	if p == PriorityBackground {
+		w = s.addBackground()
+	}
+	err := s.block(ctx, p, w)
+	if w != nil && s.removeBackground(w) {
+		// Pass on the wakeup we might have received as well.
+		s.wake()
+		return errShed
+	}
+	if err != nil {
+		s.wake()
+	}
+	return err
+}
+
+// block blocks until the goroutine is woken up, its context is done or, for
+// background goroutines, it's claimed for shedding.
+func (s *semaphore) block(ctx context.Context, p Priority, w *shedWaiter) error {
+	// Check again now that we're counted as waiting, or we might miss the
+	// wakeup of a release that happened in between.
+	if capacity, in := unpack(s.state.Load()); s.mayAcquire(p, capacity, in) {
+		return nil
+	}
+
+	var shed chan struct{}
+	if w != nil {
+		shed = w.ch
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-shed:
+		return nil
+	case <-s.queues[p]:
+		return nil
+	}
+}
+
+// addBackground registers a waiting background goroutine.
+func (s *semaphore) addBackground() *shedWaiter {
+	w := &shedWaiter{ch: make(chan struct{})}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.elem = s.background.PushBack(w)
+	return w
+}
+
+// removeBackground unregisters a background goroutine that stops waiting,
+// and returns whether it was claimed for shedding.
+func (s *semaphore) removeBackground(w *shedWaiter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.shed {
+		return true
+	}
+	s.background.Remove(w.elem)
+	return false
+}
+
+// wake wakes up a waiting goroutine, preferring interactive ones.
+func (s *semaphore) wake() {
+	queue := s.queues[PriorityBackground]
+	if s.waiting[PriorityInteractive].Load() > 0 {
+		queue = s.queues[PriorityInteractive]
+	}
+	select {
+	case queue <- struct{}{}:
+	default:
+		// We generate more wakeups than we might need as we don't know
+		// how many goroutines are waiting here. It is therefore okay
+		// to drop the poke on the floor here as this case would mean we
+		// have enough wakeups to wake up as many goroutines as this semaphore
+		// can take, which is guaranteed to be enough.
+	}
+}
+
+// shedBackground claims the most recently queued background goroutine, which
+// gives up waiting, and returns whether there was one. The claim is atomic, so
+// each goroutine is only ever shed by a single caller.
+func (s *semaphore) shedBackground() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem := s.background.Back()
+	if elem == nil {
+		return false
+	}
+	w := s.background.Remove(elem).(*shedWaiter)
+	w.shed = true
+	close(w.ch)
+	return true
+}
+
 // release releases capacity in the semaphore.
 // If the semaphore capacity was reduced in between and as a result inFlight is greater
 // than capacity, we don't wake up goroutines as they'd not get any capacity anyway.
@@ -250,15 +398,7 @@ func (s *semaphore) release() {
 		in--
 		if s.state.CAS(old, pack(capacity, in)) {
 			if in < capacity {
-				select {
-				case s.queue <- struct{}{}:
-				default:
-					// We generate more wakeups than we might need as we don't know
-					// how many goroutines are waiting here. It is therefore okay
-					// to drop the poke on the floor here as this case would mean we
-					// have enough wakeups to wake up as many goroutines as this semaphore
-					// can take, which is guaranteed to be enough.
-				}
+				s.wake()
 			}
 			return
 		}
@@ -280,11 +420,7 @@ func (s *semaphore) updateCapacity(size int) {
 		if s.state.CAS(old, pack(s64, in)) {
 			if s64 > capacity {
 				for i := uint64(0); i < s64-capacity; i++ {
-					select {
-					case s.queue <- struct{}{}:
-					default:
-						// See comment in `release` for explanation of this case.
-					}
+					s.wake()
 				}
 			}
 			return
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"go.uber.org/atomic"
 )
 
 const (
@@ -112,6 +114,203 @@ func TestBreakerOverloadMixed(t *testing.T) {
 	cb()
 }
 
+func TestBreakerPriorityDequeue(t *testing.T) {
+	params := BreakerParams{QueueDepth: 10, MaxConcurrency: 1, InitialCapacity: 1}
+	b := NewBreaker(params)
+	holder, interactive, background := newRequestor(b), newRequestor(b), newRequestor(b)
+	background.priority = PriorityBackground
+
+	// Bring breaker to capacity and queue a background request before an
+	// interactive one.
+	holder.request()
+	for _, in := unpack(b.sem.state.Load()); in != 1; _, in = unpack(b.sem.state.Load()) {
+		time.Sleep(time.Millisecond * 2)
+	}
+	background.request()
+	for b.sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+	interactive.request()
+	for b.sem.waiting[PriorityInteractive].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+
+	// The freed slot goes to the interactive request.
+	holder.processSuccessfully(t)
+	for b.sem.waiting[PriorityInteractive].Load() != 0 {
+		time.Sleep(time.Millisecond * 2)
+	}
+	time.Sleep(semNoChangeTimeout)
+	if got := b.sem.waiting[PriorityBackground].Load(); got != 1 {
+		t.Errorf("Waiting background requests = %d, want: 1", got)
+	}
+	interactive.processSuccessfully(t)
+	background.processSuccessfully(t)
+}
+
+func TestBreakerShedsBackground(t *testing.T) {
+	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1}
+	b := NewBreaker(params) // Breaker capacity = 2
+	holder, interactive, background := newRequestor(b), newRequestor(b), newRequestor(b)
+	background.priority = PriorityBackground
+
+	// Bring breaker to capacity with a background request queued.
+	holder.request()
+	for _, in := unpack(b.sem.state.Load()); in != 1; _, in = unpack(b.sem.state.Load()) {
+		time.Sleep(time.Millisecond * 2)
+	}
+	background.request()
+	for b.sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+
+	// Background requests can't shed each other.
+	background.request()
+	background.expectFailure(t)
+
+	// An interactive request takes the place of the queued background one.
+	interactive.request()
+	background.expectFailure(t)
+	holder.processSuccessfully(t)
+	interactive.processSuccessfully(t)
+
+	if got := b.InFlight(); got != 0 {
+		t.Errorf("InFlight = %d, want: 0", got)
+	}
+
+	// Without background requests to shed, interactive ones fail as well.
+	holder.request()
+	holder.request()
+	for b.InFlight() != 2 {
+		time.Sleep(time.Millisecond * 2)
+	}
+	interactive.request()
+	interactive.expectFailure(t)
+	holder.processSuccessfully(t)
+	holder.processSuccessfully(t)
+}
+
+func TestBreakerShedConcurrent(t *testing.T) {
+	const interactiveRequests = 50
+	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1}
+	b := NewBreaker(params) // Breaker capacity = 2
+	holder, background := newRequestor(b), newRequestor(b)
+	background.priority = PriorityBackground
+
+	holder.request()
+	for _, in := unpack(b.sem.state.Load()); in != 1; _, in = unpack(b.sem.state.Load()) {
+		time.Sleep(time.Millisecond * 2)
+	}
+	background.request()
+	for b.sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+
+	// The pending slots are never overcommitted while interactive requests
+	// race to shed the single background request.
+	stop := make(chan struct{})
+	overcommitted := atomic.NewBool(false)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				if b.InFlight() > params.QueueDepth+params.MaxConcurrency {
+					overcommitted.Store(true)
+				}
+			}
+		}
+	}()
+
+	startCh, barrierCh := make(chan struct{}), make(chan struct{})
+	errs := make(chan error, interactiveRequests)
+	for i := 0; i < interactiveRequests; i++ {
+		go func() {
+			<-startCh
+			errs <- b.Maybe(context.Background(), PriorityInteractive, func() {
+				<-barrierCh
+			})
+		}()
+	}
+	close(startCh)
+	// The background request is shed exactly once, and all but the
+	// interactive request that shed it are rejected.
+	background.expectFailure(t)
+	for i := 0; i < interactiveRequests-1; i++ {
+		if err := <-errs; err != ErrRequestQueueFull {
+			t.Fatalf("Maybe() = %v, want: %v", err, ErrRequestQueueFull)
+		}
+	}
+	holder.processSuccessfully(t)
+	close(barrierCh)
+	if err := <-errs; err != nil {
+		t.Fatal("Maybe() =", err)
+	}
+	close(stop)
+
+	if overcommitted.Load() {
+		t.Errorf("InFlight exceeded the breaker capacity of %d", params.QueueDepth+params.MaxConcurrency)
+	}
+	if got := b.InFlight(); got != 0 {
+		t.Errorf("InFlight = %d, want: 0", got)
+	}
+
+	// No shed signal outlives its waiter: later background requests queue.
+	holder.request()
+	for _, in := unpack(b.sem.state.Load()); in != 1; _, in = unpack(b.sem.state.Load()) {
+		time.Sleep(time.Millisecond * 2)
+	}
+	background.request()
+	for b.sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+	time.Sleep(semNoChangeTimeout)
+	holder.processSuccessfully(t)
+	background.processSuccessfully(t)
+}
+
+func TestSemaphoreShedBackgroundOnce(t *testing.T) {
+	sem := newSemaphore(1, 0)
+	if sem.shedBackground() {
+		t.Fatal("shedBackground() = true without background waiters")
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- sem.acquire(context.Background(), PriorityBackground)
+	}()
+	for sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+
+	// The waiter can only be claimed once, even before it noticed.
+	if !sem.shedBackground() {
+		t.Fatal("shedBackground() = false, want: true")
+	}
+	if sem.shedBackground() {
+		t.Error("shedBackground() claimed the same waiter twice")
+	}
+	if err := <-errCh; err != errShed {
+		t.Errorf("acquire() = %v, want: %v", err, errShed)
+	}
+
+	// A waiter that got capacity can't be claimed anymore.
+	go func() {
+		errCh <- sem.acquire(context.Background(), PriorityBackground)
+	}()
+	for sem.waiting[PriorityBackground].Load() != 1 {
+		time.Sleep(time.Millisecond * 2)
+	}
+	sem.updateCapacity(1)
+	if err := <-errCh; err != nil {
+		t.Fatal("acquire() =", err)
+	}
+	if sem.shedBackground() {
+		t.Error("shedBackground() claimed a goroutine that stopped waiting")
+	}
+}
+
 func TestBreakerOverload(t *testing.T) {
 	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1}
 	b := NewBreaker(params) // Breaker capacity = 2
@@ -273,7 +472,7 @@ func TestSemaphoreAcquireHasCapacity(t *testing.T) {
 
 func TestSemaphoreRelease(t *testing.T) {
 	sem := newSemaphore(1, 1)
-	sem.acquire(context.Background())
+	sem.acquire(context.Background(), PriorityInteractive)
 	func() {
 		defer func() {
 			if e := recover(); e != nil {
@@ -298,7 +497,7 @@ func TestSemaphoreUpdateCapacity(t *testing.T) {
 	if got, want := sem.Capacity(), 1; got != want {
 		t.Errorf("Capacity = %d, want: %d", got, want)
 	}
-	sem.acquire(context.Background())
+	sem.acquire(context.Background(), PriorityInteractive)
 	sem.updateCapacity(initialCapacity + 2)
 	if got, want := sem.Capacity(), 3; got != want {
 		t.Errorf("Capacity = %d, want: %d", got, want)
@@ -319,7 +518,7 @@ func TestPackUnpack(t *testing.T) {
 func tryAcquire(sem *semaphore, gotChan chan struct{}) {
 	go func() {
 		// blocking until someone puts the token into the semaphore
-		sem.acquire(context.Background())
+		sem.acquire(context.Background(), PriorityInteractive)
 		gotChan <- struct{}{}
 	}()
 }
@@ -327,6 +526,7 @@ func tryAcquire(sem *semaphore, gotChan chan struct{}) {
 // requestor is a set of test helpers around breaker testing.
 type requestor struct {
 	breaker    *Breaker
+	priority   Priority
 	acceptedCh chan bool
 	barrierCh  chan struct{}
 }
@@ -349,7 +549,7 @@ func (r *requestor) request() {
 // or block until processSuccessfully is called.
 func (r *requestor) requestWithContext(ctx context.Context) {
 	go func() {
-		err := r.breaker.Maybe(ctx, func() {
+		err := r.breaker.Maybe(ctx, r.priority, func() {
 			<-r.barrierCh
 		})
 		r.acceptedCh <- err == nil
@@ -382,14 +582,14 @@ func BenchmarkBreakerMaybe(b *testing.B) {
 
 		b.Run(fmt.Sprintf("%d-sequential", c), func(b *testing.B) {
 			for j := 0; j < b.N; j++ {
-				breaker.Maybe(context.Background(), op)
+				breaker.Maybe(context.Background(), PriorityInteractive, op)
 			}
 		})
 
 		b.Run(fmt.Sprintf("%d-parallel", c), func(b *testing.B) {
 			b.RunParallel(func(pb *testing.PB) {
 				for pb.Next() {
-					breaker.Maybe(context.Background(), op)
+					breaker.Maybe(context.Background(), PriorityInteractive, op)
 				}
 			})
 		})
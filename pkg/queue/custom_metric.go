@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// CustomMetricPath is the path the user container exposes the custom
+// metric the revision scales on at, in the Prometheus text format.
+const CustomMetricPath = "/metrics"
+
+// CustomMetricScraper scrapes a gauge reported by the user container.
+type CustomMetricScraper struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewCustomMetricScraper creates a scraper for the gauge with the given name
+// exposed by the user container at the given URL.
+func NewCustomMetricScraper(name, url string, client *http.Client) *CustomMetricScraper {
+	return &CustomMetricScraper{
+		name:   name,
+		url:    url,
+		client: client,
+	}
+}
+
+// Scrape returns the current value of the gauge, summed over all of its series.
+func (s *CustomMetricScraper) Scrape(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GET request for URL %q returned HTTP status %v", s.url, resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+	family, ok := families[s.name]
+	if !ok {
+		return 0, fmt.Errorf("metric %q not found", s.name)
+	}
+
+	var total float64
+	for _, m := range family.Metric {
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			total += m.GetGauge().GetValue()
+		case dto.MetricType_UNTYPED:
+			total += m.GetUntyped().GetValue()
+		default:
+			return 0, fmt.Errorf("metric %q has type %v, want a gauge", s.name, family.GetType())
+		}
+	}
+	return total, nil
+}
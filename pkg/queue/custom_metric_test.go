@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomMetricScraper(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    float64
+		wantErr bool
+	}{{
+		name: "gauge",
+		body: "# TYPE queue_backlog gauge\nqueue_backlog 42\n",
+		want: 42,
+	}, {
+		name: "gauge series are summed",
+		body: "# TYPE queue_backlog gauge\nqueue_backlog{queue=\"a\"} 2\nqueue_backlog{queue=\"b\"} 3.5\n",
+		want: 5.5,
+	}, {
+		name: "untyped",
+		body: "other 1\nqueue_backlog 7\n",
+		want: 7,
+	}, {
+		name:    "counter",
+		body:    "# TYPE queue_backlog counter\nqueue_backlog 7\n",
+		wantErr: true,
+	}, {
+		name:    "missing",
+		body:    "other 1\n",
+		wantErr: true,
+	}, {
+		name:    "malformed",
+		body:    "queue_backlog seven\n",
+		wantErr: true,
+	}, {
+		name:    "error status",
+		status:  http.StatusInternalServerError,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != CustomMetricPath {
+					t.Errorf("Path = %s, want: %s", r.URL.Path, CustomMetricPath)
+				}
+				if test.status != 0 {
+					w.WriteHeader(test.status)
+				}
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			s := NewCustomMetricScraper("queue_backlog", server.URL+CustomMetricPath, server.Client())
+			got, err := s.Scrape(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Scrape() = %v, wantErr = %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Scrape() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
@@ -28,8 +28,9 @@ import (
 )
 
 // ProxyHandler sends requests to the `next` handler at a rate controlled by
-// the passed `breaker`, while recording stats to `stats`.
-func ProxyHandler(breaker *Breaker, stats *network.RequestStats, tracingEnabled bool, next http.Handler) http.HandlerFunc {
+// the passed `breaker`, while recording stats to `stats` and the stats of the
+// requests waiting in the breaker queue to `queueStats`.
+func ProxyHandler(breaker *Breaker, stats *network.RequestStats, queueStats *QueueStats, tracingEnabled bool, next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if network.IsKubeletProbe(r) {
 			next.ServeHTTP(w, r)
@@ -59,11 +60,15 @@ func ProxyHandler(breaker *Breaker, stats *network.RequestStats, tracingEnabled
 			if tracingEnabled {
 				_, waitSpan = trace.StartSpan(r.Context(), "queue_wait")
 			}
-			if err := breaker.Maybe(r.Context(), func() {
+			enqueued := time.Now()
+			queueStats.Enqueue(enqueued)
+			if err := breaker.Maybe(r.Context(), PriorityFromHeader(r.Header), func() {
 				waitSpan.End()
+				queueStats.Dequeue(time.Now(), enqueued)
 				next.ServeHTTP(w, r)
 			}); err != nil {
 				waitSpan.End()
+				queueStats.Dequeue(time.Now(), enqueued)
 				if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRequestQueueFull) {
 					http.Error(w, err.Error(), http.StatusServiceUnavailable)
 				} else {
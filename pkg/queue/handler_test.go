@@ -48,7 +48,7 @@ func TestHandlerBreakerQueueFull(t *testing.T) {
 		QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1,
 	})
 	stats := network.NewRequestStats(time.Now())
-	h := ProxyHandler(breaker, stats, false /*tracingEnabled*/, blockHandler)
+	h := ProxyHandler(breaker, stats, NewQueueStats(time.Now()), false /*tracingEnabled*/, blockHandler)
 
 	req := httptest.NewRequest(http.MethodGet, "http://localhost:8081/time", nil)
 	resps := make(chan *httptest.ResponseRecorder)
@@ -96,7 +96,7 @@ func TestHandlerBreakerTimeout(t *testing.T) {
 		QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1,
 	})
 	stats := network.NewRequestStats(time.Now())
-	h := ProxyHandler(breaker, stats, false /*tracingEnabled*/, blockHandler)
+	h := ProxyHandler(breaker, stats, NewQueueStats(time.Now()), false /*tracingEnabled*/, blockHandler)
 
 	go func() {
 		h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:8081/time", nil))
@@ -154,7 +154,7 @@ func TestHandlerReqEvent(t *testing.T) {
 			proxy := httputil.NewSingleHostReverseProxy(serverURL)
 
 			stats := network.NewRequestStats(time.Now())
-			h := ProxyHandler(br, stats, true /*tracingEnabled*/, proxy)
+			h := ProxyHandler(br, stats, NewQueueStats(time.Now()), true /*tracingEnabled*/, proxy)
 
 			writer := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
@@ -215,7 +215,7 @@ func TestIgnoreProbe(t *testing.T) {
 	// Ensure no more than 1 request can be queued. So we'll send 3.
 	breaker := NewBreaker(BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1})
 	stats := network.NewRequestStats(time.Now())
-	h := ProxyHandler(breaker, stats, false /*tracingEnabled*/, proxy)
+	h := ProxyHandler(breaker, stats, NewQueueStats(time.Now()), false /*tracingEnabled*/, proxy)
 
 	req := httptest.NewRequest(http.MethodPost, "http://prob.in", nil)
 	req.Header.Set(network.KubeletProbeHeaderName, "1") // Mark it a probe.
@@ -276,7 +276,7 @@ func BenchmarkProxyHandler(b *testing.B) {
 			}
 		}()
 
-		h := ProxyHandler(tc.breaker, stats, true /*tracingEnabled*/, baseHandler)
+		h := ProxyHandler(tc.breaker, stats, NewQueueStats(time.Now()), true /*tracingEnabled*/, baseHandler)
 		b.Run("sequential-"+tc.label, func(b *testing.B) {
 			resp := httptest.NewRecorder()
 			for j := 0; j < b.N; j++ {
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// PriorityHeaderName is the header requests declare their priority class with.
+	PriorityHeaderName = "Knative-Serving-Priority"
+	// PriorityInteractiveValue is the PriorityHeaderName value of PriorityInteractive.
+	PriorityInteractiveValue = "interactive"
+	// PriorityBackgroundValue is the PriorityHeaderName value of PriorityBackground.
+	PriorityBackgroundValue = "background"
+)
+
+// Priority is the priority class of a request waiting for capacity in a Breaker.
+type Priority int
+
+const (
+	// PriorityInteractive requests are dequeued before background requests.
+	// It's the priority of requests that don't declare one.
+	PriorityInteractive Priority = iota
+	// PriorityBackground requests are dequeued after interactive requests and
+	// are shed first when the queue is full.
+	PriorityBackground
+
+	numPriorities
+)
+
+// PriorityFromHeader returns the priority class the request headers declare.
+func PriorityFromHeader(h http.Header) Priority {
+	if strings.EqualFold(h.Get(PriorityHeaderName), PriorityBackgroundValue) {
+		return PriorityBackground
+	}
+	return PriorityInteractive
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPriorityFromHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  Priority
+	}{{
+		name: "no header",
+		want: PriorityInteractive,
+	}, {
+		name:  "interactive",
+		value: PriorityInteractiveValue,
+		want:  PriorityInteractive,
+	}, {
+		name:  "background",
+		value: PriorityBackgroundValue,
+		want:  PriorityBackground,
+	}, {
+		name:  "background in another case",
+		value: "Background",
+		want:  PriorityBackground,
+	}, {
+		name:  "unknown",
+		value: "urgent",
+		want:  PriorityInteractive,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := http.Header{}
+			if test.value != "" {
+				h.Set(PriorityHeaderName, test.value)
+			}
+			if got := PriorityFromHeader(h); got != test.want {
+				t.Errorf("PriorityFromHeader = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
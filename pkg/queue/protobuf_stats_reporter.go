@@ -17,6 +17,7 @@ limitations under the License.
 package queue
 
 import (
+	"math"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -33,8 +34,17 @@ const (
 
 // ProtobufStatsReporter structure represents a protobuf stats reporter.
 type ProtobufStatsReporter struct {
+	// customMetric holds the bits of the last value of the custom metric.
+	// It is first to be 64-bit aligned for atomic access.
+	customMetric uint64
+
+	// queue holds the last queue stats.
+	queue atomic.Value
+
 	startTime time.Time
 	stat      atomic.Value
+	// latencies holds the last latency histogram counts.
+	latencies atomic.Value
 	podName   string
 
 	// RequestCount and ProxiedRequestCount need to be divided by the reporting period
@@ -61,6 +71,7 @@ func NewProtobufStatsReporter(pod string, reportingPeriod time.Duration) *Protob
 
 // Report captures request metrics.
 func (r *ProtobufStatsReporter) Report(stats network.RequestStatsReport) {
+	queue, _ := r.queue.Load().(QueueStatsReport)
 	r.stat.Store(metrics.Stat{
 		PodName:       r.podName,
 		ProcessUptime: time.Since(r.startTime).Seconds(),
@@ -70,12 +81,56 @@ func (r *ProtobufStatsReporter) Report(stats network.RequestStatsReport) {
 		ProxiedRequestCount:              stats.ProxiedRequestCount / r.reportingPeriodSeconds,
 		AverageConcurrentRequests:        stats.AverageConcurrency,
 		AverageProxiedConcurrentRequests: stats.AverageProxiedConcurrency,
+
+		CustomMetric:     math.Float64frombits(atomic.LoadUint64(&r.customMetric)),
+		LatencyHistogram: r.latencyHistogram(),
+
+		AverageQueuedRequests: queue.AverageQueued,
+		AverageQueueWait:      float64(queue.AverageWait) / float64(time.Millisecond),
 	})
 }
 
+// Stat returns the last reported stat.
+func (r *ProtobufStatsReporter) Stat() metrics.Stat {
+	return r.stat.Load().(metrics.Stat)
+}
+
+// ReportCustomMetric captures the value of the custom metric, which is
+// reported along with the next request metrics.
+func (r *ProtobufStatsReporter) ReportCustomMetric(value float64) {
+	atomic.StoreUint64(&r.customMetric, math.Float64bits(value))
+}
+
+// ReportLatencyHistogram captures the counts of the latency histogram collected
+// over the reporting period, which are reported along with the next request metrics.
+func (r *ProtobufStatsReporter) ReportLatencyHistogram(counts []float64) {
+	r.latencies.Store(counts)
+}
+
+// ReportQueue captures the stats of the requests waiting in the breaker
+// queue over the reporting period, which are reported along with the next
+// request metrics.
+func (r *ProtobufStatsReporter) ReportQueue(report QueueStatsReport) {
+	r.queue.Store(report)
+}
+
+// latencyHistogram returns the last latency histogram counts as a rate
+// per second, like the request counts.
+func (r *ProtobufStatsReporter) latencyHistogram() []float64 {
+	counts, _ := r.latencies.Load().([]float64)
+	if len(counts) == 0 {
+		return nil
+	}
+	ret := make([]float64, len(counts))
+	for i, c := range counts {
+		ret[i] = c / r.reportingPeriodSeconds
+	}
+	return ret
+}
+
 // ServeHTTP serves the stats in protobuf format over HTTP.
 func (r *ProtobufStatsReporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
-	data := r.stat.Load().(metrics.Stat)
+	data := r.Stat()
 	buffer, err := proto.Marshal(&data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -25,6 +25,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	network "knative.dev/networking/pkg"
 	"knative.dev/serving/pkg/autoscaler/metrics"
 )
 
@@ -47,6 +48,48 @@ func TestProtobufStatsReporterReport(t *testing.T) {
 	}
 }
 
+func TestProtobufStatsReporterReportCustomMetric(t *testing.T) {
+	reporter := NewProtobufStatsReporter(pod, time.Second)
+	reporter.ReportCustomMetric(42)
+	if got := scrapeProtobufStat(t, reporter).CustomMetric; got != 0 {
+		t.Errorf("CustomMetric before Report = %v, want: 0", got)
+	}
+
+	reporter.Report(network.RequestStatsReport{AverageConcurrency: 1})
+	if got := scrapeProtobufStat(t, reporter).CustomMetric; got != 42 {
+		t.Errorf("CustomMetric = %v, want: 42", got)
+	}
+}
+
+func TestProtobufStatsReporterReportLatencyHistogram(t *testing.T) {
+	reporter := NewProtobufStatsReporter(pod, 2*time.Second)
+	reporter.ReportLatencyHistogram([]float64{4, 0, 2})
+	reporter.Report(network.RequestStatsReport{AverageConcurrency: 1})
+	// The counts are reported per second.
+	if got, want := scrapeProtobufStat(t, reporter).LatencyHistogram, []float64{2, 0, 1}; !cmp.Equal(got, want) {
+		t.Errorf("LatencyHistogram = %v, want: %v", got, want)
+	}
+}
+
+func TestProtobufStatsReporterReportQueue(t *testing.T) {
+	reporter := NewProtobufStatsReporter(pod, time.Second)
+	reporter.ReportQueue(QueueStatsReport{AverageQueued: 2.5, AverageWait: 1500 * time.Microsecond})
+	reporter.Report(network.RequestStatsReport{AverageConcurrency: 1})
+	got := scrapeProtobufStat(t, reporter)
+	if got.AverageQueuedRequests != 2.5 || got.AverageQueueWait != 1.5 {
+		t.Errorf("Queue = %v, %v, want: 2.5, 1.5", got.AverageQueuedRequests, got.AverageQueueWait)
+	}
+}
+
+func TestProtobufStatsReporterStat(t *testing.T) {
+	reporter := NewProtobufStatsReporter(pod, time.Second)
+	reporter.Report(network.RequestStatsReport{AverageConcurrency: 3})
+	// The pushed stat is the same as the scraped one.
+	if got, want := reporter.Stat(), scrapeProtobufStat(t, reporter); !cmp.Equal(got, want) {
+		t.Errorf("Stat() mismatch; diff(-want,+got):\n%s", cmp.Diff(want, got))
+	}
+}
+
 func TestInitialProtobufStateValid(t *testing.T) {
 	r := NewProtobufStatsReporter(pod, 1*time.Second)
 	emptyStat := metrics.Stat{
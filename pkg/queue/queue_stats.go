@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+	"time"
+)
+
+// QueueStats computes the average number of requests waiting for a slot
+// within a concurrency limit and the average time they waited, over
+// reporting periods.
+type QueueStats struct {
+	mux sync.Mutex
+
+	// queued is the number of requests currently waiting.
+	queued float64
+	// computedQueued is the integral of queued over the current reporting
+	// period, in request-seconds.
+	computedQueued float64
+	// lastChange is the time queued last changed, or the start of the
+	// current reporting period if it did not change since.
+	lastChange  time.Time
+	periodStart time.Time
+
+	// dequeued is the number of requests that stopped waiting over the
+	// current reporting period and waited is their total wait.
+	dequeued float64
+	waited   time.Duration
+}
+
+// QueueStatsReport are the queue stats of a reporting period.
+type QueueStatsReport struct {
+	// AverageQueued is the average number of requests waiting.
+	AverageQueued float64
+	// AverageWait is the average time the requests that stopped waiting
+	// over the period waited.
+	AverageWait time.Duration
+}
+
+// NewQueueStats builds a QueueStats whose first reporting period starts at
+// the given time.
+func NewQueueStats(startedAt time.Time) *QueueStats {
+	return &QueueStats{
+		lastChange:  startedAt,
+		periodStart: startedAt,
+	}
+}
+
+// compute adds the requests waiting since the last change to the integral.
+// mux needs to be held.
+func (s *QueueStats) compute(now time.Time) {
+	if d := now.Sub(s.lastChange); d > 0 {
+		s.computedQueued += s.queued * d.Seconds()
+		s.lastChange = now
+	}
+}
+
+// Enqueue records a request starting to wait at the given time.
+func (s *QueueStats) Enqueue(now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.compute(now)
+	s.queued++
+}
+
+// Dequeue records a request that started to wait at enqueued stopping to
+// wait at the given time, whether it got a slot or gave up.
+func (s *QueueStats) Dequeue(now, enqueued time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.compute(now)
+	s.queued--
+	s.dequeued++
+	s.waited += now.Sub(enqueued)
+}
+
+// Report returns the stats of the reporting period ending at the given time,
+// and starts a new one.
+func (s *QueueStats) Report(now time.Time) QueueStatsReport {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.compute(now)
+	var report QueueStatsReport
+	if d := now.Sub(s.periodStart); d > 0 {
+		report.AverageQueued = s.computedQueued / d.Seconds()
+	}
+	if s.dequeued > 0 {
+		report.AverageWait = time.Duration(float64(s.waited) / s.dequeued)
+	}
+
+	s.computedQueued = 0
+	s.dequeued = 0
+	s.waited = 0
+	s.periodStart = now
+	return report
+}
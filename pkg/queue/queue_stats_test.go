@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueStats(t *testing.T) {
+	start := time.Now()
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	s := NewQueueStats(start)
+
+	if got, want := s.Report(at(time.Second)), (QueueStatsReport{}); got != want {
+		t.Errorf("Report() = %v, want: %v", got, want)
+	}
+
+	// One request waits the whole period, another one half of it.
+	s.Enqueue(at(time.Second))
+	s.Enqueue(at(time.Second))
+	s.Dequeue(at(1500*time.Millisecond), at(time.Second))
+	want := QueueStatsReport{AverageQueued: 1.5, AverageWait: 500 * time.Millisecond}
+	if got := s.Report(at(2 * time.Second)); got != want {
+		t.Errorf("Report() = %v, want: %v", got, want)
+	}
+
+	// The request still waiting is accounted for in the next period.
+	s.Dequeue(at(3*time.Second), at(time.Second))
+	want = QueueStatsReport{AverageQueued: 0.5, AverageWait: 2 * time.Second}
+	if got := s.Report(at(4 * time.Second)); got != want {
+		t.Errorf("Report() = %v, want: %v", got, want)
+	}
+
+	if got, want := s.Report(at(5*time.Second)), (QueueStatsReport{}); got != want {
+		t.Errorf("Report() = %v, want: %v", got, want)
+	}
+}
@@ -19,6 +19,8 @@ package queue
 import (
 	"context"
 	"net/http"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats"
@@ -27,6 +29,7 @@ import (
 
 	network "knative.dev/networking/pkg"
 	pkgmetrics "knative.dev/pkg/metrics"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 	pkghttp "knative.dev/serving/pkg/http"
 	"knative.dev/serving/pkg/metrics"
 )
@@ -205,6 +208,61 @@ func (h *appRequestMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 	h.next.ServeHTTP(rr, r)
 }
 
+// LatencyHistogram counts the requests by their latency in the buckets of
+// the latency histogram reported to the autoscaler.
+type LatencyHistogram struct {
+	// counts are accessed atomically. The last one counts the requests
+	// exceeding all bucket bounds.
+	counts []uint64
+}
+
+// NewLatencyHistogram creates an empty LatencyHistogram.
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{
+		counts: make([]uint64, len(asmetrics.LatencyBucketBounds)+1),
+	}
+}
+
+// Observe counts a request with the given latency.
+func (h *LatencyHistogram) Observe(latency time.Duration) {
+	ms := float64(latency) / float64(time.Millisecond)
+	atomic.AddUint64(&h.counts[sort.SearchFloat64s(asmetrics.LatencyBucketBounds, ms)], 1)
+}
+
+// Snapshot returns the counts of the requests observed since the previous
+// snapshot.
+func (h *LatencyHistogram) Snapshot() []float64 {
+	ret := make([]float64, len(h.counts))
+	for i := range h.counts {
+		ret[i] = float64(atomic.SwapUint64(&h.counts[i], 0))
+	}
+	return ret
+}
+
+type latencyHistogramHandler struct {
+	next      http.Handler
+	latencies *LatencyHistogram
+}
+
+// NewLatencyHistogramHandler creates an http.Handler that counts the latencies
+// of the requests, excluding probes, in the given histogram.
+func NewLatencyHistogramHandler(next http.Handler, latencies *LatencyHistogram) http.Handler {
+	return &latencyHistogramHandler{
+		next:      next,
+		latencies: latencies,
+	}
+}
+
+func (h *latencyHistogramHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	defer func() {
+		if !network.IsProbe(r) {
+			h.latencies.Observe(time.Since(startTime))
+		}
+	}()
+	h.next.ServeHTTP(w, r)
+}
+
 /*
 TODO: add the routeTag back after stackdriver adds support for it.
 https://github.com/knative/serving/issues/8970
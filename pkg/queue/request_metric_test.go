@@ -21,12 +21,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"go.opencensus.io/resource"
 	network "knative.dev/networking/pkg"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 	_ "knative.dev/pkg/metrics/testing"
+	asmetrics "knative.dev/serving/pkg/autoscaler/metrics"
 )
 
 const targetURI = "http://example.com"
@@ -289,6 +292,50 @@ func TestAppRequestMetricsHandler(t *testing.T) {
 	metricstest.AssertMetric(t, metricstest.DistributionCountOnlyMetric("app_request_latencies", 1, wantTags).WithResource(wantResource))
 }
 
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogram()
+	h.Observe(3 * time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(7 * time.Millisecond)
+	h.Observe(time.Hour)
+
+	got := h.Snapshot()
+	if len(got) != len(asmetrics.LatencyBucketBounds)+1 {
+		t.Fatalf("len(Snapshot()) = %d, want: %d", len(got), len(asmetrics.LatencyBucketBounds)+1)
+	}
+	// The bounds are inclusive.
+	if got[0] != 2 || got[1] != 1 || got[len(got)-1] != 1 {
+		t.Errorf("Snapshot() = %v, want 2 requests in the first, 1 in the second and 1 in the last bucket", got)
+	}
+
+	// The snapshot resets the counts.
+	if got, want := h.Snapshot(), make([]float64, len(got)); !cmp.Equal(got, want) {
+		t.Errorf("Second Snapshot() = %v, want: %v", got, want)
+	}
+}
+
+func TestLatencyHistogramHandler(t *testing.T) {
+	latencies := NewLatencyHistogram()
+	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := NewLatencyHistogramHandler(baseHandler, latencies)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, targetURI, bytes.NewBufferString("test"))
+	handler.ServeHTTP(resp, req)
+
+	// A probe request should not be observed.
+	req.Header.Set(network.ProbeHeaderName, "activator")
+	handler.ServeHTTP(resp, req)
+
+	var total float64
+	for _, c := range latencies.Snapshot() {
+		total += c
+	}
+	if total != 1 {
+		t.Errorf("Observed requests = %v, want: 1", total)
+	}
+}
+
 func BenchmarkRequestMetricsHandler(b *testing.B) {
 	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
 	handler, _ := NewRequestMetricsHandler(baseHandler, "ns", "svc", "cfg", "rev", "pod")
@@ -59,6 +59,13 @@ func MakeHPA(pa *v1alpha1.PodAutoscaler, config *autoscalerconfig.Config) *autos
 		hpa.Spec.MinReplicas = &min
 	}
 
+	if mt := pa.MetricType(); mt != "" {
+		if metric, ok := makeAdapterMetric(pa, mt); ok {
+			hpa.Spec.Metrics = []autoscalingv2beta1.MetricSpec{metric}
+		}
+		return hpa
+	}
+
 	switch pa.Metric() {
 	case autoscaling.CPU:
 		if target, ok := pa.Target(); ok {
@@ -89,3 +96,75 @@ func MakeHPA(pa *v1alpha1.PodAutoscaler, config *autoscalerconfig.Config) *autos
 	}
 	return hpa
 }
+
+// makeAdapterMetric builds the spec of the metric of the given type served by
+// a metrics adapter, from the annotations of the PA.
+func makeAdapterMetric(pa *v1alpha1.PodAutoscaler, metricType string) (autoscalingv2beta1.MetricSpec, bool) {
+	t, ok := pa.Target()
+	if !ok {
+		return autoscalingv2beta1.MetricSpec{}, false
+	}
+	target := resource.NewMilliQuantity(int64(math.Ceil(t*1000)), resource.DecimalSI)
+	var selector *metav1.LabelSelector
+	if s, ok := pa.Annotations[autoscaling.MetricSelectorAnnotationKey]; ok {
+		// The selector is checked by the annotation validation.
+		if selector, _ = metav1.ParseToLabelSelector(s); selector != nil && len(selector.MatchExpressions) == 0 {
+			// As the API server would store it.
+			selector.MatchExpressions = nil
+		}
+	}
+	average := pa.Annotations[autoscaling.MetricTargetTypeAnnotationKey] == autoscaling.MetricTargetAverageValue
+
+	switch metricType {
+	case autoscaling.MetricTypePods:
+		return autoscalingv2beta1.MetricSpec{
+			Type: autoscalingv2beta1.PodsMetricSourceType,
+			Pods: &autoscalingv2beta1.PodsMetricSource{
+				MetricName:         pa.Metric(),
+				TargetAverageValue: *target,
+				Selector:           selector,
+			},
+		}, true
+	case autoscaling.MetricTypeObject:
+		ref := autoscalingv2beta1.CrossVersionObjectReference{
+			APIVersion: servingv1.SchemeGroupVersion.String(),
+			Kind:       "revision",
+			Name:       pa.Name,
+		}
+		if o, err := autoscaling.ParseMetricObject(pa.Annotations[autoscaling.MetricObjectAnnotationKey]); err == nil {
+			ref = autoscalingv2beta1.CrossVersionObjectReference{
+				APIVersion: o.APIVersion,
+				Kind:       o.Kind,
+				Name:       o.Name,
+			}
+		}
+		source := &autoscalingv2beta1.ObjectMetricSource{
+			Target:      ref,
+			MetricName:  pa.Metric(),
+			TargetValue: *target,
+			Selector:    selector,
+		}
+		if average {
+			source.AverageValue = target
+		}
+		return autoscalingv2beta1.MetricSpec{
+			Type:   autoscalingv2beta1.ObjectMetricSourceType,
+			Object: source,
+		}, true
+	case autoscaling.MetricTypeExternal:
+		source := &autoscalingv2beta1.ExternalMetricSource{
+			MetricName:     pa.Metric(),
+			MetricSelector: selector,
+		}
+		if average {
+			source.TargetAverageValue = target
+		} else {
+			source.TargetValue = target
+		}
+		return autoscalingv2beta1.MetricSpec{
+			Type:     autoscalingv2beta1.ExternalMetricSourceType,
+			External: source,
+		}, true
+	}
+	return autoscalingv2beta1.MetricSpec{}, false
+}
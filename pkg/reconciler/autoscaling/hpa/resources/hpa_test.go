@@ -159,6 +159,115 @@ func TestMakeHPA(t *testing.T) {
 					TargetValue:  *resource.NewQuantity(50, resource.DecimalSI),
 				},
 			})),
+	}, {
+		name: "with pods metric",
+		pa: pa(WithTargetAnnotation("2.5"), WithMetricAnnotation("queue_backlog"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypePods),
+			withPAAnnotation(autoscaling.MetricSelectorAnnotationKey, "queue=orders")),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_backlog"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "2.5"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypePods),
+			withAnnotationValue(autoscaling.MetricSelectorAnnotationKey, "queue=orders"),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.PodsMetricSourceType,
+				Pods: &autoscalingv2beta1.PodsMetricSource{
+					MetricName:         "queue_backlog",
+					TargetAverageValue: *resource.NewMilliQuantity(2500, resource.DecimalSI),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"queue": "orders"},
+					},
+				},
+			})),
+	}, {
+		name: "with object metric of the revision",
+		pa: pa(WithTargetAnnotation("30"), WithMetricAnnotation("queue_backlog"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeObject)),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_backlog"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "30"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeObject),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ObjectMetricSourceType,
+				Object: &autoscalingv2beta1.ObjectMetricSource{
+					Target: autoscalingv2beta1.CrossVersionObjectReference{
+						APIVersion: servingv1.SchemeGroupVersion.String(),
+						Kind:       "revision",
+						Name:       testName,
+					},
+					MetricName:  "queue_backlog",
+					TargetValue: *resource.NewMilliQuantity(30000, resource.DecimalSI),
+				},
+			})),
+	}, {
+		name: "with averaged object metric",
+		pa: pa(WithTargetAnnotation("30"), WithMetricAnnotation("queue_backlog"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeObject),
+			withPAAnnotation(autoscaling.MetricObjectAnnotationKey, "v1/Service/broker"),
+			withPAAnnotation(autoscaling.MetricTargetTypeAnnotationKey, autoscaling.MetricTargetAverageValue)),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_backlog"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "30"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeObject),
+			withAnnotationValue(autoscaling.MetricObjectAnnotationKey, "v1/Service/broker"),
+			withAnnotationValue(autoscaling.MetricTargetTypeAnnotationKey, autoscaling.MetricTargetAverageValue),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ObjectMetricSourceType,
+				Object: &autoscalingv2beta1.ObjectMetricSource{
+					Target: autoscalingv2beta1.CrossVersionObjectReference{
+						APIVersion: "v1",
+						Kind:       "Service",
+						Name:       "broker",
+					},
+					MetricName:   "queue_backlog",
+					AverageValue: resource.NewMilliQuantity(30000, resource.DecimalSI),
+					TargetValue:  *resource.NewMilliQuantity(30000, resource.DecimalSI),
+				},
+			})),
+	}, {
+		name: "with external metric",
+		pa: pa(WithTargetAnnotation("30"), WithMetricAnnotation("queue_messages_ready"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal),
+			withPAAnnotation(autoscaling.MetricSelectorAnnotationKey, "queue=orders")),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_messages_ready"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "30"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal),
+			withAnnotationValue(autoscaling.MetricSelectorAnnotationKey, "queue=orders"),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ExternalMetricSourceType,
+				External: &autoscalingv2beta1.ExternalMetricSource{
+					MetricName: "queue_messages_ready",
+					MetricSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"queue": "orders"},
+					},
+					TargetValue: resource.NewMilliQuantity(30000, resource.DecimalSI),
+				},
+			})),
+	}, {
+		name: "with averaged external metric",
+		pa: pa(WithTargetAnnotation("30"), WithMetricAnnotation("queue_messages_ready"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal),
+			withPAAnnotation(autoscaling.MetricTargetTypeAnnotationKey, autoscaling.MetricTargetAverageValue)),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_messages_ready"),
+			withAnnotationValue(autoscaling.TargetAnnotationKey, "30"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal),
+			withAnnotationValue(autoscaling.MetricTargetTypeAnnotationKey, autoscaling.MetricTargetAverageValue),
+			withMetric(autoscalingv2beta1.MetricSpec{
+				Type: autoscalingv2beta1.ExternalMetricSourceType,
+				External: &autoscalingv2beta1.ExternalMetricSource{
+					MetricName:         "queue_messages_ready",
+					TargetAverageValue: resource.NewMilliQuantity(30000, resource.DecimalSI),
+				},
+			})),
+	}, {
+		name: "with external metric without target",
+		pa: pa(WithMetricAnnotation("queue_messages_ready"),
+			withPAAnnotation(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal)),
+		want: hpa(
+			withAnnotationValue(autoscaling.MetricAnnotationKey, "queue_messages_ready"),
+			withAnnotationValue(autoscaling.MetricTypeAnnotationKey, autoscaling.MetricTypeExternal)),
 	}}
 
 	for _, tc := range cases {
@@ -234,6 +343,12 @@ func hpa(options ...hpaOption) *autoscalingv2beta1.HorizontalPodAutoscaler {
 	return h
 }
 
+func withPAAnnotation(key, value string) PodAutoscalerOption {
+	return func(pa *v1alpha1.PodAutoscaler) {
+		pa.Annotations[key] = value
+	}
+}
+
 type hpaOption func(*autoscalingv2beta1.HorizontalPodAutoscaler)
 
 func withAnnotationValue(key, value string) hpaOption {
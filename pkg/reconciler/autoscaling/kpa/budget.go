@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpa
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/kmeta"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+)
+
+// namespaceBudget computes the pods the revisions of a namespace can scale to
+// within the budget set by the namespace's namespaceMaxScale annotation.
+type namespaceBudget struct {
+	nsLister corev1listers.NamespaceLister
+	paLister palisters.PodAutoscalerLister
+}
+
+func newNamespaceBudget(nsLister corev1listers.NamespaceLister, paLister palisters.PodAutoscalerLister) *namespaceBudget {
+	return &namespaceBudget{
+		nsLister: nsLister,
+		paLister: paLister,
+	}
+}
+
+// available returns the number of pods the given PA can scale to without
+// exceeding the budget of its namespace, or false if the namespace has no budget.
+// The budget is shared with the pods held by the other PAs of the namespace
+// as of their last reconciliation, so concurrent scale ups may briefly exceed it.
+func (b *namespaceBudget) available(pa *pav1alpha1.PodAutoscaler) (int32, bool, error) {
+	ns, err := b.nsLister.Get(pa.Namespace)
+	if apierrs.IsNotFound(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	v, ok := ns.Annotations[autoscaling.NamespaceMaxScaleAnnotationKey]
+	if !ok {
+		return 0, false, nil
+	}
+	budget, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || budget < 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q of namespace %s",
+			autoscaling.NamespaceMaxScaleAnnotationKey, v, pa.Namespace)
+	}
+
+	pas, err := b.paLister.PodAutoscalers(pa.Namespace).List(labels.Everything())
+	if err != nil {
+		return 0, false, err
+	}
+	available := int32(budget)
+	for _, other := range pas {
+		if other.Name != pa.Name {
+			available -= other.Status.PodCount()
+		}
+	}
+	if available < 0 {
+		available = 0
+	}
+	return available, true, nil
+}
+
+// budgetEnqueuer enqueues the PAs whose scale may change with the pods
+// available in the budget of their namespace.
+type budgetEnqueuer struct {
+	logger   *zap.SugaredLogger
+	paLister palisters.PodAutoscalerLister
+	// filter selects the PAs that are limited by the budget.
+	filter  func(interface{}) bool
+	enqueue func(interface{})
+}
+
+// enqueueNamespace enqueues the PAs of the namespace, or only those whose
+// scale is limited by the budget if limitedOnly is set.
+func (e *budgetEnqueuer) enqueueNamespace(namespace string, limitedOnly bool) {
+	pas, err := e.paLister.PodAutoscalers(namespace).List(labels.Everything())
+	if err != nil {
+		e.logger.Errorw("Failed to list the PodAutoscalers of namespace "+namespace, zap.Error(err))
+		return
+	}
+	for _, pa := range pas {
+		if e.filter(pa) && (!limitedOnly || pa.Status.IsScaleLimited()) {
+			e.enqueue(pa)
+		}
+	}
+}
+
+// namespaceHandler enqueues the PAs of a namespace when its budget changes.
+func (e *budgetEnqueuer) namespaceHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNs, newNs := oldObj.(*corev1.Namespace), newObj.(*corev1.Namespace)
+			if oldNs.Annotations[autoscaling.NamespaceMaxScaleAnnotationKey] !=
+				newNs.Annotations[autoscaling.NamespaceMaxScaleAnnotationKey] {
+				e.enqueueNamespace(newNs.Name, false /*limitedOnly*/)
+			}
+		},
+	}
+}
+
+// podAutoscalerHandler enqueues the PAs limited by the budget of a namespace
+// when another PA of the namespace releases pods, so they can take them.
+func (e *budgetEnqueuer) podAutoscalerHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPA, newPA := oldObj.(*pav1alpha1.PodAutoscaler), newObj.(*pav1alpha1.PodAutoscaler)
+			if newPA.Status.PodCount() < oldPA.Status.PodCount() {
+				e.enqueueNamespace(newPA.Namespace, true /*limitedOnly*/)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			accessor, err := kmeta.DeletionHandlingAccessor(obj)
+			if err != nil {
+				e.logger.Errorw("Error accessing object", zap.Error(err))
+				return
+			}
+			e.enqueueNamespace(accessor.GetNamespace(), true /*limitedOnly*/)
+		},
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpa
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	asv1a1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+
+	. "knative.dev/serving/pkg/reconciler/testing/v1"
+)
+
+func namespace(budget string) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testNamespace,
+		},
+	}
+	if budget != "" {
+		ns.Annotations = map[string]string{autoscaling.NamespaceMaxScaleAnnotationKey: budget}
+	}
+	return ns
+}
+
+func TestNamespaceBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		objs      []runtime.Object
+		want      int32
+		wantOK    bool
+		wantError bool
+	}{{
+		name: "no namespace",
+	}, {
+		name: "no budget",
+		objs: []runtime.Object{namespace("")},
+	}, {
+		name:      "invalid budget",
+		objs:      []runtime.Object{namespace("lots")},
+		wantError: true,
+	}, {
+		name:   "whole budget",
+		objs:   []runtime.Object{namespace("10")},
+		want:   10,
+		wantOK: true,
+	}, {
+		name: "the pods of the PA itself are not counted",
+		objs: []runtime.Object{
+			namespace("10"),
+			kpa(testNamespace, testRevision, withScales(4, 4)),
+		},
+		want:   10,
+		wantOK: true,
+	}, {
+		name: "shared budget",
+		objs: []runtime.Object{
+			namespace("10"),
+			kpa(testNamespace, "other", withScales(4, 3)),
+			kpa(testNamespace, "warm", withScales(0, 0), withWarmScale(1)),
+			kpa("other-namespace", "other", withScales(5, 5)),
+		},
+		want:   5,
+		wantOK: true,
+	}, {
+		name: "exhausted budget",
+		objs: []runtime.Object{
+			namespace("10"),
+			kpa(testNamespace, "other", withScales(12, 12)),
+		},
+		want:   0,
+		wantOK: true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			listers := NewListers(tc.objs)
+			b := newNamespaceBudget(listers.GetNamespaceLister(), listers.GetPodAutoscalerLister())
+			got, ok, err := b.available(kpa(testNamespace, testRevision))
+			if (err != nil) != tc.wantError {
+				t.Fatalf("available() error = %v, want error: %v", err, tc.wantError)
+			}
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("available() = %d, %v; want: %d, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func withScaleLimited(pa *asv1a1.PodAutoscaler) {
+	pa.Status.MarkScaleLimited("NamespaceBudgetExhausted", "")
+}
+
+func withHPAClass(pa *asv1a1.PodAutoscaler) {
+	pa.Annotations[autoscaling.ClassAnnotationKey] = autoscaling.HPA
+}
+
+func TestBudgetEnqueuer(t *testing.T) {
+	budgetNS := namespace("10")
+	labeledNS := budgetNS.DeepCopy()
+	labeledNS.Labels = map[string]string{"team": "a"}
+	otherBudgetNS := namespace("20")
+
+	other := kpa(testNamespace, "other", withScales(4, 4))
+	shrunk := kpa(testNamespace, "other", withScales(2, 2))
+
+	tests := []struct {
+		name string
+		// event sends the event to the handlers.
+		event func(ns, pa cache.ResourceEventHandler)
+		want  sets.String
+	}{{
+		name: "budget changed",
+		event: func(ns, _ cache.ResourceEventHandler) {
+			ns.OnUpdate(budgetNS, otherBudgetNS)
+		},
+		want: sets.NewString("limited", "unlimited", "other"),
+	}, {
+		name: "budget unchanged",
+		event: func(ns, _ cache.ResourceEventHandler) {
+			ns.OnUpdate(budgetNS, labeledNS)
+		},
+		want: sets.NewString(),
+	}, {
+		name: "pods released",
+		event: func(_, pa cache.ResourceEventHandler) {
+			pa.OnUpdate(other, shrunk)
+		},
+		want: sets.NewString("limited"),
+	}, {
+		name: "pods taken",
+		event: func(_, pa cache.ResourceEventHandler) {
+			pa.OnUpdate(shrunk, other)
+		},
+		want: sets.NewString(),
+	}, {
+		name: "PA deleted",
+		event: func(_, pa cache.ResourceEventHandler) {
+			pa.OnDelete(cache.DeletedFinalStateUnknown{Key: testNamespace + "/other", Obj: other})
+		},
+		want: sets.NewString("limited"),
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			listers := NewListers([]runtime.Object{
+				kpa(testNamespace, "limited", withScaleLimited),
+				kpa(testNamespace, "unlimited"),
+				other,
+				kpa(testNamespace, "hpa", withHPAClass, withScaleLimited),
+				kpa("other-namespace", "limited", withScaleLimited),
+			})
+			got := sets.NewString()
+			e := &budgetEnqueuer{
+				logger:   logtesting.TestLogger(t),
+				paLister: listers.GetPodAutoscalerLister(),
+				filter: pkgreconciler.AnnotationFilterFunc(
+					autoscaling.ClassAnnotationKey, autoscaling.KPA, false /*allowUnset*/),
+				enqueue: func(obj interface{}) {
+					pa := obj.(*asv1a1.PodAutoscaler)
+					if pa.Namespace != testNamespace {
+						t.Errorf("Enqueued %s/%s of another namespace", pa.Namespace, pa.Name)
+					}
+					got.Insert(pa.Name)
+				},
+			}
+			tc.event(e.namespaceHandler(), e.podAutoscalerHandler())
+			if !got.Equal(tc.want) {
+				t.Error("Enqueued PAs (-want, +got):", cmp.Diff(tc.want.List(), got.List()))
+			}
+		})
+	}
+}
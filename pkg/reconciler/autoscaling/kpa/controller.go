@@ -24,6 +24,7 @@ import (
 
 	networkingclient "knative.dev/networking/pkg/client/injection/client"
 	sksinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/serverlessservice"
+	nsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
 	servingclient "knative.dev/serving/pkg/client/injection/client"
 	"knative.dev/serving/pkg/client/injection/ducks/autoscaling/v1alpha1/podscalable"
@@ -40,6 +41,7 @@ import (
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+	"knative.dev/serving/pkg/autoscaler/metrics"
 	"knative.dev/serving/pkg/deployment"
 	servingreconciler "knative.dev/serving/pkg/reconciler"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
@@ -55,12 +57,14 @@ func NewController(
 	ctx context.Context,
 	cmw configmap.Watcher,
 	deciders resources.Deciders,
+	podMetrics metrics.PodMetricClient,
 ) *controller.Impl {
 	ctx = servingreconciler.AnnotateLoggerWithName(ctx, controllerAgentName)
 	logger := logging.FromContext(ctx)
 	paInformer := painformer.Get(ctx)
 	sksInformer := sksinformer.Get(ctx)
 	podsInformer := podinformer.Get(ctx)
+	nsInformer := nsinformer.Get(ctx)
 	metricInformer := metricinformer.Get(ctx)
 	psInformerFactory := podscalable.Get(ctx)
 
@@ -90,7 +94,8 @@ func NewController(
 		configStore.WatchConfigs(cmw)
 		return controller.Options{ConfigStore: configStore}
 	})
-	c.scaler = newScaler(ctx, psInformerFactory, impl.EnqueueAfter)
+	c.scaler = newScaler(ctx, psInformerFactory, podsInformer.Lister(), podMetrics,
+		newNamespaceBudget(nsInformer.Lister(), paInformer.Lister()), impl.EnqueueAfter)
 
 	logger.Info("Setting up KPA-Class event handlers")
 
@@ -125,6 +130,17 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueLabelOfNamespaceScopedResource("", serving.RevisionLabelKey)),
 	})
 
+	// Reconcile the PAs whose scale is limited by the pod budget of their
+	// namespace when the budget changes or other PAs release pods.
+	budgetEnqueuer := &budgetEnqueuer{
+		logger:   logger,
+		paLister: paInformer.Lister(),
+		filter:   onlyKPAClass,
+		enqueue:  impl.Enqueue,
+	}
+	nsInformer.Informer().AddEventHandler(budgetEnqueuer.namespaceHandler())
+	paInformer.Informer().AddEventHandler(budgetEnqueuer.podAutoscalerHandler())
+
 	// Have the Deciders enqueue the PAs whose decisions have changed.
 	deciders.Watch(impl.EnqueueKey)
 
@@ -101,6 +101,9 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pa *pav1alpha1.PodAutosc
 	if err != nil {
 		return fmt.Errorf("error reconciling Decider: %w", err)
 	}
+	if t := decider.Status.LastRequestTime; !t.IsZero() {
+		pa.Status.LastRequestTime = &t
+	}
 
 	if err := c.ReconcileMetric(ctx, pa, resolveScrapeTarget(ctx, pa)); err != nil {
 		return fmt.Errorf("error reconciling Metric: %w", err)
@@ -108,7 +111,11 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pa *pav1alpha1.PodAutosc
 
 	// Get the appropriate current scale from the metric, and right size
 	// the scaleTargetRef based on it.
-	want, err := c.scaler.scale(ctx, pa, sks, decider.Status.DesiredScale)
+	var decision *scaling.ScaleDecision
+	if n := len(decider.Status.Decisions); n > 0 {
+		decision = &decider.Status.Decisions[n-1]
+	}
+	want, err := c.scaler.scale(ctx, pa, sks, decider.Status.DesiredScale, decision)
 	if err != nil {
 		return fmt.Errorf("error scaling target: %w", err)
 	}
@@ -196,6 +203,13 @@ func computeStatus(ctx context.Context, pa *pav1alpha1.PodAutoscaler, pc podCoun
 
 	reportMetrics(pa, pc)
 	computeActiveCondition(ctx, pa, pc)
+
+	// The ready pods of an inactive PA with a warm pool are kept warm behind
+	// the activator rather than serving.
+	pa.Status.WarmScale = nil
+	if warm, ok := pa.WarmPool(); ok && warm > 0 && pa.Status.IsInactive() {
+		pa.Status.WarmScale, pa.Status.ActualScale = ptr.Int32(int32(pc.ready)), ptr.Int32(0)
+	}
 	logger.Debugf("PA Status after reconcile: %#v", pa.Status.Status)
 }
 
@@ -273,10 +287,11 @@ func computeActiveCondition(ctx context.Context, pa *pav1alpha1.PodAutoscaler, p
 	}
 }
 
-// activeThreshold returns the scale required for the pa to be marked Active
+// activeThreshold returns the scale required for the pa to be marked Active,
+// including the min scale of the active minScaleSchedule window.
 func activeThreshold(ctx context.Context, pa *pav1alpha1.PodAutoscaler) int {
 	asConfig := config.FromContext(ctx).Autoscaler
-	min, _ := pa.ScaleBounds(asConfig)
+	min, _ := scaleBounds(pa, asConfig)
 	if !pa.Status.IsScaleTargetInitialized() {
 		initialScale := resources.GetInitialScale(asConfig, pa)
 		return int(intMax(min, initialScale))
@@ -32,6 +32,7 @@ import (
 	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
 	fakesksinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/serverlessservice/fake"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace/fake"
 	fakepodsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
 	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
 	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
@@ -155,6 +156,18 @@ func withScales(g, w int32) PodAutoscalerOption {
 	}
 }
 
+func withLastRequestTime(t time.Time) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Status.LastRequestTime = &metav1.Time{Time: t}
+	}
+}
+
+func withWarmScale(w int32) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Status.WarmScale = ptr.Int32(w)
+	}
+}
+
 func metricWithDiffSvc(ns, n string) *asv1a1.Metric {
 	m := metric(ns, n)
 	m.Spec.ScrapeTarget = "something-else"
@@ -237,6 +250,9 @@ func TestReconcile(t *testing.T) {
 		d.Spec.Replicas = ptr.Int32(overscale)
 	})
 
+	// The status only keeps the time to the second.
+	lastRequest := time.Now().Add(-time.Hour).Truncate(time.Second)
+
 	minScalePatch := clientgotesting.PatchActionImpl{
 		ActionImpl: clientgotesting.ActionImpl{Namespace: testNamespace},
 		Name:       deployName,
@@ -408,6 +424,9 @@ func TestReconcile(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":11}]`),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 1 to 11"),
+		},
 	}, {
 		Name: "scale up deployment failure",
 		Key:  key,
@@ -685,6 +704,32 @@ func TestReconcile(t *testing.T) {
 				d.Spec.Replicas = ptr.Int32(0)
 			}),
 		},
+	}, {
+		Name: "steady not serving, reports the last request",
+		Key:  key,
+		Ctx: context.WithValue(context.Background(), deciderKey{}, func() *scaling.Decider {
+			d := decider(testNamespace, testRevision, 0 /* desiredScale */, 0 /* ebc */, scaling.MinActivators)
+			d.Status.LastRequestTime = metav1.NewTime(lastRequest)
+			return d
+		}()),
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, WithScaleTargetInitialized, withScales(0, 0),
+				WithNoTraffic(noTrafficReason, "The target is not receiving traffic."),
+				WithPASKSReady, markOld, WithPAStatusService(testRevision),
+				WithPAMetricsService(privateSvc), WithObservedGeneration(1)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
+			metric(testNamespace, testRevision),
+			deploy(testNamespace, testRevision, func(d *appsv1.Deployment) {
+				d.Spec.Replicas = ptr.Int32(0)
+			}),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, WithScaleTargetInitialized, withScales(0, 0),
+				WithNoTraffic(noTrafficReason, "The target is not receiving traffic."),
+				WithPASKSReady, markOld, WithPAStatusService(testRevision),
+				WithPAMetricsService(privateSvc), WithObservedGeneration(1),
+				withLastRequestTime(lastRequest)),
+		}},
 	}, {
 		Name: "steady not serving (scale to zero)",
 		Key:  key,
@@ -706,6 +751,55 @@ func TestReconcile(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":0}]`),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 1 to 0"),
+		},
+	}, {
+		Name: "steady not serving with a warm pool",
+		Key:  key,
+		Ctx: context.WithValue(context.Background(), deciderKey{},
+			decider(testNamespace, testRevision, 0 /* desiredScale */, 0 /* ebc */, scaling.MinActivators)),
+		Objects: append([]runtime.Object{
+			kpa(testNamespace, testRevision, WithScaleTargetInitialized, withScales(0, 0), withWarmScale(2),
+				WithNoTraffic(noTrafficReason, "The target is not receiving traffic."),
+				WithPASKSReady, markOld, WithPAStatusService(testRevision), WithWarmPoolAnnotation("2"),
+				WithPAMetricsService(privateSvc), WithObservedGeneration(1)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
+			metric(testNamespace, testRevision),
+			deploy(testNamespace, testRevision, func(d *appsv1.Deployment) {
+				d.Spec.Replicas = ptr.Int32(2)
+			}),
+		}, makeReadyPods(2, testNamespace, testRevision)...),
+	}, {
+		Name: "scale to zero keeps a warm pool",
+		Key:  key,
+		Ctx: context.WithValue(context.Background(), deciderKey{},
+			decider(testNamespace, testRevision, 0 /* desiredScale */, 0 /* ebc */, scaling.MinActivators)),
+		Objects: append([]runtime.Object{
+			kpa(testNamespace, testRevision, WithScaleTargetInitialized, withScales(0, 0),
+				WithNoTraffic(noTrafficReason, "The target is not receiving traffic."),
+				WithPASKSReady, markOld, WithPAStatusService(testRevision), WithWarmPoolAnnotation("2"),
+				WithPAMetricsService(privateSvc), WithObservedGeneration(1)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
+			metric(testNamespace, testRevision),
+			deploy(testNamespace, testRevision),
+		}, makeReadyPods(1, testNamespace, testRevision)...),
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNamespace,
+			},
+			Name:  deployName,
+			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":2}]`),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, WithScaleTargetInitialized, withScales(0, 0), withWarmScale(1),
+				WithNoTraffic(noTrafficReason, "The target is not receiving traffic."),
+				WithPASKSReady, markOld, WithPAStatusService(testRevision), WithWarmPoolAnnotation("2"),
+				WithPAMetricsService(privateSvc), WithObservedGeneration(1)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 1 to 2 keeping a warm pool of 2"),
+		},
 	}, {
 		Name: "from serving to proxy",
 		Key:  key,
@@ -769,6 +863,9 @@ func TestReconcile(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":0}]`),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 1 to 0"),
+		},
 	}, {
 		Name: "want=-1, underscaled, PA inactive",
 		// No-op
@@ -803,6 +900,9 @@ func TestReconcile(t *testing.T) {
 			Object: sks(testNamespace, testRevision, WithDeployRef(deployName),
 				WithPubService, WithPrivateService),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 10 to 11"),
+		},
 	}, {
 		Name: "underscaled, PA activating",
 		// Scale to `minScale`
@@ -816,6 +916,9 @@ func TestReconcile(t *testing.T) {
 		WantPatches: []clientgotesting.PatchActionImpl{
 			minScalePatch,
 		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 10 to 11"),
+		},
 	}, {
 		Name: "underscaled, PA active",
 		// Mark PA "activating"
@@ -832,6 +935,9 @@ func TestReconcile(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: activatingKPAMinScale(underscale, markScaleTargetInitialized, WithPASKSReady),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 10 to 11"),
+		},
 	}, {
 		// Scale to `minScale` and mark PA "active"
 		Name: "overscaled, PA inactive",
@@ -852,6 +958,9 @@ func TestReconcile(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: activeKPAMinScale(overscale, defaultScale),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 12 to 11"),
+		},
 	}, {
 		Name: "overscaled, PA activating",
 		// Scale to `minScale` and mark PA "active"
@@ -872,6 +981,9 @@ func TestReconcile(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: activeKPAMinScale(overscale, defaultScale),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 12 to 11"),
+		},
 	}, {
 		Name: "over maxScale for real, PA active",
 		// No-op.
@@ -900,6 +1012,9 @@ func TestReconcile(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: activeKPAMinScale(overscale, defaultScale),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 12 to 11"),
+		},
 	}, {
 		Name: "scaled-to-0-no-scale-data",
 		Key:  key,
@@ -1008,6 +1123,9 @@ func TestReconcile(t *testing.T) {
 			Name:       deployName,
 			Patch:      []byte(fmt.Sprintf(`[{"op":"replace","path":"/spec/replicas","value":%d}]`, 20)),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 11 to 20"),
+		},
 	}, {
 		Name: "initial scale reached, mark PA as active",
 		Key:  key,
@@ -1036,6 +1154,9 @@ func TestReconcile(t *testing.T) {
 			Name:       deployName,
 			Patch:      []byte(fmt.Sprintf(`[{"op":"replace","path":"/spec/replicas","value":%d}]`, 20)),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Scaled", "Scaled from 11 to 20"),
+		},
 	}, {
 		Name: "initial scale zero: scale to zero",
 		Key:  key,
@@ -1180,7 +1301,8 @@ func TestReconcile(t *testing.T) {
 			testConfigs.Autoscaler = asConfig.(*autoscalerconfig.Config)
 		}
 		psf := podscalable.Get(ctx)
-		scaler := newScaler(ctx, psf, func(interface{}, time.Duration) {})
+		budget := newNamespaceBudget(listers.GetNamespaceLister(), listers.GetPodAutoscalerLister())
+		scaler := newScaler(ctx, psf, listers.GetPodsLister(), nil /*podMetrics*/, budget, func(interface{}, time.Duration) {})
 		scaler.activatorProbe = func(*asv1a1.PodAutoscaler, http.RoundTripper) (bool, error) { return true, nil }
 		r := &Reconciler{
 			Base: &areconciler.Base{
@@ -1232,7 +1354,7 @@ func TestGlobalResyncOnUpdateAutoscalerConfigMap(t *testing.T) {
 	watcher := &configmap.ManualWatcher{Namespace: system.Namespace()}
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, watcher, fakeDeciders)
+	ctl := NewController(ctx, watcher, fakeDeciders, nil /*podMetrics*/)
 
 	// Load default config
 	watcher.OnChange(&corev1.ConfigMap{
@@ -1318,7 +1440,7 @@ func TestReconcileDeciderCreatesAndDeletes(t *testing.T) {
 	ctx, cancel, informers := SetupFakeContextWithCancel(t)
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders)
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, nil /*podMetrics*/)
 
 	wf, err := controller.RunInformers(ctx.Done(), informers...)
 	if err != nil {
@@ -1391,7 +1513,7 @@ func TestUpdate(t *testing.T) {
 	t.Cleanup(cancel)
 
 	fakeDeciders := newTestDeciders()
-	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders)
+	ctl := NewController(ctx, newConfigWatcher(), fakeDeciders, nil /*podMetrics*/)
 
 	rev := newTestRevision(testNamespace, testRevision)
 	fakeservingclient.Get(ctx).ServingV1().Revisions(testNamespace).Create(ctx, rev, metav1.CreateOptions{})
@@ -1478,7 +1600,7 @@ func TestControllerCreateError(t *testing.T) {
 		&failingDeciders{
 			getErr:    apierrors.NewNotFound(asv1a1.Resource("Deciders"), key),
 			createErr: want,
-		})
+		}, nil /*podMetrics*/)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(ctx, kpa, metav1.CreateOptions{})
@@ -1519,7 +1641,7 @@ func TestControllerUpdateError(t *testing.T) {
 		&failingDeciders{
 			getErr:    apierrors.NewNotFound(asv1a1.Resource("Deciders"), key),
 			createErr: want,
-		})
+		}, nil /*podMetrics*/)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(ctx, kpa, metav1.CreateOptions{})
@@ -1559,7 +1681,7 @@ func TestControllerGetError(t *testing.T) {
 	ctl := NewController(ctx, newConfigWatcher(),
 		&failingDeciders{
 			getErr: want,
-		})
+		}, nil /*podMetrics*/)
 
 	kpa := revisionresources.MakePA(newTestRevision(testNamespace, testRevision))
 	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(ctx, kpa, metav1.CreateOptions{})
@@ -1594,7 +1716,7 @@ func TestScaleFailure(t *testing.T) {
 		waitInformers()
 	}()
 
-	ctl := NewController(ctx, newConfigWatcher(), newTestDeciders())
+	ctl := NewController(ctx, newConfigWatcher(), newTestDeciders(), nil /*podMetrics*/)
 
 	// Only put the KPA in the lister, which will prompt failures scaling it.
 	rev := newTestRevision(testNamespace, testRevision)
@@ -1845,6 +1967,41 @@ func TestMetricsReporter(t *testing.T) {
 	metricstest.AssertMetric(t, wantMetrics...)
 }
 
+func TestComputeActiveConditionScheduledMin(t *testing.T) {
+	ctx := config.ToContext(context.Background(), defaultConfig())
+	tests := []struct {
+		name           string
+		pc             podCounts
+		wantActivating bool
+	}{{
+		name:           "fewer ready pods than the scheduled min",
+		pc:             podCounts{want: 5, ready: 2},
+		wantActivating: true,
+	}, {
+		name: "scheduled min ready",
+		pc:   podCounts{want: 5, ready: 5},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// As scaled for the open window, while the metric of the PA is
+			// unknown, e.g. because it can't be computed.
+			pa := kpa(testNamespace, testRevision, markScaleTargetInitialized, WithPAStatusService(testRevision))
+			pa.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "always * * * * * 1h 5"
+			pa.Status.ActiveMinScaleSchedule = "always"
+			pa.Status.MarkActive()
+
+			computeActiveCondition(ctx, pa, test.pc)
+			if got := pa.Status.IsActivating(); got != test.wantActivating {
+				t.Errorf("IsActivating() = %v, want: %v", got, test.wantActivating)
+			}
+			if got := pa.Status.IsActive(); got == test.wantActivating {
+				t.Errorf("IsActive() = %v, want: %v", got, !test.wantActivating)
+			}
+		})
+	}
+}
+
 func TestResolveScrapeTarget(t *testing.T) {
 	pa := kpa(testNamespace, testRevision, WithPAMetricsService("echo"))
 	tc := &testConfigStore{config: defaultConfig()}
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	asv1a1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 	"knative.dev/serving/pkg/autoscaler/scaling"
@@ -53,6 +54,21 @@ func MakeDecider(_ context.Context, pa *asv1a1.PodAutoscaler, config *autoscaler
 		panicThresholdPercentage = x
 	}
 
+	panicExitThresholdPercentage := config.PanicExitThresholdPercentage
+	if x, ok := pa.PanicExitThresholdPercentage(); ok {
+		panicExitThresholdPercentage = x
+	}
+
+	panicMinDuration := config.PanicMinDuration
+	if x, ok := pa.PanicMinDuration(); ok {
+		panicMinDuration = x
+	}
+
+	panicCooldown := config.PanicCooldown
+	if x, ok := pa.PanicCooldown(); ok {
+		panicCooldown = x
+	}
+
 	target, total := resources.ResolveMetricTarget(pa, config)
 	panicThreshold := panicThresholdPercentage / 100.0
 
@@ -61,24 +77,49 @@ func MakeDecider(_ context.Context, pa *asv1a1.PodAutoscaler, config *autoscaler
 		tbc = x
 	}
 
+	maxScaleUpRate := config.MaxScaleUpRate
+	if x, ok := pa.MaxScaleUpRate(); ok {
+		maxScaleUpRate = x
+	}
+
+	maxScaleDownRate := config.MaxScaleDownRate
+	if x, ok := pa.MaxScaleDownRate(); ok {
+		maxScaleDownRate = x
+	}
+
 	scaleDownDelay := config.ScaleDownDelay
 	if sdd, ok := pa.ScaleDownDelay(); ok {
 		scaleDownDelay = sdd
 	}
 
+	// The idle timeout has no cluster-wide default.
+	idleTimeout, _ := pa.IdleTimeout()
+
+	var latencyPercentile float64
+	if pa.Metric() == autoscaling.Latency {
+		latencyPercentile = pa.LatencyPercentile()
+	}
+
 	return &scaling.Decider{
 		ObjectMeta: *pa.ObjectMeta.DeepCopy(),
 		Spec: scaling.DeciderSpec{
-			MaxScaleUpRate:      config.MaxScaleUpRate,
-			MaxScaleDownRate:    config.MaxScaleDownRate,
+			Algorithm:           pa.Algorithm(),
+			MaxScaleUpRate:      maxScaleUpRate,
+			MaxScaleDownRate:    maxScaleDownRate,
 			ScalingMetric:       pa.Metric(),
 			TargetValue:         target,
 			TotalValue:          total,
+			LatencyPercentile:   latencyPercentile,
 			TargetBurstCapacity: tbc,
 			ActivatorCapacity:   config.ActivatorCapacity,
 			PanicThreshold:      panicThreshold,
+			PanicExitThreshold:  panicExitThresholdPercentage / 100.0,
+			PanicMinDuration:    panicMinDuration,
+			PanicCooldown:       panicCooldown,
+			PanicQueueDepth:     config.PanicQueueDepth,
 			StableWindow:        resources.StableWindow(pa, config),
 			ScaleDownDelay:      scaleDownDelay,
+			IdleTimeout:         idleTimeout,
 			InitialScale:        GetInitialScale(config, pa),
 			Reachable:           pa.Spec.Reachability != asv1a1.ReachabilityUnreachable,
 		},
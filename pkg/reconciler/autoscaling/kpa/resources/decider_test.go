@@ -75,6 +75,18 @@ func TestMakeDecider(t *testing.T) {
 			c.MaxScaleDownRate = 19.88
 			return &c
 		},
+	}, {
+		name: "scale up and scale down rates from annotations",
+		pa:   pa(WithMaxScaleUpRateAnnotation("100"), WithMaxScaleDownRateAnnotation("1.5")),
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100),
+			withScaleUpDownRates(100, 1.5),
+			withDeciderAnnotation(autoscaling.MaxScaleUpRateAnnotationKey, "100"),
+			withDeciderAnnotation(autoscaling.MaxScaleDownRateAnnotationKey, "1.5")),
+		cfgOpt: func(c autoscalerconfig.Config) *autoscalerconfig.Config {
+			c.MaxScaleUpRate = 19.84
+			c.MaxScaleDownRate = 19.88
+			return &c
+		},
 	}, {
 		name: "with container concurrency 1",
 		pa:   pa(WithPAContainerConcurrency(1)),
@@ -136,6 +148,14 @@ func TestMakeDecider(t *testing.T) {
 		name: "with metric annotation",
 		pa:   pa(WithMetricAnnotation("rps")),
 		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100), withMetric("rps"), withMetricAnnotation("rps")),
+	}, {
+		name: "with latency metric",
+		pa: pa(WithMetricAnnotation(autoscaling.Latency), WithLatencyTargetAnnotation("300ms"),
+			WithLatencyPercentileAnnotation("99")),
+		want: decider(withTarget(300), withPanicThreshold(2.0), withTotal(300), withMetric(autoscaling.Latency),
+			withMetricAnnotation(autoscaling.Latency), withLatencyPercentile(99),
+			withDeciderAnnotation(autoscaling.LatencyTargetAnnotationKey, "300ms"),
+			withDeciderAnnotation(autoscaling.LatencyPercentileAnnotationKey, "99")),
 	}, {
 		name: "with scale down delay from config",
 		pa:   pa(),
@@ -152,6 +172,53 @@ func TestMakeDecider(t *testing.T) {
 			return &c
 		},
 		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100), withScaleDownDelay(10*time.Minute), withDeciderScaleDownDelayAnnotation("10m")),
+	}, {
+		name: "with panic hysteresis from config",
+		pa:   pa(),
+		cfgOpt: func(c autoscalerconfig.Config) *autoscalerconfig.Config {
+			c.PanicExitThresholdPercentage = 150
+			c.PanicMinDuration = time.Minute
+			c.PanicCooldown = 30 * time.Second
+			return &c
+		},
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100),
+			func(d *scaling.Decider) {
+				d.Spec.PanicExitThreshold = 1.5
+				d.Spec.PanicMinDuration = time.Minute
+				d.Spec.PanicCooldown = 30 * time.Second
+			}),
+	}, {
+		name: "with panic hysteresis from annotations",
+		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
+			pa.Annotations[autoscaling.PanicExitThresholdPercentageAnnotationKey] = "120"
+			pa.Annotations[autoscaling.PanicMinDurationAnnotationKey] = "2m"
+			pa.Annotations[autoscaling.PanicCooldownAnnotationKey] = "1m"
+		}),
+		cfgOpt: func(c autoscalerconfig.Config) *autoscalerconfig.Config {
+			c.PanicExitThresholdPercentage = 150
+			c.PanicMinDuration = time.Minute
+			c.PanicCooldown = 30 * time.Second
+			return &c
+		},
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100),
+			func(d *scaling.Decider) {
+				d.Spec.PanicExitThreshold = 1.2
+				d.Spec.PanicMinDuration = 2 * time.Minute
+				d.Spec.PanicCooldown = time.Minute
+				d.Annotations[autoscaling.PanicExitThresholdPercentageAnnotationKey] = "120"
+				d.Annotations[autoscaling.PanicMinDurationAnnotationKey] = "2m"
+				d.Annotations[autoscaling.PanicCooldownAnnotationKey] = "1m"
+			}),
+	}, {
+		name: "with idle timeout",
+		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
+			pa.Annotations[autoscaling.IdleTimeoutAnnotationKey] = "30s"
+		}),
+		want: decider(withTarget(100.0), withPanicThreshold(2.0), withTotal(100),
+			func(d *scaling.Decider) {
+				d.Spec.IdleTimeout = 30 * time.Second
+				d.Annotations[autoscaling.IdleTimeoutAnnotationKey] = "30s"
+			}),
 	}, {
 		name: "with initial scale",
 		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
@@ -288,6 +355,7 @@ func decider(options ...deciderOption) *scaling.Decider {
 			},
 		},
 		Spec: scaling.DeciderSpec{
+			Algorithm:           autoscaling.AlgorithmStablePanic,
 			MaxScaleUpRate:      config.MaxScaleUpRate,
 			ScalingMetric:       "concurrency",
 			TargetValue:         100,
@@ -306,6 +374,18 @@ func decider(options ...deciderOption) *scaling.Decider {
 	return m
 }
 
+func withLatencyPercentile(p float64) deciderOption {
+	return func(d *scaling.Decider) {
+		d.Spec.LatencyPercentile = p
+	}
+}
+
+func withDeciderAnnotation(key, value string) deciderOption {
+	return func(d *scaling.Decider) {
+		d.Annotations[key] = value
+	}
+}
+
 func withActivatorCapacity(x float64) deciderOption {
 	return func(d *scaling.Decider) {
 		d.Spec.ActivatorCapacity = x
@@ -19,10 +19,14 @@ package kpa
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"knative.dev/pkg/apis/duck"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/logging"
 
@@ -33,15 +37,24 @@ import (
 	pkgnet "knative.dev/pkg/network"
 	"knative.dev/serving/pkg/activator"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
+	"knative.dev/serving/pkg/autoscaler/metrics"
+	"knative.dev/serving/pkg/autoscaler/scaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
 	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
 	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
 	"knative.dev/serving/pkg/resources"
 
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
 const (
@@ -62,6 +75,22 @@ const (
 	// race the Revision reconciler and scale down the pods before it can actually surface the pod errors.
 	// We should instead do pod failure diagnostics here immediately before scaling down the Deployment.
 	activationTimeoutBuffer = 30 * time.Second
+
+	// podDeletionCostAnnotationKey is the annotation the ReplicaSet controller
+	// uses to pick the pods to delete on scale down, lower costs first.
+	podDeletionCostAnnotationKey = "controller.kubernetes.io/pod-deletion-cost"
+
+	// deletionCostPerRequest is the deletion cost of one concurrent request,
+	// so that fractional concurrencies still order the pods.
+	deletionCostPerRequest = 100
+
+	// maxDeletionCostPatches is the most pods annotated with their deletion
+	// cost in parallel.
+	maxDeletionCostPatches = 10
+
+	// scheduleHorizon is how far ahead we look for the next minScaleSchedule
+	// window. If none opens within the horizon, the PA is re-enqueued after it.
+	scheduleHorizon = 24 * time.Hour
 )
 
 var probeOptions = []interface{}{
@@ -80,8 +109,16 @@ type asyncProber interface {
 type scaler struct {
 	psInformerFactory duck.InformerFactory
 	dynamicClient     dynamic.Interface
+	kubeClient        kubernetes.Interface
+	podsLister        corev1listers.PodLister
 	transport         http.RoundTripper
 
+	// podMetrics reports the concurrency of the individual pods, if known,
+	// to remove the least busy pods on scale down.
+	podMetrics metrics.PodMetricClient
+	// budget limits the scale by the pod budget of the namespace, if not nil.
+	budget *namespaceBudget
+
 	// For sync probes.
 	activatorProbe func(pa *pav1alpha1.PodAutoscaler, transport http.RoundTripper) (bool, error)
 
@@ -91,7 +128,8 @@ type scaler struct {
 }
 
 // newScaler creates a scaler.
-func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, enqueueCB func(interface{}, time.Duration)) *scaler {
+func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, podsLister corev1listers.PodLister,
+	podMetrics metrics.PodMetricClient, budget *namespaceBudget, enqueueCB func(interface{}, time.Duration)) *scaler {
 	logger := logging.FromContext(ctx)
 	transport := pkgnet.NewProberTransport()
 	ks := &scaler{
@@ -99,7 +137,11 @@ func newScaler(ctx context.Context, psInformerFactory duck.InformerFactory, enqu
 		// informer/lister each time.
 		psInformerFactory: psInformerFactory,
 		dynamicClient:     dynamicclient.Get(ctx),
+		kubeClient:        kubeclient.Get(ctx),
+		podsLister:        podsLister,
 		transport:         transport,
+		podMetrics:        podMetrics,
+		budget:            budget,
 
 		// Production setup uses the default probe implementation.
 		activatorProbe: activatorProbe,
@@ -150,6 +192,78 @@ func applyBounds(min, max, x int32) int32 {
 	return x
 }
 
+// scheduledMinScale returns the minimum scale required by the currently open
+// minScaleSchedule windows of the PA and records the entry that determines it
+// in the PA status. The PA is re-enqueued for the time the next window opens
+// or the current one closes.
+func (ks *scaler) scheduledMinScale(pa *pav1alpha1.PodAutoscaler, now time.Time) int32 {
+	pa.Status.ActiveMinScaleSchedule = ""
+	// As with minScale, schedules are ignored if the PA is not reachable.
+	if pa.Spec.Reachability == pav1alpha1.ReachabilityUnreachable {
+		return 0
+	}
+	schedules, ok := pa.MinScaleSchedules()
+	if !ok || len(schedules) == 0 {
+		return 0
+	}
+
+	var (
+		min  int32
+		next = now.Add(scheduleHorizon)
+	)
+	for i := range schedules {
+		ss := &schedules[i]
+		if end, ok := ss.ActiveUntil(now); ok {
+			if end.Before(next) {
+				next = end
+			}
+			if pa.Status.ActiveMinScaleSchedule == "" || ss.MinScale > min {
+				min = ss.MinScale
+				pa.Status.ActiveMinScaleSchedule = ss.Name
+			}
+		} else if start, ok := ss.NextStart(now, scheduleHorizon); ok && start.Before(next) {
+			next = start
+		}
+	}
+	ks.enqueueCB(pa, next.Sub(now))
+	return min
+}
+
+// scaleBounds returns the min and max scale of the PA, the min raised to the
+// min scale of the minScaleSchedule window recorded as active in the PA status
+// by scheduledMinScale, within the max scale.
+func scaleBounds(pa *pav1alpha1.PodAutoscaler, asConfig *autoscalerconfig.Config) (int32, int32) {
+	min, max := pa.ScaleBounds(asConfig)
+	if pa.Status.ActiveMinScaleSchedule == "" {
+		return min, max
+	}
+	schedules, _ := pa.MinScaleSchedules()
+	for _, ss := range schedules {
+		if ss.Name != pa.Status.ActiveMinScaleSchedule || ss.MinScale <= min {
+			continue
+		}
+		min = ss.MinScale
+		if max != 0 && min > max {
+			min = max
+		}
+	}
+	return min, max
+}
+
+// warmPool returns the number of pods to keep ready while the PA is scaled
+// to zero, bounded by the max scale. Unreachable revisions get no traffic to
+// keep pods warm for.
+func warmPool(pa *pav1alpha1.PodAutoscaler, max int32) int32 {
+	if pa.Spec.Reachability == pav1alpha1.ReachabilityUnreachable {
+		return 0
+	}
+	warm, ok := pa.WarmPool()
+	if !ok {
+		return 0
+	}
+	return applyBounds(0, max, warm)
+}
+
 func durationMax(d1, d2 time.Duration) time.Duration {
 	if d1 < d2 {
 		return d2
@@ -195,6 +309,11 @@ func (ks *scaler) handleScaleToZero(ctx context.Context, pa *pav1alpha1.PodAutos
 		// Don't scale-to-zero if the PA is active
 		// but return `(0, false)` to mark PA inactive, instead.
 		sw := aresources.StableWindow(pa, cfgAS)
+		if it, ok := pa.IdleTimeout(); ok {
+			// The idle timeout rather than the stable window decides when
+			// the revision is idle.
+			sw = it
+		}
 		af := pa.Status.ActiveFor(now)
 		if af >= sw {
 			// If SKS is in proxy mode, then there is high probability
@@ -287,6 +406,13 @@ func (ks *scaler) applyScale(ctx context.Context, pa *pav1alpha1.PodAutoscaler,
 		return err
 	}
 
+	if desiredScale > 0 && ps.Spec.Replicas != nil && desiredScale < *ps.Spec.Replicas {
+		// Best effort, the scale target picks the pods to remove anyway.
+		if err := ks.updateDeletionCosts(ctx, pa); err != nil {
+			logger.Warnw("Failed to update the pod deletion costs", zap.Error(err))
+		}
+	}
+
 	psNew := ps.DeepCopy()
 	psNew.Spec.Replicas = &desiredScale
 	patch, err := duck.CreatePatch(ps, psNew)
@@ -308,17 +434,120 @@ func (ks *scaler) applyScale(ctx context.Context, pa *pav1alpha1.PodAutoscaler,
 	return nil
 }
 
+// updateDeletionCosts annotates the pods of the PA's revision with a deletion
+// cost proportional to their concurrency as of the last scrape, so that the
+// pods with the fewest active requests are removed first on scale down.
+// The pods missing from the last scrape are assumed to have the average
+// concurrency of the scraped ones, as the scraper does. Only the pods whose
+// cost changed are patched, up to maxDeletionCostPatches at a time.
+func (ks *scaler) updateDeletionCosts(ctx context.Context, pa *pav1alpha1.PodAutoscaler) error {
+	if ks.podMetrics == nil {
+		return nil
+	}
+	podConcurrency, ok := ks.podMetrics.PodConcurrency(types.NamespacedName{Namespace: pa.Namespace, Name: pa.Name})
+	if !ok || len(podConcurrency) == 0 {
+		return nil
+	}
+	var avg float64
+	for _, c := range podConcurrency {
+		avg += c
+	}
+	avg /= float64(len(podConcurrency))
+
+	var pods []*corev1.Pod
+	podAccessor := resources.NewPodAccessor(ks.podsLister, pa.Namespace, pa.Labels[serving.RevisionLabelKey])
+	if err := podAccessor.ProcessPods(func(p *corev1.Pod) {
+		pods = append(pods, p)
+	}, func(p *corev1.Pod) bool {
+		return p.DeletionTimestamp == nil
+	}); err != nil {
+		return err
+	}
+
+	type podCost struct {
+		pod  *corev1.Pod
+		cost string
+	}
+	var changed []podCost
+	for _, p := range pods {
+		c, ok := podConcurrency[p.Name]
+		if !ok {
+			c = avg
+		}
+		cost := strconv.FormatInt(int64(math.Min(math.Round(c*deletionCostPerRequest), math.MaxInt32)), 10)
+		if p.Annotations[podDeletionCostAnnotationKey] != cost {
+			changed = append(changed, podCost{pod: p, cost: cost})
+		}
+	}
+
+	var grp errgroup.Group
+	idx := atomic.NewInt32(-1)
+	for i := 0; i < len(changed) && i < maxDeletionCostPatches; i++ {
+		grp.Go(func() error {
+			// Keep annotating the other pods if one fails.
+			var err error
+			for {
+				myIdx := int(idx.Inc())
+				if myIdx >= len(changed) {
+					return err
+				}
+				p := changed[myIdx]
+				patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, podDeletionCostAnnotationKey, p.cost)
+				if _, perr := ks.kubeClient.CoreV1().Pods(p.pod.Namespace).Patch(ctx, p.pod.Name, types.MergePatchType,
+					[]byte(patch), metav1.PatchOptions{}); perr != nil {
+					err = fmt.Errorf("failed to annotate pod %s: %w", p.pod.Name, perr)
+				}
+			}
+		})
+	}
+	return grp.Wait()
+}
+
+// availableBudget returns the number of pods the PA can scale to within the
+// pod budget of its namespace, or false if the namespace has no budget.
+func (ks *scaler) availableBudget(ctx context.Context, pa *pav1alpha1.PodAutoscaler) (int32, bool) {
+	if ks.budget == nil {
+		return 0, false
+	}
+	budget, ok, err := ks.budget.available(pa)
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Failed to compute the pod budget of the namespace", zap.Error(err))
+		return 0, false
+	}
+	return budget, ok
+}
+
 // scale attempts to scale the given PA's target reference to the desired scale.
-func (ks *scaler) scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, sks *nv1a1.ServerlessService, desiredScale int32) (int32, error) {
+// decision is the latest autoscaler decision, if known, and is used to explain
+// the scale change.
+func (ks *scaler) scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, sks *nv1a1.ServerlessService,
+	desiredScale int32, decision *scaling.ScaleDecision) (int32, error) {
 	asConfig := config.FromContext(ctx).Autoscaler
 	logger := logging.FromContext(ctx)
 
+	// Evaluate the schedule first, so that PA status is up to date
+	// even if we are not going to scale.
+	scheduledMin := ks.scheduledMinScale(pa, time.Now())
+
+	// The scheduled min scale applies even without metrics, e.g. if the metric
+	// of the PA can't be collected, but then the PA is only ever scaled up.
+	scaleUpOnly := false
 	if desiredScale < 0 && !pa.Status.IsActivating() {
-		logger.Debug("Metrics are not yet being collected.")
-		return desiredScale, nil
+		if scheduledMin == 0 {
+			logger.Debug("Metrics are not yet being collected.")
+			return desiredScale, nil
+		}
+		logger.Debugf("Metrics are not yet being collected, applying the schedule %q", pa.Status.ActiveMinScaleSchedule)
+		desiredScale, scaleUpOnly = 0, true
 	}
 
-	min, max := pa.ScaleBounds(asConfig)
+	min, max := scaleBounds(pa, asConfig)
+	if staticMin, _ := pa.ScaleBounds(asConfig); min > staticMin {
+		logger.Debugf("Adjusting min to meet the schedule %q: %d -> %d",
+			pa.Status.ActiveMinScaleSchedule, staticMin, min)
+	}
+	// The pod budget of the namespace never limits the scale below minScale.
+	minScale := min
 	initialScale := kparesources.GetInitialScale(asConfig, pa)
 	// Log reachability as quoted string, since default value is "".
 	logger.Debugf("MinScale = %d, MaxScale = %d, InitialScale = %d, DesiredScale = %d Reachable = %q",
@@ -336,11 +565,39 @@ func (ks *scaler) scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, sks *
 		desiredScale = newScale
 	}
 
+	// The pod budget of the namespace takes precedence over the initial and max
+	// scale of the PA, but not over its min scale, so that an exhausted budget
+	// doesn't scale a revision that must keep pods to zero.
+	budget, hasBudget := ks.availableBudget(ctx, pa)
+	if hasBudget {
+		budget = intMax(budget, minScale)
+	}
+	if hasBudget && desiredScale > budget {
+		logger.Infof("Limiting desiredScale to the pod budget of the namespace: %d -> %d", desiredScale, budget)
+		pa.Status.MarkScaleLimited("NamespaceBudgetExhausted", fmt.Sprintf(
+			"The desired scale %d exceeds the %d pods available in the namespace.", desiredScale, budget))
+		desiredScale = budget
+	} else {
+		pa.Status.MarkScaleNotLimited()
+	}
+
 	desiredScale, shouldApplyScale := ks.handleScaleToZero(ctx, pa, sks, desiredScale)
 	if !shouldApplyScale {
 		return desiredScale, nil
 	}
 
+	// While the PA is inactive the scale target keeps the warm pool, so that
+	// the activator can forward the first requests to the warm pods, which
+	// are then promoted instead of new pods being created.
+	targetScale := desiredScale
+	if warm := warmPool(pa, max); warm > targetScale && pa.Status.IsInactive() {
+		if hasBudget && warm > budget {
+			warm = budget
+		}
+		logger.Debugf("Adjusting the scale to keep the warm pool: %d -> %d", targetScale, warm)
+		targetScale = warm
+	}
+
 	ps, err := resources.GetScaleResource(ctx, pa.Namespace, pa.Spec.ScaleTargetRef, ks.psInformerFactory)
 	if err != nil {
 		return desiredScale, fmt.Errorf("failed to get scale target %v: %w", pa.Spec.ScaleTargetRef, err)
@@ -350,10 +607,21 @@ func (ks *scaler) scale(ctx context.Context, pa *pav1alpha1.PodAutoscaler, sks *
 	if ps.Spec.Replicas != nil {
 		currentScale = *ps.Spec.Replicas
 	}
-	if desiredScale == currentScale {
+	if targetScale == currentScale || scaleUpOnly && targetScale < currentScale {
 		return desiredScale, nil
 	}
 
-	logger.Infof("Scaling from %d to %d", currentScale, desiredScale)
-	return desiredScale, ks.applyScale(ctx, pa, desiredScale, ps)
+	logger.Infof("Scaling from %d to %d", currentScale, targetScale)
+	if err := ks.applyScale(ctx, pa, targetScale, ps); err != nil {
+		return desiredScale, err
+	}
+	msg := fmt.Sprintf("Scaled from %d to %d", currentScale, targetScale)
+	if targetScale != desiredScale {
+		msg += fmt.Sprintf(" keeping a warm pool of %d", targetScale)
+	}
+	if decision != nil {
+		msg += ", autoscaler " + decision.String()
+	}
+	controller.GetEventRecorder(ctx).Event(pa, corev1.EventTypeNormal, "Scaled", msg)
+	return desiredScale, nil
 }
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	// These are the fake informers we want setup.
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	fakepodsinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
 	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	podscalable "knative.dev/serving/pkg/client/injection/ducks/autoscaling/v1alpha1/podscalable/fake"
@@ -34,6 +36,7 @@ import (
 	nv1a1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/network"
 	_ "knative.dev/pkg/system/testing"
 	"knative.dev/serving/pkg/activator"
@@ -41,21 +44,27 @@ import (
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/autoscaler/scaling"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
 	revisionresources "knative.dev/serving/pkg/reconciler/revision/resources"
 	"knative.dev/serving/pkg/reconciler/revision/resources/names"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/dynamic"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 
 	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/serving/pkg/reconciler/testing/v1"
 	. "knative.dev/serving/pkg/testing"
 )
 
@@ -74,6 +83,7 @@ func TestScaler(t *testing.T) {
 		minScale            int32
 		maxScale            int32
 		wantReplicas        int32
+		wantTargetReplicas  int32
 		wantScaling         bool
 		sks                 SKSOption
 		paMutation          func(*pav1alpha1.PodAutoscaler)
@@ -81,6 +91,7 @@ func TestScaler(t *testing.T) {
 		configMutator       func(*config.Config)
 		wantCBCount         int
 		wantAsyncProbeCount int
+		wantActiveSchedule  string
 	}{{
 		label:         "waits to scale to zero (just before idle period)",
 		startReplicas: 1,
@@ -113,6 +124,27 @@ func TestScaler(t *testing.T) {
 			WithWindowAnnotation(paStableWindow.String())(k)
 			paMarkActive(k, time.Now().Add(-stableWindow))
 		},
+	}, {
+		label:         "waits to scale to zero (just before idle timeout)",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  1,
+		wantScaling:   false,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.IdleTimeoutAnnotationKey] = "10s"
+			paMarkActive(k, time.Now().Add(-10*time.Second).Add(1*time.Second))
+		},
+		wantCBCount: 1,
+	}, {
+		label:         "idle timeout, check for idle timeout rather than window, no probe",
+		startReplicas: 1,
+		scaleTo:       0,
+		wantReplicas:  0,
+		wantScaling:   false,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			k.Annotations[autoscaling.IdleTimeoutAnnotationKey] = "10s"
+			paMarkActive(k, time.Now().Add(-10*time.Second))
+		},
 	}, {
 		label:         "scale to 1 waiting for idle expires",
 		startReplicas: 10,
@@ -463,6 +495,155 @@ func TestScaler(t *testing.T) {
 		configMutator: func(c *config.Config) {
 			c.Autoscaler.AllowZeroInitialScale = true
 		},
+	}, {
+		label:         "min scale schedule window is open",
+		startReplicas: 1,
+		scaleTo:       1,
+		minScale:      2,
+		wantReplicas:  5,
+		wantScaling:   true,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "low * * * * * 1h 3; high * * * * * 1h 5"
+		},
+		wantActiveSchedule: "high",
+	}, {
+		label:         "min scale schedule window is open, capped by max scale",
+		startReplicas: 1,
+		scaleTo:       1,
+		maxScale:      4,
+		wantReplicas:  4,
+		wantScaling:   true,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "always * * * * * 1h 5"
+		},
+		wantActiveSchedule: "always",
+	}, {
+		// E.g. the autoscaler can't compute the metric of the PA.
+		label:         "min scale schedule window is open, metrics unknown",
+		startReplicas: 1,
+		scaleTo:       scaleUnknown,
+		wantReplicas:  5,
+		wantScaling:   true,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "always * * * * * 1h 5"
+		},
+		wantActiveSchedule: "always",
+	}, {
+		label:         "min scale schedule window is open, metrics unknown, no scale down",
+		startReplicas: 8,
+		scaleTo:       scaleUnknown,
+		wantReplicas:  5,
+		wantScaling:   false,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "always * * * * * 1h 5"
+		},
+		wantActiveSchedule: "always",
+	}, {
+		label:         "min scale schedule window is closed, metrics unknown",
+		startReplicas: 1,
+		scaleTo:       scaleUnknown,
+		wantReplicas:  scaleUnknown,
+		wantScaling:   false,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "never 0 0 30 2 * 1h 5"
+		},
+	}, {
+		label:         "min scale schedule window is closed",
+		startReplicas: 1,
+		scaleTo:       1,
+		minScale:      2,
+		wantReplicas:  2,
+		wantScaling:   true,
+		wantCBCount:   1,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "never 0 0 30 2 * 1h 5"
+		},
+	}, {
+		label:              "scale to zero keeps the warm pool",
+		startReplicas:      1,
+		scaleTo:            0,
+		wantReplicas:       0,
+		wantTargetReplicas: 2,
+		wantScaling:        true,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkInactive(k, time.Now().Add(-gracePeriod))
+			WithWarmPoolAnnotation("2")(k)
+		},
+	}, {
+		label:         "warm pool is kept while inactive",
+		startReplicas: 2,
+		scaleTo:       0,
+		wantReplicas:  0,
+		wantScaling:   false,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkInactive(k, time.Now().Add(-gracePeriod))
+			WithWarmPoolAnnotation("2")(k)
+		},
+	}, {
+		label:         "warm pool is bounded by max scale",
+		startReplicas: 2,
+		scaleTo:       0,
+		maxScale:      1,
+		wantReplicas:  0,
+		wantScaling:   true,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkInactive(k, time.Now().Add(-gracePeriod))
+			WithWarmPoolAnnotation("2")(k)
+		},
+		wantTargetReplicas: 1,
+	}, {
+		label:         "warm pods are promoted on activation",
+		startReplicas: 2,
+		scaleTo:       1,
+		wantReplicas:  1,
+		wantScaling:   false,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkInactive(k, time.Now().Add(-gracePeriod))
+			WithWarmPoolAnnotation("2")(k)
+		},
+	}, {
+		label:         "warm pool is not kept when active",
+		startReplicas: 2,
+		scaleTo:       1,
+		wantReplicas:  1,
+		wantScaling:   true,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			WithWarmPoolAnnotation("2")(k)
+		},
+	}, {
+		label:         "warm pool is dropped when unreachable",
+		startReplicas: 2,
+		scaleTo:       0,
+		wantReplicas:  0,
+		wantScaling:   true,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkInactive(k, time.Now().Add(-gracePeriod))
+			WithWarmPoolAnnotation("2")(k)
+			WithReachabilityUnreachable(k)
+		},
+	}, {
+		label:         "min scale schedule is ignored when unreachable",
+		startReplicas: 1,
+		scaleTo:       1,
+		wantReplicas:  1,
+		wantScaling:   false,
+		paMutation: func(k *pav1alpha1.PodAutoscaler) {
+			paMarkActive(k, time.Now())
+			k.Spec.Reachability = pav1alpha1.ReachabilityUnreachable
+			k.Annotations[autoscaling.MinScaleScheduleAnnotationKey] = "always * * * * * 1h 5"
+		},
 	}}
 
 	for _, test := range tests {
@@ -474,7 +655,7 @@ func TestScaler(t *testing.T) {
 			revision := newRevision(ctx, t, fakeservingclient.Get(ctx), test.minScale, test.maxScale)
 			deployment := newDeployment(ctx, t, dynamicClient, names.Deployment(revision), test.startReplicas)
 			cbCount := 0
-			revisionScaler := newScaler(ctx, podscalable.Get(ctx), func(interface{}, time.Duration) {
+			revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), nil /*podMetrics*/, nil /*budget*/, func(interface{}, time.Duration) {
 				cbCount++
 			})
 			if test.proberfunc != nil {
@@ -513,7 +694,7 @@ func TestScaler(t *testing.T) {
 				test.configMutator(cfg)
 			}
 			ctx = config.ToContext(ctx, cfg)
-			desiredScale, err := revisionScaler.scale(ctx, pa, sks, test.scaleTo)
+			desiredScale, err := revisionScaler.scale(ctx, pa, sks, test.scaleTo, nil)
 			if err != nil {
 				t.Error("Scale got an unexpected error:", err)
 			}
@@ -526,13 +707,300 @@ func TestScaler(t *testing.T) {
 			if got, want := cbCount, test.wantCBCount; got != want {
 				t.Errorf("Enqueue callback invoked = %d time, want: %d", got, want)
 			}
+			if got, want := pa.Status.ActiveMinScaleSchedule, test.wantActiveSchedule; got != want {
+				t.Errorf("ActiveMinScaleSchedule = %q, want: %q", got, want)
+			}
 			if test.wantScaling {
 				if !gotScaling {
 					t.Error("want scaling, but got no scaling")
 				}
-				checkReplicas(t, dynamicClient, deployment, test.wantReplicas)
+				wantTarget := test.wantReplicas
+				if test.wantTargetReplicas != 0 {
+					wantTarget = test.wantTargetReplicas
+				}
+				checkReplicas(t, dynamicClient, deployment, wantTarget)
+			}
+		})
+	}
+}
+
+func TestWarmPool(t *testing.T) {
+	tests := []struct {
+		name string
+		pa   *pav1alpha1.PodAutoscaler
+		max  int32
+		want int32
+	}{{
+		name: "no annotation",
+		pa:   kpa(testNamespace, testRevision),
+	}, {
+		name: "annotation",
+		pa:   kpa(testNamespace, testRevision, WithWarmPoolAnnotation("2")),
+		want: 2,
+	}, {
+		name: "bounded by max scale",
+		pa:   kpa(testNamespace, testRevision, WithWarmPoolAnnotation("2")),
+		max:  1,
+		want: 1,
+	}, {
+		name: "reachable",
+		pa:   kpa(testNamespace, testRevision, WithWarmPoolAnnotation("2"), WithReachabilityReachable),
+		want: 2,
+	}, {
+		name: "unreachable",
+		pa:   kpa(testNamespace, testRevision, WithWarmPoolAnnotation("2"), WithReachabilityUnreachable),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := warmPool(test.pa, test.max); got != test.want {
+				t.Errorf("warmPool() = %d, want: %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScaleEvent(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	dynamicClient := fakedynamicclient.Get(ctx)
+
+	revision := newRevision(ctx, t, fakeservingclient.Get(ctx), 0, 0)
+	newDeployment(ctx, t, dynamicClient, names.Deployment(revision), 1)
+	revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), nil /*podMetrics*/, nil /*budget*/, func(interface{}, time.Duration) {})
+	dynamicClient.PrependReactor("patch", "deployments",
+		func(action clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+	pa := newKPA(ctx, t, fakeservingclient.Get(ctx), revision)
+	ctx = config.ToContext(ctx, defaultConfig())
+
+	decision := &scaling.ScaleDecision{
+		ObservedStableValue: 30,
+		ObservedPanicValue:  50,
+		TargetValue:         10,
+		ReadyPodCount:       1,
+		MaxScaleUp:          10,
+		InPanic:             true,
+		RecommendedPodCount: 5,
+		DesiredPodCount:     5,
+	}
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 5, decision); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+
+	recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
+	select {
+	case got := <-recorder.Events:
+		want := "Normal Scaled Scaled from 1 to 5, autoscaler " + decision.String()
+		if got != want {
+			t.Errorf("Event = %q, want: %q", got, want)
+		}
+	default:
+		t.Error("No event was recorded")
+	}
+}
+
+type fakePodMetrics map[string]float64
+
+func (f fakePodMetrics) PodConcurrency(types.NamespacedName) (map[string]float64, bool) {
+	return f, f != nil
+}
+
+func TestScaleDownDeletionCosts(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	dynamicClient := fakedynamicclient.Get(ctx)
+
+	revision := newRevision(ctx, t, fakeservingclient.Get(ctx), 0, 0)
+	newDeployment(ctx, t, dynamicClient, names.Deployment(revision), 3)
+	for _, p := range makeReadyPods(3, testNamespace, testRevision) {
+		pod := p.(*corev1.Pod)
+		fakekubeclient.Get(ctx).CoreV1().Pods(testNamespace).Create(ctx, pod, metav1.CreateOptions{})
+		fakepodsinformer.Get(ctx).Informer().GetIndexer().Add(pod)
+	}
+	// The third pod was not scraped and gets the average concurrency.
+	podMetrics := fakePodMetrics{testRevision + "0": 2, testRevision + "1": 0.5}
+	revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), podMetrics, nil /*budget*/, func(interface{}, time.Duration) {})
+	dynamicClient.PrependReactor("patch", "deployments",
+		func(action clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+	pa := newKPA(ctx, t, fakeservingclient.Get(ctx), revision)
+	paMarkActive(pa, time.Now())
+	ctx = config.ToContext(ctx, defaultConfig())
+
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 2, nil); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+
+	for name, want := range map[string]string{
+		testRevision + "0": "200",
+		testRevision + "1": "50",
+		testRevision + "2": "125",
+	} {
+		pod, err := fakekubeclient.Get(ctx).CoreV1().Pods(testNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", name, err)
+		}
+		if got := pod.Annotations[podDeletionCostAnnotationKey]; got != want {
+			t.Errorf("Pod %s deletion cost = %q, want: %q", name, got, want)
+		}
+	}
+
+	// Scaling up doesn't touch the pods.
+	podMetrics[testRevision+"0"] = 0
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 5, nil); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	pod, err := fakekubeclient.Get(ctx).CoreV1().Pods(testNamespace).Get(ctx, testRevision+"0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got, want := pod.Annotations[podDeletionCostAnnotationKey], "200"; got != want {
+		t.Errorf("Deletion cost after scale up = %q, want: %q", got, want)
+	}
+}
+
+func TestScaleDownDeletionCostsChangedOnly(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	dynamicClient := fakedynamicclient.Get(ctx)
+	kubeClient := fakekubeclient.Get(ctx)
+
+	// More pods than are patched in parallel.
+	const numPods = 3*maxDeletionCostPatches + 1
+	revision := newRevision(ctx, t, fakeservingclient.Get(ctx), 0, 0)
+	newDeployment(ctx, t, dynamicClient, names.Deployment(revision), numPods)
+	podMetrics := fakePodMetrics{}
+	for i, p := range makeReadyPods(numPods, testNamespace, testRevision) {
+		pod := p.(*corev1.Pod)
+		podMetrics[pod.Name] = float64(i)
+		// Half of the pods already have their cost.
+		if i%2 == 0 {
+			pod.Annotations = map[string]string{podDeletionCostAnnotationKey: strconv.Itoa(i * deletionCostPerRequest)}
+		}
+		kubeClient.CoreV1().Pods(testNamespace).Create(ctx, pod, metav1.CreateOptions{})
+		fakepodsinformer.Get(ctx).Informer().GetIndexer().Add(pod)
+	}
+	revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), podMetrics, nil /*budget*/, func(interface{}, time.Duration) {})
+	dynamicClient.PrependReactor("patch", "deployments",
+		func(action clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+	pa := newKPA(ctx, t, fakeservingclient.Get(ctx), revision)
+	paMarkActive(pa, time.Now())
+	ctx = config.ToContext(ctx, defaultConfig())
+
+	podPatches := func() (patched []string) {
+		for _, a := range kubeClient.Actions() {
+			if a.GetVerb() == "patch" && a.GetResource().Resource == "pods" {
+				patched = append(patched, a.(clientgotesting.PatchAction).GetName())
 			}
+		}
+		kubeClient.ClearActions()
+		return patched
+	}
+	podPatches()
+
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 2, nil); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	patched := sets.NewString(podPatches()...)
+	for i := 0; i < numPods; i++ {
+		name := testRevision + strconv.Itoa(i)
+		if got, want := patched.Has(name), i%2 == 1; got != want {
+			t.Errorf("Pod %s patched = %v, want: %v", name, got, want)
+		}
+		pod, err := kubeClient.CoreV1().Pods(testNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", name, err)
+		}
+		if got, want := pod.Annotations[podDeletionCostAnnotationKey], strconv.Itoa(i*deletionCostPerRequest); got != want {
+			t.Errorf("Pod %s deletion cost = %q, want: %q", name, got, want)
+		}
+		fakepodsinformer.Get(ctx).Informer().GetIndexer().Update(pod)
+	}
+
+	// Nothing to patch if the costs didn't change.
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 2, nil); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	if got := podPatches(); len(got) != 0 {
+		t.Errorf("Patched pods %v, want none", got)
+	}
+}
+
+func TestScaleNamespaceBudget(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	dynamicClient := fakedynamicclient.Get(ctx)
+
+	revision := newRevision(ctx, t, fakeservingclient.Get(ctx), 0, 0)
+	deployment := newDeployment(ctx, t, dynamicClient, names.Deployment(revision), 1)
+	listers := NewListers([]runtime.Object{
+		namespace("10"),
+		kpa(testNamespace, "other", withScales(5, 5)),
+	})
+	budget := newNamespaceBudget(listers.GetNamespaceLister(), listers.GetPodAutoscalerLister())
+	revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), nil /*podMetrics*/, budget,
+		func(interface{}, time.Duration) {})
+	dynamicClient.PrependReactor("patch", "deployments",
+		func(action clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
 		})
+	pa := newKPA(ctx, t, fakeservingclient.Get(ctx), revision)
+	paMarkActive(pa, time.Now())
+	ctx = config.ToContext(ctx, defaultConfig())
+
+	got, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 8, nil)
+	if err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	if want := int32(5); got != want {
+		t.Errorf("desiredScale = %d, want: %d", got, want)
+	}
+	checkReplicas(t, dynamicClient, deployment, 5)
+	if !pa.Status.IsScaleLimited() {
+		t.Error("ScaleLimited = false, want true")
+	}
+
+	if _, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 5, nil); err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	if pa.Status.IsScaleLimited() {
+		t.Error("ScaleLimited = true within the budget")
+	}
+}
+
+func TestScaleNamespaceBudgetMinScale(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	dynamicClient := fakedynamicclient.Get(ctx)
+
+	revision := newRevision(ctx, t, fakeservingclient.Get(ctx), 2, 0)
+	deployment := newDeployment(ctx, t, dynamicClient, names.Deployment(revision), 3)
+	listers := NewListers([]runtime.Object{
+		namespace("10"),
+		kpa(testNamespace, "other", withScales(12, 12)),
+	})
+	budget := newNamespaceBudget(listers.GetNamespaceLister(), listers.GetPodAutoscalerLister())
+	revisionScaler := newScaler(ctx, podscalable.Get(ctx), fakepodsinformer.Get(ctx).Lister(), nil /*podMetrics*/, budget,
+		func(interface{}, time.Duration) {})
+	dynamicClient.PrependReactor("patch", "deployments",
+		func(action clientgotesting.Action) (bool, runtime.Object, error) {
+			return true, nil, nil
+		})
+	pa := newKPA(ctx, t, fakeservingclient.Get(ctx), revision)
+	paMarkActive(pa, time.Now())
+	ctx = config.ToContext(ctx, defaultConfig())
+
+	// The budget is exhausted, but the revision keeps its min scale.
+	got, err := revisionScaler.scale(ctx, pa, sks("ns", "name"), 5, nil)
+	if err != nil {
+		t.Fatal("Scale got an unexpected error:", err)
+	}
+	if want := int32(2); got != want {
+		t.Errorf("desiredScale = %d, want: %d", got, want)
+	}
+	checkReplicas(t, dynamicClient, deployment, 2)
+	if !pa.Status.IsScaleLimited() {
+		t.Error("ScaleLimited = false, want true")
 	}
 }
 
@@ -598,7 +1066,7 @@ func TestDisableScaleToZero(t *testing.T) {
 			conf := defaultConfig()
 			conf.Autoscaler.EnableScaleToZero = false
 			ctx = config.ToContext(ctx, conf)
-			desiredScale, err := revisionScaler.scale(ctx, pa, nil /*sks doesn't matter in this test*/, test.scaleTo)
+			desiredScale, err := revisionScaler.scale(ctx, pa, nil /*sks doesn't matter in this test*/, test.scaleTo, nil)
 
 			if err != nil {
 				t.Error("Scale got an unexpected error:", err)
@@ -50,6 +50,18 @@ func MakeMetric(pa *v1alpha1.PodAutoscaler, metricSvc string, config *autoscaler
 	if panicWindow < asconfig.BucketSize {
 		panicWindow = asconfig.BucketSize
 	}
+	halfLife, ok := pa.WindowHalfLife()
+	if !ok {
+		halfLife = config.WindowHalfLife
+	}
+	scrapeStrategy, ok := pa.ScrapeStrategy()
+	if !ok {
+		scrapeStrategy = config.ScrapeStrategy
+	}
+	scrapeSamplePercentage, ok := pa.ScrapeSamplePercentage()
+	if !ok {
+		scrapeSamplePercentage = config.ScrapeSamplePercentage
+	}
 	return &v1alpha1.Metric{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:       pa.Namespace,
@@ -59,9 +71,16 @@ func MakeMetric(pa *v1alpha1.PodAutoscaler, metricSvc string, config *autoscaler
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(pa)},
 		},
 		Spec: v1alpha1.MetricSpec{
-			StableWindow: stableWindow,
-			PanicWindow:  panicWindow,
-			ScrapeTarget: metricSvc,
+			StableWindow:   stableWindow,
+			PanicWindow:    panicWindow,
+			ScrapeTarget:   metricSvc,
+			WindowHalfLife: halfLife,
+
+			ScrapeStrategy:         scrapeStrategy,
+			ScrapeSamplePercentage: scrapeSamplePercentage,
+			ScrapeFullThreshold:    config.ScrapeFullThreshold,
+			ScrapeTimeout:          config.ScrapeTimeout,
+			ScrapeConcurrency:      config.ScrapeConcurrency,
 		},
 	}
 }
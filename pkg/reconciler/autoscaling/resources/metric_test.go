@@ -72,6 +72,13 @@ func TestMakeMetric(t *testing.T) {
 			withScrapeTarget("dansen"),
 			withStableWindow(time.Minute), withPanicWindow(31*time.Second),
 			withPanicWindowPercentageAnnotation("51")),
+	}, {
+		name: "with window half-life",
+		pa:   pa(WithWindowHalfLifeAnnotation("10s")),
+		msn:  "halb",
+		want: metric(
+			withScrapeTarget("halb"), withWindowHalfLife(10*time.Second),
+			withWindowHalfLifeAnnotation("10s")),
 	}}
 
 	for _, tc := range cases {
@@ -83,6 +90,51 @@ func TestMakeMetric(t *testing.T) {
 	}
 }
 
+func TestMakeMetricDefaultWindowHalfLife(t *testing.T) {
+	cfg := *config
+	cfg.WindowHalfLife = 5 * time.Second
+	if got, want := MakeMetric(pa(), "", &cfg).Spec.WindowHalfLife, 5*time.Second; got != want {
+		t.Errorf("WindowHalfLife = %v, want: %v", got, want)
+	}
+	// The annotation takes precedence.
+	thePa := pa(WithWindowHalfLifeAnnotation("0s"))
+	if got := MakeMetric(thePa, "", &cfg).Spec.WindowHalfLife; got != 0 {
+		t.Errorf("WindowHalfLife = %v, want: 0s", got)
+	}
+}
+
+func TestMakeMetricScrapeSettings(t *testing.T) {
+	cfg := *config
+	cfg.ScrapeStrategy = autoscaling.ScrapeStrategyFull
+	cfg.ScrapeSamplePercentage = 10
+	cfg.ScrapeFullThreshold = 5
+	cfg.ScrapeTimeout = time.Second
+	cfg.ScrapeConcurrency = 4
+	want := v1alpha1.MetricSpec{
+		ScrapeStrategy:         autoscaling.ScrapeStrategyFull,
+		ScrapeSamplePercentage: 10,
+		ScrapeFullThreshold:    5,
+		ScrapeTimeout:          time.Second,
+		ScrapeConcurrency:      4,
+	}
+	got := MakeMetric(pa(), "", &cfg).Spec
+	got.StableWindow, got.PanicWindow = 0, 0
+	if !cmp.Equal(got, want) {
+		t.Errorf("Spec mismatch (-want,+got):\n%s", cmp.Diff(want, got))
+	}
+
+	// The annotations take precedence.
+	thePa := pa(func(pa *v1alpha1.PodAutoscaler) {
+		pa.Annotations[autoscaling.ScrapeStrategyAnnotationKey] = autoscaling.ScrapeStrategyPercentage
+		pa.Annotations[autoscaling.ScrapeSamplePercentageAnnotationKey] = "25"
+	})
+	got = MakeMetric(thePa, "", &cfg).Spec
+	if got.ScrapeStrategy != autoscaling.ScrapeStrategyPercentage || got.ScrapeSamplePercentage != 25 {
+		t.Errorf("Scrape strategy = %s, %v, want: %s, 25", got.ScrapeStrategy, got.ScrapeSamplePercentage,
+			autoscaling.ScrapeStrategyPercentage)
+	}
+}
+
 func TestStableWindow(t *testing.T) {
 	// Not set on PA.
 	thePa := pa()
@@ -116,6 +168,18 @@ func withWindowAnnotation(window string) MetricOption {
 	}
 }
 
+func withWindowHalfLife(halfLife time.Duration) MetricOption {
+	return func(metric *v1alpha1.Metric) {
+		metric.Spec.WindowHalfLife = halfLife
+	}
+}
+
+func withWindowHalfLifeAnnotation(halfLife string) MetricOption {
+	return func(metric *v1alpha1.Metric) {
+		metric.Annotations[autoscaling.WindowHalfLifeAnnotationKey] = halfLife
+	}
+}
+
 func withPanicWindowPercentageAnnotation(percentage string) MetricOption {
 	return func(metric *v1alpha1.Metric) {
 		metric.Annotations[autoscaling.PanicWindowPercentageAnnotationKey] = percentage
@@ -24,6 +24,10 @@ import (
 	"knative.dev/serving/pkg/autoscaler/config/autoscalerconfig"
 )
 
+// resourceUtilizationTargetDefault is the default target utilization in
+// percent for the cpu and memory metrics.
+const resourceUtilizationTargetDefault = 80
+
 // ResolveMetricTarget takes scaling metric knobs from multiple locations
 // and resolves them to the final value to be used by the autoscaler.
 // `target` is the target value of scaling metric that we autoscaler will aim for;
@@ -31,10 +35,25 @@ import (
 func ResolveMetricTarget(pa *v1alpha1.PodAutoscaler, config *autoscalerconfig.Config) (target, total float64) {
 	tu := 0.
 
-	switch pa.Metric() {
-	case autoscaling.RPS:
+	switch metric := pa.Metric(); {
+	case metric == autoscaling.Latency:
+		// The latency target is set in milliseconds via its own annotation,
+		// which is required by the webhook, and is never partially utilized.
+		lt, _ := pa.LatencyTarget()
+		target = math.Max(autoscaling.TargetMin, float64(lt.Milliseconds()))
+		return target, target
+	case metric == autoscaling.RPS:
 		total = config.RPSTargetDefault
 		tu = config.TargetUtilization
+	case metric == autoscaling.CPU || metric == autoscaling.Memory:
+		// Resource metrics are reported in percent of the requests of the
+		// user container, and target the same utilization as the HPA does by default.
+		total = resourceUtilizationTargetDefault
+		tu = 1
+	case autoscaling.IsCustomMetric(metric):
+		// Custom metrics have their total set via the target annotation,
+		// which is fully utilized unless configured otherwise.
+		tu = 1
 	default:
 		// Concurrency is used by default
 		total = float64(pa.Spec.ContainerConcurrency)
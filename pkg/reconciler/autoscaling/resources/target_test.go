@@ -170,6 +170,31 @@ func TestResolveMetricTarget(t *testing.T) {
 		pa:         pa(WithMetricAnnotation(autoscaling.RPS), WithTargetAnnotation("300")),
 		wantTarget: 210,
 		wantTotal:  300,
+	}, {
+		name:       "CPU: defaults",
+		pa:         pa(WithMetricAnnotation(autoscaling.CPU), WithPAContainerConcurrency(1)),
+		wantTarget: 80,
+		wantTotal:  80,
+	}, {
+		name:       "memory: with target annotation 60",
+		pa:         pa(WithMetricAnnotation(autoscaling.Memory), WithTargetAnnotation("60")),
+		wantTarget: 60,
+		wantTotal:  60,
+	}, {
+		name:       "latency: with latency target annotation",
+		pa:         pa(WithMetricAnnotation(autoscaling.Latency), WithLatencyTargetAnnotation("250ms"), WithPAContainerConcurrency(1)),
+		wantTarget: 250,
+		wantTotal:  250,
+	}, {
+		name:       "custom metric",
+		pa:         pa(WithMetricAnnotation("queue_backlog"), WithTargetAnnotation("50"), WithPAContainerConcurrency(1)),
+		wantTarget: 50,
+		wantTotal:  50,
+	}, {
+		name:       "custom metric with TU annotation 80%",
+		pa:         pa(WithMetricAnnotation("queue_backlog"), WithTargetAnnotation("50"), WithTUAnnotation("80")),
+		wantTarget: 40,
+		wantTotal:  50,
 	}}
 
 	for _, tc := range cases {
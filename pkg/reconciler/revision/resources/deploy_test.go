@@ -168,6 +168,21 @@ var (
 		}, {
 			Name:  "METRICS_COLLECTOR_ADDRESS",
 			Value: "",
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC",
+			Value: "",
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC_PATH",
+			Value: "",
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC_PORT",
+			Value: "",
+		}, {
+			Name:  "SERVING_REPORT_LATENCY",
+			Value: "false",
+		}, {
+			Name:  "SERVING_PUSH_STATS",
+			Value: "false",
 		}},
 	}
 
@@ -1094,6 +1109,44 @@ func TestMissingProbeError(t *testing.T) {
 	}
 }
 
+func TestMakeDeploymentNoHostPath(t *testing.T) {
+	// The pods must pass the baseline Pod Security Standard, whatever the
+	// revision scales on.
+	for _, metric := range []string{autoscaling.Concurrency, autoscaling.RPS, autoscaling.CPU,
+		autoscaling.Memory, autoscaling.Latency, "custom"} {
+		t.Run(metric, func(t *testing.T) {
+			rev := revision("bar", "foo",
+				withContainers([]corev1.Container{{
+					Name:           servingContainerName,
+					Image:          "busybox",
+					ReadinessProbe: withTCPReadinessProbe(v1.DefaultUserPort),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}}),
+				func(revision *v1.Revision) {
+					revision.Annotations = map[string]string{
+						autoscaling.MetricAnnotationKey: metric,
+					}
+				})
+			cfg := (&revCfg).DeepCopy()
+			cfg.Observability = &metrics.ObservabilityConfig{EnableVarLogCollection: true}
+			got, err := MakeDeployment(rev, cfg)
+			if err != nil {
+				t.Fatal("MakeDeployment returned error:", err)
+			}
+			for _, v := range got.Spec.Template.Spec.Volumes {
+				if v.HostPath != nil {
+					t.Errorf("Volume %q is a hostPath volume: %#v", v.Name, v.HostPath)
+				}
+			}
+		})
+	}
+}
+
 func TestMakeDeployment(t *testing.T) {
 	tests := []struct {
 		name      string
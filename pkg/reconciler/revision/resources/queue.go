@@ -32,6 +32,7 @@ import (
 	"knative.dev/pkg/profiling"
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 	"knative.dev/serving/pkg/deployment"
@@ -358,10 +359,60 @@ func makeQueueContainer(rev *v1.Revision, cfg *config.Config) (*corev1.Container
 		}, {
 			Name:  "METRICS_COLLECTOR_ADDRESS",
 			Value: cfg.Observability.MetricsCollectorAddress,
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC",
+			Value: customMetric(rev),
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC_PATH",
+			Value: customMetricAnnotation(rev, autoscaling.MetricPathAnnotationKey),
+		}, {
+			Name:  "SERVING_CUSTOM_METRIC_PORT",
+			Value: customMetricAnnotation(rev, autoscaling.MetricPortAnnotationKey),
+		}, {
+			Name:  "SERVING_REPORT_LATENCY",
+			Value: strconv.FormatBool(rev.Annotations[autoscaling.MetricAnnotationKey] == autoscaling.Latency),
+		}, {
+			Name:  "SERVING_PUSH_STATS",
+			Value: strconv.FormatBool(pushesStats(rev, cfg)),
 		}},
 	}, nil
 }
 
+// pushesStats returns true if the queue-proxy pushes its stats to the
+// autoscaler. The pods of revisions scaling on cpu or memory are scraped, as
+// the autoscaler adds their usage, read from the resource metrics API, to the
+// scraped stats.
+func pushesStats(rev *v1.Revision, cfg *config.Config) bool {
+	switch rev.Annotations[autoscaling.MetricAnnotationKey] {
+	case autoscaling.CPU, autoscaling.Memory:
+		return false
+	}
+	return cfg.Autoscaler.EnableStatsPush
+}
+
+// customMetric returns the custom metric the KPA scales the revision on, if any.
+// The HPA reads custom metrics from the metrics APIs, so the queue-proxy
+// doesn't need to scrape them.
+func customMetric(rev *v1.Revision) string {
+	if rev.Annotations[autoscaling.ClassAnnotationKey] == autoscaling.HPA {
+		return ""
+	}
+	if m := rev.Annotations[autoscaling.MetricAnnotationKey]; autoscaling.IsCustomMetric(m) {
+		return m
+	}
+	return ""
+}
+
+// customMetricAnnotation returns the value of the annotation describing where
+// the custom metric is scraped from, if the queue-proxy scrapes one. The
+// queue-proxy defaults what isn't set.
+func customMetricAnnotation(rev *v1.Revision, key string) string {
+	if customMetric(rev) == "" {
+		return ""
+	}
+	return rev.Annotations[key]
+}
+
 func applyReadinessProbeDefaults(p *corev1.Probe, port int32) {
 	switch {
 	case p == nil:
@@ -39,6 +39,7 @@ import (
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
 	tracingconfig "knative.dev/pkg/tracing/config"
+	"knative.dev/serving/pkg/apis/autoscaling"
 	apicfg "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
@@ -99,6 +100,7 @@ func TestMakeQueueContainer(t *testing.T) {
 		nc   network.Config
 		oc   metrics.ObservabilityConfig
 		dc   deployment.Config
+		ac   autoscalerconfig.Config
 		want corev1.Container
 	}{{
 		name: "autoscaler single",
@@ -110,6 +112,91 @@ func TestMakeQueueContainer(t *testing.T) {
 				"CONTAINER_CONCURRENCY": "1",
 			})
 		}),
+	}, {
+		name: "custom metric",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricAnnotationKey: "queue_backlog",
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SERVING_CUSTOM_METRIC": "queue_backlog",
+			})
+		}),
+	}, {
+		name: "custom metric with path and port",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricAnnotationKey:     "queue_backlog",
+					autoscaling.MetricPathAnnotationKey: "/stats/prometheus",
+					autoscaling.MetricPortAnnotationKey: "9090",
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SERVING_CUSTOM_METRIC":      "queue_backlog",
+				"SERVING_CUSTOM_METRIC_PATH": "/stats/prometheus",
+				"SERVING_CUSTOM_METRIC_PORT": "9090",
+			})
+		}),
+	}, {
+		name: "metric path without custom metric",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricPathAnnotationKey: "/stats/prometheus",
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+		}),
+	}, {
+		name: "custom metric with hpa class",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.ClassAnnotationKey:      autoscaling.HPA,
+					autoscaling.MetricAnnotationKey:     "queue_backlog",
+					autoscaling.MetricTypeAnnotationKey: autoscaling.MetricTypePods,
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+		}),
+	}, {
+		name: "latency metric",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricAnnotationKey:        autoscaling.Latency,
+					autoscaling.LatencyTargetAnnotationKey: "100ms",
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SERVING_REPORT_LATENCY": "true",
+			})
+		}),
+	}, {
+		name: "builtin metric",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricAnnotationKey: autoscaling.RPS,
+				}
+			}),
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+		}),
 	}, {
 		name: "custom sidecar image, container port, protocol",
 		rev: revision("bar", "foo",
@@ -309,6 +396,33 @@ func TestMakeQueueContainer(t *testing.T) {
 				"METRICS_COLLECTOR_ADDRESS":       "otel:55678",
 			})
 		}),
+	}, {
+		name: "stats push",
+		rev: revision("bar", "foo",
+			withContainers(containers)),
+		ac: autoscalerconfig.Config{
+			EnableStatsPush: true,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{
+				"SERVING_PUSH_STATS": "true",
+			})
+		}),
+	}, {
+		name: "stats push with cpu metric",
+		rev: revision("bar", "foo",
+			withContainers(containers),
+			func(revision *v1.Revision) {
+				revision.Annotations = map[string]string{
+					autoscaling.MetricAnnotationKey: autoscaling.CPU,
+				}
+			}),
+		ac: autoscalerconfig.Config{
+			EnableStatsPush: true,
+		},
+		want: queueContainer(func(c *corev1.Container) {
+			c.Env = env(map[string]string{})
+		}),
 	}}
 
 	for _, test := range tests {
@@ -322,6 +436,9 @@ func TestMakeQueueContainer(t *testing.T) {
 				}
 			}
 			cfg := &config.Config{
+				Config: &apicfg.Config{
+					Autoscaler: &test.ac,
+				},
 				Tracing:       &traceConfig,
 				Logging:       &test.lc,
 				Observability: &test.oc,
@@ -785,6 +902,11 @@ var defaultEnv = map[string]string{
 	"ENABLE_PROFILING":                      "false",
 	"METRICS_DOMAIN":                        metrics.Domain(),
 	"METRICS_COLLECTOR_ADDRESS":             "",
+	"SERVING_CUSTOM_METRIC":                 "",
+	"SERVING_CUSTOM_METRIC_PATH":            "",
+	"SERVING_CUSTOM_METRIC_PORT":            "",
+	"SERVING_REPORT_LATENCY":                "false",
+	"SERVING_PUSH_STATS":                    "false",
 	"QUEUE_SERVING_PORT":                    "8012",
 	"REVISION_TIMEOUT_SECONDS":              "45",
 	"SERVING_CONFIGURATION":                 "",
@@ -184,6 +184,34 @@ func WithWindowAnnotation(window string) PodAutoscalerOption {
 	return withAnnotationValue(autoscaling.WindowAnnotationKey, window)
 }
 
+// WithWindowHalfLifeAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/windowHalfLife annotation to the
+// provided value.
+func WithWindowHalfLifeAnnotation(halfLife string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.WindowHalfLifeAnnotationKey, halfLife)
+}
+
+// WithWarmPoolAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/warmPool annotation to the
+// provided value.
+func WithWarmPoolAnnotation(size string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.WarmPoolAnnotationKey, size)
+}
+
+// WithMaxScaleUpRateAnnotation returns a PodAutoscalerOption
+// which sets the PodAutoscaler autoscaling.knative.dev/maxScaleUpRate
+// annotation to the provided value.
+func WithMaxScaleUpRateAnnotation(rate string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.MaxScaleUpRateAnnotationKey, rate)
+}
+
+// WithMaxScaleDownRateAnnotation returns a PodAutoscalerOption
+// which sets the PodAutoscaler autoscaling.knative.dev/maxScaleDownRate
+// annotation to the provided value.
+func WithMaxScaleDownRateAnnotation(rate string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.MaxScaleDownRateAnnotationKey, rate)
+}
+
 // WithPanicThresholdPercentageAnnotation returns a PodAutoscalerOption
 // which sets the PodAutoscaler
 // autoscaling.knative.dev/panicThresholdPercentage annotation to the
@@ -205,6 +233,20 @@ func WithMetricAnnotation(metric string) PodAutoscalerOption {
 	return withAnnotationValue(autoscaling.MetricAnnotationKey, metric)
 }
 
+// WithLatencyTargetAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/latencyTarget annotation to the
+// provided value.
+func WithLatencyTargetAnnotation(target string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.LatencyTargetAnnotationKey, target)
+}
+
+// WithLatencyPercentileAnnotation returns a PodAutoscalerOption which sets
+// the PodAutoscaler autoscaling.knative.dev/latencyPercentile annotation to
+// the provided value.
+func WithLatencyPercentileAnnotation(percentile string) PodAutoscalerOption {
+	return withAnnotationValue(autoscaling.LatencyPercentileAnnotationKey, percentile)
+}
+
 // WithObservedGeneration returns a PodAutoScalerOption which sets
 // the Status.ObservedGeneration field to the given generation.
 func WithObservedGeneration(gen int64) PodAutoscalerOption {